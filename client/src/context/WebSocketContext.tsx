@@ -32,6 +32,12 @@ export const Events = {
   SYNC_START: "sync_start",
   SYNC_COMPLETE: "sync_complete",
   SYNC_ERROR: "sync_error",
+  SYNC_PROGRESS: "sync_progress",
+  SUBSCRIBE: "subscribe",
+  UNSUBSCRIBE: "unsubscribe",
+  SUBSCRIBED: "subscribed",
+  UNSUBSCRIBED: "unsubscribed",
+  SUBSCRIPTION_FAILURE: "subscription_failure",
   ADMIN_DOWNLOAD_PROGRESS: "admin_download_progress",
   ADMIN_DOWNLOAD_STATUS: "admin_download_status",
   ADMIN_PROCESSING_PROGRESS: "admin_processing_progress",
@@ -44,11 +50,25 @@ export interface WebSocketMessage {
   id: string;
   service?: ServiceType | string;
   event: string;
+  // Payload version of the event, see docs/events.schema.json for the payload of each event
+  version?: number;
   userId?: string;
   payload?: Record<string, unknown>;
   timestamp: string;
+  // Replay stream and position, set on messages the server keeps for replay after a reconnect
+  stream?: "user" | "admin";
+  sequence?: string;
+  // Topic the message was published to, "<kind>:<id>" such as "dump:2024-01"
+  topic?: string;
 }
 
+// Sequences are Valkey stream IDs, "<milliseconds>-<counter>"
+const compareSequences = (a: string, b: string): number => {
+  const [aMs, aSeq] = a.split("-").map(Number);
+  const [bMs, bSeq] = b.split("-").map(Number);
+  return aMs !== bMs ? aMs - bMs : aSeq - bSeq;
+};
+
 export enum ConnectionState {
   Connecting = "connecting",
   Connected = "connected",
@@ -66,6 +86,9 @@ interface WebSocketContextValue {
   lastError: () => string | null;
   lastMessage: () => string;
   sendMessage: (message: object) => void;
+  // Follow a topic, kept across reconnects until unsubscribed
+  subscribe: (topic: string) => void;
+  unsubscribe: (topic: string) => void;
   reconnect: () => void;
 }
 
@@ -86,6 +109,11 @@ export function WebSocketProvider(props: WebSocketProviderProps) {
   );
   const [wsAuthenticated, setWsAuthenticated] = createSignal<boolean>(false);
 
+  // Last sequence seen per replay stream, sent when reconnecting to replay missed messages
+  const lastSequences: Partial<Record<"user" | "admin", string>> = {};
+  // Subscriptions belong to a connection, they are sent again after every authentication
+  const topics = new Set<string>();
+
   const log = (..._args: unknown[]) => {
     logger.debug(_args[0] as string, {
       component: "WebSocket",
@@ -114,7 +142,11 @@ export function WebSocketProvider(props: WebSocketProviderProps) {
       id: crypto.randomUUID(),
       service: "system",
       event: Events.AUTH_RESPONSE,
-      payload: { token },
+      payload: {
+        token,
+        lastSequence: lastSequences.user,
+        lastAdminSequence: lastSequences.admin,
+      },
       timestamp: new Date().toISOString(),
     };
 
@@ -129,6 +161,16 @@ export function WebSocketProvider(props: WebSocketProviderProps) {
     try {
       const message: WebSocketMessage = JSON.parse(event.data);
       log("Received message:", message);
+
+      if (message.stream && message.sequence) {
+        const lastSequence = lastSequences[message.stream];
+        if (lastSequence && compareSequences(message.sequence, lastSequence) <= 0) {
+          // Already seen, replayed messages can also arrive live while reconnecting
+          return;
+        }
+        lastSequences[message.stream] = message.sequence;
+      }
+
       setLastMessage(event.data);
 
       // Validate basic message structure
@@ -146,6 +188,16 @@ export function WebSocketProvider(props: WebSocketProviderProps) {
           log("Authentication successful");
           setWsAuthenticated(true);
           setLastError(null);
+          for (const topic of topics) {
+            sendTopicMessage(Events.SUBSCRIBE, topic);
+          }
+          break;
+
+        case Events.SUBSCRIPTION_FAILURE:
+          log("Topic subscription failed:", message.payload?.topic, message.payload?.reason);
+          if (typeof message.payload?.topic === "string") {
+            topics.delete(message.payload.topic);
+          }
           break;
 
         case Events.AUTH_FAILURE:
@@ -293,6 +345,31 @@ export function WebSocketProvider(props: WebSocketProviderProps) {
     }
   };
 
+  const sendTopicMessage = (event: string, topic: string) => {
+    sendMessage({
+      id: crypto.randomUUID(),
+      service: "system",
+      event,
+      payload: { topic },
+      timestamp: new Date().toISOString(),
+    });
+  };
+
+  const subscribe = (topic: string) => {
+    if (topics.has(topic)) return;
+    topics.add(topic);
+    if (wsAuthenticated()) {
+      sendTopicMessage(Events.SUBSCRIBE, topic);
+    }
+  };
+
+  const unsubscribe = (topic: string) => {
+    if (!topics.delete(topic)) return;
+    if (wsAuthenticated()) {
+      sendTopicMessage(Events.UNSUBSCRIBE, topic);
+    }
+  };
+
   const reconnect = () => {
     const ws = wsInstance();
     if (ws && "reconnect" in ws && typeof ws.reconnect === "function") {
@@ -337,6 +414,8 @@ export function WebSocketProvider(props: WebSocketProviderProps) {
     lastError,
     lastMessage,
     sendMessage,
+    subscribe,
+    unsubscribe,
     reconnect,
   };
 
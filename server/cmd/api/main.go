@@ -69,6 +69,8 @@ func main() {
 	}
 	log.Info("Scheduler service started successfully")
 
+	app.Services.Webhook.Start(ctx)
+
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"waugzee/config"
+	"waugzee/internal/database"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/repositories"
+	"waugzee/internal/services"
+)
+
+const usage = `usage: dump <command> [arguments]
+
+commands:
+  import [-skip-verify] [-process] <YYYY-MM>
+        register dump files already in storage for a year-month and queue them for processing
+`
+
+func main() {
+	log := logger.New("dump")
+	log = log.Function("main")
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	config, err := config.New()
+	if err != nil {
+		log.Er("failed to initialize config", err)
+		os.Exit(1)
+	}
+
+	db, err := database.New(config)
+	if err != nil {
+		log.Er("failed to create database", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Er("failed to close database", err)
+		}
+	}()
+
+	switch os.Args[1] {
+	case "import":
+		err = importDump(context.Background(), db, config, os.Args[2:], log)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Er("dump command failed", err, "command", os.Args[1])
+		os.Exit(1)
+	}
+}
+
+// importDump registers the files of one year-month and, with -process, parses them right away
+// instead of leaving them for the next scheduled parser run
+func importDump(
+	ctx context.Context,
+	db database.DB,
+	config config.Config,
+	args []string,
+	log logger.Logger,
+) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	skipVerify := flags.Bool("skip-verify", false, "register the files without validating them against CHECKSUM.txt")
+	process := flags.Bool("process", false, "parse the dump immediately instead of waiting for the scheduled job")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	yearMonth := flags.Arg(0)
+
+	repos := repositories.New(db)
+
+	dumpStorage, err := services.NewDumpStorage(config)
+	if err != nil {
+		return log.Err("failed to create dump storage", err)
+	}
+	downloadService := services.NewDownloadService(config, nil, dumpStorage)
+	importService := services.NewDumpImportService(repos, downloadService, dumpStorage)
+
+	record, err := importService.RegisterExistingDump(ctx, yearMonth, services.DumpImportOptions{
+		SkipChecksumValidation: *skipVerify,
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Dump registered", "yearMonth", record.YearMonth, "status", record.Status)
+
+	if !*process {
+		return nil
+	}
+
+	log.Info("Processing dump", "yearMonth", yearMonth)
+	parser := services.NewDiscogsXMLParserService(repos, db, nil, dumpStorage, config)
+	if err := parser.ParseXMLFiles(ctx); err != nil {
+		return log.Err("failed to process dump", err, "yearMonth", yearMonth)
+	}
+
+	log.Info("Dump processed", "yearMonth", yearMonth)
+	return nil
+}
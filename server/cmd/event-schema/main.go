@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"waugzee/internal/events"
+	logger "github.com/Bparsons0904/goLogger"
+)
+
+// Writes the JSON Schema of the registered events, run from the server directory after changing
+// an event payload:
+//
+//	go run ./cmd/event-schema
+func main() {
+	log := logger.New("event-schema").Function("main")
+
+	out := flag.String("out", "../docs/events.schema.json", "file to write the schema to")
+	flag.Parse()
+
+	schema, err := events.JSONSchema()
+	if err != nil {
+		log.Er("failed to generate event schema", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(*out, schema, 0o644); err != nil {
+		log.Er("failed to write event schema", err, "path", *out)
+		os.Exit(1)
+	}
+
+	log.Info("Event schema written", "path", *out, "events", len(events.Definitions()))
+}
@@ -39,6 +39,13 @@ var MODELS_TO_MIGRATE = []any{
 	&UserConfiguration{},
 	&DiscogsDataProcessing{},
 	&DailyRecommendation{},
+	&DumpDeadLetter{},
+	&JobRun{},
+	&DiscogsDataProcessingTransition{},
+	&ApiToken{},
+	&UserRole{},
+	&Webhook{},
+	&WebhookDelivery{},
 }
 
 func main() {
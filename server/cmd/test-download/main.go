@@ -45,8 +45,13 @@ func main() {
 		}
 	}()
 
-	// Initialize download service
-	downloadService := services.NewDownloadService(config, eventBus)
+	// Initialize dump storage and download service
+	dumpStorage, err := services.NewDumpStorage(config)
+	if err != nil {
+		log.Er("failed to create dump storage", err)
+		os.Exit(1)
+	}
+	downloadService := services.NewDownloadService(config, eventBus, dumpStorage)
 
 	// Create download job
 	downloadJob := jobs.NewDiscogsDownloadJob(
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"strings"
+
 	logger "github.com/Bparsons0904/goLogger"
 
 	"github.com/spf13/viper"
@@ -19,12 +21,83 @@ type Config struct {
 	DatabaseCachePort    int    `mapstructure:"DB_CACHE_PORT"`
 	DatabaseCacheReset   int    `mapstructure:"DB_CACHE_RESET"`
 	CorsAllowOrigins     string `mapstructure:"CORS_ALLOW_ORIGINS"`
+	AuthProvider         string `mapstructure:"AUTH_PROVIDER"`
 	ZitadelClientID      string `mapstructure:"ZITADEL_CLIENT_ID"`
 	ZitadelInstanceURL   string `mapstructure:"ZITADEL_INSTANCE_URL"`
 	ZitadelPrivateKey    string `mapstructure:"ZITADEL_PRIVATE_KEY"`
 	ZitadelKeyID         string `mapstructure:"ZITADEL_KEY_ID"`
 	ZitadelClientIDM2M   string `mapstructure:"ZITADEL_CLIENT_ID_M2M"`
 	VictoriaLogsURL      string `mapstructure:"VICTORIA_LOGS_URL"`
+
+	// Generic OIDC provider used when AUTH_PROVIDER is "oidc", such as Keycloak, Authentik or Dex.
+	// OIDC_PROVIDER_NAME is recorded on users signing in, defaults to "oidc".
+	OIDCIssuerURL    string `mapstructure:"OIDC_ISSUER_URL"`
+	OIDCClientID     string `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDCProviderName string `mapstructure:"OIDC_PROVIDER_NAME"`
+
+	// Signing secret for AUTH_PROVIDER "local", empty generates one per process
+	LocalAuthSecret string `mapstructure:"LOCAL_AUTH_SECRET"`
+
+	// Roles: the role signed in users get when none of their claims is mapped, defaults to "member",
+	// and how role or group claims from the identity provider map to app roles, as "claim=role"
+	// pairs separated by commas. Claims not in the mapping grant nothing.
+	AuthDefaultRole      string `mapstructure:"AUTH_DEFAULT_ROLE"`
+	AuthRoleClaimMapping string `mapstructure:"AUTH_ROLE_CLAIM_MAPPING"`
+
+	// Minutes websocket messages are kept for replay to reconnecting clients, zero uses the
+	// built-in default
+	WebsocketReplayRetentionMinutes int `mapstructure:"WEBSOCKET_REPLAY_RETENTION_MINUTES"`
+
+	// Dump ingestion pipeline tuning, zero uses the built-in defaults
+	DiscogsConverterWorkers int     `mapstructure:"DISCOGS_CONVERTER_WORKERS"`
+	DiscogsBatchWriters     int     `mapstructure:"DISCOGS_BATCH_WRITERS"`
+	DiscogsMaxFailureRatio  float64 `mapstructure:"DISCOGS_MAX_FAILURE_RATIO"`
+
+	// Minutes a downloading or processing record may go without a heartbeat before the watchdog
+	// fails it, zero uses the built-in default
+	DiscogsHeartbeatTimeoutMinutes int `mapstructure:"DISCOGS_HEARTBEAT_TIMEOUT_MINUTES"`
+
+	// Release freshness: days a synced release stays fresh and how many stale releases are refreshed
+	// per user per job run, zero uses the built-in defaults
+	ReleaseStaleAfterDays   int `mapstructure:"RELEASE_STALE_AFTER_DAYS"`
+	ReleaseRefreshBatchSize int `mapstructure:"RELEASE_REFRESH_BATCH_SIZE"`
+
+	// Where dumps are downloaded from: a mirror base URL (http, https or file) or a local directory
+	// laid out like the Discogs bucket, empty uses the public Discogs bucket
+	DiscogsDumpSource string `mapstructure:"DISCOGS_DUMP_SOURCE"`
+
+	// Dump file storage, "local" (default) or "s3" for any S3-compatible bucket
+	DiscogsStorageBackend string `mapstructure:"DISCOGS_STORAGE_BACKEND"`
+	DiscogsS3Endpoint     string `mapstructure:"DISCOGS_S3_ENDPOINT"`
+	DiscogsS3Bucket       string `mapstructure:"DISCOGS_S3_BUCKET"`
+	DiscogsS3Region       string `mapstructure:"DISCOGS_S3_REGION"`
+	DiscogsS3AccessKey    string `mapstructure:"DISCOGS_S3_ACCESS_KEY"`
+	DiscogsS3SecretKey    string `mapstructure:"DISCOGS_S3_SECRET_KEY"`
+	DiscogsS3UseSSL       bool   `mapstructure:"DISCOGS_S3_USE_SSL"`
+	DiscogsS3Prefix       string `mapstructure:"DISCOGS_S3_PREFIX"`
+
+	// Cover image cache storage, "local" (default) or "s3". The s3 backend connects with the
+	// DISCOGS_S3_* settings and uses its own bucket and prefix.
+	ImageStorageBackend string `mapstructure:"IMAGE_STORAGE_BACKEND"`
+	ImageStorageDir     string `mapstructure:"IMAGE_STORAGE_DIR"`
+	ImageS3Bucket       string `mapstructure:"IMAGE_S3_BUCKET"`
+	ImageS3Prefix       string `mapstructure:"IMAGE_S3_PREFIX"`
+
+	// Cron expressions (UTC) for scheduled jobs, empty uses each job's default schedule
+	JobScheduleDiscogsDownload    string `mapstructure:"JOB_SCHEDULE_DISCOGS_DOWNLOAD"`
+	JobScheduleDiscogsXMLParser   string `mapstructure:"JOB_SCHEDULE_DISCOGS_XML_PARSER"`
+	JobScheduleFileCleanup        string `mapstructure:"JOB_SCHEDULE_FILE_CLEANUP"`
+	JobScheduleProcessingWatchdog string `mapstructure:"JOB_SCHEDULE_PROCESSING_WATCHDOG"`
+	JobScheduleReleaseRefresh     string `mapstructure:"JOB_SCHEDULE_RELEASE_REFRESH"`
+
+	// Lets webhooks deliver to loopback and private network addresses, off by default so a
+	// webhook can not reach services inside the deployment
+	WebhookAllowPrivateNetworks bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE_NETWORKS"`
+
+	// Bearer token Prometheus scrapes /metrics with, empty leaves the endpoint open for deployments
+	// that keep it off the public network
+	MetricsToken string `mapstructure:"METRICS_TOKEN"`
 }
 
 var ConfigInstance Config
@@ -41,8 +114,24 @@ func New() (Config, error) {
 		"GENERAL_VERSION", "ENVIRONMENT", "SERVER_PORT", "DB_HOST", "DB_PORT", "DB_NAME", "DB_USER", "DB_PASSWORD",
 		"DB_CACHE_ADDRESS", "DB_CACHE_PORT", "DB_CACHE_RESET",
 		"CORS_ALLOW_ORIGINS",
+		"AUTH_PROVIDER",
 		"ZITADEL_CLIENT_ID", "ZITADEL_INSTANCE_URL", "ZITADEL_PRIVATE_KEY", "ZITADEL_KEY_ID", "ZITADEL_CLIENT_ID_M2M",
 		"VICTORIA_LOGS_URL",
+		"OIDC_ISSUER_URL", "OIDC_CLIENT_ID", "OIDC_CLIENT_SECRET", "OIDC_PROVIDER_NAME",
+		"LOCAL_AUTH_SECRET",
+		"AUTH_DEFAULT_ROLE", "AUTH_ROLE_CLAIM_MAPPING",
+		"WEBSOCKET_REPLAY_RETENTION_MINUTES",
+		"DISCOGS_CONVERTER_WORKERS", "DISCOGS_BATCH_WRITERS", "DISCOGS_MAX_FAILURE_RATIO",
+		"DISCOGS_HEARTBEAT_TIMEOUT_MINUTES",
+		"RELEASE_STALE_AFTER_DAYS", "RELEASE_REFRESH_BATCH_SIZE",
+		"DISCOGS_DUMP_SOURCE",
+		"DISCOGS_STORAGE_BACKEND", "DISCOGS_S3_ENDPOINT", "DISCOGS_S3_BUCKET", "DISCOGS_S3_REGION",
+		"DISCOGS_S3_ACCESS_KEY", "DISCOGS_S3_SECRET_KEY", "DISCOGS_S3_USE_SSL", "DISCOGS_S3_PREFIX",
+		"IMAGE_STORAGE_BACKEND", "IMAGE_STORAGE_DIR", "IMAGE_S3_BUCKET", "IMAGE_S3_PREFIX",
+		"JOB_SCHEDULE_DISCOGS_DOWNLOAD", "JOB_SCHEDULE_DISCOGS_XML_PARSER", "JOB_SCHEDULE_FILE_CLEANUP",
+		"JOB_SCHEDULE_PROCESSING_WATCHDOG", "JOB_SCHEDULE_RELEASE_REFRESH",
+		"WEBHOOK_ALLOW_PRIVATE_NETWORKS",
+		"METRICS_TOKEN",
 	}
 
 	for _, env := range envVars {
@@ -105,28 +194,48 @@ func validateConfig(config Config, log logger.Logger) error {
 		)
 	}
 
+	switch strings.ToLower(config.AuthProvider) {
+	case "", "zitadel":
+	case "oidc":
+		if config.OIDCIssuerURL == "" || config.OIDCClientID == "" {
+			return log.ErrMsg(
+				"Fatal error: OIDC_ISSUER_URL and OIDC_CLIENT_ID required when AUTH_PROVIDER is oidc",
+			)
+		}
+	case "local":
+		if config.Environment == "production" {
+			return log.ErrMsg("Fatal error: AUTH_PROVIDER local is not allowed in production")
+		}
+	default:
+		return log.Error("Fatal error: unknown AUTH_PROVIDER", "provider", config.AuthProvider)
+	}
+
 	// Validate Zitadel configuration
 	if config.ZitadelInstanceURL != "" {
 		if config.ZitadelClientID == "" {
-			return log.Err(
+			return log.ErrMsg(
 				"Fatal error: ZITADEL_CLIENT_ID required when ZITADEL_INSTANCE_URL is set",
-				nil,
 			)
 		}
 		if config.ZitadelPrivateKey != "" && config.ZitadelKeyID == "" {
-			return log.Err(
+			return log.ErrMsg(
 				"Fatal error: ZITADEL_KEY_ID required when ZITADEL_PRIVATE_KEY is set",
-				nil,
 			)
 		}
 		if config.ZitadelPrivateKey != "" && config.ZitadelClientIDM2M == "" {
-			return log.Err(
+			return log.ErrMsg(
 				"Fatal error: ZITADEL_CLIENT_ID_M2M required when ZITADEL_PRIVATE_KEY is set",
-				nil,
 			)
 		}
 	}
 
+	if config.DiscogsStorageBackend == "s3" &&
+		(config.DiscogsS3Endpoint == "" || config.DiscogsS3Bucket == "") {
+		return log.ErrMsg(
+			"Fatal error: DISCOGS_S3_ENDPOINT and DISCOGS_S3_BUCKET required when DISCOGS_STORAGE_BACKEND is s3",
+		)
+	}
+
 	ConfigInstance = config
 	return nil
 }
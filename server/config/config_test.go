@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+
+	logger "github.com/Bparsons0904/goLogger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	valid := Config{ServerPort: 8288}
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"invalid port", func(c *Config) { c.ServerPort = 0 }, true},
+		{"unknown provider", func(c *Config) { c.AuthProvider = "saml" }, true},
+		{"local in development", func(c *Config) {
+			c.AuthProvider = "local"
+			c.Environment = "development"
+		}, false},
+		{"local in production", func(c *Config) {
+			c.AuthProvider = "local"
+			c.Environment = "production"
+		}, true},
+		{"oidc without issuer", func(c *Config) {
+			c.AuthProvider = "oidc"
+			c.OIDCClientID = "waugzee"
+		}, true},
+		{"oidc", func(c *Config) {
+			c.AuthProvider = "oidc"
+			c.OIDCIssuerURL = "https://keycloak.example.com/realms/waugzee"
+			c.OIDCClientID = "waugzee"
+		}, false},
+		{"zitadel without client", func(c *Config) { c.ZitadelInstanceURL = "https://auth.example.com" }, true},
+		{"s3 without bucket", func(c *Config) {
+			c.DiscogsStorageBackend = "s3"
+			c.DiscogsS3Endpoint = "s3.example.com"
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+
+			err := validateConfig(config, logger.New("test"))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"waugzee/internal/handlers/middleware"
 	"waugzee/internal/jobs"
 	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/metrics"
 	"waugzee/internal/repositories"
 	"waugzee/internal/services"
 	"waugzee/internal/websockets"
@@ -58,6 +59,13 @@ func New() (*App, error) {
 		return &App{}, log.Err("failed to create websocket manager", err)
 	}
 
+	if err := metrics.RegisterWebSocketConnections(websocket.ConnectionCounts); err != nil {
+		return &App{}, log.Err("failed to register websocket metrics", err)
+	}
+	if err := metrics.RegisterDiscogsRateLimit(servicesComposite.DiscogsRateLimiter.Usage); err != nil {
+		return &App{}, log.Err("failed to register rate limit metrics", err)
+	}
+
 	middleware := middleware.New(db, eventBus, config, repos)
 	controllersComposite := controllers.New(servicesComposite, repos, eventBus, config, db)
 
@@ -92,8 +100,12 @@ func (a *App) Close() (err error) {
 		}
 	}
 
-	if a.Services.Zitadel != nil {
-		if closeErr := a.Services.Zitadel.Close(); closeErr != nil {
+	if a.Services.Webhook != nil {
+		a.Services.Webhook.Stop()
+	}
+
+	if a.Services.Auth != nil {
+		if closeErr := a.Services.Auth.Close(); closeErr != nil {
 			err = closeErr
 		}
 	}
@@ -1,7 +1,9 @@
 package constants
 
 const (
-	JobDiscogsDownload  = "DiscogsDailyDownloadCheck"
-	JobDiscogsXMLParser = "DiscogsXMLParser"
-	JobFileCleanup      = "MonthlyFileCleanup"
+	JobDiscogsDownload    = "DiscogsDailyDownloadCheck"
+	JobDiscogsXMLParser   = "DiscogsXMLParser"
+	JobFileCleanup        = "MonthlyFileCleanup"
+	JobProcessingWatchdog = "DiscogsProcessingWatchdog"
+	JobReleaseRefresh     = "ReleaseRefresh"
 )
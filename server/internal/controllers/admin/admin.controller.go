@@ -2,6 +2,7 @@ package admin
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 	"waugzee/internal/constants"
@@ -16,12 +17,16 @@ import (
 
 type AdminControllerInterface interface {
 	GetDownloadStatus(ctx context.Context) (*DownloadStatusResponse, error)
+	GetProcessingDashboard(ctx context.Context) (*ProcessingDashboardResponse, error)
 	TriggerDownload(ctx context.Context) error
 	TriggerReprocess(ctx context.Context) error
 	ResetStuckDownload(ctx context.Context) error
+	ImportDump(ctx context.Context, yearMonth string, options services.DumpImportOptions) (*DownloadStatusResponse, error)
 	ListStoredFiles(ctx context.Context) (*StoredFilesResponse, error)
 	CleanupAllFiles(ctx context.Context) error
 	CleanupYearMonth(ctx context.Context, yearMonth string) error
+	ListJobs(ctx context.Context) ([]services.JobInfo, error)
+	RunJob(ctx context.Context, jobName string) error
 	// TODO: REMOVE_AFTER_MIGRATION - One-time Kleio data import method
 	ImportKleioData(ctx context.Context, userID uuid.UUID, jsonData []byte) (*services.ImportSummary, error)
 }
@@ -30,6 +35,7 @@ type AdminController struct {
 	db                   *gorm.DB
 	processingRepo       repositories.DiscogsDataProcessingRepository
 	downloadService      *services.DownloadService
+	dumpImportService    *services.DumpImportService
 	xmlProcessingService *services.DiscogsXMLParserService
 	schedulerService     *services.SchedulerService
 	fileCleanupService   *services.FileCleanupService
@@ -40,6 +46,7 @@ func NewAdminController(
 	db *gorm.DB,
 	processingRepo repositories.DiscogsDataProcessingRepository,
 	downloadService *services.DownloadService,
+	dumpImportService *services.DumpImportService,
 	xmlProcessingService *services.DiscogsXMLParserService,
 	schedulerService *services.SchedulerService,
 	fileCleanupService *services.FileCleanupService,
@@ -49,6 +56,7 @@ func NewAdminController(
 		db:                   db,
 		processingRepo:       processingRepo,
 		downloadService:      downloadService,
+		dumpImportService:    dumpImportService,
 		xmlProcessingService: xmlProcessingService,
 		schedulerService:     schedulerService,
 		fileCleanupService:   fileCleanupService,
@@ -57,16 +65,17 @@ func NewAdminController(
 }
 
 type DownloadStatusResponse struct {
-	YearMonth             string                                       `json:"year_month"`
-	Status                models.ProcessingStatus                      `json:"status"`
-	StartedAt             *time.Time                                   `json:"started_at,omitempty"`
-	DownloadCompletedAt   *time.Time                                   `json:"download_completed_at,omitempty"`
-	ProcessingCompletedAt *time.Time                                   `json:"processing_completed_at,omitempty"`
-	FileChecksums         *models.FileChecksums                        `json:"file_checksums,omitempty"`
-	Files                 *FileStatusInfo                              `json:"files,omitempty"`
-	ProcessingSteps       map[models.ProcessingStep]*models.StepStatus `json:"processing_steps,omitempty"`
-	RetryCount            int                                          `json:"retry_count"`
-	ErrorMessage          *string                                      `json:"error_message,omitempty"`
+	YearMonth             string                                           `json:"year_month"`
+	Status                models.ProcessingStatus                          `json:"status"`
+	StartedAt             *time.Time                                       `json:"started_at,omitempty"`
+	DownloadCompletedAt   *time.Time                                       `json:"download_completed_at,omitempty"`
+	ProcessingCompletedAt *time.Time                                       `json:"processing_completed_at,omitempty"`
+	FileChecksums         *models.FileChecksums                            `json:"file_checksums,omitempty"`
+	Files                 *FileStatusInfo                                  `json:"files,omitempty"`
+	ProcessingSteps       map[models.ProcessingStep]*models.StepStatus     `json:"processing_steps,omitempty"`
+	Checkpoints           map[models.ProcessingStep]*models.StepCheckpoint `json:"checkpoints,omitempty"`
+	RetryCount            int                                              `json:"retry_count"`
+	ErrorMessage          *string                                          `json:"error_message,omitempty"`
 }
 
 type FileStatusInfo struct {
@@ -119,6 +128,16 @@ func (c *AdminController) modelToResponse(
 			Releases: record.ProcessingStats.ReleasesFile,
 		}
 		resp.ProcessingSteps = record.ProcessingStats.ProcessingSteps
+
+		// Unfinished steps with a checkpoint will resume from it on the next run
+		for step := range record.ProcessingStats.ProcessingSteps {
+			if checkpoint := record.GetStepCheckpoint(step); checkpoint != nil {
+				if resp.Checkpoints == nil {
+					resp.Checkpoints = make(map[models.ProcessingStep]*models.StepCheckpoint)
+				}
+				resp.Checkpoints[step] = checkpoint
+			}
+		}
 	}
 
 	return resp
@@ -134,6 +153,12 @@ func (c *AdminController) TriggerDownload(ctx context.Context) error {
 		return log.Err("failed to check existing record", err)
 	}
 
+	if existingRecord != nil &&
+		(existingRecord.Status == models.ProcessingStatusDownloading ||
+			existingRecord.Status == models.ProcessingStatusProcessing) {
+		return log.Err("cannot trigger download", services.ErrDumpImportInProgress, "status", existingRecord.Status)
+	}
+
 	if existingRecord != nil {
 		log.Info("Deleting existing processing record", "yearMonth", yearMonth, "status", existingRecord.Status)
 		if err := c.processingRepo.Delete(ctx, existingRecord.ID); err != nil {
@@ -173,21 +198,20 @@ func (c *AdminController) TriggerReprocess(ctx context.Context) error {
 		return log.Err("no processing record found", fmt.Errorf("no processing record found"))
 	}
 
-	statusValid := record.Status == models.ProcessingStatusReadyForProcessing ||
-		record.Status == models.ProcessingStatusProcessing ||
-		record.Status == models.ProcessingStatusCompleted ||
-		record.Status == models.ProcessingStatusFailed
-
-	if !statusValid {
+	if record.Status == models.ProcessingStatusNotStarted ||
+		record.Status == models.ProcessingStatusDownloading {
 		return log.Err(
 			"files not downloaded",
 			fmt.Errorf("files must be downloaded before reprocessing"),
 		)
 	}
 
+	if err := record.UpdateStatus(models.ProcessingStatusReadyForProcessing, "admin reprocess"); err != nil {
+		return log.Err("cannot reprocess record in this state", err)
+	}
+
 	record.InitializeProcessingStats()
 	record.ProcessingStats.ProcessingSteps = make(map[models.ProcessingStep]*models.StepStatus)
-	record.Status = models.ProcessingStatusReadyForProcessing
 	record.ProcessingCompletedAt = nil
 	record.ErrorMessage = nil
 
@@ -229,7 +253,9 @@ func (c *AdminController) ResetStuckDownload(ctx context.Context) error {
 		return log.Err("failed to cleanup files", err)
 	}
 
-	record.Status = models.ProcessingStatusNotStarted
+	if err := record.UpdateStatus(models.ProcessingStatusNotStarted, "admin reset"); err != nil {
+		return log.Err("cannot reset record in this state", err)
+	}
 	record.StartedAt = nil
 	record.DownloadCompletedAt = nil
 	record.ProcessingCompletedAt = nil
@@ -246,6 +272,32 @@ func (c *AdminController) ResetStuckDownload(ctx context.Context) error {
 	return nil
 }
 
+// ImportDump registers dump files already in storage for a past or offline year-month and queues
+// them for the XML parser
+func (c *AdminController) ImportDump(
+	ctx context.Context,
+	yearMonth string,
+	options services.DumpImportOptions,
+) (*DownloadStatusResponse, error) {
+	log := logger.New("adminController").TraceFromContext(ctx).Function("ImportDump")
+
+	record, err := c.dumpImportService.RegisterExistingDump(ctx, yearMonth, options)
+	if err != nil {
+		return nil, log.Err("failed to register dump", err, "yearMonth", yearMonth)
+	}
+
+	// A parser run already in progress picks the queued record up on its next run
+	if err := c.schedulerService.TriggerJobByName(ctx, constants.JobDiscogsXMLParser); err != nil {
+		if !errors.Is(err, services.ErrJobAlreadyRunning) {
+			return nil, log.Err("failed to trigger xml parser job", err)
+		}
+		log.Info("XML parser already running, dump stays queued", "yearMonth", yearMonth)
+	}
+
+	log.Info("Imported dump for processing", "yearMonth", yearMonth)
+	return c.modelToResponse(record), nil
+}
+
 func (c *AdminController) ListStoredFiles(ctx context.Context) (*StoredFilesResponse, error) {
 	log := logger.New("adminController").TraceFromContext(ctx).Function("ListStoredFiles")
 
@@ -310,3 +362,24 @@ func (c *AdminController) ImportKleioData(
 
 	return summary, nil
 }
+
+func (c *AdminController) ListJobs(ctx context.Context) ([]services.JobInfo, error) {
+	log := logger.New("adminController").TraceFromContext(ctx).Function("ListJobs")
+
+	jobs, err := c.schedulerService.ListJobs(ctx)
+	if err != nil {
+		return nil, log.Err("failed to list jobs", err)
+	}
+
+	return jobs, nil
+}
+
+func (c *AdminController) RunJob(ctx context.Context, jobName string) error {
+	log := logger.New("adminController").TraceFromContext(ctx).Function("RunJob")
+
+	if err := c.schedulerService.TriggerJobByName(ctx, jobName); err != nil {
+		return log.Err("failed to trigger job", err, "job", jobName)
+	}
+
+	return nil
+}
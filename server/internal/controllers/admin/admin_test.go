@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"context"
+	"testing"
+	"waugzee/internal/models"
+	"waugzee/internal/repositories"
+	"waugzee/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// activeProcessing reports a record for every month in the given status
+type activeProcessing struct {
+	repositories.DiscogsDataProcessingRepository
+	status models.ProcessingStatus
+}
+
+func (r activeProcessing) GetByYearMonth(ctx context.Context, yearMonth string) (*models.DiscogsDataProcessing, error) {
+	return &models.DiscogsDataProcessing{YearMonth: yearMonth, Status: r.status}, nil
+}
+
+func TestTriggerDownloadRefusesWhileInProgress(t *testing.T) {
+	for _, status := range []models.ProcessingStatus{
+		models.ProcessingStatusDownloading,
+		models.ProcessingStatusProcessing,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			controller := &AdminController{processingRepo: activeProcessing{status: status}}
+
+			err := controller.TriggerDownload(context.Background())
+			assert.ErrorIs(t, err, services.ErrDumpImportInProgress)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package admin
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/models"
+	"waugzee/internal/services"
+)
+
+// throughputLookbackRuns is how many earlier runs a step's throughput is averaged over for ETAs
+const throughputLookbackRuns = 3
+
+const (
+	StepStateCompleted  = "completed"
+	StepStateInProgress = "in_progress"
+	StepStateFailed     = "failed"
+	StepStatePending    = "pending"
+)
+
+type ProcessingDashboardResponse struct {
+	Runs         []ProcessingRunSummary `json:"runs"`
+	EntityCounts map[string]int64       `json:"entity_counts"`
+	StoredBytes  int64                  `json:"stored_bytes"`
+}
+
+// ProcessingRunSummary is one DiscogsDataProcessing run as shown on the dashboard, newest first
+type ProcessingRunSummary struct {
+	YearMonth             string                  `json:"year_month"`
+	Status                models.ProcessingStatus `json:"status"`
+	StartedAt             *time.Time              `json:"started_at,omitempty"`
+	DownloadCompletedAt   *time.Time              `json:"download_completed_at,omitempty"`
+	ProcessingCompletedAt *time.Time              `json:"processing_completed_at,omitempty"`
+	RetryCount            int                     `json:"retry_count"`
+	ErrorMessage          *string                 `json:"error_message,omitempty"`
+	Steps                 []ProcessingStepSummary `json:"steps"`
+	// EntityCounts is the snapshot taken when the run completed, or the live counts while it runs
+	EntityCounts        map[string]int64      `json:"entity_counts,omitempty"`
+	EntityCountDeltas   map[string]int64      `json:"entity_count_deltas,omitempty"`
+	StoredFiles         []services.StoredFile `json:"stored_files"`
+	StoredBytes         int64                 `json:"stored_bytes"`
+	EstimatedCompletion *time.Time            `json:"estimated_completion,omitempty"`
+}
+
+type ProcessingStepSummary struct {
+	Step            models.ProcessingStep `json:"step"`
+	State           string                `json:"state"`
+	DurationSeconds *float64              `json:"duration_seconds,omitempty"`
+	RecordsCount    *int64                `json:"records_count,omitempty"`
+	FailedRecords   *int64                `json:"failed_records,omitempty"`
+	Changes         *models.ChangeCounts  `json:"changes,omitempty"`
+	ErrorMessage    *string               `json:"error_message,omitempty"`
+	// Progress and ETA of an in-progress step, estimated from earlier runs of the same step
+	RecordsCommitted    *int64     `json:"records_committed,omitempty"`
+	ExpectedRecords     *int64     `json:"expected_records,omitempty"`
+	RemainingSeconds    *float64   `json:"remaining_seconds,omitempty"`
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"`
+}
+
+// GetProcessingDashboard returns every processing run with per-step timings, entity count changes
+// month over month, the dump files still stored for it and an ETA for the run in progress
+func (c *AdminController) GetProcessingDashboard(ctx context.Context) (*ProcessingDashboardResponse, error) {
+	log := logger.New("adminController").TraceFromContext(ctx).Function("GetProcessingDashboard")
+
+	records, err := c.processingRepo.GetAll(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get processing records", err)
+	}
+
+	counts, err := c.xmlProcessingService.GetDatabaseCounts(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get database counts", err)
+	}
+
+	files, err := c.fileCleanupService.ListStoredFiles(ctx)
+	if err != nil {
+		return nil, log.Err("failed to list stored files", err)
+	}
+
+	return buildProcessingDashboard(records, counts, files, time.Now().UTC()), nil
+}
+
+func buildProcessingDashboard(
+	records []*models.DiscogsDataProcessing,
+	liveCounts map[string]int64,
+	files []services.StoredFile,
+	now time.Time,
+) *ProcessingDashboardResponse {
+	// Oldest first so each run can look back at the runs before it
+	sorted := make([]*models.DiscogsDataProcessing, len(records))
+	copy(sorted, records)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].YearMonth < sorted[j].YearMonth })
+
+	filesByYearMonth := make(map[string][]services.StoredFile)
+	var storedBytes int64
+	for _, file := range files {
+		yearMonth, _, _ := strings.Cut(file.Path, "/")
+		filesByYearMonth[yearMonth] = append(filesByYearMonth[yearMonth], file)
+		storedBytes += file.Size
+	}
+
+	runs := make([]ProcessingRunSummary, 0, len(sorted))
+	var previousCounts map[string]int64
+	for i, record := range sorted {
+		run := ProcessingRunSummary{
+			YearMonth:             record.YearMonth,
+			Status:                record.Status,
+			StartedAt:             record.StartedAt,
+			DownloadCompletedAt:   record.DownloadCompletedAt,
+			ProcessingCompletedAt: record.ProcessingCompletedAt,
+			RetryCount:            record.RetryCount,
+			ErrorMessage:          record.ErrorMessage,
+			StoredFiles:           filesByYearMonth[record.YearMonth],
+		}
+		if run.StoredFiles == nil {
+			run.StoredFiles = []services.StoredFile{}
+		}
+		for _, file := range run.StoredFiles {
+			run.StoredBytes += file.Size
+		}
+
+		if record.ProcessingStats != nil && record.ProcessingStats.EntityCounts != nil {
+			run.EntityCounts = record.ProcessingStats.EntityCounts
+		} else if record.Status == models.ProcessingStatusProcessing {
+			run.EntityCounts = liveCounts
+		}
+		if run.EntityCounts != nil && previousCounts != nil {
+			run.EntityCountDeltas = make(map[string]int64, len(run.EntityCounts))
+			for entity, count := range run.EntityCounts {
+				run.EntityCountDeltas[entity] = count - previousCounts[entity]
+			}
+		}
+		if record.ProcessingStats != nil && record.ProcessingStats.EntityCounts != nil {
+			previousCounts = record.ProcessingStats.EntityCounts
+		}
+
+		run.Steps, run.EstimatedCompletion = summarizeSteps(record, sorted[:i], now)
+		runs = append(runs, run)
+	}
+
+	// Newest first for display
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+
+	return &ProcessingDashboardResponse{
+		Runs:         runs,
+		EntityCounts: liveCounts,
+		StoredBytes:  storedBytes,
+	}
+}
+
+// summarizeSteps reports every step of a run in execution order. While the run is processing, the
+// first unfinished step is in progress and the run's completion is estimated from earlier runs.
+func summarizeSteps(
+	record *models.DiscogsDataProcessing,
+	earlier []*models.DiscogsDataProcessing,
+	now time.Time,
+) ([]ProcessingStepSummary, *time.Time) {
+	steps := make([]ProcessingStepSummary, 0, len(models.ProcessingSteps))
+	processing := record.Status == models.ProcessingStatusProcessing
+	inProgressFound := false
+	var remaining float64
+	estimable := processing
+
+	for _, step := range models.ProcessingSteps {
+		summary := ProcessingStepSummary{Step: step, State: StepStatePending}
+		status := record.GetStepStatus(step)
+
+		if status != nil {
+			summary.RecordsCount = status.RecordsCount
+			summary.FailedRecords = status.FailedRecords
+			summary.Changes = status.Changes
+			summary.ErrorMessage = status.ErrorMessage
+			if seconds, ok := stepSeconds(status); ok {
+				summary.DurationSeconds = &seconds
+			}
+		}
+
+		switch {
+		case status != nil && status.Completed:
+			summary.State = StepStateCompleted
+		case processing && !inProgressFound:
+			inProgressFound = true
+			summary.State = StepStateInProgress
+			summary.ErrorMessage = nil
+
+			var committed int64
+			if checkpoint := record.GetStepCheckpoint(step); checkpoint != nil {
+				committed = checkpoint.ElementsCommitted
+				summary.RecordsCommitted = &committed
+			}
+
+			history, ok := stepHistory(step, earlier)
+			if !ok {
+				estimable = false
+				break
+			}
+			left := history.seconds
+			if history.rate > 0 {
+				summary.ExpectedRecords = &history.records
+				left = max(float64(history.records-committed), 0) / history.rate
+			}
+			summary.RemainingSeconds = &left
+			completion := now.Add(time.Duration(left * float64(time.Second)))
+			summary.EstimatedCompletion = &completion
+			remaining += left
+		case status != nil && status.ErrorMessage != nil:
+			summary.State = StepStateFailed
+		}
+
+		// Steps still to run take as long as they did before
+		if summary.State == StepStatePending && processing {
+			if history, ok := stepHistory(step, earlier); ok {
+				remaining += history.seconds
+			} else {
+				estimable = false
+			}
+		}
+
+		steps = append(steps, summary)
+	}
+
+	if !estimable || !inProgressFound {
+		return steps, nil
+	}
+	completion := now.Add(time.Duration(remaining * float64(time.Second)))
+	return steps, &completion
+}
+
+// stepEstimate describes how a step performed in earlier runs
+type stepEstimate struct {
+	// records is the record count of the latest earlier run, 0 when the step does not count records
+	records int64
+	// rate is records per second and seconds the duration, both averaged over the earlier runs
+	rate    float64
+	seconds float64
+}
+
+// stepHistory averages the last throughputLookbackRuns completed earlier runs of a step
+func stepHistory(step models.ProcessingStep, earlier []*models.DiscogsDataProcessing) (stepEstimate, bool) {
+	var estimate stepEstimate
+	var records int64
+	var seconds float64
+	runs := 0
+
+	for i := len(earlier) - 1; i >= 0 && runs < throughputLookbackRuns; i-- {
+		status := earlier[i].GetStepStatus(step)
+		if status == nil || !status.Completed {
+			continue
+		}
+		duration, ok := stepSeconds(status)
+		if !ok || duration <= 0 {
+			continue
+		}
+		if status.RecordsCount != nil {
+			if runs == 0 {
+				estimate.records = *status.RecordsCount
+			}
+			records += *status.RecordsCount
+		}
+		seconds += duration
+		runs++
+	}
+
+	if runs == 0 {
+		return stepEstimate{}, false
+	}
+	if records > 0 && estimate.records > 0 {
+		estimate.rate = float64(records) / seconds
+	}
+	estimate.seconds = seconds / float64(runs)
+	return estimate, true
+}
+
+// stepSeconds parses the duration recorded when a step completed
+func stepSeconds(status *models.StepStatus) (float64, bool) {
+	if status.Duration == nil {
+		return 0, false
+	}
+	duration, err := time.ParseDuration(*status.Duration)
+	if err != nil {
+		return 0, false
+	}
+	return duration.Seconds(), true
+}
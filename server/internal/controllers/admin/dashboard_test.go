@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"testing"
+	"time"
+	"waugzee/internal/models"
+	"waugzee/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func completedRun(yearMonth string, records int64, duration string, counts map[string]int64) *models.DiscogsDataProcessing {
+	record := &models.DiscogsDataProcessing{YearMonth: yearMonth, Status: models.ProcessingStatusCompleted}
+	for _, step := range models.ProcessingSteps {
+		record.MarkStepCompleted(step, &records, &duration)
+	}
+	record.ProcessingStats.EntityCounts = counts
+	return record
+}
+
+func TestBuildProcessingDashboard(t *testing.T) {
+	now := time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	inProgress := &models.DiscogsDataProcessing{YearMonth: "2025-01", Status: models.ProcessingStatusProcessing}
+	inProgress.SetStepCheckpoint(models.StepLabelsProcessing, &models.StepCheckpoint{ElementsCommitted: 60})
+
+	records := []*models.DiscogsDataProcessing{
+		inProgress,
+		completedRun("2024-11", 100, "10s", map[string]int64{"labels": 100}),
+		completedRun("2024-12", 120, "12s", map[string]int64{"labels": 130}),
+	}
+	files := []services.StoredFile{
+		{Path: "2025-01/labels.xml.gz", Size: 5},
+		{Path: "2024-12/labels.xml.gz", Size: 7},
+	}
+
+	dashboard := buildProcessingDashboard(records, map[string]int64{"labels": 150}, files, now)
+
+	require.Len(t, dashboard.Runs, 3)
+	assert.Equal(t, []string{"2025-01", "2024-12", "2024-11"}, []string{
+		dashboard.Runs[0].YearMonth, dashboard.Runs[1].YearMonth, dashboard.Runs[2].YearMonth,
+	})
+	assert.Equal(t, int64(12), dashboard.StoredBytes)
+
+	current, previous, oldest := dashboard.Runs[0], dashboard.Runs[1], dashboard.Runs[2]
+	assert.Equal(t, int64(5), current.StoredBytes)
+	assert.Equal(t, int64(7), previous.StoredBytes)
+	assert.Empty(t, oldest.StoredFiles)
+
+	assert.Nil(t, oldest.EntityCountDeltas, "the first run has nothing to compare with")
+	assert.Equal(t, int64(30), previous.EntityCountDeltas["labels"])
+	assert.Equal(t, int64(20), current.EntityCountDeltas["labels"], "a running month is compared using live counts")
+
+	require.Len(t, previous.Steps, len(models.ProcessingSteps))
+	assert.Equal(t, StepStateCompleted, previous.Steps[0].State)
+	assert.InDelta(t, 12.0, *previous.Steps[0].DurationSeconds, 0.001)
+	assert.Nil(t, previous.EstimatedCompletion)
+
+	labels := current.Steps[0]
+	assert.Equal(t, StepStateInProgress, labels.State)
+	assert.Equal(t, int64(60), *labels.RecordsCommitted)
+	assert.Equal(t, int64(120), *labels.ExpectedRecords)
+	// 220 records in 22 seconds over the last two runs, 60 records left
+	assert.InDelta(t, 6.0, *labels.RemainingSeconds, 0.001)
+	assert.Equal(t, StepStatePending, current.Steps[1].State)
+
+	// The 12 remaining steps average 11 seconds each
+	require.NotNil(t, current.EstimatedCompletion)
+	assert.Equal(t, now.Add(138*time.Second), *current.EstimatedCompletion)
+}
+
+func TestBuildProcessingDashboardWithoutHistory(t *testing.T) {
+	record := &models.DiscogsDataProcessing{YearMonth: "2025-01", Status: models.ProcessingStatusProcessing}
+	message := "connection reset"
+	record.MarkStepFailed(models.StepLabelsProcessing, message)
+
+	dashboard := buildProcessingDashboard([]*models.DiscogsDataProcessing{record}, nil, nil, time.Now())
+
+	require.Len(t, dashboard.Runs, 1)
+	run := dashboard.Runs[0]
+	assert.Equal(t, StepStateInProgress, run.Steps[0].State, "a failed step is retried when processing resumes")
+	assert.Nil(t, run.Steps[0].RemainingSeconds)
+	assert.Nil(t, run.EstimatedCompletion, "no earlier runs to estimate from")
+	assert.NotNil(t, run.StoredFiles)
+}
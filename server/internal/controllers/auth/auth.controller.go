@@ -3,6 +3,7 @@ package authController
 import (
 	"context"
 	"strings"
+	"time"
 	"waugzee/internal/database"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
@@ -12,7 +13,7 @@ import (
 )
 
 type AuthController struct {
-	zitadelService *services.ZitadelService
+	authProvider   services.AuthProvider
 	userRepo       repositories.UserRepository
 	historyRepo    repositories.HistoryRepository
 	stylusRepo     repositories.StylusRepository
@@ -23,10 +24,12 @@ type AuthControllerInterface interface {
 	GetAuthConfig() (*AuthConfigResponse, error)
 	HandleOIDCCallback(ctx context.Context, req OIDCCallbackRequest) (*TokenExchangeResult, error)
 	LogoutUser(ctx context.Context, req LogoutRequest, user *User) (*LogoutResponse, error)
+	IssueLocalToken(ctx context.Context, req LocalTokenRequest) (*LocalTokenResponse, error)
 }
 
 type AuthConfigResponse struct {
 	Configured  bool   `json:"configured"`
+	Provider    string `json:"provider,omitempty"`
 	Domain      string `json:"domain,omitempty"`
 	InstanceURL string `json:"instanceUrl,omitempty"`
 	ClientID    string `json:"clientId,omitempty"`
@@ -57,6 +60,19 @@ type LogoutRequest struct {
 	AccessToken           string `json:"access_token,omitempty"`
 }
 
+type LocalTokenRequest struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// LocalTokenResponse carries an ID token from the local provider, which the client exchanges at
+// the callback like any other provider's ID token
+type LocalTokenResponse struct {
+	IDToken   string `json:"id_token"`
+	TokenType string `json:"token_type"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
 type LogoutResponse struct {
 	Message       string   `json:"message"`
 	LogoutURL     string   `json:"logout_url,omitempty"`
@@ -69,7 +85,7 @@ func New(
 	db database.DB,
 ) AuthControllerInterface {
 	return &AuthController{
-		zitadelService: services.Zitadel,
+		authProvider:   services.Auth,
 		userRepo:       repos.User,
 		historyRepo:    repos.History,
 		stylusRepo:     repos.Stylus,
@@ -78,9 +94,10 @@ func New(
 }
 
 func (ac *AuthController) GetAuthConfig() (*AuthConfigResponse, error) {
-	config := ac.zitadelService.GetConfig()
+	config := ac.authProvider.GetConfig()
 	return &AuthConfigResponse{
 		Configured:  true,
+		Provider:    config.Provider,
 		Domain:      config.Domain,
 		InstanceURL: config.InstanceURL,
 		ClientID:    config.ClientID,
@@ -136,7 +153,7 @@ func (ac *AuthController) getOrCreateOIDCUser(
 	}
 
 	// Set OIDC provider
-	provider := "zitadel"
+	provider := ac.authProvider.Name()
 	user.OIDCProvider = &provider
 
 	// Set project ID if available
@@ -166,7 +183,7 @@ func (ac *AuthController) HandleOIDCCallback(
 ) (*TokenExchangeResult, error) {
 	log := logger.New("authController").TraceFromContext(ctx).Function("HandleOIDCCallback")
 
-	tokenInfo, err := ac.zitadelService.ValidateIDToken(ctx, req.IDToken)
+	tokenInfo, err := ac.authProvider.ValidateIDToken(ctx, req.IDToken)
 	if err != nil {
 		log.Info("ID token validation failed", "error", err.Error())
 		return nil, log.ErrMsg("authentication failed")
@@ -214,7 +231,7 @@ func (ac *AuthController) LogoutUser(
 
 	// Get OIDC User ID from the ID token since cached user may not have it
 	if req.AccessToken != "" {
-		tokenInfo, err := ac.zitadelService.ValidateIDToken(ctx, req.AccessToken)
+		tokenInfo, err := ac.authProvider.ValidateIDToken(ctx, req.AccessToken)
 		if err != nil {
 			log.Warn("failed to validate ID token during logout", "error", err.Error())
 		} else {
@@ -227,7 +244,7 @@ func (ac *AuthController) LogoutUser(
 
 	// Revoke access token if present
 	if req.AccessToken != "" {
-		if err := ac.zitadelService.RevokeToken(ctx, req.AccessToken, "access_token"); err != nil {
+		if err := ac.authProvider.RevokeToken(ctx, req.AccessToken, "access_token"); err != nil {
 			log.Warn("failed to revoke access token", "error", err.Error())
 		} else {
 			revokedTokens = append(revokedTokens, "access_token")
@@ -237,7 +254,7 @@ func (ac *AuthController) LogoutUser(
 
 	// Revoke refresh token if provided
 	if req.RefreshToken != "" {
-		if err := ac.zitadelService.RevokeToken(ctx, req.RefreshToken, "refresh_token"); err != nil {
+		if err := ac.authProvider.RevokeToken(ctx, req.RefreshToken, "refresh_token"); err != nil {
 			log.Warn("failed to revoke refresh token", "error", err.Error())
 		} else {
 			revokedTokens = append(revokedTokens, "refresh_token")
@@ -290,7 +307,7 @@ func (ac *AuthController) LogoutUser(
 
 	// Generate logout URL
 	var logoutURL string
-	url, err := ac.zitadelService.GetLogoutURL(
+	url, err := ac.authProvider.GetLogoutURL(
 		ctx,
 		req.IDToken,
 		req.PostLogoutRedirectURI,
@@ -327,3 +344,28 @@ func (ac *AuthController) LogoutUser(
 
 	return response, nil
 }
+
+// IssueLocalToken signs an ID token for the email when the local provider is configured
+func (ac *AuthController) IssueLocalToken(
+	ctx context.Context,
+	req LocalTokenRequest,
+) (*LocalTokenResponse, error) {
+	log := logger.New("authController").TraceFromContext(ctx).Function("IssueLocalToken")
+
+	localProvider, ok := ac.authProvider.(*services.LocalAuthProvider)
+	if !ok {
+		return nil, log.Err("local token requested", services.ErrLocalAuthDisabled)
+	}
+
+	idToken, expiresAt, err := localProvider.IssueIDToken(req.Email, req.Name)
+	if err != nil {
+		return nil, log.Err("failed to issue local ID token", err)
+	}
+
+	log.Info("Issued local ID token", "email", req.Email)
+	return &LocalTokenResponse{
+		IDToken:   idToken,
+		TokenType: "Bearer",
+		ExpiresIn: int64(time.Until(expiresAt).Seconds()),
+	}, nil
+}
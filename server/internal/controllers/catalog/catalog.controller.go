@@ -0,0 +1,355 @@
+package catalogController
+
+import (
+	"context"
+	"errors"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageLimit = 25
+	MaxPageLimit     = 100
+)
+
+var ErrNotFound = errors.New("catalog entity not found")
+
+// CatalogRelease is a release annotated with whether it is in the requesting user's collection
+type CatalogRelease struct {
+	*Release
+	InCollection bool `json:"inCollection"`
+}
+
+// CatalogMaster is in the collection when any of its releases is
+type CatalogMaster struct {
+	*Master
+	InCollection bool `json:"inCollection"`
+}
+
+// CatalogArtist is in the collection when any release by the artist is
+type CatalogArtist struct {
+	*Artist
+	InCollection bool `json:"inCollection"`
+}
+
+// CatalogLabel is in the collection when any release on the label is
+type CatalogLabel struct {
+	*Label
+	InCollection bool `json:"inCollection"`
+}
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
+}
+
+type ReleasePage struct {
+	Releases   []CatalogRelease `json:"releases"`
+	Pagination Pagination       `json:"pagination"`
+}
+
+type MasterPage struct {
+	Masters    []CatalogMaster `json:"masters"`
+	Pagination Pagination      `json:"pagination"`
+}
+
+type CatalogControllerInterface interface {
+	GetRelease(ctx context.Context, user *User, releaseID int64) (*CatalogRelease, error)
+	GetMaster(ctx context.Context, user *User, masterID int64) (*CatalogMaster, error)
+	GetArtist(ctx context.Context, user *User, artistID int64) (*CatalogArtist, error)
+	GetLabel(ctx context.Context, user *User, labelID int64) (*CatalogLabel, error)
+	ListMasterReleases(
+		ctx context.Context,
+		user *User,
+		masterID int64,
+		page repositories.CatalogPage,
+	) (*ReleasePage, error)
+	ListArtistReleases(
+		ctx context.Context,
+		user *User,
+		artistID int64,
+		page repositories.CatalogPage,
+	) (*ReleasePage, error)
+	ListArtistMasters(
+		ctx context.Context,
+		user *User,
+		artistID int64,
+		page repositories.CatalogPage,
+	) (*MasterPage, error)
+	ListLabelReleases(
+		ctx context.Context,
+		user *User,
+		labelID int64,
+		page repositories.CatalogPage,
+	) (*ReleasePage, error)
+}
+
+type CatalogController struct {
+	catalogRepo     repositories.CatalogRepository
+	userReleaseRepo repositories.UserReleaseRepository
+	db              *gorm.DB
+}
+
+func New(repos repositories.Repository, db *gorm.DB) CatalogControllerInterface {
+	return &CatalogController{
+		catalogRepo:     repos.Catalog,
+		userReleaseRepo: repos.UserRelease,
+		db:              db,
+	}
+}
+
+// NewPage normalises page and limit query values
+func NewPage(page, limit int) repositories.CatalogPage {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	return repositories.CatalogPage{Page: page, Limit: limit}
+}
+
+func newPagination(page repositories.CatalogPage, total int64) Pagination {
+	totalPages := int((total + int64(page.Limit) - 1) / int64(page.Limit))
+	return Pagination{
+		Page:       page.Page,
+		Limit:      page.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+func notFoundOr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (c *CatalogController) GetRelease(
+	ctx context.Context,
+	user *User,
+	releaseID int64,
+) (*CatalogRelease, error) {
+	log := logger.New("catalogController").TraceFromContext(ctx).Function("GetRelease")
+
+	release, err := c.catalogRepo.GetRelease(ctx, c.db, releaseID)
+	if err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	releases := c.markReleases(ctx, log, user, []*Release{release})
+	return &releases[0], nil
+}
+
+func (c *CatalogController) GetMaster(
+	ctx context.Context,
+	user *User,
+	masterID int64,
+) (*CatalogMaster, error) {
+	log := logger.New("catalogController").TraceFromContext(ctx).Function("GetMaster")
+
+	master, err := c.catalogRepo.GetMaster(ctx, c.db, masterID)
+	if err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	masters := c.markMasters(ctx, log, user, []*Master{master})
+	return &masters[0], nil
+}
+
+func (c *CatalogController) GetArtist(
+	ctx context.Context,
+	user *User,
+	artistID int64,
+) (*CatalogArtist, error) {
+	log := logger.New("catalogController").TraceFromContext(ctx).Function("GetArtist")
+
+	artist, err := c.catalogRepo.GetArtist(ctx, c.db, artistID)
+	if err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	inCollection, err := c.userReleaseRepo.HasArtistInCollection(ctx, c.db, userID(user), artistID)
+	if err != nil {
+		log.Warn("failed to check artist in collection", "artistID", artistID, "error", err)
+	}
+
+	return &CatalogArtist{Artist: artist, InCollection: inCollection}, nil
+}
+
+func (c *CatalogController) GetLabel(
+	ctx context.Context,
+	user *User,
+	labelID int64,
+) (*CatalogLabel, error) {
+	log := logger.New("catalogController").TraceFromContext(ctx).Function("GetLabel")
+
+	label, err := c.catalogRepo.GetLabel(ctx, c.db, labelID)
+	if err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	inCollection, err := c.userReleaseRepo.HasLabelInCollection(ctx, c.db, userID(user), labelID)
+	if err != nil {
+		log.Warn("failed to check label in collection", "labelID", labelID, "error", err)
+	}
+
+	return &CatalogLabel{Label: label, InCollection: inCollection}, nil
+}
+
+func (c *CatalogController) ListMasterReleases(
+	ctx context.Context,
+	user *User,
+	masterID int64,
+	page repositories.CatalogPage,
+) (*ReleasePage, error) {
+	log := logger.New("catalogController").TraceFromContext(ctx).Function("ListMasterReleases")
+
+	if _, err := c.catalogRepo.GetMaster(ctx, c.db, masterID); err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	releases, total, err := c.catalogRepo.ListMasterReleases(ctx, c.db, masterID, page)
+	if err != nil {
+		return nil, log.Err("failed to list master releases", err, "masterID", masterID)
+	}
+
+	return &ReleasePage{
+		Releases:   c.markReleases(ctx, log, user, releases),
+		Pagination: newPagination(page, total),
+	}, nil
+}
+
+func (c *CatalogController) ListArtistReleases(
+	ctx context.Context,
+	user *User,
+	artistID int64,
+	page repositories.CatalogPage,
+) (*ReleasePage, error) {
+	log := logger.New("catalogController").TraceFromContext(ctx).Function("ListArtistReleases")
+
+	if _, err := c.catalogRepo.GetArtist(ctx, c.db, artistID); err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	releases, total, err := c.catalogRepo.ListArtistReleases(ctx, c.db, artistID, page)
+	if err != nil {
+		return nil, log.Err("failed to list artist releases", err, "artistID", artistID)
+	}
+
+	return &ReleasePage{
+		Releases:   c.markReleases(ctx, log, user, releases),
+		Pagination: newPagination(page, total),
+	}, nil
+}
+
+func (c *CatalogController) ListArtistMasters(
+	ctx context.Context,
+	user *User,
+	artistID int64,
+	page repositories.CatalogPage,
+) (*MasterPage, error) {
+	log := logger.New("catalogController").TraceFromContext(ctx).Function("ListArtistMasters")
+
+	if _, err := c.catalogRepo.GetArtist(ctx, c.db, artistID); err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	masters, total, err := c.catalogRepo.ListArtistMasters(ctx, c.db, artistID, page)
+	if err != nil {
+		return nil, log.Err("failed to list artist masters", err, "artistID", artistID)
+	}
+
+	return &MasterPage{
+		Masters:    c.markMasters(ctx, log, user, masters),
+		Pagination: newPagination(page, total),
+	}, nil
+}
+
+func (c *CatalogController) ListLabelReleases(
+	ctx context.Context,
+	user *User,
+	labelID int64,
+	page repositories.CatalogPage,
+) (*ReleasePage, error) {
+	log := logger.New("catalogController").TraceFromContext(ctx).Function("ListLabelReleases")
+
+	if _, err := c.catalogRepo.GetLabel(ctx, c.db, labelID); err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	releases, total, err := c.catalogRepo.ListLabelReleases(ctx, c.db, labelID, page)
+	if err != nil {
+		return nil, log.Err("failed to list label releases", err, "labelID", labelID)
+	}
+
+	return &ReleasePage{
+		Releases:   c.markReleases(ctx, log, user, releases),
+		Pagination: newPagination(page, total),
+	}, nil
+}
+
+// markReleases annotates releases with collection membership. Catalog data is shared across users
+// and cached, so membership is looked up per request; a failed lookup leaves everything unmarked.
+func (c *CatalogController) markReleases(
+	ctx context.Context,
+	log logger.Logger,
+	user *User,
+	releases []*Release,
+) []CatalogRelease {
+	ids := make([]int64, len(releases))
+	for i, release := range releases {
+		ids[i] = release.ID
+	}
+
+	owned, err := c.userReleaseRepo.GetOwnedReleaseIDs(ctx, c.db, userID(user), ids)
+	if err != nil {
+		log.Warn("failed to get owned releases", "count", len(ids), "error", err)
+	}
+
+	result := make([]CatalogRelease, len(releases))
+	for i, release := range releases {
+		result[i] = CatalogRelease{Release: release, InCollection: owned[release.ID]}
+	}
+	return result
+}
+
+func (c *CatalogController) markMasters(
+	ctx context.Context,
+	log logger.Logger,
+	user *User,
+	masters []*Master,
+) []CatalogMaster {
+	ids := make([]int64, len(masters))
+	for i, master := range masters {
+		ids[i] = master.ID
+	}
+
+	owned, err := c.userReleaseRepo.GetOwnedMasterIDs(ctx, c.db, userID(user), ids)
+	if err != nil {
+		log.Warn("failed to get owned masters", "count", len(ids), "error", err)
+	}
+
+	result := make([]CatalogMaster, len(masters))
+	for i, master := range masters {
+		result[i] = CatalogMaster{Master: master, InCollection: owned[master.ID]}
+	}
+	return result
+}
+
+func userID(user *User) uuid.UUID {
+	if user == nil {
+		return uuid.Nil
+	}
+	return user.ID
+}
@@ -0,0 +1,69 @@
+package catalogController
+
+import (
+	"context"
+	"errors"
+	"testing"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// missingCatalog finds no entity, like the repository does for unknown and tombstoned IDs
+type missingCatalog struct {
+	repositories.CatalogRepository
+	err error
+}
+
+func (m missingCatalog) GetRelease(context.Context, *gorm.DB, int64) (*Release, error) {
+	return nil, m.err
+}
+
+func (m missingCatalog) GetMaster(context.Context, *gorm.DB, int64) (*Master, error) {
+	return nil, m.err
+}
+
+func (m missingCatalog) GetArtist(context.Context, *gorm.DB, int64) (*Artist, error) {
+	return nil, m.err
+}
+
+func (m missingCatalog) GetLabel(context.Context, *gorm.DB, int64) (*Label, error) {
+	return nil, m.err
+}
+
+func TestMissingEntitiesAreNotFound(t *testing.T) {
+	ctx := context.Background()
+	page := NewPage(1, DefaultPageLimit)
+	controller := &CatalogController{
+		catalogRepo: missingCatalog{err: errors.Join(errors.New("failed to get entity"), gorm.ErrRecordNotFound)},
+	}
+
+	_, err := controller.GetRelease(ctx, nil, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = controller.GetMaster(ctx, nil, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = controller.GetArtist(ctx, nil, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = controller.GetLabel(ctx, nil, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Listings of a missing parent are not found rather than empty
+	_, err = controller.ListMasterReleases(ctx, nil, 1, page)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = controller.ListArtistReleases(ctx, nil, 1, page)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = controller.ListArtistMasters(ctx, nil, 1, page)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = controller.ListLabelReleases(ctx, nil, 1, page)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestOtherLookupErrorsAreNotNotFound(t *testing.T) {
+	controller := &CatalogController{catalogRepo: missingCatalog{err: errors.New("connection refused")}}
+
+	_, err := controller.GetRelease(context.Background(), nil, 1)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
@@ -9,12 +9,17 @@ import (
 
 	adminController "waugzee/internal/controllers/admin"
 	authController "waugzee/internal/controllers/auth"
+	catalogController "waugzee/internal/controllers/catalog"
 	historyController "waugzee/internal/controllers/history"
+	imagesController "waugzee/internal/controllers/images"
 	loggingController "waugzee/internal/controllers/logging"
 	recommendationController "waugzee/internal/controllers/recommendation"
+	roleController "waugzee/internal/controllers/roles"
 	stylusController "waugzee/internal/controllers/stylus"
 	syncController "waugzee/internal/controllers/sync"
+	tokenController "waugzee/internal/controllers/tokens"
 	userController "waugzee/internal/controllers/users"
+	webhookController "waugzee/internal/controllers/webhooks"
 )
 
 type Controllers struct {
@@ -26,6 +31,11 @@ type Controllers struct {
 	Admin          adminController.AdminControllerInterface
 	Recommendation recommendationController.RecommendationControllerInterface
 	Logging        loggingController.LoggingControllerInterface
+	Catalog        catalogController.CatalogControllerInterface
+	Images         imagesController.ImagesControllerInterface
+	Token          tokenController.TokenControllerInterface
+	Role           roleController.RoleControllerInterface
+	Webhook        webhookController.WebhookControllerInterface
 }
 
 func New(
@@ -36,15 +46,16 @@ func New(
 	db database.DB,
 ) Controllers {
 	return Controllers{
-		User:    userController.New(repos, services, config, db),
+		User:    userController.New(repos, services, eventBus, config, db),
 		Auth:    authController.New(services, repos, db),
 		Sync:    syncController.New(repos, services, eventBus, config),
 		Stylus:  stylusController.New(repos, services, config, db),
-		History: historyController.New(repos, services, config, db),
+		History: historyController.New(repos, services, eventBus, config, db),
 		Admin: adminController.NewAdminController(
 			db.SQL,
 			repos.DiscogsDataProcessing,
 			services.Download,
+			services.DumpImport,
 			services.DiscogsXMLParser,
 			services.Scheduler,
 			services.FileCleanup,
@@ -52,5 +63,10 @@ func New(
 		),
 		Recommendation: recommendationController.New(repos, &services, db.SQL, db.Cache.ClientAPI),
 		Logging:        loggingController.New(services),
+		Catalog:        catalogController.New(repos, db.SQL),
+		Images:         imagesController.New(services),
+		Token:          tokenController.New(services),
+		Role:           roleController.New(services),
+		Webhook:        webhookController.New(services),
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 	"waugzee/config"
 	"waugzee/internal/database"
+	"waugzee/internal/events"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
 	"waugzee/internal/repositories"
@@ -28,6 +29,7 @@ type HistoryController struct {
 	historyRepo        repositories.HistoryRepository
 	stylusRepo         repositories.StylusRepository
 	transactionService *services.TransactionService
+	eventBus           *events.EventBus
 	db                 database.DB
 	Config             config.Config
 }
@@ -98,6 +100,7 @@ type HistoryControllerInterface interface {
 func New(
 	repos repositories.Repository,
 	services services.Service,
+	eventBus *events.EventBus,
 	config config.Config,
 	db database.DB,
 ) HistoryControllerInterface {
@@ -105,6 +108,7 @@ func New(
 		historyRepo:        repos.History,
 		stylusRepo:         repos.Stylus,
 		transactionService: services.Transaction,
+		eventBus:           eventBus,
 		db:                 db,
 		Config:             config,
 	}
@@ -196,6 +200,8 @@ func (c *HistoryController) LogPlay(
 		playHistory.ID,
 	)
 
+	c.publishActivity(ctx, user, playLogged(playHistory))
+
 	return playHistory, nil
 }
 
@@ -374,6 +380,8 @@ func (c *HistoryController) LogCleaning(
 		cleaningHistory.ID,
 	)
 
+	c.publishActivity(ctx, user, cleaningLogged(cleaningHistory))
+
 	return cleaningHistory, nil
 }
 
@@ -598,8 +606,42 @@ func (c *HistoryController) LogBoth(
 		cleaningHistory.ID,
 	)
 
+	c.publishActivity(ctx, user, playLogged(playHistory))
+	c.publishActivity(ctx, user, cleaningLogged(cleaningHistory))
+
 	return &LogBothResponse{
 		PlayHistory:     playHistory,
 		CleaningHistory: cleaningHistory,
 	}, nil
 }
+
+// publishActivity hands a logged play or cleaning to webhooks and other follow-up work. The history
+// is already saved, so a failed publish is logged and does not fail the request.
+func (c *HistoryController) publishActivity(ctx context.Context, user *User, payload events.Payload) {
+	if err := c.eventBus.PublishActivity(user.ID.String(), payload); err != nil {
+		log := logger.New("historyController").TraceFromContext(ctx).Function("publishActivity")
+		log.Warn("Failed to publish activity", "userID", user.ID, "event", payload.EventName(), "error", err)
+	}
+}
+
+func playLogged(playHistory *PlayHistory) events.PlayLogged {
+	played := events.PlayLogged{
+		PlayHistoryID: playHistory.ID.String(),
+		UserReleaseID: playHistory.UserReleaseID.String(),
+		PlayedAt:      playHistory.PlayedAt,
+	}
+	if playHistory.UserStylusID != nil {
+		userStylusID := playHistory.UserStylusID.String()
+		played.UserStylusID = &userStylusID
+	}
+	return played
+}
+
+func cleaningLogged(cleaningHistory *CleaningHistory) events.CleaningLogged {
+	return events.CleaningLogged{
+		CleaningHistoryID: cleaningHistory.ID.String(),
+		UserReleaseID:     cleaningHistory.UserReleaseID.String(),
+		CleanedAt:         cleaningHistory.CleanedAt,
+		IsDeepClean:       cleaningHistory.IsDeepClean,
+	}
+}
@@ -0,0 +1,78 @@
+package imagesController
+
+import (
+	"context"
+	"strings"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// ReleaseImage is a cover image variant ready to serve. Image is nil when the client's copy,
+// identified by If-None-Match, is still current.
+type ReleaseImage struct {
+	ETag        string
+	NotModified bool
+	Image       *services.CachedImage
+}
+
+type ImagesController struct {
+	imageService *services.ImageService
+}
+
+type ImagesControllerInterface interface {
+	GetReleaseImage(
+		ctx context.Context,
+		userID uuid.UUID,
+		releaseID int64,
+		size string,
+		ifNoneMatch string,
+	) (*ReleaseImage, error)
+}
+
+func New(services services.Service) ImagesControllerInterface {
+	return &ImagesController{
+		imageService: services.Image,
+	}
+}
+
+// GetReleaseImage serves a size of a release's cover image from the cache, fetching and rendering
+// it on first request. Fetches on a cache miss count against the user's image fetch limit.
+func (ic *ImagesController) GetReleaseImage(
+	ctx context.Context,
+	userID uuid.UUID,
+	releaseID int64,
+	size string,
+	ifNoneMatch string,
+) (*ReleaseImage, error) {
+	log := logger.New("imagesController").TraceFromContext(ctx).Function("GetReleaseImage")
+
+	variant, err := ic.imageService.ResolveVariant(ctx, releaseID, size)
+	if err != nil {
+		return nil, log.Err("failed to resolve image", err, "releaseID", releaseID, "size", size)
+	}
+
+	etag := variant.ETag()
+	if etagMatches(ifNoneMatch, etag) {
+		return &ReleaseImage{ETag: etag, NotModified: true}, nil
+	}
+
+	image, err := ic.imageService.LoadForUser(ctx, userID, variant)
+	if err != nil {
+		return nil, log.Err("failed to load image", err, "releaseID", releaseID, "size", size)
+	}
+
+	return &ReleaseImage{ETag: etag, Image: image}, nil
+}
+
+// etagMatches checks an If-None-Match header, which may list several ETags or be "*"
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package roleController
+
+import (
+	"context"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/services"
+
+	"github.com/google/uuid"
+)
+
+type AssignRoleRequest struct {
+	Role Role `json:"role"`
+}
+
+type RoleController struct {
+	authorizationService *services.AuthorizationService
+}
+
+type RoleControllerInterface interface {
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*UserRole, error)
+	AssignRole(ctx context.Context, admin *User, userID uuid.UUID, req *AssignRoleRequest) error
+	RevokeRole(ctx context.Context, admin *User, userID uuid.UUID, role Role) error
+}
+
+func New(services services.Service) RoleControllerInterface {
+	return &RoleController{
+		authorizationService: services.Authorization,
+	}
+}
+
+// ListUserRoles returns the roles assigned to the user in the app, roles granted by the identity
+// provider are not stored and only show on the user's own /users/me
+func (rc *RoleController) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]*UserRole, error) {
+	log := logger.New("roleController").TraceFromContext(ctx).Function("ListUserRoles")
+
+	userRoles, err := rc.authorizationService.ListRoles(ctx, userID)
+	if err != nil {
+		return nil, log.Err("failed to list user roles", err, "userID", userID)
+	}
+
+	return userRoles, nil
+}
+
+func (rc *RoleController) AssignRole(
+	ctx context.Context,
+	admin *User,
+	userID uuid.UUID,
+	req *AssignRoleRequest,
+) error {
+	log := logger.New("roleController").TraceFromContext(ctx).Function("AssignRole")
+
+	if err := rc.authorizationService.AssignRole(ctx, admin, userID, req.Role); err != nil {
+		return log.Err("failed to assign role", err, "userID", userID, "role", req.Role)
+	}
+
+	return nil
+}
+
+func (rc *RoleController) RevokeRole(ctx context.Context, admin *User, userID uuid.UUID, role Role) error {
+	log := logger.New("roleController").TraceFromContext(ctx).Function("RevokeRole")
+
+	if err := rc.authorizationService.RevokeRole(ctx, userID, role); err != nil {
+		return log.Err("failed to revoke role", err, "userID", userID, "role", role, "adminID", admin.ID)
+	}
+
+	return nil
+}
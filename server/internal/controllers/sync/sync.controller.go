@@ -14,12 +14,14 @@ type SyncController struct {
 	userRepo             repositories.UserRepository
 	discogsService       *services.DiscogsService
 	orchestrationService *services.OrchestrationService
+	freshnessService     *services.ReleaseFreshnessService
 	eventBus             *events.EventBus
 	config               config.Config
 }
 
 type SyncControllerInterface interface {
 	HandleSyncRequest(ctx context.Context, user *User) error
+	RefreshRelease(ctx context.Context, user *User, releaseID int64) error
 }
 
 func New(
@@ -32,6 +34,7 @@ func New(
 		userRepo:             repos.User,
 		discogsService:       services.Discogs,
 		orchestrationService: services.Orchestration,
+		freshnessService:     services.ReleaseFreshness,
 		eventBus:             eventBus,
 		config:               config,
 	}
@@ -58,3 +61,20 @@ func (sc *SyncController) HandleSyncRequest(
 
 	return nil
 }
+
+// RefreshRelease requests fresh data for one release through the user's client proxy, whether or
+// not the staleness policy considers it stale
+func (sc *SyncController) RefreshRelease(
+	ctx context.Context,
+	user *User,
+	releaseID int64,
+) error {
+	log := logger.New("syncController").TraceFromContext(ctx).Function("RefreshRelease")
+
+	if err := sc.freshnessService.RefreshRelease(ctx, user, releaseID); err != nil {
+		return log.Err("failed to refresh release", err, "releaseID", releaseID)
+	}
+
+	log.Info("Release refresh requested", "userID", user.ID, "releaseID", releaseID)
+	return nil
+}
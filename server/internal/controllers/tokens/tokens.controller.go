@@ -0,0 +1,92 @@
+package tokenController
+
+import (
+	"context"
+	"errors"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// MaxApiTokenLifetimeDays caps the expiry a token can be created with
+const MaxApiTokenLifetimeDays = 365
+
+var ErrInvalidExpiresInDays = errors.New("expiresInDays must be between 1 and 365")
+
+type CreateTokenRequest struct {
+	Name   string          `json:"name"`
+	Scopes []ApiTokenScope `json:"scopes"`
+	// ExpiresInDays is how long the token is valid, nil creates a token that never expires
+	ExpiresInDays *int `json:"expiresInDays"`
+}
+
+// CreateTokenResponse carries the token secret, which is only ever returned here
+type CreateTokenResponse struct {
+	Token  *ApiToken `json:"token"`
+	Secret string    `json:"secret"`
+}
+
+type TokenController struct {
+	apiTokenService *services.ApiTokenService
+}
+
+type TokenControllerInterface interface {
+	ListTokens(ctx context.Context, user *User) ([]*ApiToken, error)
+	CreateToken(ctx context.Context, user *User, req *CreateTokenRequest) (*CreateTokenResponse, error)
+	RevokeToken(ctx context.Context, user *User, tokenID uuid.UUID) error
+}
+
+func New(services services.Service) TokenControllerInterface {
+	return &TokenController{
+		apiTokenService: services.ApiToken,
+	}
+}
+
+func (tc *TokenController) ListTokens(ctx context.Context, user *User) ([]*ApiToken, error) {
+	log := logger.New("tokenController").TraceFromContext(ctx).Function("ListTokens")
+
+	tokens, err := tc.apiTokenService.List(ctx, user.ID)
+	if err != nil {
+		return nil, log.Err("failed to list tokens", err, "userID", user.ID)
+	}
+
+	return tokens, nil
+}
+
+func (tc *TokenController) CreateToken(
+	ctx context.Context,
+	user *User,
+	req *CreateTokenRequest,
+) (*CreateTokenResponse, error) {
+	log := logger.New("tokenController").TraceFromContext(ctx).Function("CreateToken")
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		days := *req.ExpiresInDays
+		if days < 1 || days > MaxApiTokenLifetimeDays {
+			return nil, log.Err("invalid token expiry", ErrInvalidExpiresInDays, "expiresInDays", days)
+		}
+		expiry := time.Now().UTC().AddDate(0, 0, days)
+		expiresAt = &expiry
+	}
+
+	token, secret, err := tc.apiTokenService.Create(ctx, user, req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		return nil, log.Err("failed to create token", err, "userID", user.ID)
+	}
+
+	return &CreateTokenResponse{Token: token, Secret: secret}, nil
+}
+
+func (tc *TokenController) RevokeToken(ctx context.Context, user *User, tokenID uuid.UUID) error {
+	log := logger.New("tokenController").TraceFromContext(ctx).Function("RevokeToken")
+
+	if err := tc.apiTokenService.Revoke(ctx, user.ID, tokenID); err != nil {
+		return log.Err("failed to revoke token", err, "userID", user.ID, "tokenID", tokenID)
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 	"waugzee/config"
 	"waugzee/internal/database"
+	"waugzee/internal/events"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
 	"waugzee/internal/repositories"
@@ -24,6 +25,7 @@ type UserController struct {
 	historyRepo        repositories.HistoryRepository
 	recommendationRepo repositories.DailyRecommendationRepository
 	discogsService     *services.DiscogsService
+	eventBus           *events.EventBus
 	db                 database.DB
 	Config             config.Config
 }
@@ -65,6 +67,7 @@ type UserControllerInterface interface {
 func New(
 	repos repositories.Repository,
 	services services.Service,
+	eventBus *events.EventBus,
 	config config.Config,
 	db database.DB,
 ) UserControllerInterface {
@@ -77,6 +80,7 @@ func New(
 		historyRepo:        repos.History,
 		recommendationRepo: repos.DailyRecommendation,
 		discogsService:     services.Discogs,
+		eventBus:           eventBus,
 		db:                 db,
 		Config:             config,
 	}
@@ -417,6 +421,8 @@ func (uc *UserController) generateNewRecommendation(
 		return nil, log.Err("failed to create recommendation", err, "userID", user.ID)
 	}
 
+	uc.publishRecommendationCreated(ctx, recommendation)
+
 	recommendation, err = uc.recommendationRepo.GetMostRecentRecommendation(
 		ctx,
 		uc.db.SQL,
@@ -461,6 +467,8 @@ func (uc *UserController) generateRandomRecommendation(
 		return nil, log.Err("failed to create random recommendation", err, "userID", userID)
 	}
 
+	uc.publishRecommendationCreated(ctx, recommendation)
+
 	recommendation, err = uc.recommendationRepo.GetMostRecentRecommendation(
 		ctx,
 		uc.db.SQL,
@@ -610,3 +618,21 @@ func (uc *UserController) calculateUserStreak(
 
 	return streakData, nil
 }
+
+// publishRecommendationCreated hands a new daily recommendation to webhooks, a failed publish does
+// not fail the request
+func (uc *UserController) publishRecommendationCreated(
+	ctx context.Context,
+	recommendation *DailyRecommendation,
+) {
+	err := uc.eventBus.PublishActivity(recommendation.UserID.String(), events.RecommendationCreated{
+		RecommendationID: recommendation.ID.String(),
+		UserReleaseID:    recommendation.UserReleaseID.String(),
+		Date:             recommendation.Date.Format(time.DateOnly),
+		Algorithm:        recommendation.Algorithm,
+	})
+	if err != nil {
+		log := logger.New("userController").TraceFromContext(ctx).Function("publishRecommendationCreated")
+		log.Warn("Failed to publish recommendation created", "userID", recommendation.UserID, "error", err)
+	}
+}
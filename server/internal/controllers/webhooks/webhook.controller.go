@@ -0,0 +1,115 @@
+package webhookController
+
+import (
+	"context"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/services"
+
+	"github.com/google/uuid"
+)
+
+type CreateWebhookRequest struct {
+	URL         string         `json:"url"`
+	Description string         `json:"description"`
+	Events      []WebhookEvent `json:"events"`
+}
+
+// CreateWebhookResponse carries the signing secret, which is only ever returned here
+type CreateWebhookResponse struct {
+	Webhook *Webhook `json:"webhook"`
+	Secret  string   `json:"secret"`
+}
+
+type WebhookController struct {
+	webhookService *services.WebhookService
+}
+
+type WebhookControllerInterface interface {
+	ListWebhooks(ctx context.Context, user *User) ([]*Webhook, error)
+	CreateWebhook(ctx context.Context, user *User, req *CreateWebhookRequest) (*CreateWebhookResponse, error)
+	DeleteWebhook(ctx context.Context, user *User, webhookID uuid.UUID) error
+	ListDeliveries(ctx context.Context, user *User, webhookID uuid.UUID) ([]*WebhookDelivery, error)
+	ReplayDelivery(
+		ctx context.Context,
+		user *User,
+		webhookID uuid.UUID,
+		deliveryID uuid.UUID,
+	) (*WebhookDelivery, error)
+}
+
+func New(services services.Service) WebhookControllerInterface {
+	return &WebhookController{
+		webhookService: services.Webhook,
+	}
+}
+
+func (wc *WebhookController) ListWebhooks(ctx context.Context, user *User) ([]*Webhook, error) {
+	log := logger.New("webhookController").TraceFromContext(ctx).Function("ListWebhooks")
+
+	webhooks, err := wc.webhookService.List(ctx, user.ID)
+	if err != nil {
+		return nil, log.Err("failed to list webhooks", err, "userID", user.ID)
+	}
+
+	return webhooks, nil
+}
+
+func (wc *WebhookController) CreateWebhook(
+	ctx context.Context,
+	user *User,
+	req *CreateWebhookRequest,
+) (*CreateWebhookResponse, error) {
+	log := logger.New("webhookController").TraceFromContext(ctx).Function("CreateWebhook")
+
+	webhook, secret, err := wc.webhookService.Create(ctx, user.ID, req.URL, req.Description, req.Events)
+	if err != nil {
+		return nil, log.Err("failed to create webhook", err, "userID", user.ID)
+	}
+
+	return &CreateWebhookResponse{Webhook: webhook, Secret: secret}, nil
+}
+
+func (wc *WebhookController) DeleteWebhook(ctx context.Context, user *User, webhookID uuid.UUID) error {
+	log := logger.New("webhookController").TraceFromContext(ctx).Function("DeleteWebhook")
+
+	if err := wc.webhookService.Delete(ctx, user.ID, webhookID); err != nil {
+		return log.Err("failed to delete webhook", err, "userID", user.ID, "webhookID", webhookID)
+	}
+
+	return nil
+}
+
+func (wc *WebhookController) ListDeliveries(
+	ctx context.Context,
+	user *User,
+	webhookID uuid.UUID,
+) ([]*WebhookDelivery, error) {
+	log := logger.New("webhookController").TraceFromContext(ctx).Function("ListDeliveries")
+
+	deliveries, err := wc.webhookService.Deliveries(ctx, user.ID, webhookID)
+	if err != nil {
+		return nil, log.Err("failed to list webhook deliveries", err, "userID", user.ID, "webhookID", webhookID)
+	}
+
+	return deliveries, nil
+}
+
+func (wc *WebhookController) ReplayDelivery(
+	ctx context.Context,
+	user *User,
+	webhookID uuid.UUID,
+	deliveryID uuid.UUID,
+) (*WebhookDelivery, error) {
+	log := logger.New("webhookController").TraceFromContext(ctx).Function("ReplayDelivery")
+
+	delivery, err := wc.webhookService.Replay(ctx, user.ID, webhookID, deliveryID)
+	if err != nil {
+		return nil, log.Err("failed to replay webhook delivery", err,
+			"userID", user.ID,
+			"webhookID", webhookID,
+			"deliveryID", deliveryID)
+	}
+
+	return delivery, nil
+}
@@ -0,0 +1,368 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"waugzee/internal/metrics"
+
+	"github.com/google/uuid"
+	"github.com/valkey-io/valkey-go"
+)
+
+// Delivery is how events on a channel reach their handlers
+type Delivery int
+
+const (
+	// DeliveryPubSub hands every event to the handlers of every replica as it is published, events
+	// published while nobody listens are lost and handler errors are only logged
+	DeliveryPubSub Delivery = iota
+	// DeliveryDurable keeps events in a Valkey stream read by a consumer group, so each event is
+	// handled by one replica. Events are acknowledged once every handler succeeds, retried with
+	// backoff otherwise, and moved to a dead-letter stream after DurableMaxDeliveries attempts.
+	// Handlers may see an event more than once and must be idempotent.
+	DeliveryDurable
+)
+
+// channelDelivery selects the delivery of each channel, channels not listed use pub/sub
+var channelDelivery = map[Channel]Delivery{
+	SYNC:     DeliveryDurable,
+	ACTIVITY: DeliveryDurable,
+}
+
+func (c Channel) Delivery() Delivery {
+	return channelDelivery[c]
+}
+
+const (
+	DURABLE_STREAM_PREFIX      = "events:"
+	DURABLE_DEAD_LETTER_SUFFIX = ":dead"
+	DURABLE_CONSUMER_GROUP     = "waugzee"
+
+	// DurableMaxDeliveries is how many times an event is handed to handlers before it is dead-lettered
+	DurableMaxDeliveries = 5
+
+	durableStreamMaxLen  = 10000
+	durableReadCount     = 10
+	durableReadBlock     = 5 * time.Second
+	durableRetryInterval = 15 * time.Second
+	// Handlers should finish within the base delay, a replica reclaims events pending for longer
+	durableRetryBaseDelay = 5 * time.Minute
+	durableRetryMaxDelay  = time.Hour
+	durableErrorBackoff   = time.Second
+	durablePendingBatch   = 100
+
+	durableEventField = "event"
+)
+
+// DurableStream is the stream a durable channel's events are kept in
+func DurableStream(channel Channel) string {
+	return DURABLE_STREAM_PREFIX + channel.String()
+}
+
+// DeadLetterStream is the stream events that exhausted their deliveries are moved to
+func DeadLetterStream(channel Channel) string {
+	return DurableStream(channel) + DURABLE_DEAD_LETTER_SUFFIX
+}
+
+// consumerName identifies this replica within the consumer group
+func consumerName() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "waugzee"
+	}
+	return hostname + "-" + uuid.New().String()[:8]
+}
+
+// durableRetryDelay is how long an event that failed deliveries times waits before its next
+// attempt, doubling from the base delay up to the max delay
+func durableRetryDelay(deliveries int64) time.Duration {
+	delay := durableRetryBaseDelay
+	for i := int64(1); i < deliveries && delay < durableRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, durableRetryMaxDelay)
+}
+
+func (eb *EventBus) publishDurable(ctx context.Context, channel Channel, eventData []byte) error {
+	log := eb.logger.Function("publishDurable")
+
+	err := eb.client.Do(ctx, eb.client.B().Xadd().
+		Key(DurableStream(channel)).
+		Maxlen().
+		Almost().
+		Threshold(strconv.Itoa(durableStreamMaxLen)).
+		Id("*").
+		FieldValue().
+		FieldValue(durableEventField, string(eventData)).
+		Build()).Error()
+	if err != nil {
+		return log.Err("failed to add event to stream", err, "channel", channel)
+	}
+
+	return nil
+}
+
+// ensureConsumerGroup creates the channel's consumer group, starting with events published from
+// now on when the stream is new
+func (eb *EventBus) ensureConsumerGroup(ctx context.Context, channel Channel) error {
+	err := eb.client.Do(ctx, eb.client.B().XgroupCreate().
+		Key(DurableStream(channel)).
+		Group(DURABLE_CONSUMER_GROUP).
+		Id("$").
+		Mkstream().
+		Build()).Error()
+	if err != nil && !valkey.IsValkeyBusyGroup(err) {
+		return err
+	}
+	return nil
+}
+
+// consumeDurable reads new events of the channel for this replica until the bus is closed
+func (eb *EventBus) consumeDurable(channel Channel) {
+	log := eb.logger.Function("consumeDurable").With("channel", channel, "consumer", eb.consumer)
+	stream := DurableStream(channel)
+
+	eb.listenerStarted(channel)
+	defer eb.listenerStopped(channel, nil)
+
+	for {
+		err := eb.ensureConsumerGroup(eb.ctx, channel)
+		if err == nil {
+			eb.listenerError(channel, nil)
+			break
+		}
+		eb.listenerError(channel, err)
+		log.Warn("Failed to create consumer group, retrying", "error", err)
+		if !eb.sleep(durableErrorBackoff) {
+			return
+		}
+	}
+
+	go eb.retryDurable(channel)
+
+	log.Info("Consuming durable channel")
+	for {
+		if eb.ctx.Err() != nil {
+			return
+		}
+
+		streams, err := eb.client.Do(eb.ctx, eb.client.B().Xreadgroup().
+			Group(DURABLE_CONSUMER_GROUP, eb.consumer).
+			Count(durableReadCount).
+			Block(durableReadBlock.Milliseconds()).
+			Streams().
+			Key(stream).
+			Id(">").
+			Build()).AsXRead()
+		if err != nil {
+			if valkey.IsValkeyNil(err) {
+				eb.listenerError(channel, nil)
+				continue
+			}
+			if eb.ctx.Err() != nil {
+				return
+			}
+			eb.listenerError(channel, err)
+			log.Warn("Failed to read durable channel", "error", err)
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				// The stream was removed, recreate the group
+				_ = eb.ensureConsumerGroup(eb.ctx, channel)
+			}
+			eb.sleep(durableErrorBackoff)
+			continue
+		}
+
+		eb.listenerError(channel, nil)
+		for _, entry := range streams[stream] {
+			eb.handleDurable(channel, entry, 1)
+		}
+	}
+}
+
+// retryDurable periodically reclaims events that were not acknowledged, either because a handler
+// failed or because the replica handling them went away, and dead-letters those out of attempts
+func (eb *EventBus) retryDurable(channel Channel) {
+	ticker := time.NewTicker(durableRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-eb.ctx.Done():
+			return
+		case <-ticker.C:
+			eb.retryPending(channel)
+		}
+	}
+}
+
+type pendingEvent struct {
+	id         string
+	idle       time.Duration
+	deliveries int64
+}
+
+func (eb *EventBus) retryPending(channel Channel) {
+	log := eb.logger.Function("retryPending").With("channel", channel)
+	stream := DurableStream(channel)
+
+	pending, err := eb.pendingEvents(channel)
+	if err != nil {
+		log.Warn("Failed to list pending events", "error", err)
+		return
+	}
+
+	for _, event := range pending {
+		delay := durableRetryDelay(event.deliveries)
+		if event.idle < delay {
+			continue
+		}
+
+		// Claiming with the delay as minimum idle time lets only one replica take the event
+		entries, err := eb.client.Do(eb.ctx, eb.client.B().Xclaim().
+			Key(stream).
+			Group(DURABLE_CONSUMER_GROUP).
+			Consumer(eb.consumer).
+			MinIdleTime(strconv.FormatInt(delay.Milliseconds(), 10)).
+			Id(event.id).
+			Build()).AsXRange()
+		if err != nil {
+			log.Warn("Failed to claim pending event", "id", event.id, "error", err)
+			continue
+		}
+		if len(entries) == 0 {
+			// Claimed by another replica first, or trimmed from the stream
+			continue
+		}
+
+		if event.deliveries >= DurableMaxDeliveries {
+			eb.deadLetter(channel, entries[0], event.deliveries)
+			continue
+		}
+
+		eb.handleDurable(channel, entries[0], event.deliveries+1)
+	}
+}
+
+// pendingEvents lists the channel's unacknowledged events that have waited at least the base delay
+func (eb *EventBus) pendingEvents(channel Channel) ([]pendingEvent, error) {
+	replies, err := eb.client.Do(eb.ctx, eb.client.B().Xpending().
+		Key(DurableStream(channel)).
+		Group(DURABLE_CONSUMER_GROUP).
+		Idle(durableRetryBaseDelay.Milliseconds()).
+		Start("-").
+		End("+").
+		Count(durablePendingBatch).
+		Build()).ToArray()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]pendingEvent, 0, len(replies))
+	for _, reply := range replies {
+		fields, err := reply.ToArray()
+		if err != nil || len(fields) < 4 {
+			continue
+		}
+		id, _ := fields[0].ToString()
+		idle, _ := fields[2].AsInt64()
+		deliveries, _ := fields[3].AsInt64()
+		pending = append(pending, pendingEvent{
+			id:         id,
+			idle:       time.Duration(idle) * time.Millisecond,
+			deliveries: deliveries,
+		})
+	}
+
+	return pending, nil
+}
+
+// handleDurable hands the event to every local handler and acknowledges it once all succeed
+func (eb *EventBus) handleDurable(channel Channel, entry valkey.XRangeEntry, delivery int64) {
+	log := eb.logger.Function("handleDurable").With("channel", channel, "id", entry.ID)
+
+	var event ChannelEvent
+	if err := json.Unmarshal([]byte(entry.FieldValues[durableEventField]), &event); err != nil {
+		log.Er("failed to unmarshal durable event", err)
+		eb.deadLetter(channel, entry, delivery)
+		return
+	}
+	metrics.EventReceived(channel.String(), event.name())
+
+	if err := eb.runHandlers(channel, event); err != nil {
+		log.Warn("Durable event handler failed, will retry",
+			"event", event.Event,
+			"delivery", delivery,
+			"retryIn", durableRetryDelay(delivery),
+			"error", err)
+		return
+	}
+
+	eb.ack(channel, entry.ID)
+}
+
+// runHandlers runs the channel's handlers one after another and returns their combined errors
+func (eb *EventBus) runHandlers(channel Channel, event ChannelEvent) error {
+	eb.mutex.RLock()
+	handlers := eb.handlers[channel]
+	eb.mutex.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deadLetter moves the event to the channel's dead-letter stream and acknowledges it
+func (eb *EventBus) deadLetter(channel Channel, entry valkey.XRangeEntry, deliveries int64) {
+	log := eb.logger.Function("deadLetter").With("channel", channel, "id", entry.ID)
+
+	err := eb.client.Do(eb.ctx, eb.client.B().Xadd().
+		Key(DeadLetterStream(channel)).
+		Maxlen().
+		Almost().
+		Threshold(strconv.Itoa(durableStreamMaxLen)).
+		Id("*").
+		FieldValue().
+		FieldValue(durableEventField, entry.FieldValues[durableEventField]).
+		FieldValue("sourceId", entry.ID).
+		FieldValue("deliveries", strconv.FormatInt(deliveries, 10)).
+		FieldValue("deadLetteredAt", time.Now().UTC().Format(time.RFC3339)).
+		Build()).Error()
+	if err != nil {
+		// Left pending, the next retry pass dead-letters it again
+		log.Er("failed to dead-letter event", err)
+		return
+	}
+
+	_ = log.Error("Event dead-lettered after failed deliveries", "deliveries", deliveries)
+	eb.ack(channel, entry.ID)
+}
+
+func (eb *EventBus) ack(channel Channel, id string) {
+	err := eb.client.Do(eb.ctx, eb.client.B().Xack().
+		Key(DurableStream(channel)).
+		Group(DURABLE_CONSUMER_GROUP).
+		Id(id).
+		Build()).Error()
+	if err != nil {
+		eb.logger.Function("ack").Warn("Failed to acknowledge event", "channel", channel, "id", id, "error", err)
+	}
+}
+
+// sleep waits for the duration and reports false when the bus was closed meanwhile
+func (eb *EventBus) sleep(d time.Duration) bool {
+	select {
+	case <-eb.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"waugzee/config"
+	logger "github.com/Bparsons0904/goLogger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelDelivery(t *testing.T) {
+	assert.Equal(t, DeliveryDurable, SYNC.Delivery())
+	assert.Equal(t, DeliveryPubSub, WEBSOCKET.Delivery(), "every replica fans websocket events out to its clients")
+	assert.Equal(t, DeliveryPubSub, Channel("unknown").Delivery())
+}
+
+func TestDurableStreams(t *testing.T) {
+	assert.Equal(t, "events:sync", DurableStream(SYNC))
+	assert.Equal(t, "events:sync:dead", DeadLetterStream(SYNC))
+}
+
+func TestDurableRetryDelay(t *testing.T) {
+	assert.Equal(t, durableRetryBaseDelay, durableRetryDelay(0))
+	assert.Equal(t, durableRetryBaseDelay, durableRetryDelay(1))
+	assert.Equal(t, 2*durableRetryBaseDelay, durableRetryDelay(2))
+	assert.Equal(t, 4*durableRetryBaseDelay, durableRetryDelay(3))
+	assert.Equal(t, durableRetryMaxDelay, durableRetryDelay(50))
+}
+
+func TestRunHandlers(t *testing.T) {
+	eb := &EventBus{logger: logger.New("test"), handlers: make(map[Channel][]EventHandler)}
+
+	var calls []string
+	errFirst := errors.New("first failed")
+	eb.handlers[SYNC] = []EventHandler{
+		func(event ChannelEvent) error {
+			calls = append(calls, "first")
+			return errFirst
+		},
+		func(event ChannelEvent) error {
+			calls = append(calls, "second")
+			return nil
+		},
+	}
+
+	err := eb.runHandlers(SYNC, ChannelEvent{Event: COLLECTION_SYNCED})
+	assert.ErrorIs(t, err, errFirst)
+	assert.Equal(t, []string{"first", "second"}, calls, "a failing handler does not stop the others")
+
+	assert.NoError(t, eb.runHandlers(WEBSOCKET, ChannelEvent{}))
+}
+
+func TestSleepStopsOnClose(t *testing.T) {
+	eb := New(nil, config.Config{})
+	eb.cancel()
+
+	start := time.Now()
+	assert.False(t, eb.sleep(time.Minute))
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 	"waugzee/config"
 	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/metrics"
 
 	"github.com/valkey-io/valkey-go"
 )
@@ -16,6 +17,11 @@ type Channel string
 const (
 	WEBSOCKET  Channel = "websocket"
 	CONTROLLER Channel = "controller"
+	// SYNC carries background work that follows a collection sync, delivered durably
+	SYNC Channel = "sync"
+	// ACTIVITY carries what users do, such as logging plays, for webhooks and other follow-up
+	// work, delivered durably
+	ACTIVITY Channel = "activity"
 )
 
 func (c Channel) String() string {
@@ -27,6 +33,14 @@ type ChannelEvent struct {
 	Message Message
 }
 
+// name is the registered event of the message, websocket events carry their delivery in Event
+func (e ChannelEvent) name() string {
+	if e.Message.Event != "" {
+		return e.Message.Event
+	}
+	return e.Event
+}
+
 type EventHandler func(event ChannelEvent) error
 
 type EventBus struct {
@@ -34,9 +48,13 @@ type EventBus struct {
 	logger   logger.Logger
 	config   config.Config
 	handlers map[Channel][]EventHandler
-	mutex    sync.RWMutex
-	ctx      context.Context
-	cancel   context.CancelFunc
+	// listeners is guarded by mutex, see health.go
+	listeners map[Channel]*listenerState
+	mutex     sync.RWMutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	// consumer names this replica in the consumer groups of durable channels
+	consumer string
 }
 
 type Service string
@@ -59,21 +77,30 @@ type Message struct {
 	ID        string         `json:"id"`
 	Service   Service        `json:"service,omitempty"`
 	Event     string         `json:"event"`
+	Version   int            `json:"version,omitempty"`
 	UserID    string         `json:"userId,omitempty"`
 	Payload   map[string]any `json:"payload,omitempty"`
 	Timestamp time.Time      `json:"timestamp"`
+	// Stream and Sequence are the replay stream, "user" or "admin", and the message's position in
+	// it, both empty for messages not replayed
+	Stream   string `json:"stream,omitempty"`
+	Sequence string `json:"sequence,omitempty"`
+	// Topic also delivers the message to the clients subscribed to it, see topic.go
+	Topic string `json:"topic,omitempty"`
 }
 
 func New(client valkey.Client, config config.Config) *EventBus {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &EventBus{
-		client:   client,
-		logger:   logger.New("EventBus"),
-		config:   config,
-		handlers: make(map[Channel][]EventHandler),
-		ctx:      ctx,
-		cancel:   cancel,
+		client:    client,
+		logger:    logger.New("EventBus"),
+		config:    config,
+		handlers:  make(map[Channel][]EventHandler),
+		listeners: make(map[Channel]*listenerState),
+		ctx:       ctx,
+		cancel:    cancel,
+		consumer:  consumerName(),
 	}
 }
 
@@ -105,16 +132,45 @@ func (eb *EventBus) Publish(
 		return log.Err("invalid event type parameter", nil)
 	}
 
+	// Events are checked against their registered payload so consumers can rely on the contract
+	if err := Validate(channelEvent.Message); err != nil {
+		return log.Err("event does not match its registered schema", err,
+			"channel", channel,
+			"event", channelEvent.Message.Event)
+	}
+	if channelEvent.Message.Version == 0 {
+		definition, _ := Definition(channelEvent.Message.Event)
+		channelEvent.Message.Version = definition.Version
+	}
+
+	ctx, cancel := context.WithTimeout(eb.ctx, 5*time.Second)
+	defer cancel()
+
+	if channel == WEBSOCKET {
+		if stream, ok := streamForEvent(channelEvent.Event, channelEvent.Message); ok {
+			channelEvent.Message.Stream = channelEvent.Event
+			// Live delivery still works without the stream, only replay is lost
+			if err := eb.appendToStream(ctx, stream, &channelEvent.Message); err != nil {
+				log.Warn("Failed to keep message for replay", "stream", stream, "error", err)
+				channelEvent.Message.Stream = ""
+			}
+		}
+	}
+
 	eventData, err := json.Marshal(channelEvent)
 	if err != nil {
 		return log.Err("failed to marshal event", err, "channel", channel, "event", channelEvent)
 	}
 
-	ctx, cancel := context.WithTimeout(eb.ctx, 5*time.Second)
-	defer cancel()
+	if channel.Delivery() == DeliveryDurable {
+		err = eb.publishDurable(ctx, channel, eventData)
+		metrics.EventPublished(channel.String(), channelEvent.name(), err)
+		return err
+	}
 
 	err = eb.client.Do(ctx, eb.client.B().Publish().Channel(channel.String()).Message(string(eventData)).Build()).
 		Error()
+	metrics.EventPublished(channel.String(), channelEvent.name(), err)
 	if err != nil {
 		return log.Err(
 			"failed to publish event to valkey",
@@ -134,10 +190,19 @@ func (eb *EventBus) Subscribe(channel Channel, handler EventHandler) error {
 
 	eb.mutex.Lock()
 	eb.handlers[channel] = append(eb.handlers[channel], handler)
+	first := len(eb.handlers[channel]) == 1
 	eb.mutex.Unlock()
 
 	log.Info("Handler subscribed to channel", "channel", channel)
 
+	if channel.Delivery() == DeliveryDurable {
+		// One consumer per channel runs every handler, so an event is acknowledged only once
+		if first {
+			go eb.consumeDurable(channel)
+		}
+		return nil
+	}
+
 	// Start listening to this channel if it's the first handler
 	go eb.listenToChannel(channel)
 
@@ -176,6 +241,7 @@ func (eb *EventBus) listenToChannel(channel Channel) {
 	defer cancel()
 
 	log.Info("Starting to listen to channel", "channel", channel)
+	eb.listenerStarted(channel)
 
 	err := eb.client.Receive(
 		ctx,
@@ -186,6 +252,7 @@ func (eb *EventBus) listenToChannel(channel Channel) {
 				log.Er("failed to unmarshal event", err, "channel", channel, "message", msg.Message)
 				return
 			}
+			metrics.EventReceived(channel.String(), event.name())
 
 			eb.notifyLocalHandlers(channel, event)
 		},
@@ -193,6 +260,7 @@ func (eb *EventBus) listenToChannel(channel Channel) {
 	if err != nil {
 		log.Er("failed to listen to channel", err, "channel", channel)
 	}
+	eb.listenerStopped(channel, err)
 }
 
 func (eb *EventBus) Close() error {
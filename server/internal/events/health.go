@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrChannelNotListening = errors.New("channel has no active listener")
+
+// listenerState tracks the listeners of a subscribed channel, see listenerStarted
+type listenerState struct {
+	active int
+	// err is the last error of the listener, cleared once it reads from Valkey again
+	err   error
+	since time.Time
+}
+
+// ChannelHealth is the state of the listener of a subscribed channel
+type ChannelHealth struct {
+	Channel   Channel   `json:"channel"`
+	Listening bool      `json:"listening"`
+	Since     time.Time `json:"since,omitzero"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func (eb *EventBus) listenerStarted(channel Channel) {
+	eb.mutex.Lock()
+	defer eb.mutex.Unlock()
+
+	state := eb.listenerState(channel)
+	state.active++
+	state.err = nil
+	state.since = time.Now()
+}
+
+func (eb *EventBus) listenerStopped(channel Channel, err error) {
+	eb.mutex.Lock()
+	defer eb.mutex.Unlock()
+
+	state := eb.listenerState(channel)
+	state.active = max(state.active-1, 0)
+	state.err = err
+	state.since = time.Now()
+}
+
+// listenerError records the error of a running listener, nil clears it
+func (eb *EventBus) listenerError(channel Channel, err error) {
+	eb.mutex.Lock()
+	defer eb.mutex.Unlock()
+
+	state := eb.listenerState(channel)
+	if (state.err == nil) != (err == nil) {
+		state.since = time.Now()
+	}
+	state.err = err
+}
+
+// listenerState must be called with the mutex held
+func (eb *EventBus) listenerState(channel Channel) *listenerState {
+	state, ok := eb.listeners[channel]
+	if !ok {
+		state = &listenerState{}
+		eb.listeners[channel] = state
+	}
+	return state
+}
+
+// Health pings Valkey and reports the listener of every channel with handlers. It fails when
+// Valkey does not answer or a channel has no listener, or one that keeps failing, since its events
+// then never reach the handlers of this replica.
+func (eb *EventBus) Health(ctx context.Context) ([]ChannelHealth, error) {
+	if err := eb.client.Do(ctx, eb.client.B().Ping().Build()).Error(); err != nil {
+		return nil, fmt.Errorf("failed to ping event bus: %w", err)
+	}
+
+	return eb.listenerHealth()
+}
+
+func (eb *EventBus) listenerHealth() ([]ChannelHealth, error) {
+	eb.mutex.RLock()
+	channels := make([]ChannelHealth, 0, len(eb.handlers))
+	var unhealthy []string
+	for channel := range eb.handlers {
+		health := ChannelHealth{Channel: channel}
+		if state, ok := eb.listeners[channel]; ok {
+			health.Listening = state.active > 0 && state.err == nil
+			health.Since = state.since
+			if state.err != nil {
+				health.Error = state.err.Error()
+			}
+		}
+		if !health.Listening {
+			unhealthy = append(unhealthy, channel.String())
+		}
+		channels = append(channels, health)
+	}
+	eb.mutex.RUnlock()
+
+	slices.SortFunc(channels, func(a, b ChannelHealth) int {
+		return strings.Compare(a.Channel.String(), b.Channel.String())
+	})
+
+	if len(unhealthy) > 0 {
+		slices.Sort(unhealthy)
+		return channels, fmt.Errorf("%w: %v", ErrChannelNotListening, unhealthy)
+	}
+	return channels, nil
+}
@@ -0,0 +1,44 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"waugzee/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerHealth(t *testing.T) {
+	eb := New(nil, config.Config{})
+	eb.handlers[WEBSOCKET] = []EventHandler{nil}
+	eb.handlers[SYNC] = []EventHandler{nil}
+
+	_, err := eb.listenerHealth()
+	assert.ErrorIs(t, err, ErrChannelNotListening, "subscribed channels are not listened to until a listener starts")
+
+	eb.listenerStarted(WEBSOCKET)
+	eb.listenerStarted(SYNC)
+	channels, err := eb.listenerHealth()
+	require.NoError(t, err)
+	require.Len(t, channels, 2)
+	assert.Equal(t, SYNC, channels[0].Channel)
+	assert.Equal(t, WEBSOCKET, channels[1].Channel)
+	assert.True(t, channels[0].Listening)
+
+	readErr := errors.New("connection refused")
+	eb.listenerError(SYNC, readErr)
+	channels, err = eb.listenerHealth()
+	assert.ErrorIs(t, err, ErrChannelNotListening)
+	assert.False(t, channels[0].Listening)
+	assert.Equal(t, readErr.Error(), channels[0].Error)
+
+	eb.listenerError(SYNC, nil)
+	_, err = eb.listenerHealth()
+	assert.NoError(t, err, "a listener recovers once it reads again")
+
+	eb.listenerStopped(WEBSOCKET, readErr)
+	channels, err = eb.listenerHealth()
+	assert.ErrorIs(t, err, ErrChannelNotListening)
+	assert.False(t, channels[1].Listening)
+}
@@ -0,0 +1,160 @@
+package events
+
+import "time"
+
+// Event names, the name and version of each event are its contract with consumers
+const (
+	SYNC_START    = "sync_start"
+	SYNC_COMPLETE = "sync_complete"
+	SYNC_ERROR    = "sync_error"
+	SYNC_PROGRESS = "sync_progress"
+	API_REQUEST   = "api_request"
+	// COLLECTION_SYNCED is published on SYNC when a user's collection sync completes
+	COLLECTION_SYNCED = "collection_synced"
+	// Published on ACTIVITY as users log their listening, see PublishActivity
+	PLAY_LOGGED            = "play_logged"
+	CLEANING_LOGGED        = "cleaning_logged"
+	RECOMMENDATION_CREATED = "recommendation_created"
+	STYLUS_REPLACEMENT_DUE = "stylus_replacement_due"
+)
+
+func init() {
+	register[SyncStarted](WEBSOCKET, 1, "A user's collection sync started")
+	register[SyncCompleted](WEBSOCKET, 1, "A user's collection sync completed")
+	register[SyncFailed](WEBSOCKET, 1, "A user's collection sync failed")
+	register[SyncProgress](WEBSOCKET, 1,
+		"Folders processed so far by a user's collection sync, sent to the sync topic")
+	register[APIRequest](WEBSOCKET, 1,
+		"Asks the user's client to call the Discogs API and answer with an api_response")
+	register[AdminDownloadProgress](WEBSOCKET, 1, "Progress of a Discogs dump file download")
+	register[AdminProcessingProgress](WEBSOCKET, 1, "Progress of processing Discogs dump files")
+	register[CollectionSynced](SYNC, 1, "Background work following a completed collection sync")
+	register[PlayLogged](ACTIVITY, 1, "A user logged a play of a record")
+	register[CleaningLogged](ACTIVITY, 1, "A user logged a cleaning of a record")
+	register[RecommendationCreated](ACTIVITY, 1, "A daily recommendation was picked for a user")
+	register[StylusReplacementDue](ACTIVITY, 1,
+		"A user's stylus is nearing its recommended replacement hours, sent once per stylus")
+}
+
+type SyncStarted struct {
+	Message string `json:"message"`
+}
+
+func (SyncStarted) EventName() string { return SYNC_START }
+
+type SyncCompleted struct {
+	Message       string `json:"message"`
+	TotalReleases int    `json:"totalReleases"`
+}
+
+func (SyncCompleted) EventName() string { return SYNC_COMPLETE }
+
+type SyncFailed struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func (SyncFailed) EventName() string { return SYNC_ERROR }
+
+type SyncProgress struct {
+	SyncOperationID  string `json:"syncOperationId"`
+	ProcessedFolders int    `json:"processedFolders"`
+	TotalFolders     int    `json:"totalFolders"`
+	TotalReleases    int    `json:"totalReleases"`
+}
+
+func (SyncProgress) EventName() string { return SYNC_PROGRESS }
+
+// APIRequest is a Discogs API call made by the client on behalf of the server, the response is
+// sent back as callbackEvent and matched to the request by requestId
+type APIRequest struct {
+	RequestID       string            `json:"requestId"`
+	RequestType     string            `json:"requestType"`
+	URL             string            `json:"url"`
+	Method          string            `json:"method"`
+	Headers         map[string]string `json:"headers"`
+	CallbackService string            `json:"callbackService"`
+	CallbackEvent   string            `json:"callbackEvent"`
+	FolderID        *int              `json:"folderID,omitempty"`
+	Page            *int              `json:"page,omitempty"`
+	ReleaseID       *int64            `json:"releaseId,omitempty"`
+	// SyncStateID is set for release requests that are part of a collection sync
+	SyncStateID string `json:"syncStateId,omitempty"`
+}
+
+func (APIRequest) EventName() string { return API_REQUEST }
+
+// AdminDownloadProgress reports a dump download. For a resumed download, downloaded and total
+// count the whole file and resumedFrom is the offset the transfer continued from.
+type AdminDownloadProgress struct {
+	YearMonth    string  `json:"yearMonth"`
+	Status       string  `json:"status"`
+	FileType     string  `json:"fileType"`
+	Stage        string  `json:"stage"`
+	Downloaded   int64   `json:"downloaded"`
+	Total        int64   `json:"total"`
+	Percentage   float64 `json:"percentage"`
+	ResumedFrom  int64   `json:"resumedFrom"`
+	ErrorMessage *string `json:"errorMessage"`
+}
+
+func (AdminDownloadProgress) EventName() string { return string(ADMIN_DOWNLOAD_PROGRESS) }
+
+type AdminProcessingProgress struct {
+	YearMonth      string  `json:"yearMonth"`
+	Status         string  `json:"status"`
+	FileType       string  `json:"fileType"`
+	Step           string  `json:"step"`
+	Stage          string  `json:"stage"`
+	FilesProcessed int64   `json:"filesProcessed"`
+	TotalFiles     int64   `json:"totalFiles"`
+	Percentage     float64 `json:"percentage"`
+	ErrorMessage   *string `json:"errorMessage"`
+}
+
+func (AdminProcessingProgress) EventName() string { return string(ADMIN_PROCESSING_PROGRESS) }
+
+type CollectionSynced struct {
+	ReleaseIDs []int64 `json:"releaseIds"`
+}
+
+func (CollectionSynced) EventName() string { return COLLECTION_SYNCED }
+
+// PlayLogged and the other activity payloads name records by their IDs in the app, userStylusId is
+// left out for plays logged without a stylus
+type PlayLogged struct {
+	PlayHistoryID string    `json:"playHistoryId"`
+	UserReleaseID string    `json:"userReleaseId"`
+	UserStylusID  *string   `json:"userStylusId,omitempty"`
+	PlayedAt      time.Time `json:"playedAt"`
+}
+
+func (PlayLogged) EventName() string { return PLAY_LOGGED }
+
+type CleaningLogged struct {
+	CleaningHistoryID string    `json:"cleaningHistoryId"`
+	UserReleaseID     string    `json:"userReleaseId"`
+	CleanedAt         time.Time `json:"cleanedAt"`
+	IsDeepClean       bool      `json:"isDeepClean"`
+}
+
+func (CleaningLogged) EventName() string { return CLEANING_LOGGED }
+
+type RecommendationCreated struct {
+	RecommendationID string `json:"recommendationId"`
+	UserReleaseID    string `json:"userReleaseId"`
+	// Date is the day the recommendation is for, as YYYY-MM-DD
+	Date      string `json:"date"`
+	Algorithm string `json:"algorithm"`
+}
+
+func (RecommendationCreated) EventName() string { return RECOMMENDATION_CREATED }
+
+type StylusReplacementDue struct {
+	UserStylusID            string  `json:"userStylusId"`
+	Stylus                  string  `json:"stylus"`
+	HoursUsed               float64 `json:"hoursUsed"`
+	RecommendedReplaceHours int     `json:"recommendedReplaceHours"`
+}
+
+func (StylusReplacementDue) EventName() string { return STYLUS_REPLACEMENT_DUE }
@@ -0,0 +1,251 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownEvent   = errors.New("event is not registered")
+	ErrEventVersion   = errors.New("event version is not supported")
+	ErrInvalidPayload = errors.New("event payload does not match its schema")
+)
+
+// Payload is the typed body of an event. Every payload type is registered in payloads.go, which
+// is the contract for the frontend and other consumers of the events.
+type Payload interface {
+	EventName() string
+}
+
+// EventDefinition describes a registered event
+type EventDefinition struct {
+	Name    string
+	Version int
+	// Channel is the channel the event is published on
+	Channel     Channel
+	Description string
+	payloadType reflect.Type
+}
+
+// VersionedName identifies the event and the version of its payload, e.g. "sync_complete.v1"
+func (d EventDefinition) VersionedName() string {
+	return fmt.Sprintf("%s.v%d", d.Name, d.Version)
+}
+
+var registry = map[string]EventDefinition{}
+
+// register adds the payload type to the registry. A breaking change to a payload bumps its
+// version rather than changing the existing one in place.
+func register[T Payload](channel Channel, version int, description string) {
+	var payload T
+	name := payload.EventName()
+	if _, exists := registry[name]; exists {
+		panic("event registered twice: " + name)
+	}
+
+	registry[name] = EventDefinition{
+		Name:        name,
+		Version:     version,
+		Channel:     channel,
+		Description: description,
+		payloadType: reflect.TypeFor[T](),
+	}
+}
+
+// Definition returns the registered event with the name
+func Definition(name string) (EventDefinition, bool) {
+	definition, ok := registry[name]
+	return definition, ok
+}
+
+// Definitions returns every registered event ordered by name
+func Definitions() []EventDefinition {
+	definitions := make([]EventDefinition, 0, len(registry))
+	for _, definition := range registry {
+		definitions = append(definitions, definition)
+	}
+	slices.SortFunc(definitions, func(a, b EventDefinition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return definitions
+}
+
+// NewMessage builds the message carrying the payload, callers set ID when the message needs a
+// specific one such as an API request ID
+func NewMessage(service Service, userID string, payload Payload) (Message, error) {
+	definition, ok := Definition(payload.EventName())
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %s", ErrUnknownEvent, payload.EventName())
+	}
+
+	encoded, err := EncodePayload(payload)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		ID:        uuid.New().String(),
+		Service:   service,
+		Event:     definition.Name,
+		Version:   definition.Version,
+		UserID:    userID,
+		Payload:   encoded,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// PublishToUser publishes the payload to the user's websocket clients
+func (eb *EventBus) PublishToUser(userID string, payload Payload) error {
+	message, err := NewMessage(USER, userID, payload)
+	if err != nil {
+		return err
+	}
+	return eb.Publish(WEBSOCKET, "user", message)
+}
+
+// PublishToAdmins publishes the payload to the websocket clients allowed to manage dumps, and to
+// the subscribers of the topic when one is given
+func (eb *EventBus) PublishToAdmins(topic string, payload Payload) error {
+	message, err := NewMessage(SYSTEM, "", payload)
+	if err != nil {
+		return err
+	}
+	message.Topic = topic
+	return eb.Publish(WEBSOCKET, "admin", message)
+}
+
+// PublishActivity publishes the payload on the durable ACTIVITY channel
+func (eb *EventBus) PublishActivity(userID string, payload Payload) error {
+	message, err := NewMessage(USER, userID, payload)
+	if err != nil {
+		return err
+	}
+	return eb.Publish(ACTIVITY, payload.EventName(), message)
+}
+
+// EncodePayload converts the payload to the map form messages carry
+func EncodePayload(payload Payload) (map[string]any, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", payload.EventName(), err)
+	}
+
+	var encoded map[string]any
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", payload.EventName(), err)
+	}
+	return encoded, nil
+}
+
+// DecodePayload reads the message's payload into its registered type after validating it
+func DecodePayload[T Payload](message Message) (T, error) {
+	var payload T
+	if message.Event != payload.EventName() {
+		return payload, fmt.Errorf("%w: expected %s, got %s",
+			ErrInvalidPayload, payload.EventName(), message.Event)
+	}
+
+	if err := Validate(message); err != nil {
+		return payload, err
+	}
+
+	data, err := json.Marshal(message.Payload)
+	if err != nil {
+		return payload, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return payload, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+	return payload, nil
+}
+
+// Validate checks the message against its registered event: the event must be known, the version
+// current, and the payload must have every required field and no unknown ones
+func Validate(message Message) error {
+	definition, ok := Definition(message.Event)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEvent, message.Event)
+	}
+
+	// Messages built before versioning carry no version and are read as the first one
+	version := message.Version
+	if version == 0 {
+		version = 1
+	}
+	if version != definition.Version {
+		return fmt.Errorf("%w: %s v%d, current is v%d",
+			ErrEventVersion, message.Event, version, definition.Version)
+	}
+
+	for _, field := range requiredFields(definition.payloadType) {
+		if _, ok := message.Payload[field]; !ok {
+			return fmt.Errorf("%w: %s is missing %s", ErrInvalidPayload, message.Event, field)
+		}
+	}
+
+	data, err := json.Marshal(message.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(reflect.New(definition.payloadType).Interface()); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidPayload, message.Event, err)
+	}
+
+	return nil
+}
+
+// payloadField is a struct field as it appears in JSON
+type payloadField struct {
+	name     string
+	field    reflect.StructField
+	optional bool
+}
+
+// payloadFields lists the JSON fields of a payload struct, in declaration order
+func payloadFields(t reflect.Type) []payloadField {
+	fields := make([]payloadField, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, payloadField{
+			name:     name,
+			field:    field,
+			optional: slices.Contains(strings.Split(options, ","), "omitempty"),
+		})
+	}
+	return fields
+}
+
+// requiredFields are the JSON fields of a payload struct not marked omitempty
+func requiredFields(t reflect.Type) []string {
+	required := []string{}
+	for _, field := range payloadFields(t) {
+		if !field.optional {
+			required = append(required, field.name)
+		}
+	}
+	return required
+}
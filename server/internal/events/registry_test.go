@@ -0,0 +1,163 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"waugzee/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefinitions(t *testing.T) {
+	definitions := Definitions()
+	require.NotEmpty(t, definitions)
+
+	for i, definition := range definitions {
+		assert.NotEmpty(t, definition.Description, definition.Name)
+		assert.Positive(t, definition.Version, definition.Name)
+		assert.NotEmpty(t, definition.Channel, definition.Name)
+		if i > 0 {
+			assert.Less(t, definitions[i-1].Name, definition.Name, "definitions are ordered by name")
+		}
+	}
+
+	definition, ok := Definition(SYNC_COMPLETE)
+	require.True(t, ok)
+	assert.Equal(t, "sync_complete.v1", definition.VersionedName())
+}
+
+func TestNewMessageRoundTrip(t *testing.T) {
+	folderID, page := 3, 2
+	request := APIRequest{
+		RequestID:       "req-1",
+		RequestType:     "folder_releases",
+		URL:             "https://api.discogs.com/users/x/collection/folders/3/releases",
+		Method:          "GET",
+		Headers:         map[string]string{"Authorization": "Discogs token=abc"},
+		CallbackService: "orchestration",
+		CallbackEvent:   "api_response",
+		FolderID:        &folderID,
+		Page:            &page,
+	}
+
+	message, err := NewMessage(API, "user-1", request)
+	require.NoError(t, err)
+	assert.Equal(t, API_REQUEST, message.Event)
+	assert.Equal(t, 1, message.Version)
+	assert.Equal(t, "user-1", message.UserID)
+	assert.NotContains(t, message.Payload, "releaseId", "unset optional fields are left out")
+
+	// Payloads cross the bus as JSON, numbers come back as float64
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+	var received Message
+	require.NoError(t, json.Unmarshal(data, &received))
+
+	decoded, err := DecodePayload[APIRequest](received)
+	require.NoError(t, err)
+	assert.Equal(t, request, decoded)
+
+	_, err = DecodePayload[SyncCompleted](received)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestValidate(t *testing.T) {
+	valid := Message{
+		Event:   SYNC_COMPLETE,
+		Version: 1,
+		Payload: map[string]any{"message": "done", "totalReleases": 12},
+	}
+	assert.NoError(t, Validate(valid))
+
+	unversioned := valid
+	unversioned.Version = 0
+	assert.NoError(t, Validate(unversioned), "messages without a version are read as v1")
+
+	tests := []struct {
+		name    string
+		message Message
+		err     error
+	}{
+		{
+			name:    "unknown event",
+			message: Message{Event: "sync_paused", Payload: map[string]any{}},
+			err:     ErrUnknownEvent,
+		},
+		{
+			name:    "unsupported version",
+			message: Message{Event: SYNC_COMPLETE, Version: 2, Payload: valid.Payload},
+			err:     ErrEventVersion,
+		},
+		{
+			name:    "missing field",
+			message: Message{Event: SYNC_COMPLETE, Payload: map[string]any{"message": "done"}},
+			err:     ErrInvalidPayload,
+		},
+		{
+			name: "unknown field",
+			message: Message{Event: SYNC_COMPLETE, Payload: map[string]any{
+				"message": "done", "totalReleases": 12, "total": 12,
+			}},
+			err: ErrInvalidPayload,
+		},
+		{
+			name: "wrong type",
+			message: Message{Event: SYNC_COMPLETE, Payload: map[string]any{
+				"message": "done", "totalReleases": "12",
+			}},
+			err: ErrInvalidPayload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, Validate(tt.message), tt.err)
+		})
+	}
+}
+
+func TestPublishRejectsInvalidEvents(t *testing.T) {
+	eb := New(nil, config.Config{})
+	defer eb.cancel()
+
+	err := eb.Publish(WEBSOCKET, "user", Message{Event: "sync_paused", UserID: "user-1"})
+	assert.ErrorIs(t, err, ErrUnknownEvent)
+
+	err = eb.Publish(WEBSOCKET, "user", Message{Event: SYNC_START, UserID: "user-1"})
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := JSONSchema()
+	require.NoError(t, err)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(schema, &document))
+	assert.Equal(t, JSONSchemaDialect, document["$schema"])
+
+	defs := document["$defs"].(map[string]any)
+	assert.Len(t, defs, len(Definitions()))
+
+	progress := defs["admin_download_progress.v1"].(map[string]any)
+	payload := progress["properties"].(map[string]any)["payload"].(map[string]any)
+	properties := payload["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "integer"}, properties["downloaded"])
+	assert.Equal(t, map[string]any{"type": []any{"string", "null"}}, properties["errorMessage"])
+
+	request := defs["api_request.v1"].(map[string]any)
+	requestPayload := request["properties"].(map[string]any)["payload"].(map[string]any)
+	assert.NotContains(t, requestPayload["required"], "releaseId")
+	assert.Contains(t, requestPayload["required"], "requestId")
+}
+
+func TestJSONSchemaDocumentIsCurrent(t *testing.T) {
+	schema, err := JSONSchema()
+	require.NoError(t, err)
+
+	committed, err := os.ReadFile("../../../docs/events.schema.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(schema), string(committed),
+		"docs/events.schema.json is stale, regenerate it with go run ./cmd/event-schema")
+}
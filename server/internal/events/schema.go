@@ -0,0 +1,107 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+const (
+	JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	JSONSchemaID      = "urn:waugzee:events"
+)
+
+// JSONSchema documents every registered event as a JSON Schema, one definition per versioned
+// event name. The document is generated into docs/events.schema.json by cmd/event-schema and
+// served at /api/events/schema.
+func JSONSchema() ([]byte, error) {
+	definitions := Definitions()
+
+	defs := make(map[string]any, len(definitions))
+	oneOf := make([]any, 0, len(definitions))
+	for _, definition := range definitions {
+		defs[definition.VersionedName()] = messageSchema(definition)
+		oneOf = append(oneOf, map[string]any{"$ref": "#/$defs/" + definition.VersionedName()})
+	}
+
+	document := map[string]any{
+		"$schema":     JSONSchemaDialect,
+		"$id":         JSONSchemaID,
+		"title":       "Waugzee events",
+		"description": "Messages published by the server, on websockets and internal channels",
+		"oneOf":       oneOf,
+		"$defs":       defs,
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// messageSchema is the schema of the message envelope carrying the event's payload
+func messageSchema(definition EventDefinition) map[string]any {
+	return map[string]any{
+		"title":       definition.Name,
+		"description": definition.Description,
+		"x-channel":   definition.Channel.String(),
+		"type":        "object",
+		"properties": map[string]any{
+			"id":        map[string]any{"type": "string"},
+			"service":   map[string]any{"type": "string", "enum": []string{"system", "user", "api"}},
+			"event":     map[string]any{"const": definition.Name},
+			"version":   map[string]any{"const": definition.Version},
+			"userId":    map[string]any{"type": "string"},
+			"payload":   typeSchema(definition.payloadType),
+			"timestamp": map[string]any{"type": "string", "format": "date-time"},
+			"stream":    map[string]any{"type": "string", "enum": []string{"user", "admin"}},
+			"sequence":  map[string]any{"type": "string"},
+			"topic":     map[string]any{"type": "string"},
+		},
+		"required": []string{"id", "event", "version", "payload", "timestamp"},
+	}
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// typeSchema maps a Go type to the schema of its JSON encoding, covering the kinds payloads use
+func typeSchema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := typeSchema(t.Elem())
+		schema["type"] = []any{schema["type"], "null"}
+		return schema
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		fields := payloadFields(t)
+		properties := make(map[string]any, len(fields))
+		for _, field := range fields {
+			properties[field.name] = typeSchema(field.field.Type)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             requiredFields(t),
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,171 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valkey-io/valkey-go"
+)
+
+// Websocket messages for a user, and for admins, are kept in a Valkey stream for a retention
+// window so clients can replay what they missed while disconnected. The stream entry ID is the
+// message sequence, clients send the last one they saw when they reconnect.
+const (
+	STREAM_KEY_PREFIX = "websocket:stream:"
+	ADMIN_STREAM      = "admin"
+
+	// DefaultStreamRetention is used when WEBSOCKET_REPLAY_RETENTION_MINUTES is not set
+	DefaultStreamRetention = time.Hour
+	// MaxReplayMessages caps how many messages one reconnect replays
+	MaxReplayMessages = 1000
+
+	streamMessageField = "message"
+)
+
+// UserStream is the stream of websocket messages sent to a user
+func UserStream(userID string) string {
+	return "user:" + userID
+}
+
+// ReplayResult is what a client missed since its last seen sequence
+type ReplayResult struct {
+	Messages []Message
+	// Complete is false when messages after the last seen sequence may have aged out of the
+	// stream, the client should reload its state instead of relying on the replay
+	Complete bool
+}
+
+// streamForEvent returns the stream a websocket event is kept in, or false for events that are
+// not replayed such as broadcasts
+func streamForEvent(event string, message Message) (string, bool) {
+	switch event {
+	case "user":
+		if message.UserID == "" {
+			return "", false
+		}
+		return UserStream(message.UserID), true
+	case "admin":
+		return ADMIN_STREAM, true
+	default:
+		return "", false
+	}
+}
+
+func (eb *EventBus) streamRetention() time.Duration {
+	if eb.config.WebsocketReplayRetentionMinutes > 0 {
+		return time.Duration(eb.config.WebsocketReplayRetentionMinutes) * time.Minute
+	}
+	return DefaultStreamRetention
+}
+
+// appendToStream adds the message to the stream, setting its sequence, and trims entries older
+// than the retention window
+func (eb *EventBus) appendToStream(ctx context.Context, stream string, message *Message) error {
+	log := eb.logger.Function("appendToStream")
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return log.Err("failed to marshal stream message", err, "stream", stream)
+	}
+
+	key := STREAM_KEY_PREFIX + stream
+	retention := eb.streamRetention()
+
+	sequence, err := eb.client.Do(ctx, eb.client.B().Xadd().
+		Key(key).
+		Minid().
+		Almost().
+		Threshold(streamMinID(time.Now(), retention)).
+		Id("*").
+		FieldValue().
+		FieldValue(streamMessageField, string(data)).
+		Build()).ToString()
+	if err != nil {
+		return log.Err("failed to append to stream", err, "stream", stream)
+	}
+	message.Sequence = sequence
+
+	// Streams of users who stop receiving messages expire instead of lingering
+	expire := eb.client.B().Expire().Key(key).Seconds(int64(retention.Seconds())).Build()
+	if err := eb.client.Do(ctx, expire).Error(); err != nil {
+		log.Warn("Failed to set stream expiry", "stream", stream, "error", err)
+	}
+
+	return nil
+}
+
+// Replay returns the messages in the stream after the sequence
+func (eb *EventBus) Replay(ctx context.Context, stream string, after string) (*ReplayResult, error) {
+	log := eb.logger.Function("Replay")
+
+	if !isStreamID(after) {
+		return nil, log.Error("invalid stream sequence", "stream", stream, "sequence", after)
+	}
+
+	key := STREAM_KEY_PREFIX + stream
+
+	// The last seen entry still being in the stream means nothing after it was trimmed
+	seen, err := eb.client.Do(ctx, eb.client.B().Xrange().Key(key).Start(after).End(after).Build()).
+		AsXRange()
+	if err != nil {
+		return nil, log.Err("failed to look up last seen sequence", err, "stream", stream)
+	}
+
+	entries, err := eb.client.Do(ctx, eb.client.B().Xrange().
+		Key(key).
+		Start("("+after).
+		End("+").
+		Count(MaxReplayMessages+1).
+		Build()).AsXRange()
+	if err != nil && !valkey.IsValkeyNil(err) {
+		return nil, log.Err("failed to read stream", err, "stream", stream)
+	}
+
+	result := &ReplayResult{Complete: len(seen) > 0 && len(entries) <= MaxReplayMessages}
+	if len(entries) > MaxReplayMessages {
+		entries = entries[:MaxReplayMessages]
+	}
+
+	result.Messages = make([]Message, 0, len(entries))
+	for _, entry := range entries {
+		message, err := decodeStreamEntry(entry)
+		if err != nil {
+			log.Warn("Skipping unreadable stream entry", "stream", stream, "id", entry.ID, "error", err)
+			continue
+		}
+		result.Messages = append(result.Messages, message)
+	}
+
+	return result, nil
+}
+
+func decodeStreamEntry(entry valkey.XRangeEntry) (Message, error) {
+	var message Message
+	if err := json.Unmarshal([]byte(entry.FieldValues[streamMessageField]), &message); err != nil {
+		return Message{}, err
+	}
+	message.Sequence = entry.ID
+	return message, nil
+}
+
+// streamMinID is the oldest entry ID kept for the retention window, stream IDs start with the
+// millisecond they were added at
+func streamMinID(now time.Time, retention time.Duration) string {
+	return strconv.FormatInt(now.Add(-retention).UnixMilli(), 10)
+}
+
+// isStreamID reports whether id is a "<milliseconds>-<sequence>" stream entry ID, sequences come
+// from clients so they are checked before being used in a range
+func isStreamID(id string) bool {
+	msPart, seqPart, found := strings.Cut(id, "-")
+	if !found {
+		return false
+	}
+
+	_, msErr := strconv.ParseUint(msPart, 10, 64)
+	_, seqErr := strconv.ParseUint(seqPart, 10, 64)
+	return msErr == nil && seqErr == nil
+}
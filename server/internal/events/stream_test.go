@@ -0,0 +1,68 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valkey-io/valkey-go"
+)
+
+func TestStreamForEvent(t *testing.T) {
+	stream, ok := streamForEvent("user", Message{UserID: "abc"})
+	assert.True(t, ok)
+	assert.Equal(t, "user:abc", stream)
+
+	_, ok = streamForEvent("user", Message{})
+	assert.False(t, ok, "user messages without a user are not kept")
+
+	stream, ok = streamForEvent("admin", Message{})
+	assert.True(t, ok)
+	assert.Equal(t, ADMIN_STREAM, stream)
+
+	_, ok = streamForEvent("broadcast", Message{})
+	assert.False(t, ok)
+}
+
+func TestIsStreamID(t *testing.T) {
+	assert.True(t, isStreamID("1712345678901-0"))
+	assert.True(t, isStreamID("0-1"))
+	assert.False(t, isStreamID("1712345678901"))
+	assert.False(t, isStreamID("-"))
+	assert.False(t, isStreamID("+"))
+	assert.False(t, isStreamID("1-2 + 3"))
+	assert.False(t, isStreamID("-1-0"))
+}
+
+func TestStreamMinID(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	assert.Equal(t, "1699999940000", streamMinID(now, time.Minute))
+}
+
+func TestStreamRetention(t *testing.T) {
+	eb := &EventBus{}
+	assert.Equal(t, DefaultStreamRetention, eb.streamRetention())
+
+	eb.config.WebsocketReplayRetentionMinutes = 15
+	assert.Equal(t, 15*time.Minute, eb.streamRetention())
+}
+
+func TestDecodeStreamEntry(t *testing.T) {
+	entry := valkey.XRangeEntry{
+		ID: "1712345678901-3",
+		FieldValues: map[string]string{
+			streamMessageField: `{"id":"m1","event":"sync_complete","userId":"abc","stream":"user"}`,
+		},
+	}
+
+	message, err := decodeStreamEntry(entry)
+	require.NoError(t, err)
+	assert.Equal(t, "m1", message.ID)
+	assert.Equal(t, "sync_complete", message.Event)
+	assert.Equal(t, "user", message.Stream)
+	assert.Equal(t, "1712345678901-3", message.Sequence)
+
+	_, err = decodeStreamEntry(valkey.XRangeEntry{ID: "1-0", FieldValues: map[string]string{}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Topics narrow websocket fan-out to the clients subscribed to them. A topic is "<kind>:<id>",
+// e.g. "dump:2024-01". Messages on a topic reach its subscribers in addition to their usual
+// audience, and messages published to a topic alone reach only its subscribers and are not kept
+// for replay.
+const (
+	// TOPIC_SYNC follows a user's collection sync. A user runs one sync at a time, so the topic is
+	// keyed by user and its events name the sync operation.
+	TOPIC_SYNC = "sync"
+	// TOPIC_DUMP follows the download and processing of one Discogs dump year-month
+	TOPIC_DUMP = "dump"
+
+	topicSeparator = ":"
+)
+
+func SyncTopic(userID string) string {
+	return TOPIC_SYNC + topicSeparator + userID
+}
+
+func DumpTopic(yearMonth string) string {
+	return TOPIC_DUMP + topicSeparator + yearMonth
+}
+
+// ParseTopic splits a topic into its kind and ID, reporting false for topics of unknown kinds or
+// malformed IDs
+func ParseTopic(topic string) (kind string, id string, ok bool) {
+	kind, id, found := strings.Cut(topic, topicSeparator)
+	if !found || id == "" {
+		return "", "", false
+	}
+
+	switch kind {
+	case TOPIC_SYNC:
+		if _, err := uuid.Parse(id); err != nil {
+			return "", "", false
+		}
+		return kind, id, true
+	case TOPIC_DUMP:
+		if _, err := time.Parse("2006-01", id); err != nil {
+			return "", "", false
+		}
+		return kind, id, true
+	default:
+		return "", "", false
+	}
+}
+
+// PublishToTopic publishes the payload to the websocket clients subscribed to the topic
+func (eb *EventBus) PublishToTopic(topic string, payload Payload) error {
+	message, err := NewMessage(SYSTEM, "", payload)
+	if err != nil {
+		return err
+	}
+	message.Topic = topic
+	return eb.Publish(WEBSOCKET, "topic", message)
+}
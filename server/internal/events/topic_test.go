@@ -0,0 +1,36 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTopic(t *testing.T) {
+	userID := "6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b"
+
+	tests := []struct {
+		topic string
+		kind  string
+		id    string
+		ok    bool
+	}{
+		{topic: SyncTopic(userID), kind: TOPIC_SYNC, id: userID, ok: true},
+		{topic: DumpTopic("2024-01"), kind: TOPIC_DUMP, id: "2024-01", ok: true},
+		{topic: "sync:not-a-user"},
+		{topic: "dump:2024-13"},
+		{topic: "dump:"},
+		{topic: "session:abc"},
+		{topic: "dump"},
+		{topic: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			kind, id, ok := ParseTopic(tt.topic)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.kind, kind)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"io"
 	"strings"
 	"waugzee/internal/app"
 	"waugzee/internal/controllers/admin"
 	"waugzee/internal/handlers/middleware"
+	"waugzee/internal/models"
+	"waugzee/internal/services"
 	logger "github.com/Bparsons0904/goLogger"
 
 	"github.com/gofiber/fiber/v2"
@@ -29,19 +32,31 @@ func NewAdminHandler(app app.App, router fiber.Router) *AdminHandler {
 }
 
 func (h *AdminHandler) Register() {
-	admin := h.router.Group("/admin", h.middleware.RequireAdmin())
+	admin := h.router.Group("/admin")
 
-	admin.Get("/downloads/status", h.getDownloadStatus)
-	admin.Post("/downloads/trigger", h.triggerDownload)
-	admin.Post("/downloads/reprocess", h.triggerReprocess)
-	admin.Post("/downloads/reset", h.resetStuckDownload)
+	// Catalog maintainers run the dump pipeline without full admin
+	requireDumps := h.middleware.RequirePermission(models.PermissionDumpsManage)
+	requireSystem := h.middleware.RequirePermission(models.PermissionSystemManage)
 
-	admin.Get("/files", h.listStoredFiles)
-	admin.Delete("/files", h.cleanupAllFiles)
-	admin.Delete("/files/:yearMonth", h.cleanupYearMonth)
+	downloads := admin.Group("/downloads", requireDumps)
+	downloads.Get("/status", h.getDownloadStatus)
+	downloads.Get("/history", h.getProcessingDashboard)
+	downloads.Post("/trigger", h.triggerDownload)
+	downloads.Post("/reprocess", h.triggerReprocess)
+	downloads.Post("/reset", h.resetStuckDownload)
+	downloads.Post("/import/:yearMonth", h.importDump)
+
+	files := admin.Group("/files", requireDumps)
+	files.Get("", h.listStoredFiles)
+	files.Delete("", h.cleanupAllFiles)
+	files.Delete("/:yearMonth", h.cleanupYearMonth)
+
+	jobs := admin.Group("/jobs", requireSystem)
+	jobs.Get("", h.listJobs)
+	jobs.Post("/:name/run", h.runJob)
 
 	// TODO: REMOVE_AFTER_MIGRATION - One-time Kleio data import endpoint
-	admin.Post("/import-kleio-data", h.importKleioData)
+	admin.Post("/import-kleio-data", requireSystem, h.importKleioData)
 }
 
 func (h *AdminHandler) getDownloadStatus(c *fiber.Ctx) error {
@@ -57,6 +72,19 @@ func (h *AdminHandler) getDownloadStatus(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(status)
 }
 
+func (h *AdminHandler) getProcessingDashboard(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("admin_handler").Function("getProcessingDashboard")
+
+	dashboard, err := h.adminController.GetProcessingDashboard(c.UserContext())
+	if err != nil {
+		_ = log.Err("Failed to get processing dashboard", err)
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(fiber.Map{"error": "Failed to get processing dashboard"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dashboard)
+}
+
 func (h *AdminHandler) triggerDownload(c *fiber.Ctx) error {
 	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("admin_handler").Function("triggerDownload")
 
@@ -66,7 +94,7 @@ func (h *AdminHandler) triggerDownload(c *fiber.Ctx) error {
 
 	err := h.adminController.TriggerDownload(c.UserContext())
 	if err != nil {
-		if err.Error() == "download or processing already in progress" {
+		if errors.Is(err, services.ErrDumpImportInProgress) || errors.Is(err, services.ErrJobAlreadyRunning) {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
 		}
 		_ = log.Err("Failed to trigger download", err)
@@ -90,6 +118,11 @@ func (h *AdminHandler) triggerReprocess(c *fiber.Ctx) error {
 			err.Error() == "files must be downloaded before reprocessing" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
+		if errors.Is(err, services.ErrJobAlreadyRunning) ||
+			errors.Is(err, models.ErrInvalidStatusTransition) ||
+			errors.Is(err, models.ErrProcessingStatusConflict) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
 		_ = log.Err("Failed to trigger reprocess", err)
 		return c.Status(fiber.StatusInternalServerError).
 			JSON(fiber.Map{"error": "Failed to trigger reprocess"})
@@ -112,6 +145,10 @@ func (h *AdminHandler) resetStuckDownload(c *fiber.Ctx) error {
 			err.Error() == "cannot reset record in this state" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
+		if errors.Is(err, models.ErrInvalidStatusTransition) ||
+			errors.Is(err, models.ErrProcessingStatusConflict) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
 		_ = log.Err("Failed to reset download", err)
 		return c.Status(fiber.StatusInternalServerError).
 			JSON(fiber.Map{"error": "Failed to reset download"})
@@ -122,6 +159,55 @@ func (h *AdminHandler) resetStuckDownload(c *fiber.Ctx) error {
 	})
 }
 
+type importDumpRequest struct {
+	SkipChecksumValidation bool `json:"skip_checksum_validation"`
+}
+
+func (h *AdminHandler) importDump(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("admin_handler").Function("importDump")
+
+	yearMonth := c.Params("yearMonth")
+
+	var request importDumpRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+	}
+
+	user := middleware.GetUser(c)
+
+	log.Info("Admin importing existing dump",
+		"userID", user.ID,
+		"email", user.Email,
+		"yearMonth", yearMonth,
+		"skipChecksumValidation", request.SkipChecksumValidation)
+
+	status, err := h.adminController.ImportDump(c.UserContext(), yearMonth, services.DumpImportOptions{
+		SkipChecksumValidation: request.SkipChecksumValidation,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidYearMonth),
+			errors.Is(err, services.ErrDumpFilesMissing),
+			errors.Is(err, services.ErrDumpChecksumMismatch):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrDumpImportInProgress),
+			errors.Is(err, models.ErrInvalidStatusTransition),
+			errors.Is(err, models.ErrProcessingStatusConflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		_ = log.Err("Failed to import dump", err, "yearMonth", yearMonth)
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(fiber.Map{"error": "Failed to import dump"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Dump registered and queued for processing",
+		"status":  status,
+	})
+}
+
 func (h *AdminHandler) listStoredFiles(c *fiber.Ctx) error {
 	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("admin_handler").Function("listStoredFiles")
 
@@ -183,6 +269,46 @@ func (h *AdminHandler) cleanupYearMonth(c *fiber.Ctx) error {
 	})
 }
 
+func (h *AdminHandler) listJobs(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("admin_handler").Function("listJobs")
+
+	jobs, err := h.adminController.ListJobs(c.UserContext())
+	if err != nil {
+		_ = log.Err("Failed to list jobs", err)
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(fiber.Map{"error": "Failed to list jobs"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"jobs": jobs})
+}
+
+func (h *AdminHandler) runJob(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("admin_handler").Function("runJob")
+
+	jobName := c.Params("name")
+	user := middleware.GetUser(c)
+
+	log.Info("Admin triggering job", "userID", user.ID, "email", user.Email, "job", jobName)
+
+	err := h.adminController.RunJob(c.UserContext(), jobName)
+	if err != nil {
+		if errors.Is(err, services.ErrJobNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Job not found"})
+		}
+		if errors.Is(err, services.ErrJobAlreadyRunning) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		_ = log.Err("Failed to trigger job", err, "job", jobName)
+		return c.Status(fiber.StatusInternalServerError).
+			JSON(fiber.Map{"error": "Failed to trigger job"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Job triggered successfully",
+		"job":     jobName,
+	})
+}
+
 // TODO: REMOVE_AFTER_MIGRATION
 // This handler is for one-time Kleio data migration and should be deleted after import is complete.
 func (h *AdminHandler) importKleioData(c *fiber.Ctx) error {
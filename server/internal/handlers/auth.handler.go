@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strings"
 	"time"
 	"waugzee/internal/app"
@@ -16,15 +17,19 @@ import (
 
 type AuthHandler struct {
 	Handler
-	authController authController.AuthControllerInterface
-	zitadelService *services.ZitadelService
+	authController       authController.AuthControllerInterface
+	authProvider         services.AuthProvider
+	apiTokenService      *services.ApiTokenService
+	authorizationService *services.AuthorizationService
 }
 
 func NewAuthHandler(app app.App, router fiber.Router) *AuthHandler {
 	log := logger.New("handlers").File("auth_handler")
 	return &AuthHandler{
-		authController: app.Controllers.Auth,
-		zitadelService: app.Services.Zitadel,
+		authController:       app.Controllers.Auth,
+		authProvider:         app.Services.Auth,
+		apiTokenService:      app.Services.ApiToken,
+		authorizationService: app.Services.Authorization,
 		Handler: Handler{
 			log:        log,
 			router:     router,
@@ -56,8 +61,13 @@ func (h *AuthHandler) Register() {
 
 	auth.Get("/config", h.getAuthConfig)
 	auth.Post("/callback", authRateLimit, h.oidcCallback)
+	auth.Post("/local/token", authRateLimit, h.issueLocalToken)
 
-	protected := auth.Group("/", h.middleware.RequireAuth(h.zitadelService))
+	protected := auth.Group("/", h.middleware.RequireAuth(
+		h.authProvider,
+		h.apiTokenService,
+		h.authorizationService,
+	))
 	protected.Post("/logout", h.logout)
 }
 
@@ -78,6 +88,40 @@ func (h *AuthHandler) getAuthConfig(c *fiber.Ctx) error {
 	return c.JSON(config)
 }
 
+// issueLocalToken signs in with the local development provider, it answers 404 with any other
+// provider configured
+func (h *AuthHandler) issueLocalToken(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("auth_handler").Function("issueLocalToken")
+
+	var req authController.LocalTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	response, err := h.authController.IssueLocalToken(c.UserContext(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrLocalAuthDisabled):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Local authentication is not enabled",
+			})
+		case errors.Is(err, services.ErrLocalAuthInvalidEmail):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "A valid email is required",
+			})
+		default:
+			_ = log.Err("Failed to issue local token", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to issue token",
+			})
+		}
+	}
+
+	return c.JSON(response)
+}
+
 func (h *AuthHandler) logout(c *fiber.Ctx) error {
 	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("auth_handler").Function("logout")
 
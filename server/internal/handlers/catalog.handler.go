@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"waugzee/internal/app"
+	catalogController "waugzee/internal/controllers/catalog"
+	"waugzee/internal/handlers/middleware"
+	"waugzee/internal/models"
+	logger "github.com/Bparsons0904/goLogger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CatalogHandler struct {
+	Handler
+	catalogController catalogController.CatalogControllerInterface
+}
+
+func NewCatalogHandler(app app.App, router fiber.Router) *CatalogHandler {
+	log := logger.New("handlers").File("catalog_handler")
+	return &CatalogHandler{
+		catalogController: app.Controllers.Catalog,
+		Handler: Handler{
+			log:        log,
+			router:     router,
+			middleware: app.Middleware,
+		},
+	}
+}
+
+func (h *CatalogHandler) Register() {
+	requireCatalogRead := h.middleware.RequirePermission(models.PermissionCatalogRead)
+
+	releases := h.router.Group("/releases", requireCatalogRead)
+	releases.Get("/:id", h.getRelease)
+
+	masters := h.router.Group("/masters", requireCatalogRead)
+	masters.Get("/:id", h.getMaster)
+	masters.Get("/:id/releases", h.listMasterReleases)
+
+	artists := h.router.Group("/artists", requireCatalogRead)
+	artists.Get("/:id", h.getArtist)
+	artists.Get("/:id/releases", h.listArtistReleases)
+	artists.Get("/:id/masters", h.listArtistMasters)
+
+	labels := h.router.Group("/labels", requireCatalogRead)
+	labels.Get("/:id", h.getLabel)
+	labels.Get("/:id/releases", h.listLabelReleases)
+}
+
+func parseCatalogID(c *fiber.Ctx) (int64, bool) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+func catalogPageFromQuery(c *fiber.Ctx) (page, limit int) {
+	return c.QueryInt("page", 1), c.QueryInt("limit", catalogController.DefaultPageLimit)
+}
+
+// catalogResponse maps controller results to a response, translating not-found errors to 404
+func catalogResponse(c *fiber.Ctx, log logger.Logger, name string, key string, result any, err error) error {
+	if err != nil {
+		if errors.Is(err, catalogController.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": name + " not found",
+			})
+		}
+		_ = log.Err("Failed to get "+name, err, "id", c.Params("id"))
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get " + name,
+		})
+	}
+
+	if key == "" {
+		return c.Status(fiber.StatusOK).JSON(result)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{key: result})
+}
+
+func invalidCatalogID(c *fiber.Ctx, log logger.Logger, name string) error {
+	log.Warn("Invalid "+name+" ID", "id", c.Params("id"))
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "Invalid " + name + " ID",
+	})
+}
+
+func (h *CatalogHandler) getRelease(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("catalog_handler").Function("getRelease")
+
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return invalidCatalogID(c, log, "release")
+	}
+
+	release, err := h.catalogController.GetRelease(c.UserContext(), middleware.GetUser(c), id)
+	return catalogResponse(c, log, "release", "release", release, err)
+}
+
+func (h *CatalogHandler) getMaster(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("catalog_handler").Function("getMaster")
+
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return invalidCatalogID(c, log, "master")
+	}
+
+	master, err := h.catalogController.GetMaster(c.UserContext(), middleware.GetUser(c), id)
+	return catalogResponse(c, log, "master", "master", master, err)
+}
+
+func (h *CatalogHandler) listMasterReleases(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("catalog_handler").Function("listMasterReleases")
+
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return invalidCatalogID(c, log, "master")
+	}
+
+	page := catalogController.NewPage(catalogPageFromQuery(c))
+	releases, err := h.catalogController.ListMasterReleases(c.UserContext(), middleware.GetUser(c), id, page)
+	return catalogResponse(c, log, "master", "", releases, err)
+}
+
+func (h *CatalogHandler) getArtist(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("catalog_handler").Function("getArtist")
+
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return invalidCatalogID(c, log, "artist")
+	}
+
+	artist, err := h.catalogController.GetArtist(c.UserContext(), middleware.GetUser(c), id)
+	return catalogResponse(c, log, "artist", "artist", artist, err)
+}
+
+func (h *CatalogHandler) listArtistReleases(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("catalog_handler").Function("listArtistReleases")
+
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return invalidCatalogID(c, log, "artist")
+	}
+
+	page := catalogController.NewPage(catalogPageFromQuery(c))
+	releases, err := h.catalogController.ListArtistReleases(c.UserContext(), middleware.GetUser(c), id, page)
+	return catalogResponse(c, log, "artist", "", releases, err)
+}
+
+func (h *CatalogHandler) listArtistMasters(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("catalog_handler").Function("listArtistMasters")
+
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return invalidCatalogID(c, log, "artist")
+	}
+
+	page := catalogController.NewPage(catalogPageFromQuery(c))
+	masters, err := h.catalogController.ListArtistMasters(c.UserContext(), middleware.GetUser(c), id, page)
+	return catalogResponse(c, log, "artist", "", masters, err)
+}
+
+func (h *CatalogHandler) getLabel(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("catalog_handler").Function("getLabel")
+
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return invalidCatalogID(c, log, "label")
+	}
+
+	label, err := h.catalogController.GetLabel(c.UserContext(), middleware.GetUser(c), id)
+	return catalogResponse(c, log, "label", "label", label, err)
+}
+
+func (h *CatalogHandler) listLabelReleases(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("catalog_handler").Function("listLabelReleases")
+
+	id, ok := parseCatalogID(c)
+	if !ok {
+		return invalidCatalogID(c, log, "label")
+	}
+
+	page := catalogController.NewPage(catalogPageFromQuery(c))
+	releases, err := h.catalogController.ListLabelReleases(c.UserContext(), middleware.GetUser(c), id, page)
+	return catalogResponse(c, log, "label", "", releases, err)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	catalogController "waugzee/internal/controllers/catalog"
+	logger "github.com/Bparsons0904/goLogger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogResponseStatus(t *testing.T) {
+	log := logger.New("handlers").File("catalog_handler_test")
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "found", status: fiber.StatusOK},
+		{name: "not found", err: catalogController.ErrNotFound, status: fiber.StatusNotFound},
+		{name: "failure", err: errors.New("connection refused"), status: fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/releases/:id", func(c *fiber.Ctx) error {
+				return catalogResponse(c, log, "release", "release", fiber.Map{}, tt.err)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/releases/1", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
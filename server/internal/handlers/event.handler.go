@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"waugzee/internal/events"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EventSchemaHandler serves the JSON Schema of the events the server publishes, public so
+// third-party consumers can read the contract without signing in
+func EventSchemaHandler(router fiber.Router) {
+	router.Get("/events/schema", func(c *fiber.Ctx) error {
+		schema, err := events.JSONSchema()
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).
+				JSON(fiber.Map{"error": "Failed to generate event schema"})
+		}
+
+		c.Set(fiber.HeaderContentType, "application/schema+json")
+		return c.Send(schema)
+	})
+}
@@ -2,16 +2,32 @@ package handlers
 
 import (
 	"waugzee/config"
+	"waugzee/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func HealthHandler(router fiber.Router, config config.Config) {
+// HealthHandler adds the probes, which run before authentication. /health/live only answers when
+// the process can serve requests, /health/ready checks the dependencies and answers 503 once a
+// critical one is down so the orchestrator stops routing to the replica.
+func HealthHandler(router fiber.Router, config config.Config, health *services.HealthService) {
 	router.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"status":  "ok",
 			"version": config.GeneralVersion,
-			"service": "billy_wu_api",
+			"service": "waugzee_api",
 		})
 	})
+
+	router.Get("/health/live", func(c *fiber.Ctx) error {
+		return c.JSON(health.Live())
+	})
+
+	router.Get("/health/ready", func(c *fiber.Ctx) error {
+		report := health.Ready(c.UserContext())
+		if !report.Ready() {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+		}
+		return c.JSON(report)
+	})
 }
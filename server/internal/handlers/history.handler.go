@@ -5,6 +5,7 @@ import (
 	"waugzee/internal/app"
 	historyController "waugzee/internal/controllers/history"
 	"waugzee/internal/handlers/middleware"
+	"waugzee/internal/models"
 	logger "github.com/Bparsons0904/goLogger"
 
 	"github.com/gofiber/fiber/v2"
@@ -29,17 +30,19 @@ func NewHistoryHandler(app app.App, router fiber.Router) *HistoryHandler {
 }
 
 func (h *HistoryHandler) Register() {
-	plays := h.router.Group("/plays")
+	requireHistoryWrite := h.middleware.RequirePermission(models.PermissionHistoryWrite)
+
+	plays := h.router.Group("/plays", requireHistoryWrite)
 	plays.Post("", h.logPlay)
 	plays.Put("/:id", h.updatePlayHistory)
 	plays.Delete("/:id", h.deletePlayHistory)
 
-	cleanings := h.router.Group("/cleanings")
+	cleanings := h.router.Group("/cleanings", requireHistoryWrite)
 	cleanings.Post("", h.logCleaning)
 	cleanings.Put("/:id", h.updateCleaningHistory)
 	cleanings.Delete("/:id", h.deleteCleaningHistory)
 
-	h.router.Post("/logBoth", h.logBoth)
+	h.router.Post("/logBoth", requireHistoryWrite, h.logBoth)
 }
 
 func (h *HistoryHandler) logPlay(c *fiber.Ctx) error {
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"waugzee/internal/app"
+	imagesController "waugzee/internal/controllers/images"
+	"waugzee/internal/handlers/middleware"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// imageCacheControl lets the user's browser, but no shared cache, keep an image for a day and
+// revalidate it with its ETag after
+const imageCacheControl = "private, max-age=86400"
+
+type ImageHandler struct {
+	Handler
+	imagesController imagesController.ImagesControllerInterface
+}
+
+func NewImageHandler(app app.App, router fiber.Router) *ImageHandler {
+	log := logger.New("handlers").File("image_handler")
+	return &ImageHandler{
+		imagesController: app.Controllers.Images,
+		Handler: Handler{
+			log:        log,
+			router:     router,
+			middleware: app.Middleware,
+		},
+	}
+}
+
+// Register adds the image routes. They sit behind RequireAuth, as a cache miss downloads from
+// Discogs and renders on this server.
+func (h *ImageHandler) Register() {
+	images := h.router.Group("/images")
+	images.Get("/:releaseId/:size", h.getReleaseImage)
+}
+
+func (h *ImageHandler) getReleaseImage(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("image_handler").Function("getReleaseImage")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		log.Warn("Unauthorized access attempt")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	releaseID, err := strconv.ParseInt(c.Params("releaseId"), 10, 64)
+	if err != nil || releaseID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid release ID",
+		})
+	}
+
+	result, err := h.imagesController.GetReleaseImage(
+		c.UserContext(),
+		user.ID,
+		releaseID,
+		c.Params("size"),
+		c.Get(fiber.HeaderIfNoneMatch),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidImageSize):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid image size",
+			})
+		case errors.Is(err, services.ErrImageNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Image not found",
+			})
+		case errors.Is(err, services.ErrImageRateLimited):
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many uncached image requests, try again shortly",
+			})
+		case errors.Is(err, services.ErrImageUnavailable):
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+				"error": "Image could not be fetched",
+			})
+		default:
+			_ = log.Err("Failed to get release image", err, "releaseID", releaseID)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to get image",
+			})
+		}
+	}
+
+	c.Set(fiber.HeaderETag, result.ETag)
+	c.Set(fiber.HeaderCacheControl, imageCacheControl)
+	if result.NotModified {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, result.Image.ContentType)
+	c.Set(fiber.HeaderLastModified, result.Image.ModifiedAt.UTC().Format(http.TimeFormat))
+	return c.Status(fiber.StatusOK).Send(result.Image.Data)
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"strings"
+	"waugzee/config"
+	"waugzee/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsHandler serves the Prometheus metrics of this replica on /metrics, outside /api so it runs
+// without user authentication. With METRICS_TOKEN set scrapes must send it as a bearer token.
+func MetricsHandler(router fiber.Router, config config.Config) {
+	handler := adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+
+	router.Get("/metrics", func(c *fiber.Ctx) error {
+		if config.MetricsToken != "" {
+			token, found := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(token), []byte(config.MetricsToken)) != 1 {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Invalid metrics token",
+				})
+			}
+		}
+
+		return handler(c)
+	})
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"waugzee/internal/models"
 	"waugzee/internal/services"
@@ -15,12 +16,40 @@ import (
 type AuthContextKey string
 
 const (
-	UserKey      AuthContextKey = "user"
-	UserKeyFiber string         = "User" // Fiber context key (string)
+	UserKey               AuthContextKey = "user"
+	UserKeyFiber          string         = "User"          // Fiber context key (string)
+	ApiTokenKeyFiber      string         = "ApiToken"      // Fiber context key for personal access tokens
+	AuthorizationKeyFiber string         = "Authorization" // Fiber context key for the user's roles
 )
 
-// RequireAuth middleware validates OIDC tokens and requires authentication
-func (m *Middleware) RequireAuth(zitadelService *services.ZitadelService) fiber.Handler {
+// apiTokenRoute maps a path prefix to the scope a personal access token needs to call it.
+// An empty scope means the routes are never reachable with a personal access token.
+type apiTokenRoute struct {
+	prefix string
+	write  bool
+	scope  models.ApiTokenScope
+}
+
+// apiTokenRoutes are checked in order, the first matching entry decides. Requests that match no
+// entry need the admin scope for writes and the read scope otherwise.
+var apiTokenRoutes = []apiTokenRoute{
+	{prefix: "/api/users/me/tokens"},
+	{prefix: "/api/webhooks"},
+	{prefix: "/api/auth"},
+	{prefix: "/api/admin", scope: models.ApiTokenScopeAdmin},
+	{prefix: "/api/plays", write: true, scope: models.ApiTokenScopeWriteHistory},
+	{prefix: "/api/cleanings", write: true, scope: models.ApiTokenScopeWriteHistory},
+	{prefix: "/api/logBoth", write: true, scope: models.ApiTokenScopeWriteHistory},
+	{prefix: "/api/recommendations", write: true, scope: models.ApiTokenScopeWriteHistory},
+}
+
+// RequireAuth middleware validates OIDC ID tokens or personal access tokens, requires
+// authentication and resolves the user's roles for RequirePermission
+func (m *Middleware) RequireAuth(
+	authProvider services.AuthProvider,
+	apiTokenService *services.ApiTokenService,
+	authorizationService *services.AuthorizationService,
+) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log := logger.New("middleware").TraceFromContext(c.UserContext()).Function("RequireAuth")
 
@@ -49,8 +78,12 @@ func (m *Middleware) RequireAuth(zitadelService *services.ZitadelService) fiber.
 			})
 		}
 
-		// Validate ID token (JWT) with Zitadel
-		tokenInfo, err := zitadelService.ValidateIDToken(c.UserContext(), token)
+		if services.IsApiToken(token) {
+			return m.authenticateApiToken(c, apiTokenService, authorizationService, token)
+		}
+
+		// Validate ID token (JWT) with the configured provider
+		tokenInfo, err := authProvider.ValidateIDToken(c.UserContext(), token)
 		if err != nil {
 			log.Info("token validation failed", "error", err.Error())
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -75,6 +108,7 @@ func (m *Middleware) RequireAuth(zitadelService *services.ZitadelService) fiber.
 
 		// Store user in Fiber context
 		c.Locals(UserKeyFiber, user)
+		c.Locals(AuthorizationKeyFiber, authorizationService.Resolve(user, tokenInfo.Roles))
 
 		// Add to Go context for services (preserve trace ID from TraceID middleware)
 		ctx := context.WithValue(c.UserContext(), UserKey, user)
@@ -93,6 +127,83 @@ func (m *Middleware) RequireAuth(zitadelService *services.ZitadelService) fiber.
 	}
 }
 
+// authenticateApiToken signs the request in as the owner of a personal access token when the
+// token's scopes allow the request
+func (m *Middleware) authenticateApiToken(
+	c *fiber.Ctx,
+	apiTokenService *services.ApiTokenService,
+	authorizationService *services.AuthorizationService,
+	secret string,
+) error {
+	log := logger.New("middleware").TraceFromContext(c.UserContext()).Function("authenticateApiToken")
+
+	apiToken, err := apiTokenService.Authenticate(c.UserContext(), secret)
+	if err != nil {
+		log.Info("api token validation failed", "error", err.Error())
+		if errors.Is(err, services.ErrApiTokenExpired) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token expired",
+			})
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid token",
+		})
+	}
+
+	scope, allowed := requiredApiTokenScope(c.Method(), c.Path())
+	if !allowed || !apiToken.HasScope(scope) {
+		log.Info(
+			"api token scope does not allow request",
+			"tokenID", apiToken.ID,
+			"method", c.Method(),
+			"path", c.Path(),
+		)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Token scope does not allow this request",
+		})
+	}
+
+	user := apiToken.User
+	c.Locals(UserKeyFiber, user)
+	c.Locals(ApiTokenKeyFiber, apiToken)
+	c.Locals(AuthorizationKeyFiber, authorizationService.Resolve(user, nil))
+
+	ctx := context.WithValue(c.UserContext(), UserKey, user)
+	c.SetUserContext(ctx)
+
+	log.Info("user authenticated with api token", "dbUserID", user.ID, "tokenID", apiToken.ID)
+	return c.Next()
+}
+
+// requiredApiTokenScope returns the scope a personal access token needs for a request, or false
+// when no token may make the request. Fiber routes match regardless of case, so the prefixes do too.
+func requiredApiTokenScope(method string, path string) (models.ApiTokenScope, bool) {
+	write := !isReadMethod(method)
+	path = strings.ToLower(path)
+
+	for _, route := range apiTokenRoutes {
+		if !strings.HasPrefix(path, strings.ToLower(route.prefix)) || (route.write && !write) {
+			continue
+		}
+		return route.scope, route.scope != ""
+	}
+
+	if write {
+		return models.ApiTokenScopeAdmin, true
+	}
+	return models.ApiTokenScopeReadCollection, true
+}
+
+// GetApiToken returns the personal access token that authenticated the request, or nil when the
+// request was authenticated with an OIDC ID token
+func GetApiToken(c *fiber.Ctx) *models.ApiToken {
+	apiToken, ok := c.Locals(ApiTokenKeyFiber).(*models.ApiToken)
+	if !ok {
+		return nil
+	}
+	return apiToken
+}
+
 // GetUser extracts user from Fiber context
 func GetUser(c *fiber.Ctx) *models.User {
 	user, ok := c.Locals(UserKeyFiber).(*models.User)
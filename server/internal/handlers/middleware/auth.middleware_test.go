@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"waugzee/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredApiTokenScope(t *testing.T) {
+	tests := []struct {
+		method  string
+		path    string
+		scope   models.ApiTokenScope
+		allowed bool
+	}{
+		{fiber.MethodGet, "/api/users/me", models.ApiTokenScopeReadCollection, true},
+		{fiber.MethodGet, "/api/releases/123", models.ApiTokenScopeReadCollection, true},
+		{fiber.MethodPost, "/api/plays", models.ApiTokenScopeWriteHistory, true},
+		{fiber.MethodDelete, "/api/cleanings/abc", models.ApiTokenScopeWriteHistory, true},
+		{fiber.MethodPost, "/api/logBoth", models.ApiTokenScopeWriteHistory, true},
+		{fiber.MethodPost, "/api/recommendations/abc/listen", models.ApiTokenScopeWriteHistory, true},
+		{fiber.MethodPut, "/api/users/me/preferences", models.ApiTokenScopeAdmin, true},
+		{fiber.MethodGet, "/api/admin/jobs", models.ApiTokenScopeAdmin, true},
+		{fiber.MethodGet, "/api/users/me/tokens", "", false},
+		{fiber.MethodPost, "/api/users/me/tokens", "", false},
+		{fiber.MethodPost, "/api/auth/logout", "", false},
+		// Fiber routes are case insensitive, so the prefixes must be too
+		{fiber.MethodGet, "/API/Users/me/tokens", "", false},
+		{fiber.MethodPost, "/Api/webhooks", "", false},
+		{fiber.MethodGet, "/api/ADMIN/jobs", models.ApiTokenScopeAdmin, true},
+		{fiber.MethodPost, "/api/logboth", models.ApiTokenScopeWriteHistory, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			scope, allowed := requiredApiTokenScope(tt.method, tt.path)
+			assert.Equal(t, tt.allowed, allowed)
+			assert.Equal(t, tt.scope, scope)
+		})
+	}
+}
+
+// The scope check must see the same paths the router does, whatever their case
+func TestRequiredApiTokenScopeMatchesRouter(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/users/me/tokens", func(c *fiber.Ctx) error {
+		_, allowed := requiredApiTokenScope(c.Method(), c.Path())
+		if allowed {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		return c.SendStatus(fiber.StatusForbidden)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/API/Users/me/tokens", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+	"waugzee/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UnmatchedRoute labels requests no route handled, so probes for random paths share one series
+const UnmatchedRoute = "unmatched"
+
+// Metrics records the latency and status of every request by route pattern. Register it before
+// any other middleware so their time and early responses are included.
+func (m *Middleware) Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status, route := c.Response().StatusCode(), c.Route().Path
+		if err != nil {
+			// The error handler answers with the error's status after this middleware returns
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+			// Fiber answers requests no route matched with a not found error, while the last
+			// route is whatever middleware ran
+			if status == fiber.StatusNotFound {
+				route = UnmatchedRoute
+			}
+		}
+
+		metrics.ObserveHTTPRequest(c.Method(), route, status, time.Since(start))
+		return err
+	}
+}
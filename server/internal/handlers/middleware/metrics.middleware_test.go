@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"waugzee/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestCount reads waugzee_http_requests_total for the labels from the registry
+func requestCount(t *testing.T, method, route, status string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "waugzee_http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["method"] == method && labels["route"] == route && labels["status"] == status {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := &Middleware{}
+	app := fiber.New()
+	app.Use(m.Metrics())
+	api := app.Group("/api", func(c *fiber.Ctx) error { return c.Next() })
+	api.Get("/records/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "missing" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Record not found"})
+		}
+		return c.JSON(fiber.Map{"id": c.Params("id")})
+	})
+	api.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusBadGateway, "upstream failed")
+	})
+
+	for _, path := range []string{"/api/records/1", "/api/records/2", "/api/records/missing", "/api/fail", "/api/nope"} {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, float64(2), requestCount(t, "GET", "/api/records/:id", "200"), "paths share their route pattern")
+	assert.Equal(t, float64(1), requestCount(t, "GET", "/api/records/:id", "404"))
+	assert.Equal(t, float64(1), requestCount(t, "GET", "/api/fail", "502"), "errors count with the status they answer")
+	assert.Equal(t, float64(1), requestCount(t, "GET", UnmatchedRoute, "404"))
+}
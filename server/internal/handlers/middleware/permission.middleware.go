@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"waugzee/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission allows the request when the user has every listed permission, for use on a
+// route group after RequireAuth
+func (m *Middleware) RequirePermission(permissions ...models.Permission) fiber.Handler {
+	log := m.log.Function("RequirePermission")
+
+	return func(c *fiber.Ctx) error {
+		user := GetUser(c)
+		if user == nil {
+			log.Info("user not found in context")
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authentication required",
+			})
+		}
+
+		authorization := GetAuthorization(c)
+		for _, permission := range permissions {
+			if !authorization.Can(permission) {
+				log.Info(
+					"user lacks permission",
+					"userID", user.ID,
+					"permission", permission,
+					"method", c.Method(),
+					"path", c.Path(),
+				)
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error":      "Permission required",
+					"permission": permission,
+				})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireReadWrite requires the read permission for safe methods and the write permission for
+// everything else, for route groups that mix reads and writes
+func (m *Middleware) RequireReadWrite(read models.Permission, write models.Permission) fiber.Handler {
+	requireRead := m.RequirePermission(read)
+	requireWrite := m.RequirePermission(write)
+
+	return func(c *fiber.Ctx) error {
+		if isReadMethod(c.Method()) {
+			return requireRead(c)
+		}
+		return requireWrite(c)
+	}
+}
+
+func isReadMethod(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
+
+// GetAuthorization returns the roles and permissions resolved for the request, or nil before
+// RequireAuth
+func GetAuthorization(c *fiber.Ctx) *models.Authorization {
+	authorization, ok := c.Locals(AuthorizationKeyFiber).(*models.Authorization)
+	if !ok {
+		return nil
+	}
+	return authorization
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"waugzee/internal/models"
+
+	logger "github.com/Bparsons0904/goLogger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPermissionTestApp(roles ...models.Role) *fiber.App {
+	m := &Middleware{log: logger.New("middleware")}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(UserKeyFiber, &models.User{})
+		c.Locals(AuthorizationKeyFiber, models.NewAuthorization(roles))
+		return c.Next()
+	})
+
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	downloads := app.Group("/admin/downloads", m.RequirePermission(models.PermissionDumpsManage))
+	downloads.Post("/reprocess", ok)
+	jobs := app.Group("/admin/jobs", m.RequirePermission(models.PermissionSystemManage))
+	jobs.Post("/:name/run", ok)
+	styluses := app.Group(
+		"/styluses",
+		m.RequireReadWrite(models.PermissionCollectionRead, models.PermissionCollectionWrite),
+	)
+	styluses.Get("", ok)
+	styluses.Post("", ok)
+
+	return app
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name   string
+		roles  []models.Role
+		method string
+		path   string
+		status int
+	}{
+		{"catalog maintainer reprocesses dumps", []models.Role{models.RoleMember, models.RoleCatalogMaintainer}, fiber.MethodPost, "/admin/downloads/reprocess", fiber.StatusOK},
+		{"catalog maintainer can not run jobs", []models.Role{models.RoleCatalogMaintainer}, fiber.MethodPost, "/admin/jobs/cleanup/run", fiber.StatusForbidden},
+		{"member can not reprocess dumps", []models.Role{models.RoleMember}, fiber.MethodPost, "/admin/downloads/reprocess", fiber.StatusForbidden},
+		{"admin runs jobs", []models.Role{models.RoleAdmin}, fiber.MethodPost, "/admin/jobs/cleanup/run", fiber.StatusOK},
+		{"viewer reads", []models.Role{models.RoleViewer}, fiber.MethodGet, "/styluses", fiber.StatusOK},
+		{"viewer can not write", []models.Role{models.RoleViewer}, fiber.MethodPost, "/styluses", fiber.StatusForbidden},
+		{"member writes", []models.Role{models.RoleMember}, fiber.MethodPost, "/styluses", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newPermissionTestApp(tt.roles...)
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestRequirePermission_Unauthenticated(t *testing.T) {
+	m := &Middleware{log: logger.New("middleware")}
+	app := fiber.New()
+	app.Get("/", m.RequirePermission(models.PermissionCollectionRead), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
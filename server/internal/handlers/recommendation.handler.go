@@ -5,6 +5,7 @@ import (
 	"waugzee/internal/app"
 	recommendationController "waugzee/internal/controllers/recommendation"
 	"waugzee/internal/handlers/middleware"
+	"waugzee/internal/models"
 	logger "github.com/Bparsons0904/goLogger"
 
 	"github.com/gofiber/fiber/v2"
@@ -29,7 +30,10 @@ func NewRecommendationHandler(app app.App, router fiber.Router) *RecommendationH
 }
 
 func (h *RecommendationHandler) Register() {
-	recommendations := h.router.Group("/recommendations")
+	recommendations := h.router.Group(
+		"/recommendations",
+		h.middleware.RequirePermission(models.PermissionHistoryWrite),
+	)
 	recommendations.Post("/:id/listen", h.markAsListened)
 }
 
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"waugzee/internal/app"
+	roleController "waugzee/internal/controllers/roles"
+	"waugzee/internal/handlers/middleware"
+	"waugzee/internal/models"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RoleHandler struct {
+	Handler
+	roleController roleController.RoleControllerInterface
+}
+
+func NewRoleHandler(app app.App, router fiber.Router) *RoleHandler {
+	log := logger.New("handlers").File("role_handler")
+	return &RoleHandler{
+		roleController: app.Controllers.Role,
+		Handler: Handler{
+			log:        log,
+			router:     router,
+			middleware: app.Middleware,
+		},
+	}
+}
+
+func (h *RoleHandler) Register() {
+	roles := h.router.Group(
+		"/admin/users/:id/roles",
+		h.middleware.RequirePermission(models.PermissionUsersManage),
+	)
+	roles.Get("", h.listUserRoles)
+	roles.Post("", h.assignRole)
+	roles.Delete("/:role", h.revokeRole)
+}
+
+func (h *RoleHandler) listUserRoles(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("role_handler").Function("listUserRoles")
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	userRoles, err := h.roleController.ListUserRoles(c.UserContext(), userID)
+	if err != nil {
+		_ = log.Err("Failed to list user roles", err, "userID", userID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list user roles",
+		})
+	}
+
+	return c.JSON(fiber.Map{"roles": userRoles})
+}
+
+func (h *RoleHandler) assignRole(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("role_handler").Function("assignRole")
+
+	admin := middleware.GetUser(c)
+	if admin == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req roleController.AssignRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("Invalid request body", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := h.roleController.AssignRole(c.UserContext(), admin, userID, &req); err != nil {
+		return h.roleError(c, err, "Failed to assign role")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *RoleHandler) revokeRole(c *fiber.Ctx) error {
+	admin := middleware.GetUser(c)
+	if admin == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	role := models.Role(c.Params("role"))
+	if err := h.roleController.RevokeRole(c.UserContext(), admin, userID, role); err != nil {
+		return h.roleError(c, err, "Failed to revoke role")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// roleError maps role assignment errors to responses
+func (h *RoleHandler) roleError(c *fiber.Ctx, err error, message string) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("role_handler").Function("roleError")
+
+	switch {
+	case errors.Is(err, services.ErrInvalidRole):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role",
+		})
+	case errors.Is(err, services.ErrRoleUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	case errors.Is(err, services.ErrUserRoleNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User does not have the role",
+		})
+	default:
+		_ = log.Err(message, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+}
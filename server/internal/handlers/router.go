@@ -17,21 +17,32 @@ type Handler struct {
 
 func Router(router fiber.Router, app *app.App) (err error) {
 	setupWebSocketRoute(router, app)
+	MetricsHandler(router, app.Config)
 
 	api := router.Group("/api")
 
 	// Apply TraceID middleware to all API routes
 	api.Use(app.Middleware.TraceID())
 
-	HealthHandler(api, app.Config)
+	HealthHandler(api, app.Config, app.Services.Health)
+	EventSchemaHandler(api)
 	NewAuthHandler(*app, api).Register()
-	api.Use(app.Middleware.RequireAuth(app.Services.Zitadel))
+	api.Use(app.Middleware.RequireAuth(
+		app.Services.Auth,
+		app.Services.ApiToken,
+		app.Services.Authorization,
+	))
 	NewUserHandler(*app, api).Register()
+	NewImageHandler(*app, api).Register()
+	NewTokenHandler(*app, api).Register()
+	NewWebhookHandler(*app, api).Register()
 	NewSyncHandler(*app, api).Register()
 	NewStylusHandler(*app, api).Register()
 	NewHistoryHandler(*app, api).Register()
 	NewRecommendationHandler(*app, api).Register()
+	NewCatalogHandler(*app, api).Register()
 	NewAdminHandler(*app, api).Register()
+	NewRoleHandler(*app, api).Register()
 	NewLoggingHandler(*app, api).Register()
 
 	return nil
@@ -4,6 +4,7 @@ import (
 	"waugzee/internal/app"
 	stylusController "waugzee/internal/controllers/stylus"
 	"waugzee/internal/handlers/middleware"
+	"waugzee/internal/models"
 	logger "github.com/Bparsons0904/goLogger"
 
 	"github.com/gofiber/fiber/v2"
@@ -28,7 +29,10 @@ func NewStylusHandler(app app.App, router fiber.Router) *StylusHandler {
 }
 
 func (h *StylusHandler) Register() {
-	styluses := h.router.Group("/styluses")
+	styluses := h.router.Group(
+		"/styluses",
+		h.middleware.RequireReadWrite(models.PermissionCollectionRead, models.PermissionCollectionWrite),
+	)
 
 	styluses.Get("/available", h.getAvailableStyluses)
 	styluses.Post("/custom", h.createCustomStylus)
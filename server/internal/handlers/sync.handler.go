@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"strconv"
 	"waugzee/internal/app"
 	syncController "waugzee/internal/controllers/sync"
 	"waugzee/internal/handlers/middleware"
+	"waugzee/internal/models"
 	logger "github.com/Bparsons0904/goLogger"
 	"waugzee/internal/services"
 
@@ -13,14 +16,12 @@ import (
 type SyncHandler struct {
 	Handler
 	syncController syncController.SyncControllerInterface
-	zitadelService *services.ZitadelService
 }
 
 func NewSyncHandler(app app.App, router fiber.Router) *SyncHandler {
 	log := logger.New("handlers").File("sync_handler")
 	return &SyncHandler{
 		syncController: app.Controllers.Sync,
-		zitadelService: app.Services.Zitadel,
 		Handler: Handler{
 			log:        log,
 			router:     router,
@@ -30,9 +31,10 @@ func NewSyncHandler(app app.App, router fiber.Router) *SyncHandler {
 }
 
 func (h *SyncHandler) Register() {
-	sync := h.router.Group("/sync")
+	sync := h.router.Group("/sync", h.middleware.RequirePermission(models.PermissionCollectionWrite))
 
 	sync.Post("/syncCollection", h.InitiateCollectionSync)
+	sync.Post("/releases/:id/refresh", h.RefreshRelease)
 }
 
 func (h *SyncHandler) InitiateCollectionSync(c *fiber.Ctx) error {
@@ -61,3 +63,45 @@ func (h *SyncHandler) InitiateCollectionSync(c *fiber.Ctx) error {
 		"message": "Collection sync initiated successfully",
 	})
 }
+
+func (h *SyncHandler) RefreshRelease(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("sync_handler").Function("RefreshRelease")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		log.Warn("Unauthorized access attempt")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	releaseID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || releaseID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid release ID",
+		})
+	}
+
+	err = h.syncController.RefreshRelease(c.UserContext(), user, releaseID)
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrDiscogsTokenMissing):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Discogs token is not configured",
+		})
+	case errors.Is(err, services.ErrReleaseRefreshNotRequested):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Release refresh could not be requested, try again later",
+		})
+	default:
+		_ = log.Err("Failed to refresh release", err, "userID", user.ID, "releaseID", releaseID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to refresh release",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status":    "accepted",
+		"releaseId": releaseID,
+	})
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"waugzee/internal/app"
+	tokenController "waugzee/internal/controllers/tokens"
+	"waugzee/internal/handlers/middleware"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// tokenValidationErrors are returned to the client as is when creating a token fails
+var tokenValidationErrors = []error{
+	tokenController.ErrInvalidExpiresInDays,
+	services.ErrApiTokenNameRequired,
+	services.ErrApiTokenNameTooLong,
+	services.ErrApiTokenScopeRequired,
+	services.ErrApiTokenInvalidScope,
+	services.ErrApiTokenInvalidExpiry,
+}
+
+type TokenHandler struct {
+	Handler
+	tokenController tokenController.TokenControllerInterface
+}
+
+func NewTokenHandler(app app.App, router fiber.Router) *TokenHandler {
+	log := logger.New("handlers").File("token_handler")
+	return &TokenHandler{
+		tokenController: app.Controllers.Token,
+		Handler: Handler{
+			log:        log,
+			router:     router,
+			middleware: app.Middleware,
+		},
+	}
+}
+
+// Register adds the personal access token routes. RequireAuth rejects personal access tokens on
+// them, so a leaked token can not mint or revoke tokens.
+func (h *TokenHandler) Register() {
+	tokens := h.router.Group("/users/me/tokens")
+	tokens.Get("", h.listTokens)
+	tokens.Post("", h.createToken)
+	tokens.Delete("/:id", h.revokeToken)
+}
+
+func (h *TokenHandler) listTokens(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("token_handler").Function("listTokens")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	tokens, err := h.tokenController.ListTokens(c.UserContext(), user)
+	if err != nil {
+		_ = log.Err("Failed to list tokens", err, "userID", user.ID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list tokens",
+		})
+	}
+
+	return c.JSON(fiber.Map{"tokens": tokens})
+}
+
+func (h *TokenHandler) createToken(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("token_handler").Function("createToken")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	var req tokenController.CreateTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("Invalid request body", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	result, err := h.tokenController.CreateToken(c.UserContext(), user, &req)
+	if err != nil {
+		for _, validationErr := range tokenValidationErrors {
+			if errors.Is(err, validationErr) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": validationErr.Error(),
+				})
+			}
+		}
+
+		switch {
+		case errors.Is(err, services.ErrApiTokenScopeForbidden):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Admin scope requires an admin user",
+			})
+		case errors.Is(err, services.ErrApiTokenLimitReached):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Token limit reached, revoke an unused token first",
+			})
+		default:
+			_ = log.Err("Failed to create token", err, "userID", user.ID)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to create token",
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (h *TokenHandler) revokeToken(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("token_handler").Function("revokeToken")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	tokenID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid token ID",
+		})
+	}
+
+	if err := h.tokenController.RevokeToken(c.UserContext(), user, tokenID); err != nil {
+		if errors.Is(err, services.ErrApiTokenNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Token not found",
+			})
+		}
+		_ = log.Err("Failed to revoke token", err, "userID", user.ID, "tokenID", tokenID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke token",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -4,8 +4,8 @@ import (
 	"waugzee/internal/app"
 	userController "waugzee/internal/controllers/users"
 	"waugzee/internal/handlers/middleware"
+	"waugzee/internal/models"
 	logger "github.com/Bparsons0904/goLogger"
-	"waugzee/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -20,14 +20,12 @@ type UpdateSelectedFolderRequest struct {
 
 type UserHandler struct {
 	Handler
-	zitadelService *services.ZitadelService
 	userController userController.UserControllerInterface
 }
 
 func NewUserHandler(app app.App, router fiber.Router) *UserHandler {
 	log := logger.New("handlers").File("user_handler")
 	return &UserHandler{
-		zitadelService: app.Services.Zitadel,
 		userController: app.Controllers.User,
 		Handler: Handler{
 			log:        log,
@@ -40,10 +38,13 @@ func NewUserHandler(app app.App, router fiber.Router) *UserHandler {
 func (h *UserHandler) Register() {
 	users := h.router.Group("/users")
 
+	// Not a group middleware, the /users prefix is shared with the token routes
+	requireWrite := h.middleware.RequirePermission(models.PermissionCollectionWrite)
+
 	users.Get("/me", h.getCurrentUser)
-	users.Put("/me/discogs", h.updateDiscogsToken)
-	users.Put("/me/folder", h.updateSelectedFolder)
-	users.Put("/me/preferences", h.updateUserPreferences)
+	users.Put("/me/discogs", requireWrite, h.updateDiscogsToken)
+	users.Put("/me/folder", requireWrite, h.updateSelectedFolder)
+	users.Put("/me/preferences", requireWrite, h.updateUserPreferences)
 }
 
 func (h *UserHandler) getCurrentUser(c *fiber.Ctx) error {
@@ -65,6 +66,8 @@ func (h *UserHandler) getCurrentUser(c *fiber.Ctx) error {
 		})
 	}
 
+	authorization := middleware.GetAuthorization(c)
+
 	return c.JSON(fiber.Map{
 		"user":                user,
 		"folders":             userData.Folders,
@@ -72,6 +75,8 @@ func (h *UserHandler) getCurrentUser(c *fiber.Ctx) error {
 		"styluses":            userData.Styluses,
 		"playHistory":         userData.PlayHistory,
 		"dailyRecommendation": userData.DailyRecommendation,
+		"roles":               authorization.Roles,
+		"permissions":         authorization.PermissionList(),
 	})
 }
 
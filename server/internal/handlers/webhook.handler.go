@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"waugzee/internal/app"
+	webhookController "waugzee/internal/controllers/webhooks"
+	"waugzee/internal/handlers/middleware"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/models"
+	"waugzee/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// webhookValidationErrors are returned to the client as is when creating a webhook fails
+var webhookValidationErrors = []error{
+	services.ErrWebhookURLRequired,
+	services.ErrWebhookURLInvalid,
+	services.ErrWebhookURLNotHTTPS,
+	services.ErrWebhookAddressForbidden,
+	services.ErrWebhookDescriptionLength,
+	services.ErrWebhookEventRequired,
+	services.ErrWebhookInvalidEvent,
+}
+
+type WebhookHandler struct {
+	Handler
+	webhookController webhookController.WebhookControllerInterface
+}
+
+func NewWebhookHandler(app app.App, router fiber.Router) *WebhookHandler {
+	log := logger.New("handlers").File("webhook_handler")
+	return &WebhookHandler{
+		webhookController: app.Controllers.Webhook,
+		Handler: Handler{
+			log:        log,
+			router:     router,
+			middleware: app.Middleware,
+		},
+	}
+}
+
+// Register adds the webhook routes. Webhooks send the user's collection activity elsewhere, so
+// viewing them needs collection read access, while registering endpoints and triggering sends needs
+// collection write access. RequireAuth rejects personal access tokens on them.
+func (h *WebhookHandler) Register() {
+	webhooks := h.router.Group("/webhooks", h.middleware.RequirePermission(models.PermissionCollectionRead))
+	requireWrite := h.middleware.RequirePermission(models.PermissionCollectionWrite)
+
+	webhooks.Get("", h.listWebhooks)
+	webhooks.Post("", requireWrite, h.createWebhook)
+	webhooks.Delete("/:id", requireWrite, h.deleteWebhook)
+	webhooks.Get("/:id/deliveries", h.listDeliveries)
+	webhooks.Post("/:id/deliveries/:deliveryId/replay", requireWrite, h.replayDelivery)
+}
+
+func (h *WebhookHandler) listWebhooks(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("webhook_handler").Function("listWebhooks")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	webhooks, err := h.webhookController.ListWebhooks(c.UserContext(), user)
+	if err != nil {
+		_ = log.Err("Failed to list webhooks", err, "userID", user.ID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list webhooks",
+		})
+	}
+
+	return c.JSON(fiber.Map{"webhooks": webhooks, "events": models.WebhookEvents})
+}
+
+func (h *WebhookHandler) createWebhook(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("webhook_handler").Function("createWebhook")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	var req webhookController.CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Warn("Invalid request body", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	result, err := h.webhookController.CreateWebhook(c.UserContext(), user, &req)
+	if err != nil {
+		for _, validationErr := range webhookValidationErrors {
+			if errors.Is(err, validationErr) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": validationErr.Error(),
+				})
+			}
+		}
+
+		if errors.Is(err, services.ErrWebhookLimitReached) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Webhook limit reached, delete an unused webhook first",
+			})
+		}
+		_ = log.Err("Failed to create webhook", err, "userID", user.ID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create webhook",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (h *WebhookHandler) deleteWebhook(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("webhook_handler").Function("deleteWebhook")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	webhookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	if err := h.webhookController.DeleteWebhook(c.UserContext(), user, webhookID); err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Webhook not found",
+			})
+		}
+		_ = log.Err("Failed to delete webhook", err, "userID", user.ID, "webhookID", webhookID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete webhook",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *WebhookHandler) listDeliveries(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("webhook_handler").Function("listDeliveries")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	webhookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	deliveries, err := h.webhookController.ListDeliveries(c.UserContext(), user, webhookID)
+	if err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Webhook not found",
+			})
+		}
+		_ = log.Err("Failed to list webhook deliveries", err, "userID", user.ID, "webhookID", webhookID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list webhook deliveries",
+		})
+	}
+
+	return c.JSON(fiber.Map{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) replayDelivery(c *fiber.Ctx) error {
+	log := logger.New("handlers").TraceFromContext(c.UserContext()).File("webhook_handler").Function("replayDelivery")
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Authentication required",
+		})
+	}
+
+	webhookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	deliveryID, err := uuid.Parse(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid delivery ID",
+		})
+	}
+
+	delivery, err := h.webhookController.ReplayDelivery(c.UserContext(), user, webhookID, deliveryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWebhookNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Webhook not found",
+			})
+		case errors.Is(err, services.ErrWebhookDeliveryNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Delivery not found",
+			})
+		default:
+			_ = log.Err("Failed to replay webhook delivery", err,
+				"userID", user.ID,
+				"webhookID", webhookID,
+				"deliveryID", deliveryID)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to replay webhook delivery",
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"delivery": delivery})
+}
@@ -3,9 +3,6 @@ package jobs
 import (
 	"context"
 	"errors"
-	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 	logger "github.com/Bparsons0904/goLogger"
@@ -153,7 +150,7 @@ func (j *DiscogsDownloadJob) Execute(ctx context.Context) error {
 	}
 
 	// Transition to downloading status
-	if err := processingRecord.UpdateStatus(models.ProcessingStatusDownloading); err != nil {
+	if err := processingRecord.UpdateStatus(models.ProcessingStatusDownloading, "download started"); err != nil {
 		return log.Err(
 			"failed to transition to downloading status",
 			err,
@@ -185,6 +182,10 @@ func (j *DiscogsDownloadJob) Execute(ctx context.Context) error {
 		processingRecord.Status,
 	)
 
+	// Keep the watchdog from failing the record while the download is running
+	stopHeartbeat := services.StartProcessingHeartbeat(ctx, j.repo, processingRecord.ID)
+	defer stopHeartbeat()
+
 	// Perform the actual download
 	if err := j.performDownload(ctx, processingRecord, yearMonth); err != nil {
 		// Check if it's a 404 error (data not available yet)
@@ -196,7 +197,7 @@ func (j *DiscogsDownloadJob) Execute(ctx context.Context) error {
 			)
 			j.download.BroadcastProgress(yearMonth, "not_available", "all", "waiting", 0, 0, err)
 			// Reset status back to not_started so it will be retried next day
-			if statusErr := processingRecord.UpdateStatus(models.ProcessingStatusNotStarted); statusErr != nil {
+			if statusErr := processingRecord.UpdateStatus(models.ProcessingStatusNotStarted, "dump not published yet"); statusErr != nil {
 				log.Warn("failed to reset processing record status", "error", statusErr)
 			}
 			if updateErr := j.repo.Update(ctx, processingRecord); updateErr != nil {
@@ -209,7 +210,7 @@ func (j *DiscogsDownloadJob) Execute(ctx context.Context) error {
 		j.download.BroadcastProgress(yearMonth, "failed", "all", "error", 0, 0, err)
 		errorMsg := err.Error()
 		processingRecord.ErrorMessage = &errorMsg
-		if statusErr := processingRecord.UpdateStatus(models.ProcessingStatusFailed); statusErr != nil {
+		if statusErr := processingRecord.UpdateStatus(models.ProcessingStatusFailed, errorMsg); statusErr != nil {
 			log.Warn("failed to update processing record status to failed", "error", statusErr)
 		}
 
@@ -244,8 +245,8 @@ func (j *DiscogsDownloadJob) performDownload(
 	j.download.BroadcastProgress(yearMonth, "completed", "checksum", "parsing", 0, 0, nil)
 
 	// Parse the downloaded checksum file
-	checksumFile := filepath.Join(fmt.Sprintf("/app/discogs-data/%s", yearMonth), "CHECKSUM.txt")
-	checksums, err := j.download.ParseChecksumFile(checksumFile)
+	checksumFile := services.DumpChecksumKey(yearMonth)
+	checksums, err := j.download.ParseChecksumFile(ctx, checksumFile)
 	if err != nil {
 		j.download.BroadcastProgress(yearMonth, "failed", "checksum", "error", 0, 0, err)
 		return log.Err("failed to parse checksum file", err, "checksumFile", checksumFile)
@@ -276,7 +277,8 @@ func (j *DiscogsDownloadJob) performDownload(
 
 		log.Info("Downloading XML file", "fileType", fileType, "yearMonth", yearMonth)
 		j.download.BroadcastProgress(yearMonth, "downloading", fileType, "starting", 0, 0, nil)
-		if err := j.download.DownloadXMLFile(ctx, yearMonth, fileType); err != nil {
+		checksum := processingRecord.GetFileChecksum(fileType)
+		if err := j.download.DownloadXMLFile(ctx, yearMonth, fileType, checksum); err != nil {
 			j.download.BroadcastProgress(yearMonth, "failed", fileType, "error", 0, 0, err)
 			return log.Err("failed to download XML file", err, "fileType", fileType, "yearMonth", yearMonth)
 		}
@@ -284,10 +286,9 @@ func (j *DiscogsDownloadJob) performDownload(
 		log.Info("Downloaded XML file successfully", "fileType", fileType, "yearMonth", yearMonth)
 
 		// Update file info after successful download
-		filePath := filepath.Join(fmt.Sprintf("/app/discogs-data/%s", yearMonth), fmt.Sprintf("%s.xml.gz", fileType))
-		checksum := processingRecord.GetFileChecksum(fileType)
+		fileKey := services.DumpFileKey(yearMonth, fileType)
 
-		fileInfo, err := j.download.GetFileStatus(filePath, checksum)
+		fileInfo, err := j.download.GetFileStatus(ctx, fileKey, checksum)
 		if err != nil {
 			log.Warn("failed to get file status", "fileType", fileType, "error", err)
 		} else {
@@ -299,7 +300,7 @@ func (j *DiscogsDownloadJob) performDownload(
 	}
 
 	// Transition to ready_for_processing after all downloads complete
-	if err := processingRecord.UpdateStatus(models.ProcessingStatusReadyForProcessing); err != nil {
+	if err := processingRecord.UpdateStatus(models.ProcessingStatusReadyForProcessing, "all files downloaded"); err != nil {
 		return log.Err("failed to transition to ready_for_processing status", err, "yearMonth", yearMonth)
 	}
 
@@ -312,7 +313,7 @@ func (j *DiscogsDownloadJob) performDownload(
 	}
 
 	// Clean up downloaded checksum file to save space (we only need the parsed checksums)
-	if err := os.Remove(checksumFile); err != nil {
+	if err := j.download.DeleteFile(ctx, checksumFile); err != nil {
 		log.Warn("failed to clean up checksum file", "error", err, "file", checksumFile)
 	}
 
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/services"
+)
+
+type ProcessingWatchdogJob struct {
+	watchdog *services.ProcessingWatchdogService
+	log      logger.Logger
+	schedule services.Schedule
+}
+
+func NewProcessingWatchdogJob(
+	watchdog *services.ProcessingWatchdogService,
+	schedule services.Schedule,
+) *ProcessingWatchdogJob {
+	log := logger.New("processingWatchdogJob")
+	log.Info("Creating new processing watchdog job", "schedule", schedule)
+
+	return &ProcessingWatchdogJob{
+		watchdog: watchdog,
+		log:      log,
+		schedule: schedule,
+	}
+}
+
+func (j *ProcessingWatchdogJob) Name() string {
+	return "DiscogsProcessingWatchdog"
+}
+
+func (j *ProcessingWatchdogJob) Execute(ctx context.Context) error {
+	log := j.log.Function("Execute")
+
+	failed, err := j.watchdog.FailStaleRecords(ctx)
+	if err != nil {
+		return log.Err("processing watchdog failed", err)
+	}
+
+	log.Info("Processing watchdog completed", "failedRecords", failed)
+	return nil
+}
+
+func (j *ProcessingWatchdogJob) Schedule() services.Schedule {
+	return j.schedule
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/services"
+)
+
+type ReleaseRefreshJob struct {
+	freshness *services.ReleaseFreshnessService
+	log       logger.Logger
+	schedule  services.Schedule
+}
+
+func NewReleaseRefreshJob(
+	freshness *services.ReleaseFreshnessService,
+	schedule services.Schedule,
+) *ReleaseRefreshJob {
+	log := logger.New("releaseRefreshJob")
+	log.Info("Creating new release refresh job", "schedule", schedule)
+
+	return &ReleaseRefreshJob{
+		freshness: freshness,
+		log:       log,
+		schedule:  schedule,
+	}
+}
+
+func (j *ReleaseRefreshJob) Name() string {
+	return "ReleaseRefresh"
+}
+
+func (j *ReleaseRefreshJob) Execute(ctx context.Context) error {
+	log := j.log.Function("Execute")
+
+	requested, err := j.freshness.RefreshStaleReleases(ctx)
+	if err != nil {
+		return log.Err("release refresh failed", err)
+	}
+
+	log.Info("Release refresh completed", "requested", requested)
+	return nil
+}
+
+func (j *ReleaseRefreshJob) Schedule() services.Schedule {
+	return j.schedule
+}
@@ -5,6 +5,8 @@ import (
 	logger "github.com/Bparsons0904/goLogger"
 	"waugzee/internal/repositories"
 	"waugzee/internal/services"
+
+	"github.com/robfig/cron/v3"
 )
 
 // Import schedule constants
@@ -14,6 +16,30 @@ const (
 	Monthly = services.Monthly
 )
 
+// scheduleOrDefault uses a configured cron expression when one is set. An invalid expression is
+// logged and replaced by the default, so a typo does not keep the scheduler from starting.
+func scheduleOrDefault(
+	log logger.Logger,
+	setting, configured string,
+	fallback services.Schedule,
+) services.Schedule {
+	if configured == "" {
+		return fallback
+	}
+	if services.Schedule(configured) == Monthly {
+		return Monthly
+	}
+	if _, err := cron.ParseStandard(configured); err != nil {
+		log.Warn("Invalid job schedule, using the default",
+			"setting", setting,
+			"schedule", configured,
+			"default", fallback,
+			"error", err)
+		return fallback
+	}
+	return services.Schedule(configured)
+}
+
 func RegisterAllJobs(
 	schedulerService *services.SchedulerService,
 	config config.Config,
@@ -26,30 +52,48 @@ func RegisterAllJobs(
 	discogsDownloadJob := NewDiscogsDownloadJob(
 		services.Download,
 		repos.DiscogsDataProcessing,
-		Daily,
+		scheduleOrDefault(log, "JOB_SCHEDULE_DISCOGS_DOWNLOAD", config.JobScheduleDiscogsDownload, Daily),
 	)
 	if err := schedulerService.AddJob(discogsDownloadJob); err != nil {
 		return log.Err("failed to register Discogs download job", err)
 	}
-	log.Info("Registered Discogs download job", "schedule", "hourly")
+	log.Info("Registered Discogs download job", "schedule", discogsDownloadJob.Schedule())
 
 	discogsXMLParserJob := NewDiscogsXMLParserJob(
 		services.DiscogsXMLParser,
-		Daily,
+		scheduleOrDefault(log, "JOB_SCHEDULE_DISCOGS_XML_PARSER", config.JobScheduleDiscogsXMLParser, Daily),
 	)
 	if err := schedulerService.AddJob(discogsXMLParserJob); err != nil {
 		return log.Err("failed to register Discogs XML parser job", err)
 	}
-	log.Info("Registered Discogs XML parser job", "schedule", "hourly")
+	log.Info("Registered Discogs XML parser job", "schedule", discogsXMLParserJob.Schedule())
 
 	fileCleanupJob := NewFileCleanupJob(
 		services.FileCleanup,
-		Monthly,
+		scheduleOrDefault(log, "JOB_SCHEDULE_FILE_CLEANUP", config.JobScheduleFileCleanup, Monthly),
 	)
 	if err := schedulerService.AddJob(fileCleanupJob); err != nil {
 		return log.Err("failed to register file cleanup job", err)
 	}
-	log.Info("Registered file cleanup job", "schedule", "monthly")
+	log.Info("Registered file cleanup job", "schedule", fileCleanupJob.Schedule())
+
+	processingWatchdogJob := NewProcessingWatchdogJob(
+		services.ProcessingWatchdog,
+		scheduleOrDefault(log, "JOB_SCHEDULE_PROCESSING_WATCHDOG", config.JobScheduleProcessingWatchdog, Hourly),
+	)
+	if err := schedulerService.AddJob(processingWatchdogJob); err != nil {
+		return log.Err("failed to register processing watchdog job", err)
+	}
+	log.Info("Registered processing watchdog job", "schedule", processingWatchdogJob.Schedule())
+
+	releaseRefreshJob := NewReleaseRefreshJob(
+		services.ReleaseFreshness,
+		scheduleOrDefault(log, "JOB_SCHEDULE_RELEASE_REFRESH", config.JobScheduleReleaseRefresh, Daily),
+	)
+	if err := schedulerService.AddJob(releaseRefreshJob); err != nil {
+		return log.Err("failed to register release refresh job", err)
+	}
+	log.Info("Registered release refresh job", "schedule", releaseRefreshJob.Schedule())
 
 	return nil
 }
@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"testing"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleOrDefault(t *testing.T) {
+	log := logger.New("jobs").Function("TestScheduleOrDefault")
+	tests := []struct {
+		name       string
+		configured string
+		fallback   services.Schedule
+		expected   services.Schedule
+	}{
+		{name: "unset uses default", configured: "", fallback: Daily, expected: Daily},
+		{name: "valid cron", configured: "30 4 * * 1", fallback: Daily, expected: "30 4 * * 1"},
+		{name: "descriptor", configured: "@weekly", fallback: Daily, expected: "@weekly"},
+		{name: "last day of month", configured: string(Monthly), fallback: Daily, expected: Monthly},
+		{name: "too few fields", configured: "0 2 * *", fallback: Daily, expected: Daily},
+		{name: "out of range", configured: "0 25 * * *", fallback: Hourly, expected: Hourly},
+		{name: "not cron", configured: "daily", fallback: Monthly, expected: Monthly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, scheduleOrDefault(log, "JOB_SCHEDULE_TEST", tt.configured, tt.fallback))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// rateLimitScrapeTimeout bounds reading the rate limits from Valkey during a scrape
+const rateLimitScrapeTimeout = 5 * time.Second
+
+// RateLimitUsage summarizes the Discogs rate limits of the users that called Discogs within the
+// rate limit window
+type RateLimitUsage struct {
+	ActiveUsers int
+	Requests    int
+	// MaxUtilization is the highest share of the limit used by a single user, 0 to 1
+	MaxUtilization float64
+}
+
+var (
+	webSocketConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(NAMESPACE, "websocket", "connections"),
+		"WebSocket connections to this replica by status.",
+		[]string{"status"}, nil,
+	)
+
+	rateLimitActiveUsersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(NAMESPACE, "discogs_rate_limit", "active_users"),
+		"Users with Discogs requests in the current rate limit window.",
+		nil, nil,
+	)
+	rateLimitRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(NAMESPACE, "discogs_rate_limit", "requests"),
+		"Discogs requests in the current rate limit window, summed over users.",
+		nil, nil,
+	)
+	rateLimitUtilizationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(NAMESPACE, "discogs_rate_limit", "max_utilization_ratio"),
+		"Highest share of the Discogs rate limit used by a single user.",
+		nil, nil,
+	)
+)
+
+// webSocketCollector counts the connections when scraped, so the count can not drift from the hub
+type webSocketCollector struct {
+	connections func() map[string]int
+}
+
+func (c webSocketCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- webSocketConnectionsDesc
+}
+
+func (c webSocketCollector) Collect(ch chan<- prometheus.Metric) {
+	for status, count := range c.connections() {
+		ch <- prometheus.MustNewConstMetric(webSocketConnectionsDesc, prometheus.GaugeValue, float64(count), status)
+	}
+}
+
+// RegisterWebSocketConnections reports the connection counts by status returned by connections
+func RegisterWebSocketConnections(connections func() map[string]int) error {
+	return Registry.Register(webSocketCollector{connections: connections})
+}
+
+// rateLimitCollector reads the rate limits shared by all replicas from Valkey when scraped
+type rateLimitCollector struct {
+	usage func(ctx context.Context) (RateLimitUsage, error)
+}
+
+func (c rateLimitCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rateLimitActiveUsersDesc
+	ch <- rateLimitRequestsDesc
+	ch <- rateLimitUtilizationDesc
+}
+
+func (c rateLimitCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), rateLimitScrapeTimeout)
+	defer cancel()
+
+	usage, err := c.usage(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(rateLimitActiveUsersDesc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(rateLimitActiveUsersDesc, prometheus.GaugeValue, float64(usage.ActiveUsers))
+	ch <- prometheus.MustNewConstMetric(rateLimitRequestsDesc, prometheus.GaugeValue, float64(usage.Requests))
+	ch <- prometheus.MustNewConstMetric(rateLimitUtilizationDesc, prometheus.GaugeValue, usage.MaxUtilization)
+}
+
+// RegisterDiscogsRateLimit reports the Discogs rate limit usage returned by usage
+func RegisterDiscogsRateLimit(usage func(ctx context.Context) (RateLimitUsage, error)) error {
+	return Registry.Register(rateLimitCollector{usage: usage})
+}
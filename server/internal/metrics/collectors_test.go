@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocketCollector(t *testing.T) {
+	collector := webSocketCollector{connections: func() map[string]int {
+		return map[string]int{"authenticated": 3, "pending": 1}
+	}}
+
+	expected := `
+# HELP waugzee_websocket_connections WebSocket connections to this replica by status.
+# TYPE waugzee_websocket_connections gauge
+waugzee_websocket_connections{status="authenticated"} 3
+waugzee_websocket_connections{status="pending"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
+
+func TestRateLimitCollector(t *testing.T) {
+	collector := rateLimitCollector{usage: func(ctx context.Context) (RateLimitUsage, error) {
+		return RateLimitUsage{ActiveUsers: 2, Requests: 30, MaxUtilization: 0.5}, nil
+	}}
+
+	expected := `
+# HELP waugzee_discogs_rate_limit_max_utilization_ratio Highest share of the Discogs rate limit used by a single user.
+# TYPE waugzee_discogs_rate_limit_max_utilization_ratio gauge
+waugzee_discogs_rate_limit_max_utilization_ratio 0.5
+# HELP waugzee_discogs_rate_limit_requests Discogs requests in the current rate limit window, summed over users.
+# TYPE waugzee_discogs_rate_limit_requests gauge
+waugzee_discogs_rate_limit_requests 30
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"waugzee_discogs_rate_limit_max_utilization_ratio",
+		"waugzee_discogs_rate_limit_requests"))
+
+	failing := rateLimitCollector{usage: func(ctx context.Context) (RateLimitUsage, error) {
+		return RateLimitUsage{}, errors.New("valkey unavailable")
+	}}
+	assert.Error(t, testutil.CollectAndCompare(failing, strings.NewReader("")), "a failed read fails the scrape")
+}
+
+func TestObserveDumpStageIgnoresNegativeValues(t *testing.T) {
+	assert.NotPanics(t, func() {
+		ObserveDumpStage("labels", "parse", -1, -1)
+	})
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const NAMESPACE = "waugzee"
+
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// Registry holds the metrics of this replica, served on /metrics for Prometheus to scrape
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route"})
+
+	eventsPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Subsystem: "events",
+		Name:      "published_total",
+		Help:      "Events published on the event bus by channel, event and result.",
+	}, []string{"channel", "event", "result"})
+
+	eventsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Subsystem: "events",
+		Name:      "received_total",
+		Help:      "Events received from the event bus by this replica, durable retries included.",
+	}, []string{"channel", "event"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Subsystem: "scheduler",
+		Name:      "job_duration_seconds",
+		Help:      "Duration of scheduled job runs by job and result.",
+		// From a second up to the multi hour dump imports
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"job", "result"})
+
+	dumpItems = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Subsystem: "dump",
+		Name:      "pipeline_items_total",
+		Help:      "Dump entities handled by each ingestion pipeline stage.",
+	}, []string{"entity_type", "stage"})
+
+	dumpBusy = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Subsystem: "dump",
+		Name:      "pipeline_busy_seconds_total",
+		Help:      "Time the workers of each ingestion pipeline stage spent working, summed over workers.",
+	}, []string{"entity_type", "stage"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		eventsPublished,
+		eventsReceived,
+		jobDuration,
+		dumpItems,
+		dumpBusy,
+	)
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
+
+// ObserveHTTPRequest records a request, route is the matched pattern so IDs in paths do not create
+// a series per resource
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+func EventPublished(channel, event string, err error) {
+	eventsPublished.WithLabelValues(channel, event, result(err)).Inc()
+}
+
+func EventReceived(channel, event string) {
+	eventsReceived.WithLabelValues(channel, event).Inc()
+}
+
+func ObserveJob(job string, duration time.Duration, err error) {
+	jobDuration.WithLabelValues(job, result(err)).Observe(duration.Seconds())
+}
+
+// ObserveDumpStage records work of an ingestion pipeline stage, the throughput of a stage is the
+// rate of its items
+func ObserveDumpStage(entityType, stage string, items int64, busy time.Duration) {
+	// Counters panic on negative values
+	dumpItems.WithLabelValues(entityType, stage).Add(float64(max(items, 0)))
+	dumpBusy.WithLabelValues(entityType, stage).Add(max(busy, 0).Seconds())
+}
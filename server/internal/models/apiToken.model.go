@@ -0,0 +1,54 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApiTokenScope string
+
+const (
+	// ApiTokenScopeReadCollection allows reading the collection, history and catalog
+	ApiTokenScopeReadCollection ApiTokenScope = "collection:read"
+	// ApiTokenScopeWriteHistory allows logging, editing and deleting plays and cleanings
+	ApiTokenScopeWriteHistory ApiTokenScope = "history:write"
+	// ApiTokenScopeAdmin allows every request an interactive session of the user could make,
+	// except managing tokens
+	ApiTokenScopeAdmin ApiTokenScope = "admin"
+)
+
+var ApiTokenScopes = []ApiTokenScope{
+	ApiTokenScopeReadCollection,
+	ApiTokenScopeWriteHistory,
+	ApiTokenScopeAdmin,
+}
+
+// ApiToken is a personal access token a user creates for scripts and integrations. Only a hash of
+// the secret is stored, the secret itself is shown once when the token is created.
+type ApiToken struct {
+	BaseUUIDModel
+	UserID     uuid.UUID       `gorm:"type:uuid;not null;index:idx_api_tokens_user" json:"userId"`
+	Name       string          `gorm:"type:text;not null"                           json:"name"`
+	Prefix     string          `gorm:"type:text;not null"                           json:"prefix"`
+	TokenHash  string          `gorm:"type:text;not null;uniqueIndex"               json:"-"`
+	Scopes     []ApiTokenScope `gorm:"type:jsonb;serializer:json;not null"          json:"scopes"`
+	ExpiresAt  *time.Time      `gorm:"type:timestamptz"                             json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time      `gorm:"type:timestamptz"                             json:"lastUsedAt,omitempty"`
+
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (t *ApiToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// HasScope reports whether the token grants the scope, the admin scope grants every scope
+func (t *ApiToken) HasScope(scope ApiTokenScope) bool {
+	return slices.Contains(t.Scopes, scope) || slices.Contains(t.Scopes, ApiTokenScopeAdmin)
+}
+
+func IsValidApiTokenScope(scope ApiTokenScope) bool {
+	return slices.Contains(ApiTokenScopes, scope)
+}
@@ -2,6 +2,7 @@ package models
 
 type Artist struct {
 	BaseDiscogModel
+	DumpTracking
 	Name        string `gorm:"type:text" json:"name"`
 	Profile     string `gorm:"type:text" json:"profile"`
 	Uri         string `gorm:"type:text" json:"uri"`
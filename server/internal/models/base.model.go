@@ -26,3 +26,23 @@ type BaseModel struct {
 	UpdatedAt time.Time      `gorm:"autoUpdateTime"                       json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `                                            json:"deletedAt"`
 }
+
+// DumpTracking holds change-detection state for catalog entities loaded from the monthly Discogs dump
+type DumpTracking struct {
+	ContentHash  *string    `gorm:"type:text"              json:"-"`
+	TombstonedAt *time.Time `gorm:"type:timestamptz;index" json:"tombstonedAt,omitempty"`
+}
+
+// DumpEntity is implemented by catalog models that take part in dump change detection
+type DumpEntity interface {
+	GetID() int64
+	SetContentHash(hash string)
+}
+
+func (b *BaseDiscogModel) GetID() int64 {
+	return b.ID
+}
+
+func (d *DumpTracking) SetContentHash(hash string) {
+	d.ContentHash = &hash
+}
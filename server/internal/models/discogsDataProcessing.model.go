@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -15,6 +17,66 @@ const (
 	ProcessingStatusFailed             ProcessingStatus = "failed"
 )
 
+var (
+	ErrInvalidStatusTransition = errors.New("invalid processing status transition")
+	// ErrProcessingStatusConflict means the stored record changed status since it was loaded,
+	// e.g. the watchdog failed it while a job was still holding a copy
+	ErrProcessingStatusConflict = errors.New("processing record status changed concurrently")
+)
+
+// StatusTransitionError reports a transition the state machine does not allow
+type StatusTransitionError struct {
+	From ProcessingStatus
+	To   ProcessingStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	from := e.From
+	if from == "" {
+		from = "new"
+	}
+	return fmt.Sprintf("%s: %s -> %s", ErrInvalidStatusTransition, from, e.To)
+}
+
+func (e *StatusTransitionError) Unwrap() error {
+	return ErrInvalidStatusTransition
+}
+
+// processingTransitions lists the legal next statuses of each status. The empty status is a record
+// that has not been created yet.
+var processingTransitions = map[ProcessingStatus][]ProcessingStatus{
+	"": {ProcessingStatusNotStarted, ProcessingStatusReadyForProcessing},
+	ProcessingStatusNotStarted: {
+		ProcessingStatusDownloading,
+		ProcessingStatusReadyForProcessing,
+	},
+	ProcessingStatusDownloading: {
+		ProcessingStatusReadyForProcessing,
+		ProcessingStatusNotStarted, // dump not published yet, try again tomorrow
+		ProcessingStatusFailed,
+	},
+	ProcessingStatusReadyForProcessing: {
+		ProcessingStatusProcessing,
+	},
+	ProcessingStatusProcessing: {
+		ProcessingStatusCompleted,
+		ProcessingStatusFailed,
+		ProcessingStatusReadyForProcessing, // admin reprocess
+		ProcessingStatusNotStarted,         // admin reset
+	},
+	ProcessingStatusCompleted: {
+		ProcessingStatusReadyForProcessing,
+	},
+	ProcessingStatusFailed: {
+		ProcessingStatusDownloading,
+		ProcessingStatusReadyForProcessing,
+		ProcessingStatusNotStarted,
+	},
+}
+
+// ActiveProcessingStatuses are the statuses a job is working in and must keep a heartbeat for
+var ActiveProcessingStatuses = []ProcessingStatus{ProcessingStatusDownloading, ProcessingStatusProcessing}
+
 // FileDownloadStatus represents the current status of a file download
 type FileDownloadStatus string
 
@@ -52,12 +114,17 @@ type DiscogsDataProcessing struct {
 	StartedAt             *time.Time `json:"started_at,omitempty"`
 	DownloadCompletedAt   *time.Time `json:"download_completed_at,omitempty"`
 	ProcessingCompletedAt *time.Time `json:"processing_completed_at,omitempty"`
+	// HeartbeatAt is refreshed by the job working on the record, see ActiveProcessingStatuses
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
 
 	// File checksums from CHECKSUM.txt
 	FileChecksums *FileChecksums `gorm:"serializer:json" json:"file_checksums,omitempty"`
 
 	// Processing statistics and file information
 	ProcessingStats *ProcessingStats `gorm:"serializer:json" json:"processing_stats,omitempty"`
+
+	// Transitions made since the record was last saved, written to the audit table on save
+	pendingTransitions []DiscogsDataProcessingTransition `gorm:"-"`
 }
 
 // FileChecksums represents the checksums for each Discogs data dump file
@@ -87,13 +154,62 @@ const (
 	StepReleaseArtistAssociations     ProcessingStep = "release_artist_associations"
 )
 
+// ProcessingSteps lists every processing step in the order the XML parser runs them
+var ProcessingSteps = []ProcessingStep{
+	StepLabelsProcessing,
+	StepArtistsProcessing,
+	StepMastersProcessing,
+	StepReleasesProcessing,
+	StepMasterGenresCollection,
+	StepMasterGenresUpsert,
+	StepMasterGenreAssociations,
+	StepReleaseGenresCollection,
+	StepReleaseGenresUpsert,
+	StepReleaseGenreAssociations,
+	StepReleaseLabelAssociations,
+	StepMasterArtistAssociations,
+	StepReleaseArtistAssociations,
+}
+
 // StepStatus represents the completion status of a processing step
 type StepStatus struct {
-	Completed     bool       `json:"completed"`
-	CompletedAt   *time.Time `json:"completed_at,omitempty"`
-	ErrorMessage  *string    `json:"error_message,omitempty"`
-	RecordsCount  *int64     `json:"records_count,omitempty"`
-	Duration      *string    `json:"duration,omitempty"`
+	Completed     bool            `json:"completed"`
+	CompletedAt   *time.Time      `json:"completed_at,omitempty"`
+	ErrorMessage  *string         `json:"error_message,omitempty"`
+	RecordsCount  *int64          `json:"records_count,omitempty"`
+	FailedRecords *int64          `json:"failed_records,omitempty"`
+	Duration      *string         `json:"duration,omitempty"`
+	Changes       *ChangeCounts   `json:"changes,omitempty"`
+	Checkpoint    *StepCheckpoint `json:"checkpoint,omitempty"`
+
+	Throughput map[string]*StageThroughput `json:"throughput,omitempty"`
+}
+
+// ChangeCounts records how a processing step changed the stored entities compared to the previous dump
+type ChangeCounts struct {
+	Inserted  int64 `json:"inserted"`
+	Updated   int64 `json:"updated"`
+	Unchanged int64 `json:"unchanged"`
+	Removed   int64 `json:"removed"`
+}
+
+// StepCheckpoint records how far an interrupted processing step got so it can resume
+type StepCheckpoint struct {
+	ElementsCommitted int64         `json:"elements_committed"`
+	FileSize          int64         `json:"file_size"`
+	Changes           *ChangeCounts `json:"changes,omitempty"`
+	Failed            int64         `json:"failed,omitempty"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+// StageThroughput records how one stage of the ingestion pipeline performed during a step.
+// Utilization is busy time over available worker time; the stage closest to 1 is the bottleneck.
+type StageThroughput struct {
+	Workers        int     `json:"workers"`
+	Items          int64   `json:"items"`
+	BusySeconds    float64 `json:"busy_seconds"`
+	ItemsPerSecond float64 `json:"items_per_second"`
+	Utilization    float64 `json:"utilization"`
 }
 
 // ProcessingStats tracks detailed information about file processing
@@ -105,15 +221,69 @@ type ProcessingStats struct {
 
 	// Individual processing steps tracking
 	ProcessingSteps map[ProcessingStep]*StepStatus `json:"processing_steps,omitempty"`
+
+	// Entity counts in the database once processing completed, keyed by entity type
+	EntityCounts map[string]int64 `json:"entity_counts,omitempty"`
+}
+
+// CanTransitionTo reports whether the state machine allows moving to newStatus
+func (d *DiscogsDataProcessing) CanTransitionTo(newStatus ProcessingStatus) bool {
+	for _, allowed := range processingTransitions[d.Status] {
+		if allowed == newStatus {
+			return true
+		}
+	}
+	return false
 }
 
-// UpdateStatus updates the processing status with validation
-func (d *DiscogsDataProcessing) UpdateStatus(newStatus ProcessingStatus) error {
-	// Add status transition validation if needed
+// UpdateStatus moves the record to newStatus, returning a StatusTransitionError for illegal
+// transitions. Staying in the current status is a no-op. The transition is recorded in the audit
+// table with reason when the record is next saved.
+func (d *DiscogsDataProcessing) UpdateStatus(newStatus ProcessingStatus, reason string) error {
+	if d.Status == newStatus {
+		return nil
+	}
+	if !d.CanTransitionTo(newStatus) {
+		return &StatusTransitionError{From: d.Status, To: newStatus}
+	}
+
+	transition := DiscogsDataProcessingTransition{
+		YearMonth:  d.YearMonth,
+		FromStatus: d.Status,
+		ToStatus:   newStatus,
+	}
+	if reason != "" {
+		transition.Reason = &reason
+	}
+	d.pendingTransitions = append(d.pendingTransitions, transition)
+
 	d.Status = newStatus
+	if newStatus == ProcessingStatusDownloading || newStatus == ProcessingStatusProcessing {
+		now := time.Now().UTC()
+		d.HeartbeatAt = &now
+	}
 	return nil
 }
 
+// PendingTransitions returns the transitions made since the record was last saved
+func (d *DiscogsDataProcessing) PendingTransitions() []DiscogsDataProcessingTransition {
+	return d.pendingTransitions
+}
+
+// PersistedStatus returns the status the stored record is expected to have, the status before any
+// pending transition
+func (d *DiscogsDataProcessing) PersistedStatus() ProcessingStatus {
+	if len(d.pendingTransitions) > 0 {
+		return d.pendingTransitions[0].FromStatus
+	}
+	return d.Status
+}
+
+// ClearPendingTransitions is called once the pending transitions have been saved
+func (d *DiscogsDataProcessing) ClearPendingTransitions() {
+	d.pendingTransitions = nil
+}
+
 // IsReadyForProcessing returns true if files are downloaded and validated
 func (d *DiscogsDataProcessing) IsReadyForProcessing() bool {
 	return d.Status == ProcessingStatusReadyForProcessing &&
@@ -254,13 +424,62 @@ func (d *DiscogsDataProcessing) MarkStepCompleted(step ProcessingStep, recordsCo
 	}
 }
 
-// MarkStepFailed marks a processing step as failed with error message
+// stepStatus returns the status of a step, adding an empty one if the step has none yet
+func (d *DiscogsDataProcessing) stepStatus(step ProcessingStep) *StepStatus {
+	d.InitializeProcessingSteps()
+
+	stepStatus, exists := d.ProcessingStats.ProcessingSteps[step]
+	if !exists {
+		stepStatus = &StepStatus{}
+		d.ProcessingStats.ProcessingSteps[step] = stepStatus
+	}
+	return stepStatus
+}
+
+// SetStepChanges attaches change detection counts to a processing step
+func (d *DiscogsDataProcessing) SetStepChanges(step ProcessingStep, changes *ChangeCounts) {
+	d.stepStatus(step).Changes = changes
+}
+
+// SetStepFailures records how many entities of a processing step were dead-lettered
+func (d *DiscogsDataProcessing) SetStepFailures(step ProcessingStep, failed int64) {
+	d.stepStatus(step).FailedRecords = &failed
+}
+
+// SetStepThroughput attaches per-stage pipeline throughput to a processing step
+func (d *DiscogsDataProcessing) SetStepThroughput(step ProcessingStep, throughput map[string]*StageThroughput) {
+	d.stepStatus(step).Throughput = throughput
+}
+
+// SetStepCheckpoint records the latest committed position of an in-progress step
+func (d *DiscogsDataProcessing) SetStepCheckpoint(step ProcessingStep, checkpoint *StepCheckpoint) {
+	d.stepStatus(step).Checkpoint = checkpoint
+}
+
+// GetStepCheckpoint returns the checkpoint of an unfinished step, if any
+func (d *DiscogsDataProcessing) GetStepCheckpoint(step ProcessingStep) *StepCheckpoint {
+	stepStatus := d.GetStepStatus(step)
+	if stepStatus == nil || stepStatus.Completed {
+		return nil
+	}
+
+	return stepStatus.Checkpoint
+}
+
+// MarkStepFailed marks a processing step as failed with error message.
+// Any checkpoint is kept so the next run can resume where the failed run stopped.
 func (d *DiscogsDataProcessing) MarkStepFailed(step ProcessingStep, errorMessage string) {
 	d.InitializeProcessingSteps()
 
+	var checkpoint *StepCheckpoint
+	if existing, exists := d.ProcessingStats.ProcessingSteps[step]; exists {
+		checkpoint = existing.Checkpoint
+	}
+
 	d.ProcessingStats.ProcessingSteps[step] = &StepStatus{
 		Completed:    false,
 		ErrorMessage: &errorMessage,
+		Checkpoint:   checkpoint,
 	}
 }
 
@@ -291,23 +510,7 @@ func (d *DiscogsDataProcessing) GetStepStatus(step ProcessingStep) *StepStatus {
 
 // AllStepsCompleted returns true if all processing steps have been completed
 func (d *DiscogsDataProcessing) AllStepsCompleted() bool {
-	allSteps := []ProcessingStep{
-		StepLabelsProcessing,
-		StepArtistsProcessing,
-		StepMastersProcessing,
-		StepReleasesProcessing,
-		StepMasterGenresCollection,
-		StepMasterGenresUpsert,
-		StepMasterGenreAssociations,
-		StepReleaseGenresCollection,
-		StepReleaseGenresUpsert,
-		StepReleaseGenreAssociations,
-		StepReleaseLabelAssociations,
-		StepMasterArtistAssociations,
-		StepReleaseArtistAssociations,
-	}
-
-	for _, step := range allSteps {
+	for _, step := range ProcessingSteps {
 		if !d.IsStepCompleted(step) {
 			return false
 		}
@@ -0,0 +1,12 @@
+package models
+
+// DiscogsDataProcessingTransition is the audit trail of DiscogsDataProcessing status changes.
+// FromStatus is empty for the status a record was created with.
+type DiscogsDataProcessingTransition struct {
+	BaseUUIDModel
+	ProcessingID int64            `gorm:"type:bigint;not null;index" json:"processingId"`
+	YearMonth    string           `gorm:"type:varchar(7);not null"   json:"yearMonth"`
+	FromStatus   ProcessingStatus `gorm:"type:text"                  json:"fromStatus"`
+	ToStatus     ProcessingStatus `gorm:"type:text;not null"         json:"toStatus"`
+	Reason       *string          `gorm:"type:text"                  json:"reason,omitempty"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscogsDataProcessing_UpdateStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    ProcessingStatus
+		to      ProcessingStatus
+		allowed bool
+	}{
+		{name: "new record starts not started", from: "", to: ProcessingStatusNotStarted, allowed: true},
+		{name: "new record cannot start completed", from: "", to: ProcessingStatusCompleted, allowed: false},
+		{name: "download starts", from: ProcessingStatusNotStarted, to: ProcessingStatusDownloading, allowed: true},
+		{name: "download finishes", from: ProcessingStatusDownloading, to: ProcessingStatusReadyForProcessing, allowed: true},
+		{name: "download fails", from: ProcessingStatusDownloading, to: ProcessingStatusFailed, allowed: true},
+		{name: "processing starts", from: ProcessingStatusReadyForProcessing, to: ProcessingStatusProcessing, allowed: true},
+		{name: "processing completes", from: ProcessingStatusProcessing, to: ProcessingStatusCompleted, allowed: true},
+		{name: "failed retries download", from: ProcessingStatusFailed, to: ProcessingStatusDownloading, allowed: true},
+		{name: "completed reprocesses", from: ProcessingStatusCompleted, to: ProcessingStatusReadyForProcessing, allowed: true},
+		{name: "not started cannot complete", from: ProcessingStatusNotStarted, to: ProcessingStatusCompleted, allowed: false},
+		{name: "completed cannot download", from: ProcessingStatusCompleted, to: ProcessingStatusDownloading, allowed: false},
+		{name: "ready cannot go back to downloading", from: ProcessingStatusReadyForProcessing, to: ProcessingStatusDownloading, allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &DiscogsDataProcessing{YearMonth: "2025-01", Status: tt.from}
+
+			err := record.UpdateStatus(tt.to, "test")
+			if !tt.allowed {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, ErrInvalidStatusTransition))
+				var transitionErr *StatusTransitionError
+				require.True(t, errors.As(err, &transitionErr))
+				assert.Equal(t, tt.from, transitionErr.From)
+				assert.Equal(t, tt.to, transitionErr.To)
+				assert.Equal(t, tt.from, record.Status, "a rejected transition leaves the status alone")
+				assert.Empty(t, record.PendingTransitions())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, record.Status)
+			require.Len(t, record.PendingTransitions(), 1)
+			assert.Equal(t, tt.from, record.PendingTransitions()[0].FromStatus)
+			assert.Equal(t, tt.to, record.PendingTransitions()[0].ToStatus)
+		})
+	}
+}
+
+func TestDiscogsDataProcessing_PendingTransitions(t *testing.T) {
+	record := &DiscogsDataProcessing{YearMonth: "2025-01", Status: ProcessingStatusNotStarted}
+
+	require.NoError(t, record.UpdateStatus(ProcessingStatusNotStarted, "same status"))
+	assert.Empty(t, record.PendingTransitions(), "staying in the same status is not a transition")
+
+	require.NoError(t, record.UpdateStatus(ProcessingStatusDownloading, "download started"))
+	assert.NotNil(t, record.HeartbeatAt, "entering an active status starts the heartbeat")
+	require.NoError(t, record.UpdateStatus(ProcessingStatusFailed, "connection reset"))
+
+	assert.Equal(t, ProcessingStatusNotStarted, record.PersistedStatus())
+	require.Len(t, record.PendingTransitions(), 2)
+	assert.Equal(t, "connection reset", *record.PendingTransitions()[1].Reason)
+
+	record.ClearPendingTransitions()
+	assert.Equal(t, ProcessingStatusFailed, record.PersistedStatus())
+}
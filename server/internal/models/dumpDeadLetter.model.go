@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/datatypes"
+
+// DumpDeadLetter holds a dump entity that could not be written during processing, with the error that rejected it
+type DumpDeadLetter struct {
+	BaseUUIDModel
+	YearMonth  string         `gorm:"type:varchar(7);not null;index:idx_dump_dead_letters_step,composite:0" json:"yearMonth"`
+	Step       ProcessingStep `gorm:"type:text;not null;index:idx_dump_dead_letters_step,composite:1"       json:"step"`
+	EntityType string         `gorm:"type:text;not null"                                                     json:"entityType"`
+	EntityID   *int64         `gorm:"type:bigint;index"                                                      json:"entityId,omitempty"`
+	Payload    datatypes.JSON `gorm:"type:jsonb"                                                             json:"payload"`
+	Error      string         `gorm:"type:text;not null"                                                     json:"error"`
+}
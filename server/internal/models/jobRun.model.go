@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+type JobRunStatus string
+
+const (
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+)
+
+// JobRunTrigger records whether a run came from the schedule or was started by an admin
+type JobRunTrigger string
+
+const (
+	JobRunTriggerScheduled JobRunTrigger = "scheduled"
+	JobRunTriggerManual    JobRunTrigger = "manual"
+)
+
+// JobRun is the history of a single scheduler job execution
+type JobRun struct {
+	BaseUUIDModel
+	JobName    string        `gorm:"type:text;not null;index:idx_job_runs_job_started,composite:0" json:"jobName"`
+	Trigger    JobRunTrigger `gorm:"type:text;not null"                                             json:"trigger"`
+	Status     JobRunStatus  `gorm:"type:text;not null"                                             json:"status"`
+	StartedAt  time.Time     `gorm:"not null;index:idx_job_runs_job_started,composite:1"            json:"startedAt"`
+	FinishedAt *time.Time    `                                                                      json:"finishedAt,omitempty"`
+	DurationMs *int64        `gorm:"type:bigint"                                                    json:"durationMs,omitempty"`
+	Error      *string       `gorm:"type:text"                                                      json:"error,omitempty"`
+}
+
+// Finish records the outcome of the run
+func (r *JobRun) Finish(err error) {
+	finishedAt := time.Now().UTC()
+	duration := finishedAt.Sub(r.StartedAt).Milliseconds()
+
+	r.FinishedAt = &finishedAt
+	r.DurationMs = &duration
+	r.Status = JobRunStatusSucceeded
+	if err != nil {
+		message := err.Error()
+		r.Error = &message
+		r.Status = JobRunStatusFailed
+	}
+}
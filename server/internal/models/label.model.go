@@ -2,6 +2,7 @@ package models
 
 type Label struct {
 	BaseDiscogModel
+	DumpTracking
 	Profile     *string   `gorm:"type:text"                                json:"profile,omitempty"`
 	ResourceURL string    `gorm:"type:text"                                json:"resourceUrl,omitempty"`
 	URI         string    `gorm:"type:text"                                json:"uri,omitempty"`
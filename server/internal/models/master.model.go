@@ -8,6 +8,7 @@ import (
 
 type Master struct {
 	BaseDiscogModel
+	DumpTracking
 	Title                        string     `gorm:"type:text"        json:"title"`
 	LastSynced                   *time.Time `gorm:"type:timestamptz" json:"lastSynced,omitempty"`
 	MainReleaseID                *int64     `gorm:"type:bigint"      json:"mainRelease,omitempty"`
@@ -31,6 +31,7 @@ type FormatDetails struct {
 
 type Release struct {
 	BaseDiscogModel
+	DumpTracking
 	Title             string         `gorm:"type:text;"                                                             json:"title"`
 	LabelID           *int64         `gorm:"type:bigint;"                                                           json:"labelId,omitempty"`
 	MasterID          *int64         `gorm:"type:bigint"                                                            json:"masterId,omitempty"`
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	// RoleViewer can read their collection, history and the catalog
+	RoleViewer Role = "viewer"
+	// RoleMember is every signed in user: a viewer who manages their own collection and history
+	RoleMember Role = "member"
+	// RoleCatalogMaintainer is a member who runs the Discogs dump pipeline
+	RoleCatalogMaintainer Role = "catalog-maintainer"
+	// RoleAdmin has every permission
+	RoleAdmin Role = "admin"
+)
+
+var Roles = []Role{RoleViewer, RoleMember, RoleCatalogMaintainer, RoleAdmin}
+
+type Permission string
+
+const (
+	PermissionCollectionRead  Permission = "collection:read"
+	PermissionCollectionWrite Permission = "collection:write"
+	PermissionHistoryWrite    Permission = "history:write"
+	PermissionCatalogRead     Permission = "catalog:read"
+	// PermissionDumpsManage covers downloading, importing, reprocessing and cleaning up dumps
+	PermissionDumpsManage Permission = "dumps:manage"
+	// PermissionSystemManage covers scheduled jobs and one-off data imports
+	PermissionSystemManage Permission = "system:manage"
+	PermissionUsersManage  Permission = "users:manage"
+)
+
+// RolePermissions lists what each role grants, every role includes the permissions of the roles
+// before it
+var RolePermissions = map[Role][]Permission{
+	RoleViewer: {
+		PermissionCollectionRead,
+		PermissionCatalogRead,
+	},
+	RoleMember: {
+		PermissionCollectionRead,
+		PermissionCatalogRead,
+		PermissionCollectionWrite,
+		PermissionHistoryWrite,
+	},
+	RoleCatalogMaintainer: {
+		PermissionCollectionRead,
+		PermissionCatalogRead,
+		PermissionCollectionWrite,
+		PermissionHistoryWrite,
+		PermissionDumpsManage,
+	},
+	RoleAdmin: {
+		PermissionCollectionRead,
+		PermissionCatalogRead,
+		PermissionCollectionWrite,
+		PermissionHistoryWrite,
+		PermissionDumpsManage,
+		PermissionSystemManage,
+		PermissionUsersManage,
+	},
+}
+
+// UserRole is a role assigned to a user inside the app, on top of the roles their identity
+// provider grants
+type UserRole struct {
+	BaseUUIDModel
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_user_roles_user_role,composite:0" json:"userId"`
+	Role       Role       `gorm:"type:text;not null;uniqueIndex:idx_user_roles_user_role,composite:1"  json:"role"`
+	AssignedBy *uuid.UUID `gorm:"type:uuid"                                                            json:"assignedBy,omitempty"`
+}
+
+func IsValidRole(role Role) bool {
+	return slices.Contains(Roles, role)
+}
+
+// Authorization is what a signed in user may do, resolved from all of their roles
+type Authorization struct {
+	Roles       []Role
+	Permissions map[Permission]bool
+}
+
+// NewAuthorization grants the permissions of every valid role, ignoring duplicates
+func NewAuthorization(roles []Role) *Authorization {
+	authorization := &Authorization{
+		Roles:       make([]Role, 0, len(roles)),
+		Permissions: make(map[Permission]bool),
+	}
+
+	for _, role := range roles {
+		if !IsValidRole(role) || slices.Contains(authorization.Roles, role) {
+			continue
+		}
+		authorization.Roles = append(authorization.Roles, role)
+		for _, permission := range RolePermissions[role] {
+			authorization.Permissions[permission] = true
+		}
+	}
+
+	return authorization
+}
+
+func (a *Authorization) Can(permission Permission) bool {
+	return a != nil && a.Permissions[permission]
+}
+
+func (a *Authorization) HasRole(role Role) bool {
+	return a != nil && slices.Contains(a.Roles, role)
+}
+
+// PermissionList returns the granted permissions in a stable order
+func (a *Authorization) PermissionList() []Permission {
+	permissions := make([]Permission, 0, len(a.Permissions))
+	for permission := range a.Permissions {
+		permissions = append(permissions, permission)
+	}
+	slices.Sort(permissions)
+	return permissions
+}
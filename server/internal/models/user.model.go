@@ -22,6 +22,7 @@ type User struct {
 	OIDCProvider    *string            `gorm:"column:oidc_provider;type:text"            json:"-"`
 	OIDCProjectID   *string            `gorm:"column:oidc_project_id;type:text"          json:"-"`
 	Configuration   *UserConfiguration `gorm:"foreignKey:UserID"                         json:"configuration,omitempty"`
+	Roles           []UserRole         `gorm:"foreignKey:UserID"                         json:"roles,omitempty"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// WebhookEvent is an event a webhook can subscribe to, named as it is sent to endpoints
+type WebhookEvent string
+
+const (
+	WebhookEventPlayLogged            WebhookEvent = "play.logged"
+	WebhookEventCleaningLogged        WebhookEvent = "cleaning.logged"
+	WebhookEventSyncCompleted         WebhookEvent = "sync.completed"
+	WebhookEventStylusReplacementDue  WebhookEvent = "stylus.replacement_due"
+	WebhookEventRecommendationCreated WebhookEvent = "recommendation.created"
+)
+
+var WebhookEvents = []WebhookEvent{
+	WebhookEventPlayLogged,
+	WebhookEventCleaningLogged,
+	WebhookEventSyncCompleted,
+	WebhookEventStylusReplacementDue,
+	WebhookEventRecommendationCreated,
+}
+
+func IsValidWebhookEvent(event WebhookEvent) bool {
+	return slices.Contains(WebhookEvents, event)
+}
+
+// Webhook is an HTTPS endpoint a user registered to be sent their events. The secret signs every
+// delivery, so unlike API tokens it is kept in clear. It is shown once when the webhook is created.
+type Webhook struct {
+	BaseUUIDModel
+	UserID      uuid.UUID      `gorm:"type:uuid;not null;index:idx_webhooks_user" json:"userId"`
+	URL         string         `gorm:"type:text;not null"                         json:"url"`
+	Description string         `gorm:"type:text"                                  json:"description"`
+	Events      []WebhookEvent `gorm:"type:jsonb;serializer:json;not null"        json:"events"`
+	Secret      string         `gorm:"type:text;not null"                         json:"-"`
+	IsActive    bool           `gorm:"type:bool;default:true;not null"            json:"isActive"`
+
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+	return w.IsActive && slices.Contains(w.Events, event)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending deliveries are waiting for their first attempt or a retry
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed deliveries ran out of attempts, they can still be replayed
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to a webhook, with the outcome of its latest attempt. A
+// replay is a new delivery of the same body pointing at the delivery it replays.
+type WebhookDelivery struct {
+	BaseUUIDModel
+	WebhookID uuid.UUID    `gorm:"type:uuid;not null;index:idx_webhook_deliveries_webhook;uniqueIndex:idx_webhook_deliveries_event,where:replay_of IS NULL" json:"webhookId"`
+	UserID    uuid.UUID    `gorm:"type:uuid;not null"                                                                                                      json:"userId"`
+	Event     WebhookEvent `gorm:"type:text;not null"                                                                                                      json:"event"`
+	// EventID is the ID of the event bus message, a message is delivered once per webhook
+	EventID       string                `gorm:"type:text;not null;uniqueIndex:idx_webhook_deliveries_event,where:replay_of IS NULL" json:"eventId"`
+	Payload       datatypes.JSON        `gorm:"type:jsonb;not null"                                                                 json:"payload"`
+	Status        WebhookDeliveryStatus `gorm:"type:text;not null;index:idx_webhook_deliveries_due,composite:0"                     json:"status"`
+	Attempts      int                   `gorm:"not null;default:0"                                                                  json:"attempts"`
+	NextAttemptAt *time.Time            `gorm:"type:timestamptz;index:idx_webhook_deliveries_due,composite:1"                       json:"nextAttemptAt,omitempty"`
+	LastAttemptAt *time.Time            `gorm:"type:timestamptz"                                                                    json:"lastAttemptAt,omitempty"`
+	ResponseCode  *int                  `gorm:"type:integer"                                                                        json:"responseCode,omitempty"`
+	ResponseBody  *string               `gorm:"type:text"                                                                           json:"responseBody,omitempty"`
+	Error         *string               `gorm:"type:text"                                                                           json:"error,omitempty"`
+	DurationMs    *int64                `gorm:"type:bigint"                                                                         json:"durationMs,omitempty"`
+	ReplayOf      *uuid.UUID            `gorm:"type:uuid"                                                                           json:"replayOf,omitempty"`
+
+	Webhook *Webhook `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ApiTokenRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, token *ApiToken) error
+	GetByHash(ctx context.Context, tx *gorm.DB, tokenHash string) (*ApiToken, error)
+	GetByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]*ApiToken, error)
+	CountByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (int64, error)
+	Delete(ctx context.Context, tx *gorm.DB, userID uuid.UUID, tokenID uuid.UUID) (bool, error)
+	TouchLastUsed(ctx context.Context, tx *gorm.DB, tokenID uuid.UUID, usedAt time.Time) error
+}
+
+type apiTokenRepository struct{}
+
+func NewApiTokenRepository() ApiTokenRepository {
+	return &apiTokenRepository{}
+}
+
+func (r *apiTokenRepository) Create(ctx context.Context, tx *gorm.DB, token *ApiToken) error {
+	log := logger.New("apiTokenRepository").TraceFromContext(ctx).Function("Create")
+
+	if err := tx.WithContext(ctx).Create(token).Error; err != nil {
+		return log.Err("failed to create api token", err, "userID", token.UserID)
+	}
+
+	return nil
+}
+
+// GetByHash returns the token with the hash and its user, or nil when no such token exists
+func (r *apiTokenRepository) GetByHash(ctx context.Context, tx *gorm.DB, tokenHash string) (*ApiToken, error) {
+	log := logger.New("apiTokenRepository").TraceFromContext(ctx).Function("GetByHash")
+
+	var token ApiToken
+	if err := tx.WithContext(ctx).
+		Preload("User.Configuration").
+		Preload("User.Roles").
+		First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, log.Err("failed to get api token", err)
+	}
+
+	return &token, nil
+}
+
+func (r *apiTokenRepository) GetByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]*ApiToken, error) {
+	log := logger.New("apiTokenRepository").TraceFromContext(ctx).Function("GetByUser")
+
+	tokens, err := gorm.G[*ApiToken](tx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get api tokens", err, "userID", userID)
+	}
+
+	return tokens, nil
+}
+
+func (r *apiTokenRepository) CountByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (int64, error) {
+	log := logger.New("apiTokenRepository").TraceFromContext(ctx).Function("CountByUser")
+
+	var count int64
+	if err := tx.WithContext(ctx).Model(&ApiToken{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, log.Err("failed to count api tokens", err, "userID", userID)
+	}
+
+	return count, nil
+}
+
+// Delete revokes a token owned by the user and reports whether it existed
+func (r *apiTokenRepository) Delete(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	tokenID uuid.UUID,
+) (bool, error) {
+	log := logger.New("apiTokenRepository").TraceFromContext(ctx).Function("Delete")
+
+	result := tx.WithContext(ctx).Where("id = ? AND user_id = ?", tokenID, userID).Delete(&ApiToken{})
+	if result.Error != nil {
+		return false, log.Err("failed to delete api token", result.Error, "userID", userID, "tokenID", tokenID)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *apiTokenRepository) TouchLastUsed(
+	ctx context.Context,
+	tx *gorm.DB,
+	tokenID uuid.UUID,
+	usedAt time.Time,
+) error {
+	log := logger.New("apiTokenRepository").TraceFromContext(ctx).Function("TouchLastUsed")
+
+	if err := tx.WithContext(ctx).
+		Model(&ApiToken{}).
+		Where("id = ?", tokenID).
+		UpdateColumn("last_used_at", usedAt).Error; err != nil {
+		return log.Err("failed to update api token last used", err, "tokenID", tokenID)
+	}
+
+	return nil
+}
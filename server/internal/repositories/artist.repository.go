@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"strconv"
+	"time"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
 
@@ -17,6 +18,8 @@ type ArtistRepository interface {
 	Update(ctx context.Context, tx *gorm.DB, artist *Artist) error
 	Delete(ctx context.Context, tx *gorm.DB, id string) error
 	UpsertBatch(ctx context.Context, tx *gorm.DB, artists []*Artist) error
+	InsertMissingBatch(ctx context.Context, tx *gorm.DB, artists []*Artist) error
+	CopyMergeBatch(ctx context.Context, tx *gorm.DB, artists []*Artist) error
 	GetBatchByDiscogsIDs(
 		ctx context.Context,
 		tx *gorm.DB,
@@ -121,7 +124,7 @@ func (r *artistRepository) UpsertBatch(ctx context.Context, tx *gorm.DB, artists
 
 	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"name", "profile", "updated_at", "resource_url", "uri", "releases_url"}),
+		DoUpdates: clause.AssignmentColumns(artistMerge.updateColumns()),
 	}).Create(&artists).Error; err != nil {
 		return log.Err("failed to upsert artist batch", err, "count", len(artists))
 	}
@@ -130,6 +133,53 @@ func (r *artistRepository) UpsertBatch(ctx context.Context, tx *gorm.DB, artists
 	return nil
 }
 
+// InsertMissingBatch inserts artists that do not exist yet and leaves existing rows untouched, so
+// partial data never overwrites what the dump or a full API sync stored
+func (r *artistRepository) InsertMissingBatch(ctx context.Context, tx *gorm.DB, artists []*Artist) error {
+	log := logger.New("artistRepository").TraceFromContext(ctx).Function("InsertMissingBatch")
+
+	if len(artists) == 0 {
+		return nil
+	}
+
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoNothing: true,
+	}).Create(&artists).Error; err != nil {
+		return log.Err("failed to insert missing artist batch", err, "count", len(artists))
+	}
+
+	return nil
+}
+
+var artistMerge = stagedMerge{
+	table: "artists",
+	columns: []string{
+		"id", "name", "profile", "resource_url", "uri", "releases_url",
+		"content_hash", "tombstoned_at", "created_at", "updated_at",
+	},
+}
+
+// CopyMergeBatch bulk loads artists through a staging table, with the same update semantics as UpsertBatch
+func (r *artistRepository) CopyMergeBatch(ctx context.Context, tx *gorm.DB, artists []*Artist) error {
+	log := logger.New("artistRepository").TraceFromContext(ctx).Function("CopyMergeBatch")
+
+	now := time.Now().UTC()
+	rows := make([][]any, len(artists))
+	for i, artist := range artists {
+		rows[i] = []any{
+			artist.ID, artist.Name, artist.Profile, artist.ResourceURL, artist.Uri, artist.ReleasesURL,
+			artist.ContentHash, artist.TombstonedAt, now, now,
+		}
+	}
+
+	if err := copyMerge(ctx, tx, artistMerge, rows); err != nil {
+		return log.Err("failed to merge artist batch", err, "count", len(artists))
+	}
+
+	return nil
+}
+
 func (r *artistRepository) GetBatchByDiscogsIDs(
 	ctx context.Context,
 	tx *gorm.DB,
@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"waugzee/internal/database"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	CATALOG_CACHE_PREFIX = "catalog"
+	// Catalog rows only change when a monthly dump is processed or a release is synced
+	CATALOG_CACHE_EXPIRY = 6 * time.Hour
+
+	// notTombstoned hides entities removed from the latest dump, they stay in the database for
+	// collections that still reference them but are not served from the catalog
+	notTombstoned = "tombstoned_at IS NULL"
+)
+
+// CatalogPage selects one page of a related-entity listing. Page is 1-based.
+type CatalogPage struct {
+	Page  int
+	Limit int
+}
+
+func (p CatalogPage) offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// catalogPageResult is the cached form of a related-entity listing
+type catalogPageResult[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total"`
+}
+
+type CatalogRepository interface {
+	GetRelease(ctx context.Context, tx *gorm.DB, id int64) (*Release, error)
+	GetMaster(ctx context.Context, tx *gorm.DB, id int64) (*Master, error)
+	GetArtist(ctx context.Context, tx *gorm.DB, id int64) (*Artist, error)
+	GetLabel(ctx context.Context, tx *gorm.DB, id int64) (*Label, error)
+	ListMasterReleases(
+		ctx context.Context,
+		tx *gorm.DB,
+		masterID int64,
+		page CatalogPage,
+	) ([]*Release, int64, error)
+	ListArtistReleases(
+		ctx context.Context,
+		tx *gorm.DB,
+		artistID int64,
+		page CatalogPage,
+	) ([]*Release, int64, error)
+	ListArtistMasters(
+		ctx context.Context,
+		tx *gorm.DB,
+		artistID int64,
+		page CatalogPage,
+	) ([]*Master, int64, error)
+	ListLabelReleases(
+		ctx context.Context,
+		tx *gorm.DB,
+		labelID int64,
+		page CatalogPage,
+	) ([]*Release, int64, error)
+}
+
+type catalogRepository struct {
+	cache database.CacheClient
+}
+
+func NewCatalogRepository(cache database.CacheClient) CatalogRepository {
+	return &catalogRepository{
+		cache: cache,
+	}
+}
+
+// cachedCatalogRead serves a catalog read from cache, loading and caching it on a miss.
+// Cache failures are logged and fall through to the database.
+func cachedCatalogRead[T any](
+	ctx context.Context,
+	cache database.CacheClient,
+	key string,
+	log logger.Logger,
+	load func() (T, error),
+) (T, error) {
+	var cached T
+	found, err := database.NewCacheBuilder(cache, key).
+		WithContext(ctx).
+		WithHash(CATALOG_CACHE_PREFIX).
+		Get(&cached)
+	if err != nil {
+		log.Warn("failed to get catalog entry from cache", "key", key, "error", err)
+	}
+	if found {
+		return cached, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	err = database.NewCacheBuilder(cache, key).
+		WithContext(ctx).
+		WithHash(CATALOG_CACHE_PREFIX).
+		WithStruct(value).
+		WithTTL(CATALOG_CACHE_EXPIRY).
+		Set()
+	if err != nil {
+		log.Warn("failed to set catalog entry in cache", "key", key, "error", err)
+	}
+
+	return value, nil
+}
+
+func (r *catalogRepository) GetRelease(ctx context.Context, tx *gorm.DB, id int64) (*Release, error) {
+	log := logger.New("catalogRepository").TraceFromContext(ctx).Function("GetRelease")
+
+	return cachedCatalogRead(ctx, r.cache, fmt.Sprintf("release:%d", id), log, func() (*Release, error) {
+		release, err := gorm.G[*Release](tx).
+			Preload("Master", excludeTombstoned).
+			Preload("Artists", excludeTombstoned).
+			Preload("Labels", excludeTombstoned).
+			Preload("Genres", nil).
+			Where("id = ? AND "+notTombstoned, id).
+			First(ctx)
+		if err != nil {
+			return nil, log.Err("failed to get release", err, "releaseID", id)
+		}
+		return release, nil
+	})
+}
+
+func (r *catalogRepository) GetMaster(ctx context.Context, tx *gorm.DB, id int64) (*Master, error) {
+	log := logger.New("catalogRepository").TraceFromContext(ctx).Function("GetMaster")
+
+	return cachedCatalogRead(ctx, r.cache, fmt.Sprintf("master:%d", id), log, func() (*Master, error) {
+		master, err := gorm.G[*Master](tx).
+			Preload("Artists", excludeTombstoned).
+			Preload("Genres", nil).
+			Where("id = ? AND "+notTombstoned, id).
+			First(ctx)
+		if err != nil {
+			return nil, log.Err("failed to get master", err, "masterID", id)
+		}
+		return master, nil
+	})
+}
+
+func (r *catalogRepository) GetArtist(ctx context.Context, tx *gorm.DB, id int64) (*Artist, error) {
+	log := logger.New("catalogRepository").TraceFromContext(ctx).Function("GetArtist")
+
+	return cachedCatalogRead(ctx, r.cache, fmt.Sprintf("artist:%d", id), log, func() (*Artist, error) {
+		artist, err := gorm.G[*Artist](tx).Where("id = ? AND "+notTombstoned, id).First(ctx)
+		if err != nil {
+			return nil, log.Err("failed to get artist", err, "artistID", id)
+		}
+		return artist, nil
+	})
+}
+
+func (r *catalogRepository) GetLabel(ctx context.Context, tx *gorm.DB, id int64) (*Label, error) {
+	log := logger.New("catalogRepository").TraceFromContext(ctx).Function("GetLabel")
+
+	return cachedCatalogRead(ctx, r.cache, fmt.Sprintf("label:%d", id), log, func() (*Label, error) {
+		label, err := gorm.G[*Label](tx).Where("id = ? AND "+notTombstoned, id).First(ctx)
+		if err != nil {
+			return nil, log.Err("failed to get label", err, "labelID", id)
+		}
+		return label, nil
+	})
+}
+
+func (r *catalogRepository) ListMasterReleases(
+	ctx context.Context,
+	tx *gorm.DB,
+	masterID int64,
+	page CatalogPage,
+) ([]*Release, int64, error) {
+	log := logger.New("catalogRepository").TraceFromContext(ctx).Function("ListMasterReleases")
+
+	key := fmt.Sprintf("master:%d:releases:%d:%d", masterID, page.Page, page.Limit)
+	result, err := cachedCatalogRead(ctx, r.cache, key, log, func() (catalogPageResult[*Release], error) {
+		query := tx.WithContext(ctx).Model(&Release{}).Where("master_id = ? AND "+notTombstoned, masterID)
+		return loadCatalogPage[*Release](query, page, "year NULLS LAST, id", log, "masterID", masterID)
+	})
+	return result.Items, result.Total, err
+}
+
+func (r *catalogRepository) ListArtistReleases(
+	ctx context.Context,
+	tx *gorm.DB,
+	artistID int64,
+	page CatalogPage,
+) ([]*Release, int64, error) {
+	log := logger.New("catalogRepository").TraceFromContext(ctx).Function("ListArtistReleases")
+
+	key := fmt.Sprintf("artist:%d:releases:%d:%d", artistID, page.Page, page.Limit)
+	result, err := cachedCatalogRead(ctx, r.cache, key, log, func() (catalogPageResult[*Release], error) {
+		query := tx.WithContext(ctx).
+			Model(&Release{}).
+			Joins("JOIN release_artists ON release_artists.release_id = releases.id").
+			Where("release_artists.artist_id = ? AND releases."+notTombstoned, artistID)
+		return loadCatalogPage[*Release](query, page, "releases.year NULLS LAST, releases.id", log, "artistID", artistID)
+	})
+	return result.Items, result.Total, err
+}
+
+func (r *catalogRepository) ListArtistMasters(
+	ctx context.Context,
+	tx *gorm.DB,
+	artistID int64,
+	page CatalogPage,
+) ([]*Master, int64, error) {
+	log := logger.New("catalogRepository").TraceFromContext(ctx).Function("ListArtistMasters")
+
+	key := fmt.Sprintf("artist:%d:masters:%d:%d", artistID, page.Page, page.Limit)
+	result, err := cachedCatalogRead(ctx, r.cache, key, log, func() (catalogPageResult[*Master], error) {
+		query := tx.WithContext(ctx).
+			Model(&Master{}).
+			Joins("JOIN master_artists ON master_artists.master_id = masters.id").
+			Where("master_artists.artist_id = ? AND masters."+notTombstoned, artistID)
+		return loadCatalogPage[*Master](query, page, "masters.year NULLS LAST, masters.id", log, "artistID", artistID)
+	})
+	return result.Items, result.Total, err
+}
+
+func (r *catalogRepository) ListLabelReleases(
+	ctx context.Context,
+	tx *gorm.DB,
+	labelID int64,
+	page CatalogPage,
+) ([]*Release, int64, error) {
+	log := logger.New("catalogRepository").TraceFromContext(ctx).Function("ListLabelReleases")
+
+	key := fmt.Sprintf("label:%d:releases:%d:%d", labelID, page.Page, page.Limit)
+	result, err := cachedCatalogRead(ctx, r.cache, key, log, func() (catalogPageResult[*Release], error) {
+		query := tx.WithContext(ctx).
+			Model(&Release{}).
+			Joins("JOIN release_labels ON release_labels.release_id = releases.id").
+			Where("release_labels.label_id = ? AND releases."+notTombstoned, labelID)
+		return loadCatalogPage[*Release](query, page, "releases.year NULLS LAST, releases.id", log, "labelID", labelID)
+	})
+	return result.Items, result.Total, err
+}
+
+func excludeTombstoned(db gorm.PreloadBuilder) error {
+	db.Where(notTombstoned)
+	return nil
+}
+
+// loadCatalogPage counts and fetches one page of a related-entity query
+func loadCatalogPage[T any](
+	query *gorm.DB,
+	page CatalogPage,
+	order string,
+	log logger.Logger,
+	args ...any,
+) (catalogPageResult[T], error) {
+	result := catalogPageResult[T]{Items: []T{}}
+	query = query.Session(&gorm.Session{})
+
+	if err := query.Count(&result.Total).Error; err != nil {
+		return result, log.Err("failed to count catalog page", err, args...)
+	}
+
+	if result.Total == 0 || page.offset() >= int(result.Total) {
+		return result, nil
+	}
+
+	if err := query.Order(order).Offset(page.offset()).Limit(page.Limit).Find(&result.Items).Error; err != nil {
+		return result, log.Err("failed to get catalog page", err, args...)
+	}
+
+	return result, nil
+}
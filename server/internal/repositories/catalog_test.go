@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	. "waugzee/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valkey-io/valkey-go"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+func newCatalogTestRepository(t *testing.T) (CatalogRepository, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Artist{}, &Label{}, &Master{}, &Release{}))
+
+	cache, err := valkey.NewClient(valkey.ClientOption{
+		InitAddress:  []string{miniredis.RunT(t).Addr()},
+		DisableCache: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(cache.Close)
+
+	return NewCatalogRepository(cache), db
+}
+
+func TestCatalogGetLooksUpEntities(t *testing.T) {
+	repo, db := newCatalogTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, db.Create(&Artist{BaseDiscogModel: BaseDiscogModel{ID: 1}, Name: "Artist"}).Error)
+	require.NoError(t, db.Create(&Label{BaseDiscogModel: BaseDiscogModel{ID: 2}, Name: "Label"}).Error)
+	require.NoError(t, db.Create(&Master{BaseDiscogModel: BaseDiscogModel{ID: 3}, Title: "Master"}).Error)
+	masterID := int64(3)
+	require.NoError(t, db.Create(&Release{
+		BaseDiscogModel: BaseDiscogModel{ID: 4},
+		Title:           "Release",
+		MasterID:        &masterID,
+	}).Error)
+
+	artist, err := repo.GetArtist(ctx, db, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Artist", artist.Name)
+
+	label, err := repo.GetLabel(ctx, db, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Label", label.Name)
+
+	master, err := repo.GetMaster(ctx, db, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "Master", master.Title)
+
+	release, err := repo.GetRelease(ctx, db, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "Release", release.Title)
+	require.NotNil(t, release.Master)
+	assert.Equal(t, "Master", release.Master.Title)
+
+	_, err = repo.GetRelease(ctx, db, 5)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestCatalogReadsExcludeTombstonedEntities(t *testing.T) {
+	repo, db := newCatalogTestRepository(t)
+	ctx := context.Background()
+	removed := time.Now()
+
+	require.NoError(t, db.Create(&Artist{
+		BaseDiscogModel: BaseDiscogModel{ID: 1},
+		DumpTracking:    DumpTracking{TombstonedAt: &removed},
+	}).Error)
+	require.NoError(t, db.Create(&Label{
+		BaseDiscogModel: BaseDiscogModel{ID: 2},
+		DumpTracking:    DumpTracking{TombstonedAt: &removed},
+	}).Error)
+	require.NoError(t, db.Create(&Master{BaseDiscogModel: BaseDiscogModel{ID: 3}}).Error)
+	masterID := int64(3)
+	require.NoError(t, db.Create(&Release{
+		BaseDiscogModel: BaseDiscogModel{ID: 4},
+		MasterID:        &masterID,
+	}).Error)
+	require.NoError(t, db.Create(&Release{
+		BaseDiscogModel: BaseDiscogModel{ID: 5},
+		MasterID:        &masterID,
+		DumpTracking:    DumpTracking{TombstonedAt: &removed},
+	}).Error)
+
+	_, err := repo.GetArtist(ctx, db, 1)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	_, err = repo.GetLabel(ctx, db, 2)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	_, err = repo.GetRelease(ctx, db, 5)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	releases, total, err := repo.ListMasterReleases(ctx, db, 3, CatalogPage{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, releases, 1)
+	assert.Equal(t, int64(4), releases[0].ID)
+}
@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"fmt"
+	"time"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
 
@@ -15,6 +17,10 @@ type DiscogsDataProcessingRepository interface {
 	Delete(ctx context.Context, id int64) error
 	GetAll(ctx context.Context) ([]*DiscogsDataProcessing, error)
 	GetLatestProcessing(ctx context.Context) (*DiscogsDataProcessing, error)
+	GetNextQueued(ctx context.Context) (*DiscogsDataProcessing, error)
+	Heartbeat(ctx context.Context, id int64) error
+	GetStale(ctx context.Context, deadline time.Time) ([]*DiscogsDataProcessing, error)
+	GetTransitions(ctx context.Context, processingID int64) ([]*DiscogsDataProcessingTransition, error)
 }
 
 type discogsDataProcessingRepository struct {
@@ -33,11 +39,31 @@ func (r *discogsDataProcessingRepository) Create(
 ) (*DiscogsDataProcessing, error) {
 	log := logger.New("discogsDataProcessingRepository").TraceFromContext(ctx).Function("Create")
 
-	err := gorm.G[DiscogsDataProcessing](r.db).Create(ctx, processing)
+	// Records without an explicit status start the state machine at not_started
+	if processing.Status == "" {
+		if err := processing.UpdateStatus(ProcessingStatusNotStarted, ""); err != nil {
+			return nil, log.Err("failed to set initial status", err)
+		}
+	} else if len(processing.PendingTransitions()) == 0 {
+		status := processing.Status
+		processing.Status = ""
+		if err := processing.UpdateStatus(status, ""); err != nil {
+			processing.Status = status
+			return nil, log.Err("invalid initial status", err, "status", status)
+		}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := gorm.G[DiscogsDataProcessing](tx).Create(ctx, processing); err != nil {
+			return err
+		}
+		return r.createTransitions(ctx, tx, processing)
+	})
 	if err != nil {
 		return nil, log.Err("failed to create discogs data processing record", err)
 	}
 
+	processing.ClearPendingTransitions()
 	return processing, nil
 }
 
@@ -64,13 +90,56 @@ func (r *discogsDataProcessingRepository) Update(
 ) error {
 	log := logger.New("discogsDataProcessingRepository").TraceFromContext(ctx).Function("Update")
 
-	if err := r.db.WithContext(ctx).Save(processing).Error; err != nil {
-		return log.Err("failed to update discogs data processing record", err)
+	// Only write over the record while it still has the status it was loaded with, so a copy held
+	// by a job cannot undo a transition made elsewhere, e.g. by the watchdog
+	persistedStatus := processing.PersistedStatus()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(processing).
+			Where("status = ?", persistedStatus).
+			Select("*").
+			Omit("id", "created_at").
+			Updates(processing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: expected %s", ErrProcessingStatusConflict, persistedStatus)
+		}
+		return r.createTransitions(ctx, tx, processing)
+	})
+	if err != nil {
+		return log.Err(
+			"failed to update discogs data processing record",
+			err,
+			"yearMonth", processing.YearMonth,
+			"status", processing.Status,
+		)
 	}
 
+	processing.ClearPendingTransitions()
 	return nil
 }
 
+// createTransitions writes the record's pending transitions to the audit table
+func (r *discogsDataProcessingRepository) createTransitions(
+	ctx context.Context,
+	tx *gorm.DB,
+	processing *DiscogsDataProcessing,
+) error {
+	transitions := processing.PendingTransitions()
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	for i := range transitions {
+		transitions[i].ProcessingID = processing.ID
+		transitions[i].YearMonth = processing.YearMonth
+	}
+
+	return gorm.G[DiscogsDataProcessingTransition](tx).CreateInBatches(ctx, &transitions, len(transitions))
+}
+
 func (r *discogsDataProcessingRepository) Delete(ctx context.Context, id int64) error {
 	log := logger.New("discogsDataProcessingRepository").TraceFromContext(ctx).Function("Delete")
 
@@ -117,3 +186,75 @@ func (r *discogsDataProcessingRepository) GetLatestProcessing(
 
 	return &processing, nil
 }
+
+// GetNextQueued returns the record the XML parser should work on next: an interrupted run first,
+// then the oldest year-month waiting in ready_for_processing. Returns nil when nothing is queued.
+func (r *discogsDataProcessingRepository) GetNextQueued(
+	ctx context.Context,
+) (*DiscogsDataProcessing, error) {
+	log := logger.New("discogsDataProcessingRepository").TraceFromContext(ctx).Function("GetNextQueued")
+
+	processing, err := gorm.G[DiscogsDataProcessing](r.db).
+		Where("status IN ?", []ProcessingStatus{ProcessingStatusProcessing, ProcessingStatusReadyForProcessing}).
+		Order("CASE WHEN status = 'processing' THEN 0 ELSE 1 END, year_month ASC").
+		First(ctx)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, log.Err("failed to get next queued processing record", err)
+	}
+
+	return &processing, nil
+}
+
+// Heartbeat records that the job working on an active record is still alive
+func (r *discogsDataProcessingRepository) Heartbeat(ctx context.Context, id int64) error {
+	log := logger.New("discogsDataProcessingRepository").TraceFromContext(ctx).Function("Heartbeat")
+
+	err := r.db.WithContext(ctx).
+		Model(&DiscogsDataProcessing{}).
+		Where("id = ? AND status IN ?", id, ActiveProcessingStatuses).
+		UpdateColumn("heartbeat_at", time.Now().UTC()).Error
+	if err != nil {
+		return log.Err("failed to record heartbeat", err, "id", id)
+	}
+
+	return nil
+}
+
+// GetStale returns active records that have shown no sign of life since deadline. Saving the
+// record counts as a sign of life as much as a heartbeat does.
+func (r *discogsDataProcessingRepository) GetStale(
+	ctx context.Context,
+	deadline time.Time,
+) ([]*DiscogsDataProcessing, error) {
+	log := logger.New("discogsDataProcessingRepository").TraceFromContext(ctx).Function("GetStale")
+
+	results, err := gorm.G[*DiscogsDataProcessing](r.db).
+		Where("status IN ?", ActiveProcessingStatuses).
+		Where("GREATEST(heartbeat_at, updated_at) < ?", deadline).
+		Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get stale processing records", err)
+	}
+
+	return results, nil
+}
+
+func (r *discogsDataProcessingRepository) GetTransitions(
+	ctx context.Context,
+	processingID int64,
+) ([]*DiscogsDataProcessingTransition, error) {
+	log := logger.New("discogsDataProcessingRepository").TraceFromContext(ctx).Function("GetTransitions")
+
+	transitions, err := gorm.G[*DiscogsDataProcessingTransition](r.db).
+		Where("processing_id = ?", processingID).
+		Order("created_at ASC").
+		Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get processing transitions", err, "processingID", processingID)
+	}
+
+	return transitions, nil
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DumpDeadLetterRepository interface {
+	CreateBatch(ctx context.Context, tx *gorm.DB, deadLetters []*DumpDeadLetter) error
+}
+
+type dumpDeadLetterRepository struct{}
+
+func NewDumpDeadLetterRepository() DumpDeadLetterRepository {
+	return &dumpDeadLetterRepository{}
+}
+
+func (r *dumpDeadLetterRepository) CreateBatch(
+	ctx context.Context,
+	tx *gorm.DB,
+	deadLetters []*DumpDeadLetter,
+) error {
+	log := logger.New("dumpDeadLetterRepository").TraceFromContext(ctx).Function("CreateBatch")
+
+	if len(deadLetters) == 0 {
+		return nil
+	}
+
+	if err := tx.WithContext(ctx).Create(deadLetters).Error; err != nil {
+		return log.Err("failed to create dump dead letters", err, "count", len(deadLetters))
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+
+	"gorm.io/gorm"
+)
+
+// Tables that take part in monthly dump change detection
+const (
+	DumpTableLabels   = "labels"
+	DumpTableArtists  = "artists"
+	DumpTableMasters  = "masters"
+	DumpTableReleases = "releases"
+)
+
+const tombstoneScanSize = 50_000
+
+// DumpEntityState is the stored change-detection state of a single catalog entity
+type DumpEntityState struct {
+	ID           int64
+	ContentHash  *string
+	TombstonedAt *time.Time
+}
+
+type DumpEntityRepository interface {
+	GetStates(
+		ctx context.Context,
+		tx *gorm.DB,
+		table string,
+		ids []int64,
+	) (map[int64]DumpEntityState, error)
+	TombstoneMissing(
+		ctx context.Context,
+		tx *gorm.DB,
+		table string,
+		seen func(id int64) bool,
+	) (int64, error)
+}
+
+type dumpEntityRepository struct{}
+
+func NewDumpEntityRepository() DumpEntityRepository {
+	return &dumpEntityRepository{}
+}
+
+func validateDumpTable(table string) error {
+	switch table {
+	case DumpTableLabels, DumpTableArtists, DumpTableMasters, DumpTableReleases:
+		return nil
+	default:
+		return fmt.Errorf("unsupported dump table: %s", table)
+	}
+}
+
+// GetStates returns the stored content hash and tombstone state for the given IDs
+func (r *dumpEntityRepository) GetStates(
+	ctx context.Context,
+	tx *gorm.DB,
+	table string,
+	ids []int64,
+) (map[int64]DumpEntityState, error) {
+	log := logger.New("dumpEntityRepository").TraceFromContext(ctx).Function("GetStates")
+
+	if err := validateDumpTable(table); err != nil {
+		return nil, log.Err("invalid table", err)
+	}
+
+	if len(ids) == 0 {
+		return make(map[int64]DumpEntityState), nil
+	}
+
+	var states []DumpEntityState
+	if err := tx.WithContext(ctx).
+		Table(table).
+		Select("id", "content_hash", "tombstoned_at").
+		Where("id IN ?", ids).
+		Scan(&states).Error; err != nil {
+		return nil, log.Err("failed to get entity states", err, "table", table, "count", len(ids))
+	}
+
+	result := make(map[int64]DumpEntityState, len(states))
+	for _, state := range states {
+		result[state.ID] = state
+	}
+
+	return result, nil
+}
+
+// TombstoneMissing marks every dump-sourced entity that was not seen in the current dump as tombstoned.
+// Rows without a content hash never came from a dump and are left untouched.
+func (r *dumpEntityRepository) TombstoneMissing(
+	ctx context.Context,
+	tx *gorm.DB,
+	table string,
+	seen func(id int64) bool,
+) (int64, error) {
+	log := logger.New("dumpEntityRepository").TraceFromContext(ctx).Function("TombstoneMissing")
+
+	if err := validateDumpTable(table); err != nil {
+		return 0, log.Err("invalid table", err)
+	}
+
+	var totalTombstoned int64
+	lastID := int64(0)
+	now := time.Now().UTC()
+
+	for {
+		var ids []int64
+		if err := tx.WithContext(ctx).
+			Table(table).
+			Where("id > ? AND tombstoned_at IS NULL AND content_hash IS NOT NULL", lastID).
+			Order("id").
+			Limit(tombstoneScanSize).
+			Pluck("id", &ids).Error; err != nil {
+			return totalTombstoned, log.Err("failed to scan entity ids", err, "table", table, "lastID", lastID)
+		}
+
+		if len(ids) == 0 {
+			break
+		}
+		lastID = ids[len(ids)-1]
+
+		var missing []int64
+		for _, id := range ids {
+			if !seen(id) {
+				missing = append(missing, id)
+			}
+		}
+
+		if len(missing) > 0 {
+			result := tx.WithContext(ctx).
+				Table(table).
+				Where("id IN ?", missing).
+				Updates(map[string]any{"tombstoned_at": now, "updated_at": now})
+			if result.Error != nil {
+				return totalTombstoned, log.Err("failed to tombstone entities", result.Error,
+					"table", table, "count", len(missing))
+			}
+			totalTombstoned += result.RowsAffected
+		}
+
+		if len(ids) < tombstoneScanSize {
+			break
+		}
+	}
+
+	log.Info("Tombstoned entities missing from dump", "table", table, "count", totalTombstoned)
+	return totalTombstoned, nil
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type JobRunRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, run *JobRun) error
+	Update(ctx context.Context, tx *gorm.DB, run *JobRun) error
+	FailAbandoned(ctx context.Context, tx *gorm.DB, jobName string) (int64, error)
+	GetLatestByJobNames(ctx context.Context, tx *gorm.DB, jobNames []string) (map[string]*JobRun, error)
+	GetRecentByJobName(ctx context.Context, tx *gorm.DB, jobName string, limit int) ([]*JobRun, error)
+}
+
+type jobRunRepository struct{}
+
+func NewJobRunRepository() JobRunRepository {
+	return &jobRunRepository{}
+}
+
+func (r *jobRunRepository) Create(ctx context.Context, tx *gorm.DB, run *JobRun) error {
+	log := logger.New("jobRunRepository").TraceFromContext(ctx).Function("Create")
+
+	if err := tx.WithContext(ctx).Create(run).Error; err != nil {
+		return log.Err("failed to create job run", err, "job", run.JobName)
+	}
+
+	return nil
+}
+
+func (r *jobRunRepository) Update(ctx context.Context, tx *gorm.DB, run *JobRun) error {
+	log := logger.New("jobRunRepository").TraceFromContext(ctx).Function("Update")
+
+	if err := tx.WithContext(ctx).Save(run).Error; err != nil {
+		return log.Err("failed to update job run", err, "job", run.JobName, "id", run.ID)
+	}
+
+	return nil
+}
+
+// FailAbandoned marks runs of a job that are still running as failed. Only call it while holding
+// the job's lock, when no live run can exist.
+func (r *jobRunRepository) FailAbandoned(ctx context.Context, tx *gorm.DB, jobName string) (int64, error) {
+	log := logger.New("jobRunRepository").TraceFromContext(ctx).Function("FailAbandoned")
+
+	result := tx.WithContext(ctx).
+		Model(&JobRun{}).
+		Where("job_name = ? AND status = ?", jobName, JobRunStatusRunning).
+		Updates(map[string]any{
+			"status":      JobRunStatusFailed,
+			"finished_at": time.Now().UTC(),
+			"error":       "run abandoned, the instance running it stopped before it finished",
+		})
+	if result.Error != nil {
+		return 0, log.Err("failed to fail abandoned job runs", result.Error, "job", jobName)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *jobRunRepository) GetLatestByJobNames(
+	ctx context.Context,
+	tx *gorm.DB,
+	jobNames []string,
+) (map[string]*JobRun, error) {
+	log := logger.New("jobRunRepository").TraceFromContext(ctx).Function("GetLatestByJobNames")
+
+	result := make(map[string]*JobRun, len(jobNames))
+	if len(jobNames) == 0 {
+		return result, nil
+	}
+
+	var runs []*JobRun
+	if err := tx.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (job_name) * FROM job_runs
+			WHERE job_name IN ? AND deleted_at IS NULL
+			ORDER BY job_name, started_at DESC`, jobNames).
+		Scan(&runs).Error; err != nil {
+		return nil, log.Err("failed to get latest job runs", err, "count", len(jobNames))
+	}
+
+	for _, run := range runs {
+		result[run.JobName] = run
+	}
+
+	return result, nil
+}
+
+func (r *jobRunRepository) GetRecentByJobName(
+	ctx context.Context,
+	tx *gorm.DB,
+	jobName string,
+	limit int,
+) ([]*JobRun, error) {
+	log := logger.New("jobRunRepository").TraceFromContext(ctx).Function("GetRecentByJobName")
+
+	runs, err := gorm.G[*JobRun](tx).
+		Where("job_name = ?", jobName).
+		Order("started_at DESC").
+		Limit(limit).
+		Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get recent job runs", err, "job", jobName)
+	}
+
+	return runs, nil
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"strconv"
+	"time"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
 
@@ -19,6 +20,8 @@ type LabelRepository interface {
 	Delete(ctx context.Context, tx *gorm.DB, id string) error
 	UpsertFileBatch(ctx context.Context, tx *gorm.DB, labels []*Label) error
 	UpsertBatch(ctx context.Context, tx *gorm.DB, labels []*Label) error
+	InsertMissingBatch(ctx context.Context, tx *gorm.DB, labels []*Label) error
+	CopyMergeBatch(ctx context.Context, tx *gorm.DB, labels []*Label) error
 	GetBatchByDiscogsIDs(
 		ctx context.Context,
 		tx *gorm.DB,
@@ -137,7 +140,7 @@ func (r *labelRepository) UpsertBatch(ctx context.Context, tx *gorm.DB, labels [
 
 	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"name", "profile", "updated_at"}),
+		DoUpdates: clause.AssignmentColumns(labelMerge.updateColumns()),
 	}).Create(&labels).Error; err != nil {
 		return log.Err("failed to upsert label batch", err, "count", len(labels))
 	}
@@ -146,6 +149,53 @@ func (r *labelRepository) UpsertBatch(ctx context.Context, tx *gorm.DB, labels [
 	return nil
 }
 
+// InsertMissingBatch inserts labels that do not exist yet and leaves existing rows untouched, so
+// partial data never overwrites what the dump or a full API sync stored
+func (r *labelRepository) InsertMissingBatch(ctx context.Context, tx *gorm.DB, labels []*Label) error {
+	log := logger.New("labelRepository").TraceFromContext(ctx).Function("InsertMissingBatch")
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoNothing: true,
+	}).Create(&labels).Error; err != nil {
+		return log.Err("failed to insert missing label batch", err, "count", len(labels))
+	}
+
+	return nil
+}
+
+var labelMerge = stagedMerge{
+	table: "labels",
+	columns: []string{
+		"id", "name", "profile", "resource_url", "uri",
+		"content_hash", "tombstoned_at", "created_at", "updated_at",
+	},
+}
+
+// CopyMergeBatch bulk loads labels through a staging table, with the same update semantics as UpsertBatch
+func (r *labelRepository) CopyMergeBatch(ctx context.Context, tx *gorm.DB, labels []*Label) error {
+	log := logger.New("labelRepository").TraceFromContext(ctx).Function("CopyMergeBatch")
+
+	now := time.Now().UTC()
+	rows := make([][]any, len(labels))
+	for i, label := range labels {
+		rows[i] = []any{
+			label.ID, label.Name, label.Profile, label.ResourceURL, label.URI,
+			label.ContentHash, label.TombstonedAt, now, now,
+		}
+	}
+
+	if err := copyMerge(ctx, tx, labelMerge, rows); err != nil {
+		return log.Err("failed to merge label batch", err, "count", len(labels))
+	}
+
+	return nil
+}
+
 func (r *labelRepository) GetBatchByDiscogsIDs(
 	ctx context.Context,
 	tx *gorm.DB,
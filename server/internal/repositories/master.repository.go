@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
 
@@ -24,6 +25,8 @@ type MasterGenreAssociation struct {
 type MasterRepository interface {
 	GetByDiscogsID(ctx context.Context, tx *gorm.DB, discogsID int64) (*Master, error)
 	UpsertBatch(ctx context.Context, tx *gorm.DB, masters []*Master) error
+	InsertMissingBatch(ctx context.Context, tx *gorm.DB, masters []*Master) error
+	CopyMergeBatch(ctx context.Context, tx *gorm.DB, masters []*Master) error
 	// Association methods
 	CreateMasterArtistAssociations(
 		ctx context.Context,
@@ -83,7 +86,7 @@ func (r *masterRepository) UpsertBatch(ctx context.Context, tx *gorm.DB, masters
 
 	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"title", "updated_at"}),
+		DoUpdates: clause.AssignmentColumns(masterMerge.updateColumns()),
 	}).Create(&masters).Error; err != nil {
 		return log.Err("failed to upsert master batch", err, "count", len(masters))
 	}
@@ -91,6 +94,53 @@ func (r *masterRepository) UpsertBatch(ctx context.Context, tx *gorm.DB, masters
 	return nil
 }
 
+// InsertMissingBatch inserts masters that do not exist yet and leaves existing rows untouched, so
+// partial data never overwrites what the dump or a full API sync stored
+func (r *masterRepository) InsertMissingBatch(ctx context.Context, tx *gorm.DB, masters []*Master) error {
+	log := logger.New("masterRepository").TraceFromContext(ctx).Function("InsertMissingBatch")
+
+	if len(masters) == 0 {
+		return nil
+	}
+
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoNothing: true,
+	}).Create(&masters).Error; err != nil {
+		return log.Err("failed to insert missing master batch", err, "count", len(masters))
+	}
+
+	return nil
+}
+
+var masterMerge = stagedMerge{
+	table: "masters",
+	columns: []string{
+		"id", "title", "year", "main_release_id", "main_release_resource_url", "uri", "resource_url",
+		"content_hash", "tombstoned_at", "created_at", "updated_at",
+	},
+}
+
+// CopyMergeBatch bulk loads masters through a staging table, with the same update semantics as UpsertBatch
+func (r *masterRepository) CopyMergeBatch(ctx context.Context, tx *gorm.DB, masters []*Master) error {
+	log := logger.New("masterRepository").TraceFromContext(ctx).Function("CopyMergeBatch")
+
+	now := time.Now().UTC()
+	rows := make([][]any, len(masters))
+	for i, master := range masters {
+		rows[i] = []any{
+			master.ID, master.Title, master.Year, master.MainReleaseID, master.MainReleaseResourceURL,
+			master.Uri, master.ResourceURL, master.ContentHash, master.TombstonedAt, now, now,
+		}
+	}
+
+	if err := copyMerge(ctx, tx, masterMerge, rows); err != nil {
+		return log.Err("failed to merge master batch", err, "count", len(masters))
+	}
+
+	return nil
+}
+
 // CreateMasterArtistAssociations creates specific master-artist association pairs
 func (r *masterRepository) CreateMasterArtistAssociations(
 	ctx context.Context,
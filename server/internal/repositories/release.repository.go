@@ -2,10 +2,13 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
 
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -25,6 +28,40 @@ type ReleaseGenreAssociation struct {
 	GenreID   int64
 }
 
+// ReleaseCatalogState is what the enrichment pass needs to know about a stored release
+type ReleaseCatalogState struct {
+	ID                int64
+	MasterID          *int64
+	TotalDuration     *int
+	TracksJSON        []Track `gorm:"serializer:json"`
+	FormatDetailsJSON datatypes.JSON
+	LastSynced        *time.Time
+	ContentHash       *string
+}
+
+// ReleaseCatalogUpdate marks a release as fully populated from the catalog, filling its total
+// duration when the stored row has none
+type ReleaseCatalogUpdate struct {
+	ReleaseID     int64
+	TotalDuration *int
+}
+
+// ReleaseFreshness is what the staleness policy looks at for a release in a user's collection
+type ReleaseFreshness struct {
+	ID          int64
+	LastSynced  *time.Time
+	DateChanged *time.Time
+	ContentHash *string
+}
+
+// ReleaseImageSource holds the image URLs stored for a release
+type ReleaseImageSource struct {
+	ID         int64
+	Thumb      *string
+	CoverImage *string
+	ImagesJSON datatypes.JSON
+}
+
 type ReleaseImageUpdate struct {
 	ReleaseID  int64
 	Thumb      *string
@@ -34,12 +71,18 @@ type ReleaseImageUpdate struct {
 type ReleaseRepository interface {
 	GetByDiscogsID(ctx context.Context, tx *gorm.DB, discogsID int64) (*Release, error)
 	UpsertBatch(ctx context.Context, tx *gorm.DB, releases []*Release) error
+	InsertMissingBatch(ctx context.Context, tx *gorm.DB, releases []*Release) error
+	CopyMergeBatch(ctx context.Context, tx *gorm.DB, releases []*Release) error
 	CheckReleaseExistence(
 		ctx context.Context,
 		tx *gorm.DB,
 		releaseIDs []int64,
 	) (existing []int64, missing []int64, err error)
 	UpdateReleaseImages(ctx context.Context, tx *gorm.DB, updates []ReleaseImageUpdate) error
+	GetCatalogStates(ctx context.Context, tx *gorm.DB, releaseIDs []int64) ([]ReleaseCatalogState, error)
+	MarkCatalogSynced(ctx context.Context, tx *gorm.DB, updates []ReleaseCatalogUpdate) error
+	GetCollectionFreshness(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]ReleaseFreshness, error)
+	GetImageSource(ctx context.Context, tx *gorm.DB, releaseID int64) (*ReleaseImageSource, error)
 	CreateReleaseArtistAssociations(
 		ctx context.Context,
 		tx *gorm.DB,
@@ -120,17 +163,26 @@ func (r *releaseRepository) UpsertBatch(
 
 	log.Info("Upserting releases", "count", len(releases))
 
+	// Images only replace stored ones when the response has them. The dump tracking columns belong
+	// to the dump merge, keeping them means an API sync does not make the next dump see a change.
+	updates := append(clause.AssignmentColumns([]string{
+		"title",
+		"tracks_json",
+		"videos_json",
+		"format_details_json",
+		"total_duration",
+		"last_synced",
+		"date_changed",
+		"updated_at",
+	}), clause.Assignments(map[string]any{
+		"images_json": gorm.Expr("COALESCE(EXCLUDED.images_json, releases.images_json)"),
+		"thumb":       gorm.Expr("COALESCE(EXCLUDED.thumb, releases.thumb)"),
+		"cover_image": gorm.Expr("COALESCE(EXCLUDED.cover_image, releases.cover_image)"),
+	})...)
+
 	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns: []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{
-			"title",
-			"tracks_json",
-			"images_json",
-			"videos_json",
-			"format_details_json",
-			"total_duration",
-			"updated_at",
-		}),
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: updates,
 	}).Create(&releases).Error; err != nil {
 		return log.Err("failed to upsert release batch", err, "count", len(releases))
 	}
@@ -139,6 +191,75 @@ func (r *releaseRepository) UpsertBatch(
 	return nil
 }
 
+// InsertMissingBatch inserts releases that do not exist yet and leaves existing rows untouched, so
+// collection basic information never wipes tracklists loaded from the dump or a full API sync
+func (r *releaseRepository) InsertMissingBatch(
+	ctx context.Context,
+	tx *gorm.DB,
+	releases []*Release,
+) error {
+	log := logger.New("releaseRepository").TraceFromContext(ctx).Function("InsertMissingBatch")
+
+	if len(releases) == 0 {
+		return nil
+	}
+
+	if err := tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoNothing: true,
+	}).Create(&releases).Error; err != nil {
+		return log.Err("failed to insert missing release batch", err, "count", len(releases))
+	}
+
+	return nil
+}
+
+var releaseMerge = stagedMerge{
+	table: "releases",
+	columns: []string{
+		"id", "title", "master_id", "year", "country", "format", "notes", "resource_url", "uri",
+		"total_duration", "tracks_json", "images_json", "videos_json", "format_details_json",
+		"content_hash", "tombstoned_at", "created_at", "updated_at", "date_changed",
+	},
+}
+
+// CopyMergeBatch bulk loads dump releases through a staging table. Unlike UpsertBatch, which saves
+// API responses, it owns content_hash and tombstoned_at and never stamps last_synced. Only new or
+// changed dump releases reach the merge, so date_changed records when the dump last changed each release.
+func (r *releaseRepository) CopyMergeBatch(
+	ctx context.Context,
+	tx *gorm.DB,
+	releases []*Release,
+) error {
+	log := logger.New("releaseRepository").TraceFromContext(ctx).Function("CopyMergeBatch")
+
+	now := time.Now().UTC()
+	rows := make([][]any, len(releases))
+	for i, release := range releases {
+		var tracks []byte
+		if release.TracksJSON != nil {
+			var err error
+			if tracks, err = json.Marshal(release.TracksJSON); err != nil {
+				return log.Err("failed to marshal tracks", err, "releaseID", release.ID)
+			}
+		}
+
+		rows[i] = []any{
+			release.ID, release.Title, release.MasterID, release.Year, release.Country,
+			string(release.Format), release.Notes, release.ResourceURL, release.URI,
+			release.TotalDuration, jsonbValue(tracks), jsonbValue(release.ImagesJSON),
+			jsonbValue(release.VideosJSON), jsonbValue(release.FormatDetailsJSON),
+			release.ContentHash, release.TombstonedAt, now, now, now,
+		}
+	}
+
+	if err := copyMerge(ctx, tx, releaseMerge, rows); err != nil {
+		return log.Err("failed to merge release batch", err, "count", len(releases))
+	}
+
+	return nil
+}
+
 func (r *releaseRepository) AssociateArtists(
 	ctx context.Context,
 	tx *gorm.DB,
@@ -532,3 +653,107 @@ func (r *releaseRepository) UpdateReleaseImages(
 
 	return nil
 }
+
+// GetCatalogStates loads the columns that tell whether each release already holds full data.
+// Releases that are not stored at all are simply absent from the result.
+func (r *releaseRepository) GetCatalogStates(
+	ctx context.Context,
+	tx *gorm.DB,
+	releaseIDs []int64,
+) ([]ReleaseCatalogState, error) {
+	log := logger.New("releaseRepository").TraceFromContext(ctx).Function("GetCatalogStates")
+
+	if len(releaseIDs) == 0 {
+		return []ReleaseCatalogState{}, nil
+	}
+
+	var states []ReleaseCatalogState
+	if err := tx.WithContext(ctx).Model(&Release{}).
+		Select("id, master_id, total_duration, tracks_json, format_details_json, last_synced, content_hash").
+		Where("id IN ?", releaseIDs).
+		Find(&states).Error; err != nil {
+		return nil, log.Err("failed to get release catalog states", err, "releaseCount", len(releaseIDs))
+	}
+
+	return states, nil
+}
+
+// MarkCatalogSynced stamps last_synced on releases populated from the catalog and fills in any
+// total duration the enrichment pass derived
+func (r *releaseRepository) MarkCatalogSynced(
+	ctx context.Context,
+	tx *gorm.DB,
+	updates []ReleaseCatalogUpdate,
+) error {
+	log := logger.New("releaseRepository").TraceFromContext(ctx).Function("MarkCatalogSynced")
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, update := range updates {
+		updateData := map[string]any{
+			"last_synced": now,
+			"updated_at":  now,
+		}
+		if update.TotalDuration != nil {
+			updateData["total_duration"] = *update.TotalDuration
+		}
+
+		if err := tx.WithContext(ctx).
+			Model(&Release{}).
+			Where("id = ?", update.ReleaseID).
+			Updates(updateData).Error; err != nil {
+			return log.Err("failed to mark release catalog synced", err, "releaseID", update.ReleaseID)
+		}
+	}
+
+	log.Info("Marked releases synced from catalog", "count", len(updates))
+	return nil
+}
+
+// GetCollectionFreshness returns the sync timestamps of every release in a user's active collection
+func (r *releaseRepository) GetCollectionFreshness(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+) ([]ReleaseFreshness, error) {
+	log := logger.New("releaseRepository").TraceFromContext(ctx).Function("GetCollectionFreshness")
+
+	var freshness []ReleaseFreshness
+	if err := tx.WithContext(ctx).
+		Table("releases").
+		Select("DISTINCT releases.id, releases.last_synced, releases.date_changed, releases.content_hash").
+		Joins("JOIN user_releases ON user_releases.release_id = releases.id").
+		Where("user_releases.user_id = ? AND user_releases.active = ? AND user_releases.deleted_at IS NULL", userID, true).
+		Find(&freshness).Error; err != nil {
+		return nil, log.Err("failed to get collection release freshness", err, "userID", userID)
+	}
+
+	return freshness, nil
+}
+
+// GetImageSource returns the image URLs of a release, nil when the release is not stored
+func (r *releaseRepository) GetImageSource(
+	ctx context.Context,
+	tx *gorm.DB,
+	releaseID int64,
+) (*ReleaseImageSource, error) {
+	log := logger.New("releaseRepository").TraceFromContext(ctx).Function("GetImageSource")
+
+	var source ReleaseImageSource
+	result := tx.WithContext(ctx).Model(&Release{}).
+		Select("id, thumb, cover_image, images_json").
+		Where("id = ?", releaseID).
+		Limit(1).
+		Find(&source)
+	if result.Error != nil {
+		return nil, log.Err("failed to get release image source", result.Error, "releaseID", releaseID)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &source, nil
+}
@@ -18,6 +18,13 @@ type Repository struct {
 	Stylus                StylusRepository
 	History               HistoryRepository
 	DailyRecommendation   DailyRecommendationRepository
+	DumpEntity            DumpEntityRepository
+	DumpDeadLetter        DumpDeadLetterRepository
+	Catalog               CatalogRepository
+	JobRun                JobRunRepository
+	ApiToken              ApiTokenRepository
+	UserRole              UserRoleRepository
+	Webhook               WebhookRepository
 }
 
 func New(db database.DB) Repository {
@@ -35,5 +42,12 @@ func New(db database.DB) Repository {
 		Stylus:                NewStylusRepository(db.Cache.User),
 		History:               NewHistoryRepository(db.Cache.User),
 		DailyRecommendation:   NewDailyRecommendationRepository(db.Cache.User),
+		DumpEntity:            NewDumpEntityRepository(),
+		DumpDeadLetter:        NewDumpDeadLetterRepository(),
+		Catalog:               NewCatalogRepository(db.Cache.General),
+		JobRun:                NewJobRunRepository(),
+		ApiToken:              NewApiTokenRepository(),
+		UserRole:              NewUserRoleRepository(),
+		Webhook:               NewWebhookRepository(),
 	}
 }
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// stagedMerge describes a bulk load of a catalog table through a COPY-loaded staging table
+type stagedMerge struct {
+	table   string
+	columns []string
+}
+
+// updateColumns lists every loaded column except id and created_at. The dump hashes the whole
+// entity, so a column left out here would keep stale data under a matching content_hash.
+func (m stagedMerge) updateColumns() []string {
+	columns := make([]string, 0, len(m.columns))
+	for _, column := range m.columns {
+		if column != "id" && column != "created_at" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// copyMerge streams rows into a transaction-scoped staging table with COPY and merges them into the
+// target table with a single INSERT ... ON CONFLICT. The merge always runs in its own transaction on a
+// dedicated connection, so tx must not be an open transaction.
+func copyMerge(ctx context.Context, tx *gorm.DB, merge stagedMerge, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	sqlDB, err := tx.WithContext(ctx).DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sql connection pool: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("COPY requires a pgx connection, got %T", driverConn)
+		}
+
+		pgxTx, err := stdlibConn.Conn().Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin merge transaction: %w", err)
+		}
+		// Rollback is a no-op once the transaction has committed
+		defer func() { _ = pgxTx.Rollback(ctx) }()
+
+		staging := "staging_" + merge.table
+		if _, err := pgxTx.Exec(ctx, fmt.Sprintf(
+			"CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP",
+			staging, merge.table,
+		)); err != nil {
+			return fmt.Errorf("failed to create staging table %s: %w", staging, err)
+		}
+
+		if _, err := pgxTx.CopyFrom(
+			ctx,
+			pgx.Identifier{staging},
+			merge.columns,
+			pgx.CopyFromRows(rows),
+		); err != nil {
+			return fmt.Errorf("failed to copy rows into %s: %w", staging, err)
+		}
+
+		if _, err := pgxTx.Exec(ctx, merge.sql(staging)); err != nil {
+			return fmt.Errorf("failed to merge %s into %s: %w", staging, merge.table, err)
+		}
+
+		if err := pgxTx.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit merge into %s: %w", merge.table, err)
+		}
+
+		return nil
+	})
+}
+
+// sql builds the merge statement. DISTINCT ON guards against an ID appearing twice in one batch,
+// which ON CONFLICT DO UPDATE rejects.
+func (m stagedMerge) sql(staging string) string {
+	columns := strings.Join(m.columns, ", ")
+
+	updateColumns := m.updateColumns()
+	assignments := make([]string, len(updateColumns))
+	for i, column := range updateColumns {
+		assignments[i] = fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) SELECT DISTINCT ON (id) %s FROM %s ORDER BY id ON CONFLICT (id) DO UPDATE SET %s",
+		m.table, columns, columns, staging, strings.Join(assignments, ", "),
+	)
+}
+
+// jsonbValue passes raw JSON to COPY, mapping an empty document to NULL
+func jsonbValue(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	. "waugzee/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergesUpdateEveryLoadedColumn(t *testing.T) {
+	for _, merge := range []stagedMerge{artistMerge, labelMerge, masterMerge, releaseMerge} {
+		t.Run(merge.table, func(t *testing.T) {
+			statement := merge.sql("staging_" + merge.table)
+			for _, column := range merge.columns {
+				assignment := column + " = EXCLUDED." + column
+				if column == "id" || column == "created_at" {
+					assert.NotContains(t, statement, assignment)
+				} else {
+					assert.Contains(t, statement, assignment)
+				}
+			}
+		})
+	}
+}
+
+func TestMasterUpsertWritesYearOnlyChange(t *testing.T) {
+	_, db := newCatalogTestRepository(t)
+	repo := NewMasterRepository()
+	ctx := context.Background()
+
+	year, hash := 1987, "first"
+	require.NoError(t, repo.UpsertBatch(ctx, db, []*Master{{
+		BaseDiscogModel: BaseDiscogModel{ID: 1},
+		DumpTracking:    DumpTracking{ContentHash: &hash},
+		Title:           "Master",
+		Year:            &year,
+	}}))
+
+	changedYear, changedHash := 1988, "second"
+	require.NoError(t, repo.UpsertBatch(ctx, db, []*Master{{
+		BaseDiscogModel: BaseDiscogModel{ID: 1},
+		DumpTracking:    DumpTracking{ContentHash: &changedHash},
+		Title:           "Master",
+		Year:            &changedYear,
+	}}))
+
+	var stored Master
+	require.NoError(t, db.First(&stored, 1).Error)
+	require.NotNil(t, stored.Year)
+	assert.Equal(t, changedYear, *stored.Year)
+	require.NotNil(t, stored.ContentHash)
+	assert.Equal(t, changedHash, *stored.ContentHash)
+}
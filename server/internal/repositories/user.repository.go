@@ -44,7 +44,10 @@ func (r *userRepository) GetByID(
 	log := logger.New("userRepository").TraceFromContext(ctx).Function("GetByID")
 
 	var user User
-	if err := tx.WithContext(ctx).Preload("Configuration").First(&user, "id = ?", userID).Error; err != nil {
+	if err := tx.WithContext(ctx).
+		Preload("Configuration").
+		Preload("Roles").
+		First(&user, "id = ?", userID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, log.Error("user not found", "userID", userID)
 		}
@@ -114,7 +117,10 @@ func (r *userRepository) GetByOIDCUserID(
 	}
 
 	var user User
-	if err := tx.WithContext(ctx).Preload("Configuration").First(&user, "oidc_user_id = ?", oidcUserID).Error; err != nil {
+	if err := tx.WithContext(ctx).
+		Preload("Configuration").
+		Preload("Roles").
+		First(&user, "oidc_user_id = ?", oidcUserID).Error; err != nil {
 		return nil, log.Err("failed to get user by OIDC user ID", err, "oidcUserID", oidcUserID)
 	}
 
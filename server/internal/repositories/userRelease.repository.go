@@ -31,6 +31,20 @@ type UserReleaseRepository interface {
 		userID uuid.UUID,
 		folderID int,
 	) ([]*UserRelease, error)
+	GetOwnedReleaseIDs(
+		ctx context.Context,
+		tx *gorm.DB,
+		userID uuid.UUID,
+		releaseIDs []int64,
+	) (map[int64]bool, error)
+	GetOwnedMasterIDs(
+		ctx context.Context,
+		tx *gorm.DB,
+		userID uuid.UUID,
+		masterIDs []int64,
+	) (map[int64]bool, error)
+	HasArtistInCollection(ctx context.Context, tx *gorm.DB, userID uuid.UUID, artistID int64) (bool, error)
+	HasLabelInCollection(ctx context.Context, tx *gorm.DB, userID uuid.UUID, labelID int64) (bool, error)
 }
 
 type userReleaseRepository struct {
@@ -266,6 +280,110 @@ func (r *userReleaseRepository) GetUserReleasesByFolderID(
 	return userReleases, nil
 }
 
+// GetOwnedReleaseIDs returns which of the given releases are in the user's active collection
+func (r *userReleaseRepository) GetOwnedReleaseIDs(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	releaseIDs []int64,
+) (map[int64]bool, error) {
+	log := logger.New("userReleaseRepository").TraceFromContext(ctx).Function("GetOwnedReleaseIDs")
+
+	owned := make(map[int64]bool)
+	if len(releaseIDs) == 0 {
+		return owned, nil
+	}
+
+	var ids []int64
+	if err := tx.WithContext(ctx).
+		Model(&UserRelease{}).
+		Where("user_id = ? AND active = ? AND release_id IN ?", userID, true, releaseIDs).
+		Distinct().
+		Pluck("release_id", &ids).Error; err != nil {
+		return nil, log.Err("failed to get owned release ids", err, "userID", userID, "count", len(releaseIDs))
+	}
+
+	for _, id := range ids {
+		owned[id] = true
+	}
+	return owned, nil
+}
+
+// GetOwnedMasterIDs returns which of the given masters have at least one release in the user's active collection
+func (r *userReleaseRepository) GetOwnedMasterIDs(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	masterIDs []int64,
+) (map[int64]bool, error) {
+	log := logger.New("userReleaseRepository").TraceFromContext(ctx).Function("GetOwnedMasterIDs")
+
+	owned := make(map[int64]bool)
+	if len(masterIDs) == 0 {
+		return owned, nil
+	}
+
+	var ids []int64
+	if err := tx.WithContext(ctx).
+		Model(&UserRelease{}).
+		Joins("JOIN releases ON releases.id = user_releases.release_id").
+		Where("user_releases.user_id = ? AND user_releases.active = ?", userID, true).
+		Where("releases.master_id IN ?", masterIDs).
+		Distinct().
+		Pluck("releases.master_id", &ids).Error; err != nil {
+		return nil, log.Err("failed to get owned master ids", err, "userID", userID, "count", len(masterIDs))
+	}
+
+	for _, id := range ids {
+		owned[id] = true
+	}
+	return owned, nil
+}
+
+// HasArtistInCollection reports whether any release by the artist is in the user's active collection
+func (r *userReleaseRepository) HasArtistInCollection(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	artistID int64,
+) (bool, error) {
+	log := logger.New("userReleaseRepository").TraceFromContext(ctx).Function("HasArtistInCollection")
+
+	var count int64
+	if err := tx.WithContext(ctx).
+		Model(&UserRelease{}).
+		Joins("JOIN release_artists ON release_artists.release_id = user_releases.release_id").
+		Where("user_releases.user_id = ? AND user_releases.active = ?", userID, true).
+		Where("release_artists.artist_id = ?", artistID).
+		Count(&count).Error; err != nil {
+		return false, log.Err("failed to check artist in collection", err, "userID", userID, "artistID", artistID)
+	}
+
+	return count > 0, nil
+}
+
+// HasLabelInCollection reports whether any release on the label is in the user's active collection
+func (r *userReleaseRepository) HasLabelInCollection(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	labelID int64,
+) (bool, error) {
+	log := logger.New("userReleaseRepository").TraceFromContext(ctx).Function("HasLabelInCollection")
+
+	var count int64
+	if err := tx.WithContext(ctx).
+		Model(&UserRelease{}).
+		Joins("JOIN release_labels ON release_labels.release_id = user_releases.release_id").
+		Where("user_releases.user_id = ? AND user_releases.active = ?", userID, true).
+		Where("release_labels.label_id = ?", labelID).
+		Count(&count).Error; err != nil {
+		return false, log.Err("failed to check label in collection", err, "userID", userID, "labelID", labelID)
+	}
+
+	return count > 0, nil
+}
+
 func (r *userReleaseRepository) clearUserReleasesCache(
 	ctx context.Context,
 	userID uuid.UUID,
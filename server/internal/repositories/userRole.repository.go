@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserRoleRepository interface {
+	GetByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]*UserRole, error)
+	Assign(ctx context.Context, tx *gorm.DB, userRole *UserRole) error
+	Delete(ctx context.Context, tx *gorm.DB, userID uuid.UUID, role Role) (bool, error)
+}
+
+type userRoleRepository struct{}
+
+func NewUserRoleRepository() UserRoleRepository {
+	return &userRoleRepository{}
+}
+
+func (r *userRoleRepository) GetByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]*UserRole, error) {
+	log := logger.New("userRoleRepository").TraceFromContext(ctx).Function("GetByUser")
+
+	userRoles, err := gorm.G[*UserRole](tx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get user roles", err, "userID", userID)
+	}
+
+	return userRoles, nil
+}
+
+// Assign adds the role to the user, assigning a role the user already has is a no-op
+func (r *userRoleRepository) Assign(ctx context.Context, tx *gorm.DB, userRole *UserRole) error {
+	log := logger.New("userRoleRepository").TraceFromContext(ctx).Function("Assign")
+
+	if err := tx.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "role"}},
+			DoNothing: true,
+		}).
+		Create(userRole).Error; err != nil {
+		return log.Err("failed to assign user role", err, "userID", userRole.UserID, "role", userRole.Role)
+	}
+
+	return nil
+}
+
+// Delete removes the role from the user and reports whether the user had it
+func (r *userRoleRepository) Delete(ctx context.Context, tx *gorm.DB, userID uuid.UUID, role Role) (bool, error) {
+	log := logger.New("userRoleRepository").TraceFromContext(ctx).Function("Delete")
+
+	result := tx.WithContext(ctx).Where("user_id = ? AND role = ?", userID, role).Delete(&UserRole{})
+	if result.Error != nil {
+		return false, log.Err("failed to delete user role", result.Error, "userID", userID, "role", role)
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,298 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, webhook *Webhook) error
+	GetByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]*Webhook, error)
+	GetByID(ctx context.Context, tx *gorm.DB, userID uuid.UUID, webhookID uuid.UUID) (*Webhook, error)
+	CountByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (int64, error)
+	Delete(ctx context.Context, tx *gorm.DB, userID uuid.UUID, webhookID uuid.UUID) (bool, error)
+	GetSubscribed(ctx context.Context, tx *gorm.DB, userID uuid.UUID, event WebhookEvent) ([]*Webhook, error)
+
+	CreateDeliveries(ctx context.Context, tx *gorm.DB, deliveries []*WebhookDelivery) (int64, error)
+	CreateDelivery(ctx context.Context, tx *gorm.DB, delivery *WebhookDelivery) error
+	ClaimDueDeliveries(
+		ctx context.Context,
+		tx *gorm.DB,
+		now time.Time,
+		leaseUntil time.Time,
+		limit int,
+	) ([]*WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, tx *gorm.DB, delivery *WebhookDelivery) error
+	GetDeliveries(ctx context.Context, tx *gorm.DB, webhookID uuid.UUID, limit int) ([]*WebhookDelivery, error)
+	GetDelivery(
+		ctx context.Context,
+		tx *gorm.DB,
+		webhookID uuid.UUID,
+		deliveryID uuid.UUID,
+	) (*WebhookDelivery, error)
+}
+
+type webhookRepository struct{}
+
+func NewWebhookRepository() WebhookRepository {
+	return &webhookRepository{}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, tx *gorm.DB, webhook *Webhook) error {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("Create")
+
+	if err := tx.WithContext(ctx).Create(webhook).Error; err != nil {
+		return log.Err("failed to create webhook", err, "userID", webhook.UserID)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) GetByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]*Webhook, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("GetByUser")
+
+	webhooks, err := gorm.G[*Webhook](tx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get webhooks", err, "userID", userID)
+	}
+
+	return webhooks, nil
+}
+
+// GetByID returns the user's webhook, or nil when the user has no such webhook
+func (r *webhookRepository) GetByID(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	webhookID uuid.UUID,
+) (*Webhook, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("GetByID")
+
+	var webhook Webhook
+	if err := tx.WithContext(ctx).
+		First(&webhook, "id = ? AND user_id = ?", webhookID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, log.Err("failed to get webhook", err, "userID", userID, "webhookID", webhookID)
+	}
+
+	return &webhook, nil
+}
+
+func (r *webhookRepository) CountByUser(ctx context.Context, tx *gorm.DB, userID uuid.UUID) (int64, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("CountByUser")
+
+	var count int64
+	if err := tx.WithContext(ctx).Model(&Webhook{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, log.Err("failed to count webhooks", err, "userID", userID)
+	}
+
+	return count, nil
+}
+
+// Delete removes a webhook owned by the user and reports whether it existed. Pending deliveries
+// of the webhook are failed by the dispatcher when it finds the webhook gone.
+func (r *webhookRepository) Delete(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	webhookID uuid.UUID,
+) (bool, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("Delete")
+
+	result := tx.WithContext(ctx).Where("id = ? AND user_id = ?", webhookID, userID).Delete(&Webhook{})
+	if result.Error != nil {
+		return false, log.Err("failed to delete webhook", result.Error, "userID", userID, "webhookID", webhookID)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetSubscribed returns the user's active webhooks subscribed to the event
+func (r *webhookRepository) GetSubscribed(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	event WebhookEvent,
+) ([]*Webhook, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("GetSubscribed")
+
+	events, err := json.Marshal([]WebhookEvent{event})
+	if err != nil {
+		return nil, log.Err("failed to encode webhook event", err, "event", event)
+	}
+
+	webhooks, err := gorm.G[*Webhook](tx).
+		Where("user_id = ? AND is_active AND events @> ?::jsonb", userID, string(events)).
+		Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get subscribed webhooks", err, "userID", userID, "event", event)
+	}
+
+	return webhooks, nil
+}
+
+// CreateDeliveries adds deliveries of an event, skipping webhooks the event was already delivered
+// to, and returns how many were added
+func (r *webhookRepository) CreateDeliveries(
+	ctx context.Context,
+	tx *gorm.DB,
+	deliveries []*WebhookDelivery,
+) (int64, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("CreateDeliveries")
+
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	result := tx.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "replay_of IS NULL"}}},
+		DoNothing:   true,
+	}).Create(deliveries)
+	if result.Error != nil {
+		return 0, log.Err("failed to create webhook deliveries", result.Error, "count", len(deliveries))
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *webhookRepository) CreateDelivery(ctx context.Context, tx *gorm.DB, delivery *WebhookDelivery) error {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("CreateDelivery")
+
+	if err := tx.WithContext(ctx).Create(delivery).Error; err != nil {
+		return log.Err("failed to create webhook delivery", err, "webhookID", delivery.WebhookID)
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries leases pending deliveries that are due by moving their next attempt to
+// leaseUntil, and returns them with their webhook, nil for deleted webhooks. Rows claimed by another
+// replica are skipped, and a lease that runs out because its replica died is claimed again.
+func (r *webhookRepository) ClaimDueDeliveries(
+	ctx context.Context,
+	tx *gorm.DB,
+	now time.Time,
+	leaseUntil time.Time,
+	limit int,
+) ([]*WebhookDelivery, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("ClaimDueDeliveries")
+
+	var deliveries []*WebhookDelivery
+	err := tx.WithContext(ctx).Raw(`
+		UPDATE webhook_deliveries SET next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ? AND deleted_at IS NULL
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		leaseUntil, now, WebhookDeliveryPending, now, limit,
+	).Scan(&deliveries).Error
+	if err != nil {
+		return nil, log.Err("failed to claim webhook deliveries", err)
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	webhookIDs := make([]uuid.UUID, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		webhookIDs = append(webhookIDs, delivery.WebhookID)
+	}
+
+	webhooks, err := gorm.G[*Webhook](tx).Where("id IN ?", webhookIDs).Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get webhooks of deliveries", err, "count", len(deliveries))
+	}
+
+	byID := make(map[uuid.UUID]*Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		byID[webhook.ID] = webhook
+	}
+	for _, delivery := range deliveries {
+		delivery.Webhook = byID[delivery.WebhookID]
+	}
+
+	return deliveries, nil
+}
+
+// UpdateDelivery records the outcome of an attempt
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, tx *gorm.DB, delivery *WebhookDelivery) error {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("UpdateDelivery")
+
+	if err := tx.WithContext(ctx).
+		Model(delivery).
+		Select(
+			"status",
+			"attempts",
+			"next_attempt_at",
+			"last_attempt_at",
+			"response_code",
+			"response_body",
+			"error",
+			"duration_ms",
+		).
+		Updates(delivery).Error; err != nil {
+		return log.Err("failed to update webhook delivery", err, "deliveryID", delivery.ID)
+	}
+
+	return nil
+}
+
+// GetDeliveries returns the webhook's most recent deliveries, newest first
+func (r *webhookRepository) GetDeliveries(
+	ctx context.Context,
+	tx *gorm.DB,
+	webhookID uuid.UUID,
+	limit int,
+) ([]*WebhookDelivery, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("GetDeliveries")
+
+	deliveries, err := gorm.G[*WebhookDelivery](tx).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get webhook deliveries", err, "webhookID", webhookID)
+	}
+
+	return deliveries, nil
+}
+
+// GetDelivery returns a delivery of the webhook, or nil when the webhook has no such delivery
+func (r *webhookRepository) GetDelivery(
+	ctx context.Context,
+	tx *gorm.DB,
+	webhookID uuid.UUID,
+	deliveryID uuid.UUID,
+) (*WebhookDelivery, error) {
+	log := logger.New("webhookRepository").TraceFromContext(ctx).Function("GetDelivery")
+
+	var delivery WebhookDelivery
+	if err := tx.WithContext(ctx).
+		First(&delivery, "id = ? AND webhook_id = ?", deliveryID, webhookID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, log.Err("failed to get webhook delivery", err, "webhookID", webhookID, "deliveryID", deliveryID)
+	}
+
+	return &delivery, nil
+}
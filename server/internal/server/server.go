@@ -50,6 +50,8 @@ func New(app *app.App) (*AppServer, error) {
 
 	server := fiber.New(config)
 
+	server.Use(app.Middleware.Metrics())
+
 	server.Use(cors.New(cors.Config{
 		AllowOrigins:     app.Config.CorsAllowOrigins,
 		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"waugzee/internal/database"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ApiTokenPrefix marks personal access tokens so they are told apart from OIDC ID tokens
+	// without a database lookup, and are easy to find in leaked secrets
+	ApiTokenPrefix = "wgz_pat_"
+
+	MaxApiTokensPerUser   = 25
+	MaxApiTokenNameLength = 100
+
+	apiTokenSecretBytes = 32
+	// apiTokenDisplayLength is how much of the token is stored in clear to tell tokens apart
+	apiTokenDisplayLength = len(ApiTokenPrefix) + 6
+	// apiTokenTouchInterval limits last used updates to one write per token per interval
+	apiTokenTouchInterval = time.Minute
+)
+
+var (
+	ErrApiTokenInvalid        = errors.New("invalid api token")
+	ErrApiTokenExpired        = errors.New("api token expired")
+	ErrApiTokenNotFound       = errors.New("api token not found")
+	ErrApiTokenNameRequired   = errors.New("token name is required")
+	ErrApiTokenNameTooLong    = errors.New("token name is too long")
+	ErrApiTokenScopeRequired  = errors.New("at least one scope is required")
+	ErrApiTokenInvalidScope   = errors.New("invalid token scope")
+	ErrApiTokenScopeForbidden = errors.New("admin scope requires an admin user")
+	ErrApiTokenInvalidExpiry  = errors.New("token expiry must be in the future")
+	ErrApiTokenLimitReached   = errors.New("api token limit reached")
+)
+
+// ApiTokenService issues and validates personal access tokens
+type ApiTokenService struct {
+	repos repositories.Repository
+	db    database.DB
+	log   logger.Logger
+}
+
+func NewApiTokenService(repos repositories.Repository, db database.DB) *ApiTokenService {
+	return &ApiTokenService{
+		repos: repos,
+		db:    db,
+		log:   logger.New("apiTokenService"),
+	}
+}
+
+// IsApiToken reports whether a bearer token is a personal access token
+func IsApiToken(token string) bool {
+	return strings.HasPrefix(token, ApiTokenPrefix)
+}
+
+// Create issues a token for the user and returns it with its secret, which is not stored and can
+// not be shown again
+func (s *ApiTokenService) Create(
+	ctx context.Context,
+	user *User,
+	name string,
+	scopes []ApiTokenScope,
+	expiresAt *time.Time,
+) (*ApiToken, string, error) {
+	log := s.log.Function("Create").With("userID", user.ID)
+
+	name = strings.TrimSpace(name)
+	if err := validateApiToken(user, name, scopes, expiresAt, time.Now()); err != nil {
+		return nil, "", log.Err("invalid api token request", err)
+	}
+
+	count, err := s.repos.ApiToken.CountByUser(ctx, s.db.SQLWithContext(ctx), user.ID)
+	if err != nil {
+		return nil, "", log.Err("failed to count api tokens", err)
+	}
+	if count >= MaxApiTokensPerUser {
+		return nil, "", log.Err("too many api tokens", ErrApiTokenLimitReached, "count", count)
+	}
+
+	secret, err := generateApiTokenSecret()
+	if err != nil {
+		return nil, "", log.Err("failed to generate api token", err)
+	}
+
+	token := &ApiToken{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    secret[:apiTokenDisplayLength],
+		TokenHash: hashApiToken(secret),
+		Scopes:    uniqueScopes(scopes),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repos.ApiToken.Create(ctx, s.db.SQLWithContext(ctx), token); err != nil {
+		return nil, "", log.Err("failed to create api token", err)
+	}
+
+	log.Info("Created api token", "tokenID", token.ID, "scopes", token.Scopes)
+	return token, secret, nil
+}
+
+func (s *ApiTokenService) List(ctx context.Context, userID uuid.UUID) ([]*ApiToken, error) {
+	log := s.log.Function("List")
+
+	tokens, err := s.repos.ApiToken.GetByUser(ctx, s.db.SQLWithContext(ctx), userID)
+	if err != nil {
+		return nil, log.Err("failed to list api tokens", err, "userID", userID)
+	}
+
+	return tokens, nil
+}
+
+func (s *ApiTokenService) Revoke(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error {
+	log := s.log.Function("Revoke")
+
+	deleted, err := s.repos.ApiToken.Delete(ctx, s.db.SQLWithContext(ctx), userID, tokenID)
+	if err != nil {
+		return log.Err("failed to revoke api token", err, "userID", userID, "tokenID", tokenID)
+	}
+	if !deleted {
+		return log.Err("api token not found", ErrApiTokenNotFound, "userID", userID, "tokenID", tokenID)
+	}
+
+	log.Info("Revoked api token", "userID", userID, "tokenID", tokenID)
+	return nil
+}
+
+// Authenticate resolves a personal access token to the token with its user loaded and records
+// when it was used
+func (s *ApiTokenService) Authenticate(ctx context.Context, secret string) (*ApiToken, error) {
+	log := s.log.Function("Authenticate")
+
+	if !IsApiToken(secret) {
+		return nil, log.Err("malformed api token", ErrApiTokenInvalid)
+	}
+
+	token, err := s.repos.ApiToken.GetByHash(ctx, s.db.SQLWithContext(ctx), hashApiToken(secret))
+	if err != nil {
+		return nil, log.Err("failed to look up api token", err)
+	}
+	if token == nil || token.User == nil {
+		return nil, log.Err("unknown api token", ErrApiTokenInvalid)
+	}
+
+	now := time.Now()
+	if token.IsExpired(now) {
+		return nil, log.Err("api token expired", ErrApiTokenExpired, "tokenID", token.ID)
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval {
+		if err := s.repos.ApiToken.TouchLastUsed(ctx, s.db.SQLWithContext(ctx), token.ID, now); err != nil {
+			log.Warn("Failed to record api token use", "tokenID", token.ID, "error", err)
+		} else {
+			token.LastUsedAt = &now
+		}
+	}
+
+	return token, nil
+}
+
+func validateApiToken(
+	user *User,
+	name string,
+	scopes []ApiTokenScope,
+	expiresAt *time.Time,
+	now time.Time,
+) error {
+	if name == "" {
+		return ErrApiTokenNameRequired
+	}
+	if len(name) > MaxApiTokenNameLength {
+		return ErrApiTokenNameTooLong
+	}
+	if len(scopes) == 0 {
+		return ErrApiTokenScopeRequired
+	}
+	for _, scope := range scopes {
+		if !IsValidApiTokenScope(scope) {
+			return ErrApiTokenInvalidScope
+		}
+		if scope == ApiTokenScopeAdmin && !user.IsAdmin {
+			return ErrApiTokenScopeForbidden
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return ErrApiTokenInvalidExpiry
+	}
+	return nil
+}
+
+func uniqueScopes(scopes []ApiTokenScope) []ApiTokenScope {
+	unique := make([]ApiTokenScope, 0, len(scopes))
+	seen := make(map[ApiTokenScope]bool, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
+
+func generateApiTokenSecret() (string, error) {
+	buf := make([]byte, apiTokenSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return ApiTokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashApiToken hashes a token secret for storage. The secret carries 256 random bits, so a fast
+// unsalted hash is enough and lets the token be looked up by its hash.
+func hashApiToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	. "waugzee/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateApiTokenSecret(t *testing.T) {
+	first, err := generateApiTokenSecret()
+	require.NoError(t, err)
+	second, err := generateApiTokenSecret()
+	require.NoError(t, err)
+
+	assert.True(t, IsApiToken(first))
+	assert.True(t, strings.HasPrefix(first, ApiTokenPrefix))
+	assert.NotEqual(t, first, second)
+	assert.NotEqual(t, hashApiToken(first), hashApiToken(second))
+	assert.Equal(t, hashApiToken(first), hashApiToken(first))
+	assert.NotContains(t, hashApiToken(first), first)
+
+	assert.False(t, IsApiToken("eyJhbGciOiJSUzI1NiJ9.payload.signature"))
+}
+
+func TestValidateApiToken(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	user := &User{}
+	admin := &User{IsAdmin: true}
+	read := []ApiTokenScope{ApiTokenScopeReadCollection}
+
+	tests := []struct {
+		name      string
+		user      *User
+		tokenName string
+		scopes    []ApiTokenScope
+		expiresAt *time.Time
+		expected  error
+	}{
+		{"valid", user, "Home Assistant", read, &future, nil},
+		{"never expires", user, "CLI", read, nil, nil},
+		{"missing name", user, "", read, nil, ErrApiTokenNameRequired},
+		{"name too long", user, strings.Repeat("a", MaxApiTokenNameLength+1), read, nil, ErrApiTokenNameTooLong},
+		{"missing scopes", user, "CLI", nil, nil, ErrApiTokenScopeRequired},
+		{"unknown scope", user, "CLI", []ApiTokenScope{"collection:write"}, nil, ErrApiTokenInvalidScope},
+		{"admin scope for user", user, "CLI", []ApiTokenScope{ApiTokenScopeAdmin}, nil, ErrApiTokenScopeForbidden},
+		{"admin scope for admin", admin, "CLI", []ApiTokenScope{ApiTokenScopeAdmin}, nil, nil},
+		{"expiry in the past", user, "CLI", read, &past, ErrApiTokenInvalidExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateApiToken(tt.user, tt.tokenName, tt.scopes, tt.expiresAt, now)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApiToken_Scopes(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	expiry := now.Add(time.Minute)
+
+	token := &ApiToken{
+		Scopes:    uniqueScopes([]ApiTokenScope{ApiTokenScopeWriteHistory, ApiTokenScopeWriteHistory}),
+		ExpiresAt: &expiry,
+	}
+	assert.Equal(t, []ApiTokenScope{ApiTokenScopeWriteHistory}, token.Scopes)
+	assert.True(t, token.HasScope(ApiTokenScopeWriteHistory))
+	assert.False(t, token.HasScope(ApiTokenScopeReadCollection))
+	assert.False(t, token.IsExpired(now))
+	assert.True(t, token.IsExpired(expiry))
+
+	admin := &ApiToken{Scopes: []ApiTokenScope{ApiTokenScopeAdmin}}
+	assert.True(t, admin.HasScope(ApiTokenScopeReadCollection))
+	assert.False(t, admin.IsExpired(now))
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"waugzee/config"
+	"waugzee/internal/types"
+)
+
+const (
+	AuthProviderZitadel = "zitadel"
+	AuthProviderOIDC    = "oidc"
+	AuthProviderLocal   = "local"
+)
+
+// AuthProvider validates the ID tokens users sign in with and handles the provider side of logout.
+// Users are matched to tokens by the token subject, recorded as User.OIDCUserID.
+type AuthProvider interface {
+	// Name is recorded as User.OIDCProvider for users created through the provider
+	Name() string
+	ValidateIDToken(ctx context.Context, idToken string) (*types.TokenInfo, error)
+	RevokeToken(ctx context.Context, token string, tokenType string) error
+	GetLogoutURL(ctx context.Context, idTokenHint, postLogoutRedirectURI, state string) (string, error)
+	GetConfig() AuthProviderConfig
+	// CheckKeys makes sure the keys tokens are verified with are loaded and returns when they were
+	// fetched, the zero time for providers that sign tokens themselves
+	CheckKeys(ctx context.Context) (time.Time, error)
+	Close() error
+}
+
+// AuthProviderConfig is the OIDC configuration the client needs to sign users in
+type AuthProviderConfig struct {
+	Provider    string `json:"provider"`
+	Domain      string `json:"domain"`
+	InstanceURL string `json:"instanceUrl"`
+	ClientID    string `json:"clientId"`
+}
+
+// NewAuthProvider builds the provider selected by AUTH_PROVIDER, defaulting to Zitadel
+func NewAuthProvider(cfg config.Config) (AuthProvider, error) {
+	switch strings.ToLower(cfg.AuthProvider) {
+	case "", AuthProviderZitadel:
+		return NewZitadelService(cfg)
+	case AuthProviderOIDC:
+		return NewOIDCProvider(OIDCProviderConfig{
+			Name:         cfg.OIDCProviderName,
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+		})
+	case AuthProviderLocal:
+		return NewLocalAuthProvider(cfg)
+	default:
+		return nil, fmt.Errorf("unknown auth provider: %s", cfg.AuthProvider)
+	}
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"waugzee/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOIDCServer serves discovery and a JWKS for keys that can be swapped to simulate rotation
+type testOIDCServer struct {
+	*httptest.Server
+	issuer       string
+	keys         atomic.Value
+	jwksRequests atomic.Int32
+}
+
+func newTestOIDCServer(t *testing.T, issuerPath string) *testOIDCServer {
+	t.Helper()
+	server := &testOIDCServer{}
+	server.keys.Store([]JWK{})
+
+	mux := http.NewServeMux()
+	discoveryPath := strings.TrimSuffix(issuerPath, "/") + "/.well-known/openid-configuration"
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(OIDCDiscovery{
+			Issuer:             server.issuer,
+			JWKS_URI:           server.URL + "/keys",
+			EndSessionEndpoint: server.URL + "/logout?realm=test",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		server.jwksRequests.Add(1)
+		_ = json.NewEncoder(w).Encode(JWKSet{Keys: server.keys.Load().([]JWK)})
+	})
+
+	server.Server = httptest.NewServer(mux)
+	server.issuer = server.URL + issuerPath
+	t.Cleanup(server.Close)
+	return server
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) JWK {
+	return JWK{
+		Kid: kid,
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) JWK {
+	return JWK{
+		Kid: kid,
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func signIDToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func idTokenClaims(issuer string, audience string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"sub":            "user-123",
+		"aud":            audience,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"email":          "listener@example.com",
+		"email_verified": "true",
+		"given_name":     "Vinyl",
+		"family_name":    "Listener",
+		"groups":         []string{"admins", "listeners"},
+	}
+}
+
+func TestOIDCProvider_ValidateIDToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// Authentik style issuer with a trailing slash
+	server := newTestOIDCServer(t, "/application/o/waugzee/")
+	server.keys.Store([]JWK{rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey)})
+
+	provider, err := NewOIDCProvider(OIDCProviderConfig{
+		Name:      "authentik",
+		IssuerURL: server.issuer,
+		ClientID:  "waugzee",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("RSA signed token", func(t *testing.T) {
+		idToken := signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, idTokenClaims(server.issuer, "waugzee"))
+
+		info, err := provider.ValidateIDToken(ctx, idToken)
+		require.NoError(t, err)
+		assert.True(t, info.Valid)
+		assert.Equal(t, "user-123", info.UserID)
+		assert.Equal(t, "Vinyl Listener", info.Name)
+		assert.True(t, info.EmailVerified)
+		assert.Equal(t, []string{"admins", "listeners"}, info.Roles)
+	})
+
+	t.Run("EC signed token", func(t *testing.T) {
+		idToken := signIDToken(t, jwt.SigningMethodES256, "ec-1", ecKey, idTokenClaims(server.issuer, "waugzee"))
+
+		info, err := provider.ValidateIDToken(ctx, idToken)
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", info.UserID)
+	})
+
+	t.Run("rejects another audience", func(t *testing.T) {
+		idToken := signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, idTokenClaims(server.issuer, "other-app"))
+
+		_, err := provider.ValidateIDToken(ctx, idToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects another issuer", func(t *testing.T) {
+		idToken := signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, idTokenClaims("https://evil.example.com", "waugzee"))
+
+		_, err := provider.ValidateIDToken(ctx, idToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects expired token", func(t *testing.T) {
+		claims := idTokenClaims(server.issuer, "waugzee")
+		claims["exp"] = time.Now().Add(-time.Hour).Unix()
+		idToken := signIDToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)
+
+		_, err := provider.ValidateIDToken(ctx, idToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("picks up rotated keys", func(t *testing.T) {
+		rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		server.keys.Store([]JWK{rsaJWK("rsa-2", rotated)})
+		before := server.jwksRequests.Load()
+
+		idToken := signIDToken(t, jwt.SigningMethodRS256, "rsa-2", rotated, idTokenClaims(server.issuer, "waugzee"))
+		_, err = provider.ValidateIDToken(ctx, idToken)
+		require.NoError(t, err)
+		assert.Equal(t, before+1, server.jwksRequests.Load())
+	})
+
+	t.Run("unknown kids do not refetch within the interval", func(t *testing.T) {
+		before := server.jwksRequests.Load()
+
+		for _, kid := range []string{"forged-1", "forged-2", "forged-3"} {
+			idToken := signIDToken(t, jwt.SigningMethodRS256, kid, rsaKey, idTokenClaims(server.issuer, "waugzee"))
+			_, err := provider.ValidateIDToken(ctx, idToken)
+			assert.Error(t, err)
+		}
+		assert.Equal(t, before, server.jwksRequests.Load())
+
+		// Once the interval passed an unknown kid refetches again
+		provider.jwksMux.Lock()
+		provider.jwksRefreshedAt = time.Now().Add(-jwksForcedRefreshInterval)
+		provider.jwksMux.Unlock()
+
+		idToken := signIDToken(t, jwt.SigningMethodRS256, "forged-4", rsaKey, idTokenClaims(server.issuer, "waugzee"))
+		_, err := provider.ValidateIDToken(ctx, idToken)
+		assert.Error(t, err)
+		assert.Equal(t, before+1, server.jwksRequests.Load())
+	})
+}
+
+func TestOIDCProvider_CheckKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := newTestOIDCServer(t, "")
+	provider, err := NewOIDCProvider(OIDCProviderConfig{IssuerURL: server.issuer, ClientID: "waugzee"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = provider.CheckKeys(ctx)
+	assert.Error(t, err, "an empty key set can not verify tokens")
+
+	server.keys.Store([]JWK{rsaJWK("rsa-1", rsaKey)})
+	fetchedAt, err := provider.CheckKeys(ctx)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), fetchedAt, time.Second)
+
+	requests := server.jwksRequests.Load()
+	cachedAt, err := provider.CheckKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, fetchedAt, cachedAt)
+	assert.Equal(t, requests, server.jwksRequests.Load(), "cached keys are not refetched")
+}
+
+func TestOIDCProvider_GetLogoutURL(t *testing.T) {
+	server := newTestOIDCServer(t, "")
+	provider, err := NewOIDCProvider(OIDCProviderConfig{IssuerURL: server.issuer, ClientID: "waugzee"})
+	require.NoError(t, err)
+
+	logoutURL, err := provider.GetLogoutURL(context.Background(), "id-token", "https://app.example.com", "")
+	require.NoError(t, err)
+	assert.Contains(t, logoutURL, "realm=test")
+	assert.Contains(t, logoutURL, "id_token_hint=id-token")
+	assert.Contains(t, logoutURL, "client_id=waugzee")
+	assert.Equal(t, AuthProviderOIDC, provider.Name())
+}
+
+func TestLocalAuthProvider(t *testing.T) {
+	provider, err := NewLocalAuthProvider(config.Config{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	idToken, expiresAt, err := provider.IssueIDToken(" Listener@Example.com ", "")
+	require.NoError(t, err)
+	assert.True(t, expiresAt.After(time.Now()))
+
+	info, err := provider.ValidateIDToken(ctx, idToken)
+	require.NoError(t, err)
+	assert.Equal(t, "local|listener@example.com", info.UserID)
+	assert.Equal(t, "listener@example.com", info.Email)
+	assert.Equal(t, "listener", info.Name)
+	assert.True(t, info.EmailVerified)
+
+	other, err := NewLocalAuthProvider(config.Config{})
+	require.NoError(t, err)
+	_, err = other.ValidateIDToken(ctx, idToken)
+	assert.Error(t, err, "tokens signed with another secret are rejected")
+
+	_, _, err = provider.IssueIDToken("not an email", "")
+	assert.ErrorIs(t, err, ErrLocalAuthInvalidEmail)
+
+	_, err = NewLocalAuthProvider(config.Config{LocalAuthSecret: "short"})
+	assert.Error(t, err)
+}
+
+func TestClaimStrings(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, claimStrings(json.RawMessage(`["a","b"]`)))
+	assert.Equal(t, []string{"admin"}, claimStrings(json.RawMessage(`"admin"`)))
+	assert.Equal(t, []string{"admin", "user"},
+		claimStrings(json.RawMessage(`{"user":{"org":"x"},"admin":{"org":"x"}}`)))
+	assert.Nil(t, claimStrings(nil))
+}
+
+func TestNewAuthProvider(t *testing.T) {
+	provider, err := NewAuthProvider(config.Config{AuthProvider: AuthProviderLocal})
+	require.NoError(t, err)
+	assert.Equal(t, AuthProviderLocal, provider.Name())
+
+	_, err = NewAuthProvider(config.Config{AuthProvider: AuthProviderOIDC})
+	assert.Error(t, err, "oidc requires an issuer and client ID")
+
+	_, err = NewAuthProvider(config.Config{AuthProvider: "saml"})
+	assert.Error(t, err)
+}
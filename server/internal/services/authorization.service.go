@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"waugzee/config"
+	"waugzee/internal/database"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrRoleUserNotFound = errors.New("user not found")
+	ErrUserRoleNotFound = errors.New("user does not have the role")
+)
+
+// AuthorizationService resolves what a user may do from their roles. A user's roles are the roles
+// granted by their identity provider's token claims, or the default role when no claim maps to one,
+// admin for users flagged IsAdmin, and roles assigned to them in the app.
+type AuthorizationService struct {
+	repos       repositories.Repository
+	db          database.DB
+	defaultRole Role
+	claimRoles  map[string]Role
+	log         logger.Logger
+}
+
+func NewAuthorizationService(
+	repos repositories.Repository,
+	db database.DB,
+	cfg config.Config,
+) (*AuthorizationService, error) {
+	log := logger.New("authorizationService")
+
+	defaultRole := RoleMember
+	if cfg.AuthDefaultRole != "" {
+		defaultRole = Role(strings.TrimSpace(cfg.AuthDefaultRole))
+		if !IsValidRole(defaultRole) {
+			return nil, log.Err("invalid AUTH_DEFAULT_ROLE", ErrInvalidRole, "role", defaultRole)
+		}
+	}
+
+	claimRoles, err := parseRoleClaimMapping(cfg.AuthRoleClaimMapping)
+	if err != nil {
+		return nil, log.Err("invalid AUTH_ROLE_CLAIM_MAPPING", err)
+	}
+
+	return &AuthorizationService{
+		repos:       repos,
+		db:          db,
+		defaultRole: defaultRole,
+		claimRoles:  claimRoles,
+		log:         log,
+	}, nil
+}
+
+// Resolve returns the user's roles and permissions. claims are the role or group claims of the
+// token the user signed in with, nil when there was no ID token such as for personal access tokens.
+func (s *AuthorizationService) Resolve(user *User, claims []string) *Authorization {
+	var roles []Role
+	for _, claim := range claims {
+		if role, ok := s.claimRole(claim); ok {
+			roles = append(roles, role)
+		}
+	}
+
+	// Mapped claims replace the default role, so a claim mapped to viewer restricts the user
+	if len(roles) == 0 {
+		roles = append(roles, s.defaultRole)
+	}
+
+	if user.IsAdmin {
+		roles = append(roles, RoleAdmin)
+	}
+
+	for _, userRole := range user.Roles {
+		roles = append(roles, userRole.Role)
+	}
+
+	return NewAuthorization(roles)
+}
+
+// claimRole only honours claims listed in AUTH_ROLE_CLAIM_MAPPING. Whoever manages group names at
+// the identity provider must not be able to grant app roles without the app opting in.
+func (s *AuthorizationService) claimRole(claim string) (Role, bool) {
+	role, ok := s.claimRoles[claim]
+	return role, ok
+}
+
+func (s *AuthorizationService) ListRoles(ctx context.Context, userID uuid.UUID) ([]*UserRole, error) {
+	log := s.log.Function("ListRoles")
+
+	userRoles, err := s.repos.UserRole.GetByUser(ctx, s.db.SQLWithContext(ctx), userID)
+	if err != nil {
+		return nil, log.Err("failed to list user roles", err, "userID", userID)
+	}
+
+	return userRoles, nil
+}
+
+// AssignRole gives the user a role in the app on top of the roles their identity provider grants
+func (s *AuthorizationService) AssignRole(
+	ctx context.Context,
+	assignedBy *User,
+	userID uuid.UUID,
+	role Role,
+) error {
+	log := s.log.Function("AssignRole").With("userID", userID, "role", role)
+
+	if !IsValidRole(role) {
+		return log.Err("invalid role", ErrInvalidRole)
+	}
+
+	if _, err := s.repos.User.GetByID(ctx, s.db.SQLWithContext(ctx), userID); err != nil {
+		return log.Err("failed to get user", ErrRoleUserNotFound, "error", err)
+	}
+
+	userRole := &UserRole{UserID: userID, Role: role, AssignedBy: &assignedBy.ID}
+	if err := s.repos.UserRole.Assign(ctx, s.db.SQLWithContext(ctx), userRole); err != nil {
+		return log.Err("failed to assign role", err)
+	}
+
+	s.clearUserCache(ctx, userID)
+
+	log.Info("Assigned role", "assignedBy", assignedBy.ID)
+	return nil
+}
+
+// RevokeRole removes a role assigned in the app, roles granted by the identity provider are
+// managed there
+func (s *AuthorizationService) RevokeRole(ctx context.Context, userID uuid.UUID, role Role) error {
+	log := s.log.Function("RevokeRole").With("userID", userID, "role", role)
+
+	if !IsValidRole(role) {
+		return log.Err("invalid role", ErrInvalidRole)
+	}
+
+	deleted, err := s.repos.UserRole.Delete(ctx, s.db.SQLWithContext(ctx), userID, role)
+	if err != nil {
+		return log.Err("failed to revoke role", err)
+	}
+	if !deleted {
+		return log.Err("role not assigned", ErrUserRoleNotFound)
+	}
+
+	s.clearUserCache(ctx, userID)
+
+	log.Info("Revoked role")
+	return nil
+}
+
+// clearUserCache drops the cached user so role changes apply to their next request
+func (s *AuthorizationService) clearUserCache(ctx context.Context, userID uuid.UUID) {
+	if err := s.repos.User.ClearUserCacheByUserID(ctx, s.db.SQLWithContext(ctx), userID.String()); err != nil {
+		s.log.Function("clearUserCache").Warn("Failed to clear user cache", "userID", userID, "error", err)
+	}
+}
+
+// parseRoleClaimMapping parses "claim=role" pairs separated by commas
+func parseRoleClaimMapping(mapping string) (map[string]Role, error) {
+	claimRoles := make(map[string]Role)
+
+	for pair := range strings.SplitSeq(mapping, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		claim, role, found := strings.Cut(pair, "=")
+		claim = strings.TrimSpace(claim)
+		role = strings.TrimSpace(role)
+		if !found || claim == "" || !IsValidRole(Role(role)) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRole, pair)
+		}
+
+		claimRoles[claim] = Role(role)
+	}
+
+	return claimRoles, nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"waugzee/config"
+	"waugzee/internal/database"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthorizationService(t *testing.T, cfg config.Config) *AuthorizationService {
+	t.Helper()
+	service, err := NewAuthorizationService(repositories.Repository{}, database.DB{}, cfg)
+	require.NoError(t, err)
+	return service
+}
+
+func TestAuthorizationService_Resolve(t *testing.T) {
+	service := newTestAuthorizationService(t, config.Config{})
+
+	t.Run("every user is a member", func(t *testing.T) {
+		authorization := service.Resolve(&User{}, nil)
+		assert.Equal(t, []Role{RoleMember}, authorization.Roles)
+		assert.True(t, authorization.Can(PermissionCollectionWrite))
+		assert.True(t, authorization.Can(PermissionHistoryWrite))
+		assert.False(t, authorization.Can(PermissionDumpsManage))
+	})
+
+	t.Run("admin flag grants admin", func(t *testing.T) {
+		authorization := service.Resolve(&User{IsAdmin: true}, nil)
+		assert.True(t, authorization.HasRole(RoleAdmin))
+		assert.True(t, authorization.Can(PermissionUsersManage))
+	})
+
+	t.Run("claims grant nothing without a mapping", func(t *testing.T) {
+		authorization := service.Resolve(&User{}, []string{"admin", "catalog-maintainer"})
+		assert.Equal(t, []Role{RoleMember}, authorization.Roles)
+		assert.False(t, authorization.Can(PermissionDumpsManage))
+	})
+
+	t.Run("assigned roles are added", func(t *testing.T) {
+		user := &User{Roles: []UserRole{{Role: RoleCatalogMaintainer}, {Role: "unknown"}}}
+		authorization := service.Resolve(user, nil)
+		assert.Equal(t, []Role{RoleMember, RoleCatalogMaintainer}, authorization.Roles)
+	})
+}
+
+func TestAuthorizationService_ResolveWithConfig(t *testing.T) {
+	service := newTestAuthorizationService(t, config.Config{
+		AuthDefaultRole:      "viewer",
+		AuthRoleClaimMapping: "waugzee-admins=admin, catalog=catalog-maintainer",
+	})
+
+	viewer := service.Resolve(&User{}, nil)
+	assert.Equal(t, []Role{RoleViewer}, viewer.Roles)
+	assert.True(t, viewer.Can(PermissionCollectionRead))
+	assert.False(t, viewer.Can(PermissionCollectionWrite))
+
+	mapped := service.Resolve(&User{}, []string{"waugzee-admins", "catalog"})
+	assert.True(t, mapped.HasRole(RoleAdmin))
+	assert.True(t, mapped.HasRole(RoleCatalogMaintainer))
+	assert.True(t, mapped.Can(PermissionDumpsManage))
+	assert.False(t, mapped.HasRole(RoleViewer), "mapped claims replace the default role")
+
+	unmapped := service.Resolve(&User{}, []string{"admin"})
+	assert.False(t, unmapped.HasRole(RoleAdmin), "with a mapping only mapped claims grant roles")
+	assert.Equal(t, []Role{RoleViewer}, unmapped.Roles, "users without a mapped claim get the default role")
+}
+
+func TestAuthorizationService_ClaimMappedToViewerRestricts(t *testing.T) {
+	service := newTestAuthorizationService(t, config.Config{
+		AuthRoleClaimMapping: "readonly=viewer",
+	})
+
+	restricted := service.Resolve(&User{}, []string{"readonly"})
+	assert.Equal(t, []Role{RoleViewer}, restricted.Roles)
+	assert.True(t, restricted.Can(PermissionCollectionRead))
+	assert.False(t, restricted.Can(PermissionCollectionWrite))
+
+	member := service.Resolve(&User{}, []string{"staff"})
+	assert.Equal(t, []Role{RoleMember}, member.Roles)
+	assert.True(t, member.Can(PermissionCollectionWrite))
+}
+
+func TestNewAuthorizationService_InvalidConfig(t *testing.T) {
+	_, err := NewAuthorizationService(repositories.Repository{}, database.DB{}, config.Config{
+		AuthDefaultRole: "owner",
+	})
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	for _, mapping := range []string{"admins", "admins=owner", "=admin"} {
+		_, err := NewAuthorizationService(repositories.Repository{}, database.DB{}, config.Config{
+			AuthRoleClaimMapping: mapping,
+		})
+		assert.ErrorIs(t, err, ErrInvalidRole, mapping)
+	}
+}
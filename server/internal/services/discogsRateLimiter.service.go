@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/metrics"
 
 	"github.com/google/uuid"
 	"github.com/valkey-io/valkey-go"
@@ -277,3 +279,42 @@ func (d *DiscogsRateLimiterService) GetUserRateLimitStatus(
 	return int(count), DISCOGS_RATE_LIMIT, nil
 }
 
+// Usage sums up the rate limits of every user with requests in the current window, users without
+// requests have no tracker key left
+func (d *DiscogsRateLimiterService) Usage(ctx context.Context) (metrics.RateLimitUsage, error) {
+	var usage metrics.RateLimitUsage
+	pattern := fmt.Sprintf(DISCOGS_RATE_LIMIT_HASH, "*")
+
+	var cursor uint64
+	for {
+		entry, err := d.cache.Do(ctx, d.cache.B().Scan().Cursor(cursor).Match(pattern).Count(100).Build()).
+			AsScanEntry()
+		if err != nil {
+			return usage, err
+		}
+
+		for _, key := range entry.Elements {
+			userID, err := uuid.Parse(strings.TrimPrefix(key, fmt.Sprintf(DISCOGS_RATE_LIMIT_HASH, "")))
+			if err != nil {
+				continue
+			}
+
+			count, limit, err := d.GetUserRateLimitStatus(ctx, userID)
+			if err != nil {
+				return usage, err
+			}
+			if count == 0 {
+				continue
+			}
+
+			usage.ActiveUsers++
+			usage.Requests += count
+			usage.MaxUtilization = max(usage.MaxUtilization, float64(count)/float64(limit))
+		}
+
+		cursor = entry.Cursor
+		if cursor == 0 {
+			return usage, nil
+		}
+	}
+}
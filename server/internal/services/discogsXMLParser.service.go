@@ -5,13 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"waugzee/config"
 	"waugzee/internal/database"
 	"waugzee/internal/events"
 	logger "github.com/Bparsons0904/goLogger"
@@ -19,7 +20,6 @@ import (
 	"waugzee/internal/repositories"
 	"waugzee/internal/types"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -28,18 +28,28 @@ type DiscogsXMLParserService struct {
 	repos    repositories.Repository
 	db       database.DB
 	eventBus *events.EventBus
+	storage  DumpStorage
+	pipeline IngestionPipelineConfig
 }
 
 func NewDiscogsXMLParserService(
 	repos repositories.Repository,
 	db database.DB,
 	eventBus *events.EventBus,
+	storage DumpStorage,
+	config config.Config,
 ) *DiscogsXMLParserService {
 	return &DiscogsXMLParserService{
 		log:      logger.New("discogsXMLParser"),
 		repos:    repos,
 		db:       db,
 		eventBus: eventBus,
+		storage:  storage,
+		pipeline: IngestionPipelineConfig{
+			ConverterWorkers: config.DiscogsConverterWorkers,
+			BatchWriters:     config.DiscogsBatchWriters,
+			MaxFailureRatio:  config.DiscogsMaxFailureRatio,
+		},
 	}
 }
 
@@ -62,27 +72,19 @@ func (s *DiscogsXMLParserService) BroadcastProgress(
 		percentage = (float64(filesProcessed) / float64(totalFiles)) * 100
 	}
 
-	progressEvent := map[string]any{
-		"yearMonth":      yearMonth,
-		"status":         status,
-		"fileType":       fileType,
-		"step":           step,
-		"stage":          stage,
-		"filesProcessed": filesProcessed,
-		"totalFiles":     totalFiles,
-		"percentage":     percentage,
-		"errorMessage":   errorMessage,
-	}
-
-	message := events.Message{
-		ID:        uuid.New().String(),
-		Service:   events.SYSTEM,
-		Event:     string(events.ADMIN_PROCESSING_PROGRESS),
-		Payload:   progressEvent,
-		Timestamp: time.Now(),
+	progress := events.AdminProcessingProgress{
+		YearMonth:      yearMonth,
+		Status:         status,
+		FileType:       fileType,
+		Step:           step,
+		Stage:          stage,
+		FilesProcessed: filesProcessed,
+		TotalFiles:     totalFiles,
+		Percentage:     percentage,
+		ErrorMessage:   errorMessage,
 	}
 
-	if err := s.eventBus.Publish(events.WEBSOCKET, "admin", message); err != nil {
+	if err := s.eventBus.PublishToAdmins(events.DumpTopic(yearMonth), progress); err != nil {
 		s.log.Warn("Failed to publish admin processing progress", "error", err)
 	}
 }
@@ -130,16 +132,39 @@ const (
 
 // EntityProcessorConfig holds configuration for generic entity processing
 type EntityProcessorConfig[XMLType any, TModelType any] struct {
-	FilePath       string
+	Step           models.ProcessingStep
+	FileKey        string
 	ElementName    string
 	EntityTypeName string
 	ChannelSize    int
 	BatchSize      int
 	ConvertFunc    func(XMLType) *TModelType
 	UpsertFunc     func(ctx context.Context, db *gorm.DB, entities []*TModelType) error
+	// ConverterWorkers and BatchWriters override the service pipeline sizing for this step
+	ConverterWorkers int
+	BatchWriters     int
+	// ChangeDetectionTable enables hash-based change detection against the given table.
+	// Only new or changed entities are upserted and entities missing from the dump are tombstoned.
+	ChangeDetectionTable string
+	// Checkpointer persists progress after each batch and resumes an interrupted step
+	Checkpointer *stepCheckpointer
+}
+
+// EntityProcessingResult summarises a completed processing step
+type EntityProcessingResult struct {
+	Processed  int64
+	Failed     int64
+	Changes    *models.ChangeCounts
+	Throughput map[string]*models.StageThroughput
 }
 
-// ProcessXMLEntities is a generic function that processes XML entities with configurable conversion and batch operations
+// ErrFailureThresholdExceeded marks a step that dead-lettered more entities than the pipeline allows
+var ErrFailureThresholdExceeded = errors.New("dump step failure ratio exceeded threshold")
+
+// ProcessXMLEntities streams a dump file through a pipeline of one parser, N converter workers and
+// M batch writers connected by bounded channels, so a slow stage applies backpressure upstream
+// instead of buffering the file in memory. Batches may be written out of order; checkpoints only
+// advance over the contiguous prefix of written batches.
 func ProcessXMLEntities[XMLType any, TModelType any](
 	ctx context.Context,
 	config EntityProcessorConfig[XMLType, TModelType],
@@ -149,76 +174,302 @@ func ProcessXMLEntities[XMLType any, TModelType any](
 	yearMonth string,
 	stepName string,
 	totalFiles int64,
-) error {
+) (*EntityProcessingResult, error) {
 	processingLog := log.Function("ProcessXMLEntities").With("entityType", config.EntityTypeName)
+	stepStart := time.Now()
+
+	// A writer that can not record its failed entities aborts the step, the cause is returned
+	ctx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+
+	var detector *dumpChangeDetector[TModelType]
+	if config.ChangeDetectionTable != "" {
+		var err error
+		detector, err = newDumpChangeDetector[TModelType](config.ChangeDetectionTable, service.repos.DumpEntity)
+		if err != nil {
+			return nil, processingLog.Err("failed to create change detector", err)
+		}
+	}
+
+	var resumeFrom int64
+	var resumePosition commitPosition
+	if config.Checkpointer != nil && config.Checkpointer.resume != nil {
+		resumeFrom = config.Checkpointer.resume.ElementsCommitted
+		resumePosition.Failed = config.Checkpointer.resume.Failed
+		if config.Checkpointer.resume.Changes != nil {
+			resumePosition.Changes = *config.Checkpointer.resume.Changes
+		}
+		processingLog.Info("Resuming from checkpoint", "elementsCommitted", resumeFrom)
+	}
+
+	// The change detector needs every ID for tombstoning, so committed elements are
+	// decoded and marked as seen instead of being skipped by the parser
+	parserSkip := resumeFrom
+	if detector != nil {
+		parserSkip = 0
+	}
+
+	converterWorkers, batchWriters := service.pipeline.resolve(config.ConverterWorkers, config.BatchWriters)
+	meters := newPipelineMeters(config.EntityTypeName, converterWorkers, batchWriters)
+	resumePosition.Watermark = parserSkip
+	tracker := newCommitTracker(resumePosition)
+
+	processingLog.Info("Starting ingestion pipeline",
+		"converterWorkers", converterWorkers,
+		"batchWriters", batchWriters,
+		"batchSize", config.BatchSize,
+	)
 
 	xmlChan := make(chan XMLType, config.ChannelSize)
+	chunkChan := make(chan pipelineChunk[XMLType], converterWorkers)
+	batchChan := make(chan pipelineChunk[*TModelType], batchWriters)
 
+	// Stage 1: parse. A single decoder feeds xmlChan; a chunker numbers elements and groups them
+	// into batches. Time spent blocked on a full chunkChan is backpressure, not parser work.
+	var parseErr error
 	go func() {
 		defer close(xmlChan)
-		err := ParseXMLGeneric(
+		parseErr = ParseXMLGenericFrom(
 			ctx,
-			config.FilePath,
+			service.storage,
+			config.FileKey,
 			config.ElementName,
 			xmlChan,
+			parserSkip,
 			0, // No limit = 0
-			// 50_000,
 			processingLog,
 		)
-		if err != nil {
-			processingLog.Er("Failed to parse XML", err)
+		if parseErr != nil {
+			processingLog.Er("Failed to parse XML", parseErr)
 		}
 		processingLog.Info("XML parsing goroutine completed")
 	}()
 
-	processedCount := 0
-	var entities []*TModelType
-	lastBroadcastTime := time.Now()
-	broadcastInterval := 10 * time.Second
+	go func() {
+		defer close(chunkChan)
+
+		started := time.Now()
+		var blocked time.Duration
+		send := func(chunk pipelineChunk[XMLType]) {
+			sendStarted := time.Now()
+			chunkChan <- chunk
+			blocked += time.Since(sendStarted)
+		}
 
-	service.BroadcastProgress(yearMonth, "processing", config.EntityTypeName, stepName, "in_progress", 0, totalFiles, nil)
+		sequence := parserSkip
+		chunk := pipelineChunk[XMLType]{start: sequence}
+		for xmlEntity := range xmlChan {
+			chunk.items = append(chunk.items, xmlEntity)
+			sequence++
 
-	for xmlEntity := range xmlChan {
-		processedCount++
+			if len(chunk.items) >= config.BatchSize {
+				chunk.end = sequence
+				send(chunk)
+				chunk = pipelineChunk[XMLType]{start: sequence}
+			}
+		}
+		if len(chunk.items) > 0 {
+			chunk.end = sequence
+			send(chunk)
+		}
 
-		modelEntity := config.ConvertFunc(xmlEntity)
-		entities = append(entities, modelEntity)
+		meters.parse.add(sequence-parserSkip, time.Since(started)-blocked)
+	}()
 
-		if len(entities) >= config.BatchSize {
-			if err := config.UpsertFunc(ctx, db.SQLWithContext(ctx), entities); err != nil {
-				processingLog.Er(
-					"Failed to upsert batch",
-					err,
-					"batchSize",
-					len(entities),
-				)
-			} else {
-				processingLog.Info("Processed batch", "batchSize", len(entities), "totalProcessed", processedCount)
+	// Stage 2: convert
+	var convertWG sync.WaitGroup
+	for range converterWorkers {
+		convertWG.Add(1)
+		go func() {
+			defer convertWG.Done()
+			for chunk := range chunkChan {
+				started := time.Now()
+				batch := pipelineChunk[*TModelType]{
+					start: chunk.start,
+					end:   chunk.end,
+					items: make([]*TModelType, 0, len(chunk.items)),
+				}
+				for i, xmlEntity := range chunk.items {
+					modelEntity := config.ConvertFunc(xmlEntity)
+					if chunk.start+int64(i) < resumeFrom {
+						detector.MarkSeen(modelEntity)
+						continue
+					}
+					batch.items = append(batch.items, modelEntity)
+				}
+				meters.convert.record(len(chunk.items), started)
+				batchChan <- batch
 			}
-			entities = []*TModelType{}
+		}()
+	}
+	go func() {
+		convertWG.Wait()
+		close(batchChan)
+	}()
+
+	// Stage 3: write
+	var saveMu sync.Mutex
+	lastSaved := resumeFrom
+	saveCheckpoint := func(position commitPosition) {
+		if config.Checkpointer == nil {
+			return
 		}
 
-		now := time.Now()
-		if now.Sub(lastBroadcastTime) >= broadcastInterval {
-			service.BroadcastProgress(yearMonth, "processing", config.EntityTypeName, stepName, "in_progress", int64(processedCount), totalFiles, nil)
-			lastBroadcastTime = now
+		saveMu.Lock()
+		defer saveMu.Unlock()
+
+		// Writers can finish saves out of order, and a change-detection resume replays the
+		// committed prefix, so never move a checkpoint backwards
+		if position.Watermark <= lastSaved {
+			return
+		}
+		lastSaved = position.Watermark
+
+		checkpoint := models.StepCheckpoint{
+			ElementsCommitted: position.Watermark,
+			Failed:            position.Failed,
+		}
+		if detector != nil {
+			checkpoint.Changes = &position.Changes
 		}
+		config.Checkpointer.save(checkpoint)
 	}
 
-	if len(entities) > 0 {
-		if err := config.UpsertFunc(ctx, db.SQLWithContext(ctx), entities); err != nil {
-			processingLog.Er(
-				"Failed to upsert final batch",
-				err,
-				"batchSize",
-				len(entities),
-			)
-		} else {
-			processingLog.Info("Processed final batch", "batchSize", len(entities), "totalProcessed", processedCount)
+	writeBatch := func(batch []*TModelType) (models.ChangeCounts, error) {
+		var changes models.ChangeCounts
+		if len(batch) == 0 {
+			return changes, nil
+		}
+
+		if detector != nil {
+			var err error
+			batch, changes, err = detector.Filter(ctx, db.SQLWithContext(ctx), batch)
+			if err != nil {
+				return changes, err
+			}
+			if len(batch) == 0 {
+				return changes, nil
+			}
+		}
+		return changes, config.UpsertFunc(ctx, db.SQLWithContext(ctx), batch)
+	}
+
+	var writeWG sync.WaitGroup
+	for range batchWriters {
+		writeWG.Add(1)
+		go func() {
+			defer writeWG.Done()
+			for batch := range batchChan {
+				started := time.Now()
+				changes, failed := writeWithBisect(ctx, batch.items, writeBatch)
+				meters.write.record(int(batch.end-batch.start), started)
+
+				// Leave the batch uncommitted so a resumed run writes it again
+				if ctx.Err() != nil {
+					continue
+				}
+
+				if len(failed) > 0 {
+					processingLog.Warn("Isolated entities that could not be written",
+						"failed", len(failed),
+						"batchSize", len(batch.items),
+						"batchStart", batch.start,
+					)
+					// Committing the batch would move the checkpoint past entities that were neither
+					// written nor recorded, so a resumed run would never retry them
+					err := deadLetterEntities(ctx, service, yearMonth, config.Step, config.EntityTypeName, failed)
+					if err != nil {
+						abort(err)
+						continue
+					}
+				}
+
+				outcome := batchOutcome{changes: changes, failed: int64(len(failed))}
+				if position, advanced := tracker.Commit(batch.start, batch.end, outcome); advanced {
+					saveCheckpoint(position)
+				}
+			}
+		}()
+	}
+
+	writersDone := make(chan struct{})
+	go func() {
+		writeWG.Wait()
+		close(writersDone)
+	}()
+
+	service.BroadcastProgress(yearMonth, "processing", config.EntityTypeName, stepName, "in_progress", parserSkip, totalFiles, nil)
+
+	broadcastTicker := time.NewTicker(10 * time.Second)
+	defer broadcastTicker.Stop()
+
+waitForWriters:
+	for {
+		select {
+		case <-writersDone:
+			break waitForWriters
+		case <-broadcastTicker.C:
+			position := tracker.Position()
+			service.BroadcastProgress(yearMonth, "processing", config.EntityTypeName, stepName, "in_progress", position.Watermark, totalFiles, nil)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, processingLog.Err("entity processing cancelled", context.Cause(ctx))
+	}
+
+	// A parse that stopped partway leaves the rest of the file unprocessed, and would tombstone
+	// everything after the failure point with change detection
+	if parseErr != nil {
+		return nil, processingLog.Err("XML parsing did not complete", parseErr)
+	}
+
+	position := tracker.Position()
+	processedCount := position.Watermark
+	result := &EntityProcessingResult{
+		Processed:  processedCount,
+		Failed:     position.Failed,
+		Throughput: meters.throughput(time.Since(stepStart)),
+	}
+
+	// A step with too many dead letters is failed before tombstoning, since its view of the dump is incomplete
+	if service.pipeline.failureRatioExceeded(position.Failed, processedCount) {
+		return result, processingLog.Err(
+			"too many entities failed to write",
+			fmt.Errorf("%w: %d of %d failed", ErrFailureThresholdExceeded, position.Failed, processedCount),
+			"failed", position.Failed,
+			"processed", processedCount,
+		)
+	}
+
+	if detector != nil {
+		removed, err := detector.TombstoneMissing(ctx, db.SQLWithContext(ctx))
+		if err != nil {
+			return nil, processingLog.Err("failed to tombstone missing entities", err)
 		}
+
+		changes := position.Changes
+		changes.Removed = removed
+		result.Changes = &changes
+		processingLog.Info("Change detection completed",
+			"inserted", result.Changes.Inserted,
+			"updated", result.Changes.Updated,
+			"unchanged", result.Changes.Unchanged,
+			"removed", result.Changes.Removed,
+		)
 	}
 
-	service.BroadcastProgress(yearMonth, "completed", config.EntityTypeName, stepName, "completed", int64(processedCount), totalFiles, nil)
+	for stage, throughput := range result.Throughput {
+		processingLog.Info("Pipeline stage throughput",
+			"stage", stage,
+			"workers", throughput.Workers,
+			"items", throughput.Items,
+			"itemsPerSecond", throughput.ItemsPerSecond,
+			"utilization", throughput.Utilization,
+		)
+	}
+
+	service.BroadcastProgress(yearMonth, "completed", config.EntityTypeName, stepName, "completed", processedCount, totalFiles, nil)
 
 	processingLog.Info(
 		"Entity processing completed",
@@ -227,7 +478,7 @@ func ProcessXMLEntities[XMLType any, TModelType any](
 		"totalProcessed",
 		processedCount,
 	)
-	return nil
+	return result, nil
 }
 
 // convertXMLLabelToModel converts XML Label to database Label model
@@ -591,6 +842,12 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		return nil
 	}
 
+	// The record may be for another month, e.g. a dump registered through the import endpoint
+	if processing.YearMonth != yearMonth {
+		log.Info("Processing queued year-month", "yearMonth", processing.YearMonth, "currentYearMonth", yearMonth)
+		yearMonth = processing.YearMonth
+	}
+
 	// Validate processing status
 	if processing.Status != models.ProcessingStatusReadyForProcessing &&
 		processing.Status != models.ProcessingStatusProcessing {
@@ -606,30 +863,33 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 
 	// Update status to processing if not already
 	if processing.Status != models.ProcessingStatusProcessing {
-		processing.Status = models.ProcessingStatusProcessing
+		if err = processing.UpdateStatus(models.ProcessingStatusProcessing, "processing started"); err != nil {
+			return log.Err("failed to transition to processing status", err)
+		}
 		processing.StartedAt = &now
 		if err = s.repos.DiscogsDataProcessing.Update(ctx, processing); err != nil {
 			return log.Err("failed to update processing status", err)
 		}
 	}
 
-	downloadDir := fmt.Sprintf("%s/%s", DiscogsDataDir, yearMonth)
-	if err = ensureDirectory(downloadDir, log); err != nil {
-		return log.Err("failed to create download directory", err, "directory", downloadDir)
-	}
+	// Keep the watchdog from failing the record while processing is running
+	stopHeartbeat := StartProcessingHeartbeat(ctx, s.repos.DiscogsDataProcessing, processing.ID)
+	defer stopHeartbeat()
 
 	// Validate all required files exist before starting processing
 	requiredFiles := map[string]string{
-		"labels":   filepath.Join(downloadDir, "labels.xml.gz"),
-		"artists":  filepath.Join(downloadDir, "artists.xml.gz"),
-		"masters":  filepath.Join(downloadDir, "masters.xml.gz"),
-		"releases": filepath.Join(downloadDir, "releases.xml.gz"),
+		"labels":   DumpFileKey(yearMonth, "labels"),
+		"artists":  DumpFileKey(yearMonth, "artists"),
+		"masters":  DumpFileKey(yearMonth, "masters"),
+		"releases": DumpFileKey(yearMonth, "releases"),
 	}
 
-	for fileType, filePath := range requiredFiles {
-		if _, err = os.Stat(filePath); os.IsNotExist(err) {
+	for fileType, fileKey := range requiredFiles {
+		if _, err = s.storage.Stat(ctx, fileKey); errors.Is(err, ErrDumpObjectNotFound) {
 			errorMsg := fmt.Sprintf("required file not found: %s", fileType)
-			processing.Status = models.ProcessingStatusFailed
+			if statusErr := processing.UpdateStatus(models.ProcessingStatusFailed, errorMsg); statusErr != nil {
+				log.Warn("failed to transition to failed status", "error", statusErr)
+			}
 			processing.ErrorMessage = &errorMsg
 			if updateErr := s.repos.DiscogsDataProcessing.Update(ctx, processing); updateErr != nil {
 				log.Warn("failed to update processing status to failed", "error", updateErr)
@@ -638,7 +898,7 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 				"required XML file does not exist",
 				err,
 				"fileType", fileType,
-				"filePath", filePath,
+				"location", s.storage.Location(fileKey),
 				"yearMonth", yearMonth,
 			)
 		}
@@ -659,21 +919,24 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 	}
 
 	// Process labels using the abstracted entity processor
-	labelsFilePath := filepath.Join(downloadDir, "labels.xml.gz")
+	labelsFileKey := DumpFileKey(yearMonth, "labels")
 	err = s.executeProcessingStep(
 		ctx,
 		processing,
 		models.StepLabelsProcessing,
 		"Labels Processing",
-		func() error {
+		func() (*EntityProcessingResult, error) {
 			labelsConfig := EntityProcessorConfig[types.Label, models.Label]{
-				FilePath:       labelsFilePath,
-				ElementName:    "label",
-				EntityTypeName: "labels",
-				ChannelSize:    5000,
-				BatchSize:      5000,
-				ConvertFunc:    s.convertXMLLabelToModel,
-				UpsertFunc:     s.repos.Label.UpsertBatch,
+				Step:                 models.StepLabelsProcessing,
+				FileKey:              labelsFileKey,
+				ElementName:          "label",
+				EntityTypeName:       "labels",
+				ChannelSize:          5000,
+				BatchSize:            5000,
+				ConvertFunc:          s.convertXMLLabelToModel,
+				UpsertFunc:           s.repos.Label.CopyMergeBatch,
+				ChangeDetectionTable: repositories.DumpTableLabels,
+				Checkpointer:         s.newStepCheckpointer(ctx, processing, models.StepLabelsProcessing, labelsFileKey),
 			}
 			return ProcessXMLEntities(ctx, labelsConfig, s.db, log, s, yearMonth, "Labels Processing", dbCounts["labels"])
 		},
@@ -683,21 +946,24 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 	}
 
 	// Process artists using the abstracted entity processor
-	artistsFilePath := filepath.Join(downloadDir, "artists.xml.gz")
+	artistsFileKey := DumpFileKey(yearMonth, "artists")
 	err = s.executeProcessingStep(
 		ctx,
 		processing,
 		models.StepArtistsProcessing,
 		"Artists Processing",
-		func() error {
+		func() (*EntityProcessingResult, error) {
 			artistsConfig := EntityProcessorConfig[types.Artist, models.Artist]{
-				FilePath:       artistsFilePath,
-				ElementName:    "artist",
-				EntityTypeName: "artists",
-				ChannelSize:    5000,
-				BatchSize:      2500,
-				ConvertFunc:    s.convertXMLArtistToModel,
-				UpsertFunc:     s.repos.Artist.UpsertBatch,
+				Step:                 models.StepArtistsProcessing,
+				FileKey:              artistsFileKey,
+				ElementName:          "artist",
+				EntityTypeName:       "artists",
+				ChannelSize:          5000,
+				BatchSize:            2500,
+				ConvertFunc:          s.convertXMLArtistToModel,
+				UpsertFunc:           s.repos.Artist.CopyMergeBatch,
+				ChangeDetectionTable: repositories.DumpTableArtists,
+				Checkpointer:         s.newStepCheckpointer(ctx, processing, models.StepArtistsProcessing, artistsFileKey),
 			}
 			return ProcessXMLEntities(ctx, artistsConfig, s.db, log, s, yearMonth, "Artists Processing", dbCounts["artists"])
 		},
@@ -707,21 +973,24 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 	}
 
 	// Process masters using the abstracted entity processor
-	mastersFilePath := filepath.Join(downloadDir, "masters.xml.gz")
+	mastersFileKey := DumpFileKey(yearMonth, "masters")
 	err = s.executeProcessingStep(
 		ctx,
 		processing,
 		models.StepMastersProcessing,
 		"Masters Processing",
-		func() error {
+		func() (*EntityProcessingResult, error) {
 			mastersConfig := EntityProcessorConfig[types.Master, models.Master]{
-				FilePath:       mastersFilePath,
-				ElementName:    "master",
-				EntityTypeName: "masters",
-				ChannelSize:    5000,
-				BatchSize:      5000,
-				ConvertFunc:    s.convertXMLMasterToModel,
-				UpsertFunc:     s.repos.Master.UpsertBatch,
+				Step:                 models.StepMastersProcessing,
+				FileKey:              mastersFileKey,
+				ElementName:          "master",
+				EntityTypeName:       "masters",
+				ChannelSize:          5000,
+				BatchSize:            5000,
+				ConvertFunc:          s.convertXMLMasterToModel,
+				UpsertFunc:           s.repos.Master.CopyMergeBatch,
+				ChangeDetectionTable: repositories.DumpTableMasters,
+				Checkpointer:         s.newStepCheckpointer(ctx, processing, models.StepMastersProcessing, mastersFileKey),
 			}
 			return ProcessXMLEntities(ctx, mastersConfig, s.db, log, s, yearMonth, "Masters Processing", dbCounts["masters"])
 		},
@@ -731,21 +1000,24 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 	}
 
 	// Process releases using the abstracted entity processor
-	releasesFilePath := filepath.Join(downloadDir, "releases.xml.gz")
+	releasesFileKey := DumpFileKey(yearMonth, "releases")
 	err = s.executeProcessingStep(
 		ctx,
 		processing,
 		models.StepReleasesProcessing,
 		"Releases Processing",
-		func() error {
+		func() (*EntityProcessingResult, error) {
 			releasesConfig := EntityProcessorConfig[types.Release, models.Release]{
-				FilePath:       releasesFilePath,
-				ElementName:    "release",
-				EntityTypeName: "releases",
-				ChannelSize:    5000,
-				BatchSize:      2500, // Smaller batch size for releases due to more complex data
-				ConvertFunc:    s.convertXMLReleaseToModel,
-				UpsertFunc:     s.repos.Release.UpsertBatch,
+				Step:                 models.StepReleasesProcessing,
+				FileKey:              releasesFileKey,
+				ElementName:          "release",
+				EntityTypeName:       "releases",
+				ChannelSize:          5000,
+				BatchSize:            2500, // Smaller batch size for releases due to more complex data
+				ConvertFunc:          s.convertXMLReleaseToModel,
+				UpsertFunc:           s.repos.Release.CopyMergeBatch,
+				ChangeDetectionTable: repositories.DumpTableReleases,
+				Checkpointer:         s.newStepCheckpointer(ctx, processing, models.StepReleasesProcessing, releasesFileKey),
 			}
 			return ProcessXMLEntities(ctx, releasesConfig, s.db, log, s, yearMonth, "Releases Processing", dbCounts["releases"])
 		},
@@ -766,8 +1038,8 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepMasterGenresCollection,
 		"Master Genres Collection",
-		func() error {
-			return s.collectGenresFromXML(ctx, mastersFilePath, "master", genreManager, log, yearMonth, dbCounts["masters"])
+		func() (*EntityProcessingResult, error) {
+			return nil, s.collectGenresFromXML(ctx, mastersFileKey, "master", genreManager, log, yearMonth, dbCounts["masters"])
 		},
 	)
 	if err != nil {
@@ -779,8 +1051,8 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepMasterGenresUpsert,
 		"Master Genres Upsert",
-		func() error {
-			return genreManager.BatchUpsertMissingGenres(ctx, s.db.SQLWithContext(ctx))
+		func() (*EntityProcessingResult, error) {
+			return nil, genreManager.BatchUpsertMissingGenres(ctx, s.db.SQLWithContext(ctx))
 		},
 	)
 	if err != nil {
@@ -792,9 +1064,14 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepMasterGenreAssociations,
 		"Master Genre Associations",
-		func() error {
+		func() (*EntityProcessingResult, error) {
+			if err := genreManager.EnsureLoaded(ctx, s.db.SQLWithContext(ctx)); err != nil {
+				return nil, err
+			}
+
 			masterGenreConfig := EntityProcessorConfig[types.Master, []repositories.MasterGenreAssociation]{
-				FilePath:       mastersFilePath,
+				Step:           models.StepMasterGenreAssociations,
+				FileKey:        mastersFileKey,
 				ElementName:    "master",
 				EntityTypeName: "master-genre-associations",
 				ChannelSize:    5000,
@@ -802,7 +1079,8 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 				ConvertFunc: func(xmlMaster types.Master) *[]repositories.MasterGenreAssociation {
 					return s.convertMasterToGenreAssociations(xmlMaster, genreManager)
 				},
-				UpsertFunc: s.repos.Master.UpsertMasterGenreAssociationsBatch,
+				UpsertFunc:   s.repos.Master.UpsertMasterGenreAssociationsBatch,
+				Checkpointer: s.newStepCheckpointer(ctx, processing, models.StepMasterGenreAssociations, mastersFileKey),
 			}
 			return ProcessXMLEntities(ctx, masterGenreConfig, s.db, log, s, yearMonth, "Master Genre Associations", dbCounts["masters"])
 		},
@@ -817,9 +1095,9 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepReleaseGenresCollection,
 		"Release Genres Collection",
-		func() error {
+		func() (*EntityProcessingResult, error) {
 			genreManager.Reset()
-			return s.collectGenresFromXML(ctx, releasesFilePath, "release", genreManager, log, yearMonth, dbCounts["releases"])
+			return nil, s.collectGenresFromXML(ctx, releasesFileKey, "release", genreManager, log, yearMonth, dbCounts["releases"])
 		},
 	)
 	if err != nil {
@@ -831,8 +1109,8 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepReleaseGenresUpsert,
 		"Release Genres Upsert",
-		func() error {
-			return genreManager.BatchUpsertMissingGenres(ctx, s.db.SQLWithContext(ctx))
+		func() (*EntityProcessingResult, error) {
+			return nil, genreManager.BatchUpsertMissingGenres(ctx, s.db.SQLWithContext(ctx))
 		},
 	)
 	if err != nil {
@@ -844,9 +1122,14 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepReleaseGenreAssociations,
 		"Release Genre Associations",
-		func() error {
+		func() (*EntityProcessingResult, error) {
+			if err := genreManager.EnsureLoaded(ctx, s.db.SQLWithContext(ctx)); err != nil {
+				return nil, err
+			}
+
 			releaseGenreConfig := EntityProcessorConfig[types.Release, []repositories.ReleaseGenreAssociation]{
-				FilePath:       releasesFilePath,
+				Step:           models.StepReleaseGenreAssociations,
+				FileKey:        releasesFileKey,
 				ElementName:    "release",
 				EntityTypeName: "release-genre-associations",
 				ChannelSize:    5000,
@@ -854,7 +1137,8 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 				ConvertFunc: func(xmlRelease types.Release) *[]repositories.ReleaseGenreAssociation {
 					return s.convertReleaseToGenreAssociations(xmlRelease, genreManager)
 				},
-				UpsertFunc: s.repos.Release.UpsertReleaseGenreAssociationsBatch,
+				UpsertFunc:   s.repos.Release.UpsertReleaseGenreAssociationsBatch,
+				Checkpointer: s.newStepCheckpointer(ctx, processing, models.StepReleaseGenreAssociations, releasesFileKey),
 			}
 			return ProcessXMLEntities(ctx, releaseGenreConfig, s.db, log, s, yearMonth, "Release Genre Associations", dbCounts["releases"])
 		},
@@ -871,15 +1155,17 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepReleaseLabelAssociations,
 		"Release Label Associations",
-		func() error {
+		func() (*EntityProcessingResult, error) {
 			releaseLabelConfig := EntityProcessorConfig[types.Release, []repositories.ReleaseLabelAssociation]{
-				FilePath:       releasesFilePath,
+				Step:           models.StepReleaseLabelAssociations,
+				FileKey:        releasesFileKey,
 				ElementName:    "release",
 				EntityTypeName: "release-label-associations",
 				ChannelSize:    5000,
 				BatchSize:      5000,
 				ConvertFunc:    s.convertReleaseToLabelAssociations,
 				UpsertFunc:     s.repos.Release.UpsertReleaseLabelAssociationsBatch,
+				Checkpointer:   s.newStepCheckpointer(ctx, processing, models.StepReleaseLabelAssociations, releasesFileKey),
 			}
 			return ProcessXMLEntities(ctx, releaseLabelConfig, s.db, log, s, yearMonth, "Release Label Associations", dbCounts["releases"])
 		},
@@ -893,15 +1179,17 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepMasterArtistAssociations,
 		"Master Artist Associations",
-		func() error {
+		func() (*EntityProcessingResult, error) {
 			masterArtistConfig := EntityProcessorConfig[types.Master, []repositories.MasterArtistAssociation]{
-				FilePath:       mastersFilePath,
+				Step:           models.StepMasterArtistAssociations,
+				FileKey:        mastersFileKey,
 				ElementName:    "master",
 				EntityTypeName: "master-artist-associations",
 				ChannelSize:    5000,
 				BatchSize:      5000,
 				ConvertFunc:    s.convertMasterToArtistAssociations,
 				UpsertFunc:     s.repos.Master.UpsertMasterArtistAssociationsBatch,
+				Checkpointer:   s.newStepCheckpointer(ctx, processing, models.StepMasterArtistAssociations, mastersFileKey),
 			}
 			return ProcessXMLEntities(ctx, masterArtistConfig, s.db, log, s, yearMonth, "Master Artist Associations", dbCounts["masters"])
 		},
@@ -915,15 +1203,17 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 		processing,
 		models.StepReleaseArtistAssociations,
 		"Release Artist Associations",
-		func() error {
+		func() (*EntityProcessingResult, error) {
 			releaseArtistConfig := EntityProcessorConfig[types.Release, []repositories.ReleaseArtistAssociation]{
-				FilePath:       releasesFilePath,
+				Step:           models.StepReleaseArtistAssociations,
+				FileKey:        releasesFileKey,
 				ElementName:    "release",
 				EntityTypeName: "release-artist-associations",
 				ChannelSize:    5000,
 				BatchSize:      5000,
 				ConvertFunc:    s.convertReleaseToArtistAssociations,
 				UpsertFunc:     s.repos.Release.UpsertReleaseArtistAssociationsBatch,
+				Checkpointer:   s.newStepCheckpointer(ctx, processing, models.StepReleaseArtistAssociations, releasesFileKey),
 			}
 			return ProcessXMLEntities(ctx, releaseArtistConfig, s.db, log, s, yearMonth, "Release Artist Associations", dbCounts["releases"])
 		},
@@ -933,7 +1223,17 @@ func (s *DiscogsXMLParserService) ParseXMLFiles(ctx context.Context) error {
 	}
 
 	// Mark processing as completed
-	processing.Status = models.ProcessingStatusCompleted
+	if err := processing.UpdateStatus(models.ProcessingStatusCompleted, "all processing steps completed"); err != nil {
+		return log.Err("failed to transition to completed status", err)
+	}
+
+	// Snapshot the entity counts so the dashboard can show month over month changes
+	if counts, err := s.GetDatabaseCounts(ctx); err != nil {
+		log.Warn("failed to snapshot database counts", "error", err)
+	} else {
+		processing.InitializeProcessingStats()
+		processing.ProcessingStats.EntityCounts = counts
+	}
 	completedAt := time.Now().UTC()
 	processing.ProcessingCompletedAt = &completedAt
 
@@ -958,8 +1258,17 @@ func (s *DiscogsXMLParserService) getOrCreateProcessingRecord(
 ) (*models.DiscogsDataProcessing, error) {
 	log := s.log.Function("getOrCreateProcessingRecord")
 
+	// A record queued for processing wins, whichever year-month it is for
+	processing, err := s.repos.DiscogsDataProcessing.GetNextQueued(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get next queued processing record", err)
+	}
+	if processing != nil {
+		return processing, nil
+	}
+
 	// Try to get existing record
-	processing, err := s.repos.DiscogsDataProcessing.GetByYearMonth(ctx, yearMonth)
+	processing, err = s.repos.DiscogsDataProcessing.GetByYearMonth(ctx, yearMonth)
 	if err == nil {
 		return processing, nil
 	}
@@ -986,7 +1295,7 @@ func (s *DiscogsXMLParserService) executeProcessingStep(
 	processing *models.DiscogsDataProcessing,
 	step models.ProcessingStep,
 	stepName string,
-	stepFunc func() error,
+	stepFunc func() (*EntityProcessingResult, error),
 ) error {
 	log := s.log.Function("executeProcessingStep").With("step", step, "stepName", stepName)
 
@@ -1000,7 +1309,7 @@ func (s *DiscogsXMLParserService) executeProcessingStep(
 	startTime := time.Now()
 
 	// Execute the step
-	err := stepFunc()
+	result, err := stepFunc()
 
 	// Calculate duration
 	duration := time.Since(startTime)
@@ -1008,7 +1317,14 @@ func (s *DiscogsXMLParserService) executeProcessingStep(
 
 	// Update step status based on result
 	if err != nil {
+		// A step over its failure threshold restarts from scratch once the cause has been fixed
+		if errors.Is(err, ErrFailureThresholdExceeded) {
+			processing.SetStepCheckpoint(step, nil)
+		}
 		processing.MarkStepFailed(step, err.Error())
+		if result != nil && result.Failed > 0 {
+			processing.SetStepFailures(step, result.Failed)
+		}
 		if updateErr := s.repos.DiscogsDataProcessing.Update(ctx, processing); updateErr != nil {
 			log.Er("Failed to update step status after failure", updateErr)
 		}
@@ -1016,7 +1332,20 @@ func (s *DiscogsXMLParserService) executeProcessingStep(
 	}
 
 	// Mark step as completed
-	processing.MarkStepCompleted(step, nil, &durationStr)
+	var recordsCount *int64
+	if result != nil {
+		recordsCount = &result.Processed
+	}
+	processing.MarkStepCompleted(step, recordsCount, &durationStr)
+	if result != nil && result.Changes != nil {
+		processing.SetStepChanges(step, result.Changes)
+	}
+	if result != nil && result.Throughput != nil {
+		processing.SetStepThroughput(step, result.Throughput)
+	}
+	if result != nil && result.Failed > 0 {
+		processing.SetStepFailures(step, result.Failed)
+	}
 	if err := s.repos.DiscogsDataProcessing.Update(ctx, processing); err != nil {
 		return log.Err("failed to update step completion status", err, "step", step)
 	}
@@ -1025,10 +1354,53 @@ func (s *DiscogsXMLParserService) executeProcessingStep(
 	return nil
 }
 
+// stepCheckpointer persists the committed position of a processing step on the processing record
+type stepCheckpointer struct {
+	resume *models.StepCheckpoint
+	save   func(checkpoint models.StepCheckpoint)
+}
+
+// newStepCheckpointer loads the checkpoint for a step and returns a checkpointer that saves new ones.
+// A checkpoint taken against a different file (for example after a re-download) is discarded.
+func (s *DiscogsXMLParserService) newStepCheckpointer(
+	ctx context.Context,
+	processing *models.DiscogsDataProcessing,
+	step models.ProcessingStep,
+	fileKey string,
+) *stepCheckpointer {
+	log := s.log.Function("newStepCheckpointer").With("step", step)
+
+	var fileSize int64
+	if object, err := s.storage.Stat(ctx, fileKey); err == nil {
+		fileSize = object.Size
+	}
+
+	resume := processing.GetStepCheckpoint(step)
+	if resume != nil && resume.FileSize != fileSize {
+		log.Warn("Discarding checkpoint taken against a different file",
+			"checkpointFileSize", resume.FileSize,
+			"fileSize", fileSize,
+		)
+		resume = nil
+	}
+
+	return &stepCheckpointer{
+		resume: resume,
+		save: func(checkpoint models.StepCheckpoint) {
+			checkpoint.FileSize = fileSize
+			checkpoint.UpdatedAt = time.Now().UTC()
+			processing.SetStepCheckpoint(step, &checkpoint)
+			if err := s.repos.DiscogsDataProcessing.Update(ctx, processing); err != nil {
+				log.Warn("Failed to save step checkpoint", "error", err)
+			}
+		},
+	}
+}
+
 // collectGenresFromXML performs the first pass to collect all unique genre/style names
 func (s *DiscogsXMLParserService) collectGenresFromXML(
 	ctx context.Context,
-	filePath string,
+	fileKey string,
 	elementName string,
 	genreManager *GenreStyleManager,
 	log logger.Logger,
@@ -1052,7 +1424,7 @@ func (s *DiscogsXMLParserService) collectGenresFromXML(
 		// Start XML parsing in goroutine
 		go func() {
 			defer close(masterChan)
-			err := ParseXMLGeneric(ctx, filePath, elementName, masterChan, 0, log)
+			err := ParseXMLGeneric(ctx, s.storage, fileKey, elementName, masterChan, 0, log)
 			if err != nil {
 				log.Er("Failed to parse XML for genre collection", err)
 			}
@@ -1082,7 +1454,7 @@ func (s *DiscogsXMLParserService) collectGenresFromXML(
 		// Start XML parsing in goroutine
 		go func() {
 			defer close(releaseChan)
-			err := ParseXMLGeneric(ctx, filePath, elementName, releaseChan, 0, log)
+			err := ParseXMLGeneric(ctx, s.storage, fileKey, elementName, releaseChan, 0, log)
 			if err != nil {
 				log.Er("Failed to parse XML for genre collection", err)
 			}
@@ -1116,31 +1488,48 @@ func (s *DiscogsXMLParserService) collectGenresFromXML(
 	return nil
 }
 
-// isGzipFile checks if the file path indicates a gzip compressed file
-func isGzipFile(filePath string) bool {
-	return len(filePath) > 3 && filePath[len(filePath)-3:] == ".gz"
+// isGzipFile checks if the file key indicates a gzip compressed file
+func isGzipFile(fileKey string) bool {
+	return len(fileKey) > 3 && fileKey[len(fileKey)-3:] == ".gz"
 }
 
 // ParseXMLGeneric is a generic function that can parse any XML entity type
 func ParseXMLGeneric[T any](
 	ctx context.Context,
-	filePath string,
+	storage DumpStorage,
+	fileKey string,
 	elementName string,
 	resultChan chan<- T,
 	maxEntities int,
 	log logger.Logger,
+) error {
+	return ParseXMLGenericFrom(ctx, storage, fileKey, elementName, resultChan, 0, maxEntities, log)
+}
+
+// ParseXMLGenericFrom parses like ParseXMLGeneric but skips the first skip matching elements
+// without decoding them, which is how interrupted steps resume from a checkpoint.
+// The file is streamed straight from storage, it is never staged on local disk.
+func ParseXMLGenericFrom[T any](
+	ctx context.Context,
+	storage DumpStorage,
+	fileKey string,
+	elementName string,
+	resultChan chan<- T,
+	skip int64,
+	maxEntities int,
+	log logger.Logger,
 ) error {
 	log = log.Function("ParseXMLGeneric")
 	// Open the file
-	file, err := os.Open(filePath)
+	file, err := storage.Open(ctx, fileKey)
 	if err != nil {
-		return fmt.Errorf("failed to open file %s: %w", filePath, err)
+		return fmt.Errorf("failed to open file %s: %w", storage.Location(fileKey), err)
 	}
 	defer func() { _ = file.Close() }()
 
 	// Handle gzip files
 	var reader io.Reader = file
-	if isGzipFile(filePath) {
+	if isGzipFile(fileKey) {
 		gzipReader, err := gzip.NewReader(file)
 		if err != nil {
 			return fmt.Errorf("failed to create gzip reader: %w", err)
@@ -1153,8 +1542,14 @@ func ParseXMLGeneric[T any](
 	decoder := xml.NewDecoder(reader)
 	entityCount := 0
 	errorCount := 0
+	skipped := int64(0)
 
-	log.Info("Starting generic XML parsing", "elementName", elementName, "maxEntities", maxEntities)
+	log.Info(
+		"Starting generic XML parsing",
+		"elementName", elementName,
+		"maxEntities", maxEntities,
+		"skip", skip,
+	)
 
 	for {
 		select {
@@ -1178,6 +1573,18 @@ func ParseXMLGeneric[T any](
 		// Check if this is our target element
 		if startElement, ok := token.(xml.StartElement); ok &&
 			startElement.Name.Local == elementName {
+			if skipped < skip {
+				if err := decoder.Skip(); err != nil {
+					log.Er("Failed to skip entity", err, "elementName", elementName)
+					errorCount++
+				}
+				skipped++
+				if skipped == skip {
+					log.Info("Skipped already committed entities", "skipped", skipped)
+				}
+				continue
+			}
+
 			var entity T
 			if err := decoder.DecodeElement(&entity, &startElement); err != nil {
 				log.Er("Failed to decode entity", err, "elementName", elementName)
@@ -1,8 +1,14 @@
 package services
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"testing"
 	"waugzee/internal/models"
+	"waugzee/internal/types"
+
+	logger "github.com/Bparsons0904/goLogger"
 )
 
 func TestProcessingStepTracking(t *testing.T) {
@@ -122,4 +128,62 @@ func TestAllStepsCompleted(t *testing.T) {
 	if !processing.AllStepsCompleted() {
 		t.Errorf("All steps should be completed")
 	}
-}
\ No newline at end of file
+}
+
+func TestMarkStepFailedKeepsCheckpoint(t *testing.T) {
+	processing := &models.DiscogsDataProcessing{
+		YearMonth: "2025-09",
+		Status:    models.ProcessingStatusProcessing,
+	}
+
+	processing.SetStepCheckpoint(models.StepReleasesProcessing, &models.StepCheckpoint{
+		ElementsCommitted: 25000,
+		FileSize:          1024,
+	})
+	processing.MarkStepFailed(models.StepReleasesProcessing, "connection reset")
+
+	checkpoint := processing.GetStepCheckpoint(models.StepReleasesProcessing)
+	if checkpoint == nil || checkpoint.ElementsCommitted != 25000 {
+		t.Errorf("Checkpoint should survive a failed step")
+	}
+
+	processing.MarkStepCompleted(models.StepReleasesProcessing, nil, nil)
+	if processing.GetStepCheckpoint(models.StepReleasesProcessing) != nil {
+		t.Errorf("Completed step should not report a checkpoint")
+	}
+}
+
+func TestParseXMLGenericFromSkipsCommittedElements(t *testing.T) {
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	_, _ = gzipWriter.Write([]byte(`<labels>` +
+		`<label><id>1</id><name>One</name></label>` +
+		`<label><id>2</id><name>Two</name></label>` +
+		`<label><id>3</id><name>Three</name></label>` +
+		`</labels>`))
+	_ = gzipWriter.Close()
+
+	// Parse straight from storage, the dump is never staged on local disk
+	storage := newMemoryDumpStorage()
+	fileKey := DumpFileKey("2025-01", "labels")
+	err := storage.Put(context.Background(), fileKey, &buffer, int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("failed to store test file: %v", err)
+	}
+
+	resultChan := make(chan types.Label, 10)
+	err = ParseXMLGenericFrom(context.Background(), storage, fileKey, "label", resultChan, 2, 0, logger.New("test"))
+	close(resultChan)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	var ids []int64
+	for label := range resultChan {
+		ids = append(ids, label.ID)
+	}
+
+	if len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("Expected only label 3 after skipping two, got %v", ids)
+	}
+}
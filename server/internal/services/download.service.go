@@ -2,23 +2,23 @@ package services
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 	"waugzee/config"
 	"waugzee/internal/events"
 	logger "github.com/Bparsons0904/goLogger"
 	"waugzee/internal/models"
-
-	"github.com/google/uuid"
 )
 
 // Discogs S3 configuration constants
@@ -36,6 +36,11 @@ type DownloadService struct {
 	httpClient *http.Client
 	log        logger.Logger
 	eventBus   *events.EventBus
+	storage    DumpStorage
+	// sourceURL is the base URL dumps are fetched from, see resolveDumpSource
+	sourceURL string
+	// retryDelays is the wait before each retry, retrySchedule outside of tests
+	retryDelays []time.Duration
 }
 
 // Exponential backoff schedule (immediate, 5min, 25min, 75min, 375min)
@@ -49,20 +54,34 @@ var retrySchedule = []time.Duration{
 
 const maxRetries = DiscogsMaxRetries
 
-func NewDownloadService(cfg config.Config, eventBus *events.EventBus) *DownloadService {
+func NewDownloadService(
+	cfg config.Config,
+	eventBus *events.EventBus,
+	storage DumpStorage,
+) *DownloadService {
 	log := logger.New("downloadService")
 
 	// Create HTTP client with constant timeout
 	timeout := time.Duration(DiscogsTimeoutSec) * time.Second
 
+	transport := &http.Transport{
+		MaxIdleConns:       10,
+		IdleConnTimeout:    90 * time.Second,
+		DisableCompression: false,
+		MaxConnsPerHost:    10,
+	}
+
+	sourceURL, sourceDir := resolveDumpSource(cfg.DiscogsDumpSource)
+	if sourceDir != "" {
+		// A local source goes through the same download path, range resumes included. The file
+		// transport is rooted at the source directory so no other host file can be fetched.
+		transport.RegisterProtocol("file", http.NewFileTransport(http.Dir(sourceDir)))
+	}
+	log.Info("Using dump source", "source", cmp.Or(sourceDir, sourceURL))
+
 	httpClient := &http.Client{
-		Timeout: timeout,
-		Transport: &http.Transport{
-			MaxIdleConns:       10,
-			IdleConnTimeout:    90 * time.Second,
-			DisableCompression: false,
-			MaxConnsPerHost:    10,
-		},
+		Timeout:   timeout,
+		Transport: transport,
 	}
 
 	return &DownloadService{
@@ -70,10 +89,24 @@ func NewDownloadService(cfg config.Config, eventBus *events.EventBus) *DownloadS
 		httpClient: httpClient,
 		log:        log,
 		eventBus:   eventBus,
+		storage:    storage,
+		sourceURL:  sourceURL,
+
+		retryDelays: retrySchedule,
 	}
 }
 
 func (ds *DownloadService) BroadcastProgress(yearMonth, status, fileType, stage string, downloaded, total int64, err error) {
+	ds.broadcastProgress(yearMonth, status, fileType, stage, downloaded, total, 0, err)
+}
+
+// broadcastProgress publishes a progress event. For a resumed download, downloaded and total count
+// the whole file and resumedFrom is the offset the transfer continued from.
+func (ds *DownloadService) broadcastProgress(
+	yearMonth, status, fileType, stage string,
+	downloaded, total, resumedFrom int64,
+	err error,
+) {
 	if ds.eventBus == nil {
 		return
 	}
@@ -89,31 +122,24 @@ func (ds *DownloadService) BroadcastProgress(yearMonth, status, fileType, stage
 		errMsg = &msg
 	}
 
-	progressEvent := map[string]any{
-		"yearMonth":    yearMonth,
-		"status":       status,
-		"fileType":     fileType,
-		"stage":        stage,
-		"downloaded":   downloaded,
-		"total":        total,
-		"percentage":   percentage,
-		"errorMessage": errMsg,
+	progress := events.AdminDownloadProgress{
+		YearMonth:    yearMonth,
+		Status:       status,
+		FileType:     fileType,
+		Stage:        stage,
+		Downloaded:   downloaded,
+		Total:        total,
+		Percentage:   percentage,
+		ResumedFrom:  resumedFrom,
+		ErrorMessage: errMsg,
 	}
 
-	message := events.Message{
-		ID:        uuid.New().String(),
-		Service:   events.SYSTEM,
-		Event:     string(events.ADMIN_DOWNLOAD_PROGRESS),
-		Payload:   progressEvent,
-		Timestamp: time.Now(),
-	}
-
-	if err := ds.eventBus.Publish(events.WEBSOCKET, "admin", message); err != nil {
+	if err := ds.eventBus.PublishToAdmins(events.DumpTopic(yearMonth), progress); err != nil {
 		ds.log.Warn("Failed to publish admin download progress", "error", err)
 	}
 }
 
-// DownloadChecksum downloads the CHECKSUM.txt file from Discogs S3 for the current year-month
+// DownloadChecksum downloads the CHECKSUM.txt file for a year-month from the dump source
 func (ds *DownloadService) DownloadChecksum(ctx context.Context, yearMonth string) error {
 	log := ds.log.Function("DownloadChecksum")
 
@@ -125,56 +151,42 @@ func (ds *DownloadService) DownloadChecksum(ctx context.Context, yearMonth strin
 		)
 	}
 
-	// Use current year-month if not provided (always use current for URL construction)
-	currentYearMonth := time.Now().UTC().Format("2006-01")
+	// Build source URL for CHECKSUM.txt
+	checksumURL := ds.dumpURL(yearMonth, "CHECKSUM.txt")
 
-	// Extract year for URL construction
-	year := strings.Split(currentYearMonth, "-")[0]
-
-	// Build S3 URL for CHECKSUM.txt
-	checksumURL := fmt.Sprintf(
-		"%s/%s/discogs_%s01_CHECKSUM.txt",
-		DiscogsS3BaseURL,
-		year,
-		strings.ReplaceAll(currentYearMonth, "-", ""),
-	)
-
-	// Create download directory
-	downloadDir := fmt.Sprintf("%s/%s", DiscogsDataDir, yearMonth)
-	if err := ensureDirectory(downloadDir, log); err != nil {
-		return log.Err("failed to create download directory", err, "directory", downloadDir)
-	}
-
-	// Target file path
-	targetFile := filepath.Join(downloadDir, "CHECKSUM.txt")
+	// Target storage key
+	targetKey := DumpChecksumKey(yearMonth)
 
 	log.Info("Starting checksum download",
 		"url", checksumURL,
-		"targetFile", targetFile,
+		"target", ds.storage.Location(targetKey),
 		"yearMonth", yearMonth)
 
-	// Download with retry logic
-	return ds.downloadFileWithRetry(ctx, checksumURL, targetFile)
+	// Download with retry logic, the checksum file itself has nothing to verify against
+	return ds.downloadFileWithRetry(ctx, checksumURL, targetKey, "")
 }
 
-// ParseChecksumFile parses the CHECKSUM.txt file and returns FileChecksums struct
-func (ds *DownloadService) ParseChecksumFile(filePath string) (*models.FileChecksums, error) {
+// ParseChecksumFile parses the stored CHECKSUM.txt file and returns FileChecksums struct
+func (ds *DownloadService) ParseChecksumFile(
+	ctx context.Context,
+	key string,
+) (*models.FileChecksums, error) {
 	log := ds.log.Function("ParseChecksumFile")
 
-	file, err := os.Open(filePath)
+	file, err := ds.storage.Open(ctx, key)
 	if err != nil {
-		return nil, log.Err("failed to open checksum file", err, "filePath", filePath)
+		return nil, log.Err("failed to open checksum file", err, "key", key)
 	}
 	defer func() {
 		if closeErr := file.Close(); closeErr != nil {
-			log.Warn("failed to close checksum file", "error", closeErr, "filePath", filePath)
+			log.Warn("failed to close checksum file", "error", closeErr, "key", key)
 		}
 	}()
 
 	checksums := &models.FileChecksums{}
 	scanner := bufio.NewScanner(file)
 
-	log.Debug("Parsing checksum file", "filePath", filePath)
+	log.Debug("Parsing checksum file", "key", key)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -212,7 +224,7 @@ func (ds *DownloadService) ParseChecksumFile(filePath string) (*models.FileCheck
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, log.Err("error reading checksum file", err, "filePath", filePath)
+		return nil, log.Err("error reading checksum file", err, "key", key)
 	}
 
 	// Validate that we found at least some checksums
@@ -221,13 +233,13 @@ func (ds *DownloadService) ParseChecksumFile(filePath string) (*models.FileCheck
 		return nil, log.Err(
 			"no valid checksums found in file",
 			fmt.Errorf("empty or invalid checksum file"),
-			"filePath",
-			filePath,
+			"key",
+			key,
 		)
 	}
 
 	log.Info("Successfully parsed checksum file",
-		"filePath", filePath,
+		"key", key,
 		"foundArtists", checksums.ArtistsDump != "",
 		"foundLabels", checksums.LabelsDump != "",
 		"foundMasters", checksums.MastersDump != "",
@@ -236,8 +248,12 @@ func (ds *DownloadService) ParseChecksumFile(filePath string) (*models.FileCheck
 	return checksums, nil
 }
 
-// DownloadXMLFile downloads a specific XML file (artists.xml.gz or labels.xml.gz) from Discogs S3
-func (ds *DownloadService) DownloadXMLFile(ctx context.Context, yearMonth, fileType string) error {
+// DownloadXMLFile downloads a specific XML file (artists.xml.gz or labels.xml.gz) from the dump source.
+// expectedChecksum, when known, is used to verify a download that was resumed part way through.
+func (ds *DownloadService) DownloadXMLFile(
+	ctx context.Context,
+	yearMonth, fileType, expectedChecksum string,
+) error {
 	log := ds.log.Function("DownloadXMLFile")
 
 	// Validate inputs
@@ -258,46 +274,30 @@ func (ds *DownloadService) DownloadXMLFile(ctx context.Context, yearMonth, fileT
 		)
 	}
 
-	// Use current year-month for URL construction (always download current month data)
-	currentYearMonth := time.Now().UTC().Format("2006-01")
-	year := strings.Split(currentYearMonth, "-")[0]
-
-	// Build S3 URL for XML file
-	xmlURL := fmt.Sprintf(
-		"%s/%s/discogs_%s01_%s.xml.gz",
-		DiscogsS3BaseURL,
-		year,
-		strings.ReplaceAll(currentYearMonth, "-", ""),
-		fileType,
-	)
-
-	// Create download directory
-	downloadDir := fmt.Sprintf("%s/%s", DiscogsDataDir, yearMonth)
-	if err := ensureDirectory(downloadDir, log); err != nil {
-		return log.Err("failed to create download directory", err, "directory", downloadDir)
-	}
+	// Build source URL for XML file
+	xmlURL := ds.dumpURL(yearMonth, fmt.Sprintf("%s.xml.gz", fileType))
 
-	// Target file path
-	targetFile := filepath.Join(downloadDir, fmt.Sprintf("%s.xml.gz", fileType))
+	// Target storage key
+	targetKey := DumpFileKey(yearMonth, fileType)
 
 	log.Info("Starting XML file download",
 		"fileType", fileType,
 		"url", xmlURL,
-		"targetFile", targetFile,
+		"target", ds.storage.Location(targetKey),
 		"yearMonth", yearMonth)
 
 	// Download with retry logic
-	return ds.downloadFileWithRetry(ctx, xmlURL, targetFile)
+	return ds.downloadFileWithRetry(ctx, xmlURL, targetKey, expectedChecksum)
 }
 
-// ValidateFileChecksum validates an existing file against its expected SHA256 checksum
-func (ds *DownloadService) ValidateFileChecksum(filePath, expectedChecksum string) error {
+// ValidateFileChecksum validates a stored file against its expected SHA256 checksum
+func (ds *DownloadService) ValidateFileChecksum(ctx context.Context, key, expectedChecksum string) error {
 	log := ds.log.Function("ValidateFileChecksum")
 
 	// Open the file for reading
-	file, err := os.Open(filePath)
+	file, err := ds.storage.Open(ctx, key)
 	if err != nil {
-		return log.Err("failed to open file for checksum validation", err, "filePath", filePath)
+		return log.Err("failed to open file for checksum validation", err, "key", key)
 	}
 	defer func() {
 		if closeErr := file.Close(); closeErr != nil {
@@ -305,8 +305,8 @@ func (ds *DownloadService) ValidateFileChecksum(filePath, expectedChecksum strin
 				"failed to close file after checksum validation",
 				"error",
 				closeErr,
-				"filePath",
-				filePath,
+				"key",
+				key,
 			)
 		}
 	}()
@@ -320,7 +320,7 @@ func (ds *DownloadService) ValidateFileChecksum(filePath, expectedChecksum strin
 		n, readErr := file.Read(buffer)
 		if n > 0 {
 			if _, writeErr := hash.Write(buffer[:n]); writeErr != nil {
-				return log.Err("failed to write to SHA256 hash", writeErr, "filePath", filePath)
+				return log.Err("failed to write to SHA256 hash", writeErr, "key", key)
 			}
 		}
 
@@ -328,7 +328,7 @@ func (ds *DownloadService) ValidateFileChecksum(filePath, expectedChecksum strin
 			break
 		}
 		if readErr != nil {
-			return log.Err("failed to read file for checksum", readErr, "filePath", filePath)
+			return log.Err("failed to read file for checksum", readErr, "key", key)
 		}
 	}
 
@@ -339,34 +339,37 @@ func (ds *DownloadService) ValidateFileChecksum(filePath, expectedChecksum strin
 	if !strings.EqualFold(computedChecksum, expectedChecksum) {
 		return log.Err("checksum validation failed",
 			fmt.Errorf("computed: %s, expected: %s", computedChecksum, expectedChecksum),
-			"filePath", filePath,
+			"key", key,
 			"computed", computedChecksum,
 			"expected", expectedChecksum)
 	}
 
 	log.Info("Checksum validation successful",
-		"filePath", filePath,
+		"key", key,
 		"checksum", computedChecksum)
 
 	return nil
 }
 
-// downloadFileWithRetry downloads a file with exponential backoff retry logic
+// downloadFileWithRetry downloads a file into storage with exponential backoff retry logic.
+// Retries continue from the data already stored when the backend supports it, and a file
+// assembled from several transfers is verified against expectedChecksum before it is accepted.
 func (ds *DownloadService) downloadFileWithRetry(
 	ctx context.Context,
-	url, targetFile string,
+	url, targetKey, expectedChecksum string,
 ) error {
 	log := ds.log.Function("downloadFileWithRetry")
 
+	maxAttempts := min(maxRetries, len(ds.retryDelays)+1)
 	var lastErr error
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
 		// Wait for retry delay (except first attempt)
 		if attempt > 0 {
-			delay := retrySchedule[attempt-1]
+			delay := ds.retryDelays[attempt-1]
 			log.Info("Retrying download after delay",
 				"attempt", attempt+1,
-				"maxRetries", maxRetries,
+				"maxRetries", maxAttempts,
 				"delay", delay,
 				"url", url)
 
@@ -379,17 +382,29 @@ func (ds *DownloadService) downloadFileWithRetry(
 		} else {
 			log.Info("Starting download attempt",
 				"attempt", attempt+1,
-				"maxRetries", maxRetries,
+				"maxRetries", maxAttempts,
 				"url", url)
 		}
 
 		// Attempt download
-		err := ds.downloadFile(ctx, url, targetFile)
+		resumed, err := ds.downloadFile(ctx, url, targetKey)
+		if err == nil && resumed && expectedChecksum != "" {
+			// A resumed file is only as good as the bytes kept from earlier attempts, so
+			// re-verify it and start over from byte zero if they did not belong to this file
+			if err = ds.ValidateFileChecksum(ctx, targetKey, expectedChecksum); err != nil {
+				if deleteErr := ds.storage.Delete(ctx, targetKey); deleteErr != nil {
+					log.Warn("failed to delete resumed file that failed checksum validation",
+						"error", deleteErr,
+						"target", ds.storage.Location(targetKey))
+				}
+			}
+		}
 		if err == nil {
 			log.Info("Download completed successfully",
 				"attempt", attempt+1,
 				"url", url,
-				"targetFile", targetFile)
+				"resumed", resumed,
+				"target", ds.storage.Location(targetKey))
 			return nil
 		}
 
@@ -407,27 +422,48 @@ func (ds *DownloadService) downloadFileWithRetry(
 
 	return log.Err("download failed after all retry attempts",
 		lastErr,
-		"maxRetries", maxRetries,
+		"maxRetries", maxAttempts,
 		"url", url)
 }
 
-// downloadFile downloads a single file from URL to targetFile with progress-based timeout
-func (ds *DownloadService) downloadFile(ctx context.Context, url, targetFile string) error {
+var errDownloadStalled = errors.New("download stalled")
+
+// downloadFile streams a single file from URL into storage under targetKey with progress-based timeout.
+// When storage kept data from an interrupted attempt, only the remaining bytes are requested with an
+// HTTP Range request. resumed reports whether the stored file was assembled from more than one transfer.
+func (ds *DownloadService) downloadFile(ctx context.Context, url, targetKey string) (resumed bool, err error) {
 	log := ds.log.Function("downloadFile")
 
+	resumable, canResume := ds.storage.(ResumableDumpStorage)
+
+	var offset int64
+	if canResume {
+		if offset, err = resumable.PartialSize(ctx, targetKey); err != nil {
+			log.Warn("failed to read partial download size, starting from zero", "error", err)
+			offset = 0
+		}
+	}
+
+	// The stall watchdog cancels this context, which aborts both the HTTP body and the storage write
+	downloadCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
 	// Create HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(downloadCtx, "GET", url, nil)
 	if err != nil {
-		return log.Err("failed to create HTTP request", err, "url", url)
+		return false, log.Err("failed to create HTTP request", err, "url", url)
 	}
 
 	// Set User-Agent header for Discogs S3
 	req.Header.Set("User-Agent", DiscogsUserAgent)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 
 	// Make HTTP request
 	resp, err := ds.httpClient.Do(req)
 	if err != nil {
-		return log.Err("HTTP request failed", err, "url", url)
+		return false, log.Err("HTTP request failed", err, "url", url)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -436,102 +472,181 @@ func (ds *DownloadService) downloadFile(ctx context.Context, url, targetFile str
 	}()
 
 	// Check HTTP status
-	if resp.StatusCode != http.StatusOK {
-		return log.Err("HTTP request failed",
+	contentLength := resp.ContentLength
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if offset > 0 {
+			log.Info("Server ignored range request, restarting download from zero",
+				"url", url,
+				"offset", offset)
+			offset = 0
+		}
+	case http.StatusPartialContent:
+		start, total, rangeErr := parseContentRange(resp.Header.Get("Content-Range"))
+		if rangeErr != nil || start != offset {
+			ds.discardPartial(ctx, resumable, targetKey)
+			return false, log.Err("unexpected content range for resumed download",
+				fmt.Errorf("requested offset %d, got %q", offset, resp.Header.Get("Content-Range")),
+				"url", url)
+		}
+		if total >= 0 {
+			contentLength = total
+		} else if contentLength >= 0 {
+			contentLength += offset
+		}
+		log.Info("Resuming download", "url", url, "offset", offset, "total", contentLength)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The kept data is at least as long as the file, so it cannot be a prefix of it
+		ds.discardPartial(ctx, resumable, targetKey)
+		return false, log.Err("partial download does not match remote file",
+			fmt.Errorf("range starting at %d not satisfiable", offset),
+			"url", url)
+	default:
+		return false, log.Err("HTTP request failed",
 			fmt.Errorf("status code: %d", resp.StatusCode),
 			"url", url,
 			"statusCode", resp.StatusCode)
 	}
 
-	// Create target file
-	outFile, err := os.Create(targetFile)
-	if err != nil {
-		return log.Err("failed to create target file", err, "targetFile", targetFile)
-	}
-	defer func() {
-		if closeErr := outFile.Close(); closeErr != nil {
-			log.Warn("failed to close target file", "error", closeErr, "targetFile", targetFile)
-		}
-	}()
-
 	// Track download progress with stall detection
-	contentLength := resp.ContentLength
-	downloaded := int64(0)
-	lastProgressTime := time.Now()
-	lastLogTime := time.Now()
 	stallTimeout := time.Duration(DiscogsStallTimeoutSec) * time.Second
 
 	// Extract metadata for WebSocket broadcasts
-	yearMonth := ds.extractYearMonthFromPath(targetFile)
+	yearMonth := ds.extractYearMonthFromPath(targetKey)
 	fileType := ds.extractFileTypeFromURL(url)
 
 	log.Info("Starting download with progress-based timeout",
 		"url", url,
 		"stallTimeoutSec", DiscogsStallTimeoutSec,
-		"contentLength", contentLength)
+		"contentLength", contentLength,
+		"resumedFrom", offset)
 
 	// Broadcast download started
-	ds.BroadcastProgress(yearMonth, "downloading", fileType, "in_progress", 0, contentLength, nil)
-
-	// Copy response body to file with progress tracking and stall detection
-	buffer := make([]byte, 32*1024) // 32KB buffer
+	ds.broadcastProgress(yearMonth, "downloading", fileType, "in_progress", offset, contentLength, offset, nil)
 
-	for {
-		select {
-		case <-ctx.Done():
-			return log.Err("download cancelled", ctx.Err())
-		default:
-		}
-
-		// Check for stall - if no progress for stallTimeout, abort
-		if time.Since(lastProgressTime) > stallTimeout {
-			return log.Err("download stalled - no progress detected",
-				fmt.Errorf("no progress for %v seconds", DiscogsStallTimeoutSec),
-				"url", url,
-				"downloaded", downloaded,
-				"stallTimeoutSec", DiscogsStallTimeoutSec)
-		}
+	progress := &downloadProgressReader{reader: resp.Body}
+	progress.downloaded.Store(offset)
+	progress.lastProgress.Store(time.Now().UnixNano())
 
-		n, readErr := resp.Body.Read(buffer)
-		if n > 0 {
-			if _, writeErr := outFile.Write(buffer[:n]); writeErr != nil {
-				return log.Err("failed to write to file", writeErr, "targetFile", targetFile)
-			}
+	// Log and broadcast progress every 30 seconds, abort if no progress for stallTimeout
+	watchdogDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
 
-			// Progress made - reset stall timer
-			downloaded += int64(n)
-			lastProgressTime = time.Now()
-
-			// Log and broadcast progress every 30 seconds
-			now := time.Now()
-			if now.Sub(lastLogTime) >= 30*time.Second {
-				ds.logDownloadProgress(contentLength, downloaded, url)
-				ds.BroadcastProgress(yearMonth, "downloading", fileType, "in_progress", downloaded, contentLength, nil)
-				lastLogTime = now
-				log.Debug("Progress detected, stall timer reset",
-					"downloaded", downloaded,
-					"stallTimeoutSec", DiscogsStallTimeoutSec)
+		lastLogTime := time.Now()
+		for {
+			select {
+			case <-watchdogDone:
+				return
+			case now := <-ticker.C:
+				if now.Sub(time.Unix(0, progress.lastProgress.Load())) > stallTimeout {
+					cancel(errDownloadStalled)
+					return
+				}
+				if now.Sub(lastLogTime) >= 30*time.Second {
+					downloaded := progress.downloaded.Load()
+					ds.logDownloadProgress(contentLength, downloaded, url)
+					ds.broadcastProgress(yearMonth, "downloading", fileType, "in_progress", downloaded, contentLength, offset, nil)
+					lastLogTime = now
+				}
 			}
 		}
+	}()
 
-		if readErr == io.EOF {
-			break
-		}
-		if readErr != nil {
-			return log.Err("failed to read response body", readErr, "url", url)
+	// Resumable backends keep whatever arrived before a failure for the next attempt
+	if canResume {
+		err = resumable.AppendPartial(downloadCtx, targetKey, offset, progress)
+		if err == nil {
+			err = resumable.CommitPartial(ctx, targetKey)
 		}
+	} else {
+		err = ds.storage.Put(downloadCtx, targetKey, progress, resp.ContentLength)
+	}
+	close(watchdogDone)
+
+	downloaded := progress.downloaded.Load()
+	if errors.Is(context.Cause(downloadCtx), errDownloadStalled) {
+		return false, log.Err("download stalled - no progress detected",
+			fmt.Errorf("no progress for %v seconds", DiscogsStallTimeoutSec),
+			"url", url,
+			"downloaded", downloaded,
+			"stallTimeoutSec", DiscogsStallTimeoutSec)
+	}
+	if err != nil {
+		return false, log.Err("failed to store download", err,
+			"url", url,
+			"target", ds.storage.Location(targetKey),
+			"downloaded", downloaded)
 	}
 
 	// Final progress log and broadcast
 	ds.logDownloadProgress(contentLength, downloaded, url)
-	ds.BroadcastProgress(yearMonth, "completed", fileType, "completed", downloaded, contentLength, nil)
+	ds.broadcastProgress(yearMonth, "completed", fileType, "completed", downloaded, contentLength, offset, nil)
 
 	log.Info("File download completed",
 		"url", url,
-		"targetFile", targetFile,
-		"size", downloaded)
+		"target", ds.storage.Location(targetKey),
+		"size", downloaded,
+		"resumedFrom", offset)
 
-	return nil
+	return offset > 0, nil
+}
+
+// discardPartial drops kept data that cannot be resumed so the next attempt starts from zero
+func (ds *DownloadService) discardPartial(ctx context.Context, resumable ResumableDumpStorage, key string) {
+	if resumable == nil {
+		return
+	}
+	if err := resumable.DiscardPartial(ctx, key); err != nil {
+		ds.log.Warn("failed to discard partial download", "error", err, "key", key)
+	}
+}
+
+// parseContentRange parses a "bytes start-end/total" Content-Range header. total is -1 when
+// the server reports it as unknown.
+func parseContentRange(header string) (start, total int64, err error) {
+	rangeSpec, found := strings.CutPrefix(header, "bytes ")
+	if !found {
+		return 0, 0, fmt.Errorf("unsupported content range: %q", header)
+	}
+
+	span, size, found := strings.Cut(rangeSpec, "/")
+	if !found {
+		return 0, 0, fmt.Errorf("malformed content range: %q", header)
+	}
+
+	first, _, found := strings.Cut(span, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("malformed content range: %q", header)
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("malformed content range: %q", header)
+	}
+
+	if size == "*" {
+		return start, -1, nil
+	}
+	if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("malformed content range: %q", header)
+	}
+	return start, total, nil
+}
+
+// downloadProgressReader counts bytes as storage consumes the response body
+type downloadProgressReader struct {
+	reader       io.Reader
+	downloaded   atomic.Int64
+	lastProgress atomic.Int64 // unix nanoseconds of the last successful read
+}
+
+func (r *downloadProgressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.downloaded.Add(int64(n))
+		r.lastProgress.Store(time.Now().UnixNano())
+	}
+	return n, err
 }
 
 // logDownloadProgress logs download progress information
@@ -567,9 +682,9 @@ func (ds *DownloadService) extractFileTypeFromURL(url string) string {
 	return "unknown"
 }
 
-// extractYearMonthFromURL extracts yearMonth from target file path
-func (ds *DownloadService) extractYearMonthFromPath(targetFile string) string {
-	parts := strings.Split(targetFile, "/")
+// extractYearMonthFromPath extracts yearMonth from a target storage key
+func (ds *DownloadService) extractYearMonthFromPath(targetKey string) string {
+	parts := strings.Split(targetKey, "/")
 	for _, part := range parts {
 		if len(part) == 7 && strings.Count(part, "-") == 1 {
 			return part
@@ -578,38 +693,26 @@ func (ds *DownloadService) extractYearMonthFromPath(targetFile string) string {
 	return ""
 }
 
-// ensureDirectory creates directory if it doesn't exist
-func ensureDirectory(dir string, logger logger.Logger) error {
-	log := logger.Function("ensureDirectory")
-
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return log.Err("failed to create directory", err, "directory", dir)
-		}
-		log.Info("Created download directory", "directory", dir)
-	}
-	return nil
-}
-
-// CheckExistingFile checks if a file exists and optionally validates its checksum
+// CheckExistingFile checks if a file exists in storage and optionally validates its checksum
 func (ds *DownloadService) CheckExistingFile(
-	filePath string,
+	ctx context.Context,
+	key string,
 	expectedChecksum string,
 	validateChecksum bool,
 ) (exists bool, valid bool, size int64, err error) {
 	log := ds.log.Function("CheckExistingFile")
 
 	// Check if file exists
-	info, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
+	object, err := ds.storage.Stat(ctx, key)
+	if errors.Is(err, ErrDumpObjectNotFound) {
 		return false, false, 0, nil
 	}
 	if err != nil {
-		return false, false, 0, log.Err("failed to stat file", err, "filePath", filePath)
+		return false, false, 0, log.Err("failed to stat file", err, "key", key)
 	}
 
 	exists = true
-	size = info.Size()
+	size = object.Size
 
 	// If checksum validation is not requested, return early
 	if !validateChecksum || expectedChecksum == "" {
@@ -617,16 +720,16 @@ func (ds *DownloadService) CheckExistingFile(
 	}
 
 	// Validate checksum if requested
-	if err := ds.ValidateFileChecksum(filePath, expectedChecksum); err != nil {
+	if err := ds.ValidateFileChecksum(ctx, key, expectedChecksum); err != nil {
 		log.Warn("Existing file failed checksum validation",
-			"filePath", filePath,
+			"key", key,
 			"expectedChecksum", expectedChecksum,
 			"error", err)
 		return exists, false, size, nil
 	}
 
 	log.Info("Existing file validated successfully",
-		"filePath", filePath,
+		"key", key,
 		"size", size)
 
 	return exists, true, size, nil
@@ -634,18 +737,20 @@ func (ds *DownloadService) CheckExistingFile(
 
 // GetFileStatus returns the current status of a file based on existence and checksum validation
 func (ds *DownloadService) GetFileStatus(
-	filePath string,
+	ctx context.Context,
+	key string,
 	expectedChecksum string,
 ) (*models.FileDownloadInfo, error) {
 	log := ds.log.Function("GetFileStatus")
 
 	exists, valid, size, err := ds.CheckExistingFile(
-		filePath,
+		ctx,
+		key,
 		expectedChecksum,
 		expectedChecksum != "",
 	)
 	if err != nil {
-		return nil, log.Err("failed to check existing file", err, "filePath", filePath)
+		return nil, log.Err("failed to check existing file", err, "key", key)
 	}
 
 	info := &models.FileDownloadInfo{
@@ -674,6 +779,11 @@ func (ds *DownloadService) GetFileStatus(
 	return info, nil
 }
 
+// DeleteFile removes a single stored file, such as the checksum file once it has been parsed
+func (ds *DownloadService) DeleteFile(ctx context.Context, key string) error {
+	return ds.storage.Delete(ctx, key)
+}
+
 // CleanupDownloadDirectory removes all downloaded files for a specific year-month
 func (ds *DownloadService) CleanupDownloadDirectory(ctx context.Context, yearMonth string) error {
 	log := ds.log.Function("CleanupDownloadDirectory")
@@ -685,19 +795,43 @@ func (ds *DownloadService) CleanupDownloadDirectory(ctx context.Context, yearMon
 		)
 	}
 
-	downloadDir := fmt.Sprintf("%s/%s", DiscogsDataDir, yearMonth)
+	prefix := dumpYearMonthPrefix(yearMonth)
 
-	if _, err := os.Stat(downloadDir); os.IsNotExist(err) {
-		log.Info("Download directory does not exist, nothing to cleanup", "directory", downloadDir)
-		return nil
+	removed, err := ds.storage.DeletePrefix(ctx, prefix)
+	if err != nil {
+		return log.Err("failed to remove downloaded files", err, "prefix", prefix)
 	}
 
-	if err := os.RemoveAll(downloadDir); err != nil {
-		return log.Err("failed to remove download directory", err, "directory", downloadDir)
+	log.Info("Successfully cleaned up downloaded files", "prefix", prefix, "filesRemoved", removed)
+	return nil
+}
+
+// resolveDumpSource turns DISCOGS_DUMP_SOURCE into a base URL. A bare directory path is returned as
+// the directory to root the file transport at, with a file:// base URL relative to it. An empty
+// source falls back to the public Discogs bucket.
+func resolveDumpSource(source string) (baseURL, localDir string) {
+	source = strings.TrimRight(strings.TrimSpace(source), "/")
+	switch {
+	case source == "":
+		return DiscogsS3BaseURL, ""
+	case strings.HasPrefix(source, "/"):
+		return "file://", source
+	default:
+		return source, ""
 	}
+}
 
-	log.Info("Successfully cleaned up download directory", "directory", downloadDir)
-	return nil
+// dumpURL builds the source URL of a dump file using the Discogs bucket layout,
+// e.g. {source}/2025/discogs_20250101_releases.xml.gz
+func (ds *DownloadService) dumpURL(yearMonth, fileName string) string {
+	year := strings.Split(yearMonth, "-")[0]
+	return fmt.Sprintf(
+		"%s/%s/discogs_%s01_%s",
+		ds.sourceURL,
+		year,
+		strings.ReplaceAll(yearMonth, "-", ""),
+		fileName,
+	)
 }
 
 // isValidYearMonth validates YYYY-MM format
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"waugzee/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dumpServer serves payload with Range support and can cut the connection part way through
+type dumpServer struct {
+	payload     []byte
+	ignoreRange bool
+
+	mu       sync.Mutex
+	dropAt   []int // bytes sent before dropping, one entry per request to drop
+	requests []string
+}
+
+func (s *dumpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Header.Get("Range"))
+	dropAt := -1
+	if len(s.dropAt) > 0 {
+		dropAt, s.dropAt = s.dropAt[0], s.dropAt[1:]
+	}
+	s.mu.Unlock()
+
+	if s.ignoreRange {
+		r.Header.Del("Range")
+	}
+
+	if dropAt < 0 {
+		http.ServeContent(w, r, "labels.xml.gz", time.Time{}, bytes.NewReader(s.payload))
+		return
+	}
+
+	// Promise the whole file, send dropAt bytes and hang up
+	w.Header().Set("Content-Length", strconv.Itoa(len(s.payload)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(s.payload[:dropAt])
+	w.(http.Flusher).Flush()
+
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+func (s *dumpServer) rangeHeaders() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func newTestDownloadService(t *testing.T) (*DownloadService, *LocalDumpStorage) {
+	storage := NewLocalDumpStorage(t.TempDir())
+	service := NewDownloadService(config.Config{}, nil, storage)
+	service.retryDelays = []time.Duration{0, 0, 0, 0}
+	return service, storage
+}
+
+func testPayload() ([]byte, string) {
+	payload := bytes.Repeat([]byte("<label><id>1</id></label>"), 4096)
+	sum := sha256.Sum256(payload)
+	return payload, hex.EncodeToString(sum[:])
+}
+
+func readStored(t *testing.T, storage DumpStorage, key string) []byte {
+	reader, err := storage.Open(context.Background(), key)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return data
+}
+
+func TestDownloadResumesAfterDroppedConnection(t *testing.T) {
+	payload, checksum := testPayload()
+	server := &dumpServer{payload: payload, dropAt: []int{40_000}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	service, storage := newTestDownloadService(t)
+	key := DumpFileKey("2025-01", "labels")
+
+	err := service.downloadFileWithRetry(context.Background(), httpServer.URL+"/labels.xml.gz", key, checksum)
+	require.NoError(t, err)
+
+	assert.Equal(t, payload, readStored(t, storage, key))
+	assert.Equal(t, []string{"", "bytes=40000-"}, server.rangeHeaders())
+
+	partial, err := storage.PartialSize(context.Background(), key)
+	require.NoError(t, err)
+	assert.Zero(t, partial, "a committed download leaves no partial data behind")
+}
+
+func TestDownloadRestartsWhenResumedFileFailsChecksum(t *testing.T) {
+	payload, checksum := testPayload()
+	server := &dumpServer{payload: payload}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	service, storage := newTestDownloadService(t)
+	key := DumpFileKey("2025-01", "labels")
+
+	// Kept data from an older copy of the file
+	require.NoError(t, storage.AppendPartial(context.Background(), key, 0, bytes.NewReader(bytes.Repeat([]byte("x"), 1000))))
+
+	err := service.downloadFileWithRetry(context.Background(), httpServer.URL+"/labels.xml.gz", key, checksum)
+	require.NoError(t, err)
+
+	assert.Equal(t, payload, readStored(t, storage, key))
+	assert.Equal(t, []string{"bytes=1000-", ""}, server.rangeHeaders())
+}
+
+func TestDownloadRestartsWhenServerIgnoresRange(t *testing.T) {
+	payload, checksum := testPayload()
+	server := &dumpServer{payload: payload, ignoreRange: true}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	service, storage := newTestDownloadService(t)
+	key := DumpFileKey("2025-01", "labels")
+
+	require.NoError(t, storage.AppendPartial(context.Background(), key, 0, bytes.NewReader(payload[:5000])))
+
+	err := service.downloadFileWithRetry(context.Background(), httpServer.URL+"/labels.xml.gz", key, checksum)
+	require.NoError(t, err)
+
+	assert.Equal(t, payload, readStored(t, storage, key))
+	assert.Equal(t, []string{"bytes=5000-"}, server.rangeHeaders())
+}
+
+func TestDownloadDiscardsPartialLongerThanFile(t *testing.T) {
+	payload, checksum := testPayload()
+	server := &dumpServer{payload: payload}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	service, storage := newTestDownloadService(t)
+	key := DumpFileKey("2025-01", "labels")
+
+	oversized := append(append([]byte(nil), payload...), []byte("trailing")...)
+	require.NoError(t, storage.AppendPartial(context.Background(), key, 0, bytes.NewReader(oversized)))
+
+	err := service.downloadFileWithRetry(context.Background(), httpServer.URL+"/labels.xml.gz", key, checksum)
+	require.NoError(t, err)
+
+	assert.Equal(t, payload, readStored(t, storage, key))
+	_, statErr := os.Stat(storage.partialPath(key))
+	assert.True(t, os.IsNotExist(statErr), "the oversized partial should be discarded")
+}
+
+func TestParseContentRange(t *testing.T) {
+	start, total, err := parseContentRange("bytes 100-999/1000")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), start)
+	assert.Equal(t, int64(1000), total)
+
+	start, total, err = parseContentRange("bytes 5-9/*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), start)
+	assert.Equal(t, int64(-1), total)
+
+	_, _, err = parseContentRange("items 0-1/2")
+	assert.Error(t, err)
+}
+
+func TestResolveDumpSource(t *testing.T) {
+	baseURL, localDir := resolveDumpSource("")
+	assert.Equal(t, DiscogsS3BaseURL, baseURL)
+	assert.Empty(t, localDir)
+
+	baseURL, localDir = resolveDumpSource(" https://mirror.example.com/data/ ")
+	assert.Equal(t, "https://mirror.example.com/data", baseURL)
+	assert.Empty(t, localDir)
+
+	baseURL, localDir = resolveDumpSource("/srv/discogs/")
+	assert.Equal(t, "file://", baseURL)
+	assert.Equal(t, "/srv/discogs", localDir)
+}
+
+func TestDumpURLUsesRequestedYearMonth(t *testing.T) {
+	service := &DownloadService{sourceURL: "https://mirror.example.com"}
+	assert.Equal(t,
+		"https://mirror.example.com/2023/discogs_20230401_releases.xml.gz",
+		service.dumpURL("2023-04", "releases.xml.gz"))
+	assert.Equal(t,
+		"https://mirror.example.com/2023/discogs_20230401_CHECKSUM.txt",
+		service.dumpURL("2023-04", "CHECKSUM.txt"))
+}
+
+func TestDownloadFromLocalDirectorySource(t *testing.T) {
+	payload, checksum := testPayload()
+	source := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "2023"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "2023", "discogs_20230401_labels.xml.gz"), payload, 0644))
+
+	storage := NewLocalDumpStorage(t.TempDir())
+	service := NewDownloadService(config.Config{DiscogsDumpSource: source}, nil, storage)
+	service.retryDelays = []time.Duration{0}
+
+	require.NoError(t, service.DownloadXMLFile(context.Background(), "2023-04", "labels", checksum))
+	assert.Equal(t, payload, readStored(t, storage, DumpFileKey("2023-04", "labels")))
+}
+
+func TestFileTransportIsRootedAtLocalSource(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0644))
+
+	service := NewDownloadService(config.Config{DiscogsDumpSource: t.TempDir()}, nil, NewLocalDumpStorage(t.TempDir()))
+	for _, target := range []string{"file://" + outside, "file:///../../" + outside} {
+		resp, err := service.httpClient.Get(target)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, target)
+	}
+
+	// Remote sources do not read local files at all
+	service = NewDownloadService(config.Config{}, nil, NewLocalDumpStorage(t.TempDir()))
+	_, err := service.httpClient.Get("file://" + outside)
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// idSet is a growable bitmap of entity IDs seen while streaming a dump file.
+// Discogs IDs are dense, so a bitmap keeps tens of millions of IDs in a few megabytes.
+type idSet struct {
+	words []uint64
+	count int64
+}
+
+func (s *idSet) Add(id int64) {
+	if id < 0 {
+		return
+	}
+
+	word := int(id / 64)
+	if word >= len(s.words) {
+		grown := make([]uint64, word+1+word/4)
+		copy(grown, s.words)
+		s.words = grown
+	}
+
+	bit := uint64(1) << uint(id%64)
+	if s.words[word]&bit == 0 {
+		s.words[word] |= bit
+		s.count++
+	}
+}
+
+func (s *idSet) Contains(id int64) bool {
+	if id < 0 {
+		return false
+	}
+
+	word := int(id / 64)
+	if word >= len(s.words) {
+		return false
+	}
+
+	return s.words[word]&(uint64(1)<<uint(id%64)) != 0
+}
+
+func (s *idSet) Len() int64 {
+	return s.count
+}
+
+// hashEntity returns a stable content hash for a converted dump entity.
+// It must be called before the hash is assigned so the hash field itself is not part of the input.
+func hashEntity(entity any) (string, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal entity for hashing: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// dumpChangeDetector filters converted entities down to the ones that differ from what is stored.
+// It is shared by the pipeline's batch writers, so the seen set is guarded by a mutex.
+type dumpChangeDetector[TModelType any] struct {
+	table string
+	repo  repositories.DumpEntityRepository
+	mu    sync.Mutex
+	seen  idSet
+}
+
+func newDumpChangeDetector[TModelType any](
+	table string,
+	repo repositories.DumpEntityRepository,
+) (*dumpChangeDetector[TModelType], error) {
+	var probe *TModelType
+	if _, ok := any(probe).(models.DumpEntity); !ok {
+		return nil, fmt.Errorf("%T does not support change detection", probe)
+	}
+
+	return &dumpChangeDetector[TModelType]{
+		table: table,
+		repo:  repo,
+	}, nil
+}
+
+// Filter hashes the batch, records every ID as seen, and returns only new or changed entities
+// along with the change counts for the batch
+func (d *dumpChangeDetector[TModelType]) Filter(
+	ctx context.Context,
+	tx *gorm.DB,
+	entities []*TModelType,
+) ([]*TModelType, models.ChangeCounts, error) {
+	var changes models.ChangeCounts
+	ids := make([]int64, 0, len(entities))
+	hashes := make([]string, len(entities))
+
+	for i, entity := range entities {
+		hash, err := hashEntity(entity)
+		if err != nil {
+			return nil, changes, err
+		}
+
+		dumpEntity := any(entity).(models.DumpEntity)
+		dumpEntity.SetContentHash(hash)
+		hashes[i] = hash
+		ids = append(ids, dumpEntity.GetID())
+	}
+
+	d.mu.Lock()
+	for _, id := range ids {
+		d.seen.Add(id)
+	}
+	d.mu.Unlock()
+
+	states, err := d.repo.GetStates(ctx, tx, d.table, ids)
+	if err != nil {
+		return nil, changes, err
+	}
+
+	changed := make([]*TModelType, 0, len(entities))
+	for i, entity := range entities {
+		state, exists := states[ids[i]]
+		switch {
+		case !exists:
+			changes.Inserted++
+			changed = append(changed, entity)
+		case state.ContentHash == nil || *state.ContentHash != hashes[i] || state.TombstonedAt != nil:
+			changes.Updated++
+			changed = append(changed, entity)
+		default:
+			changes.Unchanged++
+		}
+	}
+
+	return changed, changes, nil
+}
+
+// TombstoneMissing tombstones stored entities that were not part of this dump and returns how many
+func (d *dumpChangeDetector[TModelType]) TombstoneMissing(ctx context.Context, tx *gorm.DB) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.repo.TombstoneMissing(ctx, tx, d.table, d.seen.Contains)
+}
+
+// MarkSeen records an entity as present in the dump without comparing or writing it
+func (d *dumpChangeDetector[TModelType]) MarkSeen(entity *TModelType) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.seen.Add(any(entity).(models.DumpEntity).GetID())
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"waugzee/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDSet(t *testing.T) {
+	var set idSet
+
+	assert.False(t, set.Contains(1))
+
+	set.Add(1)
+	set.Add(64)
+	set.Add(35_000_000)
+	set.Add(64)
+
+	assert.True(t, set.Contains(1))
+	assert.True(t, set.Contains(64))
+	assert.True(t, set.Contains(35_000_000))
+	assert.False(t, set.Contains(2))
+	assert.False(t, set.Contains(70_000_000))
+	assert.False(t, set.Contains(-1))
+	assert.Equal(t, int64(3), set.Len())
+}
+
+func TestHashEntity(t *testing.T) {
+	profile := "Detroit techno"
+	label := &models.Label{
+		BaseDiscogModel: models.BaseDiscogModel{ID: 1},
+		Name:            "Planet E",
+		Profile:         &profile,
+	}
+
+	first, err := hashEntity(label)
+	assert.NoError(t, err)
+
+	label.SetContentHash(first)
+	second, err := hashEntity(label)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second, "stored hash must not affect the content hash")
+
+	label.Name = "Planet E Communications"
+	changed, err := hashEntity(label)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+}
+
+func TestNewDumpChangeDetectorRequiresDumpEntity(t *testing.T) {
+	_, err := newDumpChangeDetector[models.Label]("labels", nil)
+	assert.NoError(t, err)
+
+	_, err = newDumpChangeDetector[models.Genre]("labels", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidYearMonth     = errors.New("invalid yearMonth, expected YYYY-MM")
+	ErrDumpFilesMissing     = errors.New("dump files missing from storage")
+	ErrDumpChecksumMismatch = errors.New("dump file failed checksum validation")
+	ErrDumpImportInProgress = errors.New("download or processing already in progress")
+)
+
+// DumpFileTypes are the dump files a year-month needs before it can be processed
+var DumpFileTypes = []string{"labels", "artists", "masters", "releases"}
+
+// DumpImportService registers dump files that are already in storage for any year-month, so
+// air-gapped and test environments can process dumps that were copied in rather than downloaded
+type DumpImportService struct {
+	repos    repositories.Repository
+	download *DownloadService
+	storage  DumpStorage
+	log      logger.Logger
+}
+
+func NewDumpImportService(
+	repos repositories.Repository,
+	download *DownloadService,
+	storage DumpStorage,
+) *DumpImportService {
+	return &DumpImportService{
+		repos:    repos,
+		download: download,
+		storage:  storage,
+		log:      logger.New("dumpImportService"),
+	}
+}
+
+type DumpImportOptions struct {
+	// SkipChecksumValidation accepts the files without hashing them against CHECKSUM.txt
+	SkipChecksumValidation bool
+}
+
+// RegisterExistingDump creates or resets the processing record for yearMonth and queues it for
+// the XML parser. All four dump files must already be in storage under the year-month. When a
+// CHECKSUM.txt is stored alongside them every file is validated against it.
+func (s *DumpImportService) RegisterExistingDump(
+	ctx context.Context,
+	yearMonth string,
+	options DumpImportOptions,
+) (*models.DiscogsDataProcessing, error) {
+	log := s.log.Function("RegisterExistingDump").With("yearMonth", yearMonth)
+
+	if _, err := time.Parse("2006-01", yearMonth); err != nil {
+		return nil, log.Err("invalid yearMonth", ErrInvalidYearMonth, "yearMonth", yearMonth)
+	}
+
+	var missing []string
+	for _, fileType := range DumpFileTypes {
+		if _, err := s.storage.Stat(ctx, DumpFileKey(yearMonth, fileType)); err != nil {
+			if !errors.Is(err, ErrDumpObjectNotFound) {
+				return nil, log.Err("failed to check dump file", err, "fileType", fileType)
+			}
+			missing = append(missing, fileType)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, log.Err("dump files missing",
+			fmt.Errorf("%w: %s", ErrDumpFilesMissing, strings.Join(missing, ", ")),
+			"location", s.storage.Location(dumpYearMonthPrefix(yearMonth)))
+	}
+
+	var checksums *models.FileChecksums
+	if !options.SkipChecksumValidation {
+		parsed, err := s.download.ParseChecksumFile(ctx, DumpChecksumKey(yearMonth))
+		switch {
+		case err == nil:
+			checksums = parsed
+		case errors.Is(err, ErrDumpObjectNotFound):
+			log.Warn("No CHECKSUM.txt stored, registering files without validation")
+		default:
+			return nil, log.Err("failed to parse checksum file", err)
+		}
+	}
+
+	record, err := s.repos.DiscogsDataProcessing.GetByYearMonth(ctx, yearMonth)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, log.Err("failed to get processing record", err)
+	}
+	if record != nil &&
+		(record.Status == models.ProcessingStatusDownloading || record.Status == models.ProcessingStatusProcessing) {
+		return nil, log.Err("cannot register dump", ErrDumpImportInProgress, "status", record.Status)
+	}
+
+	if record == nil {
+		record = &models.DiscogsDataProcessing{YearMonth: yearMonth}
+	}
+
+	// Start the record over as if the files had just been downloaded
+	if err := record.UpdateStatus(models.ProcessingStatusReadyForProcessing, "registered existing dump files"); err != nil {
+		return nil, log.Err("cannot register dump", err, "status", record.Status)
+	}
+	now := time.Now().UTC()
+	record.RetryCount = 0
+	record.ErrorMessage = nil
+	record.StartedAt = nil
+	record.DownloadCompletedAt = &now
+	record.ProcessingCompletedAt = nil
+	record.FileChecksums = checksums
+	record.ProcessingStats = nil
+
+	for _, fileType := range DumpFileTypes {
+		info, err := s.download.GetFileStatus(ctx, DumpFileKey(yearMonth, fileType), record.GetFileChecksum(fileType))
+		if err != nil {
+			return nil, log.Err("failed to get file status", err, "fileType", fileType)
+		}
+		if info.Status == models.FileDownloadStatusFailed {
+			return nil, log.Err("dump file failed validation",
+				fmt.Errorf("%w: %s", ErrDumpChecksumMismatch, fileType))
+		}
+		record.SetFileInfo(fileType, info)
+	}
+
+	if record.ID == 0 {
+		if _, err := s.repos.DiscogsDataProcessing.Create(ctx, record); err != nil {
+			return nil, log.Err("failed to create processing record", err)
+		}
+	} else if err := s.repos.DiscogsDataProcessing.Update(ctx, record); err != nil {
+		return nil, log.Err("failed to update processing record", err)
+	}
+
+	log.Info("Registered existing dump for processing",
+		"checksumsValidated", checksums != nil,
+		"location", s.storage.Location(dumpYearMonthPrefix(yearMonth)))
+
+	return record, nil
+}
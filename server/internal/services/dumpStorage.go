@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"waugzee/config"
+)
+
+const (
+	DumpStorageBackendLocal = "local"
+	DumpStorageBackendS3    = "s3"
+	DumpChecksumFileName    = "CHECKSUM.txt"
+)
+
+var ErrDumpObjectNotFound = errors.New("dump object not found")
+
+// DumpObject describes a stored dump file. Keys are slash separated and relative to the
+// storage root, e.g. "2025-01/releases.xml.gz".
+type DumpObject struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// DumpStorage holds downloaded Discogs dump files. The download job writes to it, the XML
+// parser streams from it and the cleanup job prunes it, so none of them touch the filesystem.
+type DumpStorage interface {
+	// Put streams body into key, replacing any existing object. size is -1 when unknown.
+	// A failed Put never leaves a partial object behind under key.
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+	// Open streams the object stored under key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Stat returns ErrDumpObjectNotFound when nothing is stored under key
+	Stat(ctx context.Context, key string) (*DumpObject, error)
+	// List returns every object whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]DumpObject, error)
+	// Delete removes key, a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every object under prefix and returns how many were removed
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+	// Location renders key as a path or URL for logs
+	Location(key string) string
+}
+
+// ResumableDumpStorage is implemented by backends that can keep the data of an interrupted write,
+// so a download continues where it stopped instead of starting again from byte zero. Partial data
+// is invisible to Open, Stat and List until it is committed.
+type ResumableDumpStorage interface {
+	DumpStorage
+	// PartialSize returns how many bytes of an unfinished write are kept for key, 0 if none
+	PartialSize(ctx context.Context, key string) (int64, error)
+	// AppendPartial writes body after the first offset bytes kept for key, dropping anything
+	// beyond offset. Data written before an error is kept for the next attempt.
+	AppendPartial(ctx context.Context, key string, offset int64, body io.Reader) error
+	// CommitPartial moves the completed partial data into place under key
+	CommitPartial(ctx context.Context, key string) error
+	// DiscardPartial drops any partial data kept for key
+	DiscardPartial(ctx context.Context, key string) error
+}
+
+// NewDumpStorage builds the storage backend selected by DISCOGS_STORAGE_BACKEND, defaulting to
+// the local data directory
+func NewDumpStorage(cfg config.Config) (DumpStorage, error) {
+	switch strings.ToLower(cfg.DiscogsStorageBackend) {
+	case "", DumpStorageBackendLocal:
+		return NewLocalDumpStorage(DiscogsDataDir), nil
+	case DumpStorageBackendS3:
+		return NewS3DumpStorage(S3DumpStorageConfig{
+			Endpoint:  cfg.DiscogsS3Endpoint,
+			Bucket:    cfg.DiscogsS3Bucket,
+			Region:    cfg.DiscogsS3Region,
+			AccessKey: cfg.DiscogsS3AccessKey,
+			SecretKey: cfg.DiscogsS3SecretKey,
+			UseSSL:    cfg.DiscogsS3UseSSL,
+			Prefix:    cfg.DiscogsS3Prefix,
+		})
+	default:
+		return nil, fmt.Errorf("unknown dump storage backend: %s", cfg.DiscogsStorageBackend)
+	}
+}
+
+// DumpFileKey returns the storage key of a dump file type for a year-month
+func DumpFileKey(yearMonth, fileType string) string {
+	return path.Join(yearMonth, fmt.Sprintf("%s.xml.gz", fileType))
+}
+
+// DumpChecksumKey returns the storage key of the CHECKSUM.txt file for a year-month
+func DumpChecksumKey(yearMonth string) string {
+	return path.Join(yearMonth, DumpChecksumFileName)
+}
+
+// dumpYearMonthPrefix returns the key prefix holding every file of a year-month
+func dumpYearMonthPrefix(yearMonth string) string {
+	return yearMonth + "/"
+}
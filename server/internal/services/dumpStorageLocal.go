@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Partial writes live next to their target until they are complete
+const localPartialSuffix = ".part"
+
+// LocalDumpStorage keeps dump files in a directory tree on the local filesystem. It keeps the
+// data of interrupted downloads, so it also implements ResumableDumpStorage.
+type LocalDumpStorage struct {
+	root string
+}
+
+func NewLocalDumpStorage(root string) *LocalDumpStorage {
+	return &LocalDumpStorage{root: root}
+}
+
+func (s *LocalDumpStorage) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	target := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	// Write next to the target and rename so readers never see a partial file
+	partial := s.partialPath(key)
+	file, err := os.Create(partial)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", partial, err)
+	}
+
+	_, copyErr := io.Copy(file, contextReader{ctx: ctx, reader: body})
+	closeErr := file.Close()
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		_ = os.Remove(partial)
+		return fmt.Errorf("failed to write %s: %w", key, copyErr)
+	}
+
+	if err := os.Rename(partial, target); err != nil {
+		_ = os.Remove(partial)
+		return fmt.Errorf("failed to move %s into place: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalDumpStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrDumpObjectNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	return file, nil
+}
+
+func (s *LocalDumpStorage) Stat(ctx context.Context, key string) (*DumpObject, error) {
+	info, err := os.Stat(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrDumpObjectNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is a directory", ErrDumpObjectNotFound, key)
+	}
+
+	return &DumpObject{Key: key, Size: info.Size(), ModifiedAt: info.ModTime()}, nil
+}
+
+func (s *LocalDumpStorage) List(ctx context.Context, prefix string) ([]DumpObject, error) {
+	return s.walk(prefix, false)
+}
+
+// walk lists the completed objects under prefix, or the partial writes when partials is set
+func (s *LocalDumpStorage) walk(prefix string, partials bool) ([]DumpObject, error) {
+	objects := []DumpObject{}
+
+	err := filepath.WalkDir(s.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == s.root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(path, localPartialSuffix) != partials {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := strings.TrimSuffix(filepath.ToSlash(relPath), localPartialSuffix)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, DumpObject{Key: key, Size: info.Size(), ModifiedAt: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", s.root, err)
+	}
+
+	return objects, nil
+}
+
+func (s *LocalDumpStorage) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalDumpStorage) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	objects, err := s.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, object := range objects {
+		if err := s.Delete(ctx, object.Key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	// Interrupted downloads are not listed but still take up space
+	partials, err := s.walk(prefix, true)
+	if err != nil {
+		return removed, err
+	}
+	for _, partial := range partials {
+		if err := s.DiscardPartial(ctx, partial.Key); err != nil {
+			return removed, err
+		}
+	}
+
+	s.pruneEmptyDirectories()
+	return removed, nil
+}
+
+func (s *LocalDumpStorage) PartialSize(ctx context.Context, key string) (int64, error) {
+	info, err := os.Stat(s.partialPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat partial %s: %w", key, err)
+	}
+	return info.Size(), nil
+}
+
+func (s *LocalDumpStorage) AppendPartial(
+	ctx context.Context,
+	key string,
+	offset int64,
+	body io.Reader,
+) error {
+	partial := s.partialPath(key)
+	if err := os.MkdirAll(filepath.Dir(partial), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open partial %s: %w", key, err)
+	}
+
+	// Drop anything past offset so a response that starts earlier than the kept data
+	// never leaves stale bytes at the end of the file
+	if err := file.Truncate(offset); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to truncate partial %s: %w", key, err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to seek partial %s: %w", key, err)
+	}
+
+	_, copyErr := io.Copy(file, contextReader{ctx: ctx, reader: body})
+	closeErr := file.Close()
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		return fmt.Errorf("failed to write partial %s: %w", key, copyErr)
+	}
+	return nil
+}
+
+func (s *LocalDumpStorage) CommitPartial(ctx context.Context, key string) error {
+	if err := os.Rename(s.partialPath(key), s.path(key)); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalDumpStorage) DiscardPartial(ctx context.Context, key string) error {
+	if err := os.Remove(s.partialPath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to discard partial %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalDumpStorage) Location(key string) string {
+	return s.path(key)
+}
+
+func (s *LocalDumpStorage) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+func (s *LocalDumpStorage) partialPath(key string) string {
+	return s.path(key) + localPartialSuffix
+}
+
+// pruneEmptyDirectories removes the year-month directories left empty by DeletePrefix
+func (s *LocalDumpStorage) pruneEmptyDirectories() {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			// Remove only succeeds on empty directories
+			_ = os.Remove(filepath.Join(s.root, entry.Name()))
+		}
+	}
+}
+
+// contextReader stops a copy once ctx is cancelled
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
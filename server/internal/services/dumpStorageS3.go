@@ -0,0 +1,374 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// Multipart part size for uploads of unknown length and size of the staged parts of partial writes.
+// The largest dump (releases) stays well under the 10,000 part limit at this size.
+const s3DumpPartSize = 64 * 1024 * 1024
+
+// Partial writes are staged as part objects under the target key with this suffix, each named by
+// the offset it starts at, and composed into the target once complete. S3 objects can not be
+// appended to, so this keeps every part that finished uploading when a download is interrupted.
+const s3PartialSuffix = ".part/"
+
+type S3DumpStorageConfig struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// Prefix namespaces every key inside the bucket, e.g. "discogs"
+	Prefix string
+}
+
+// S3DumpStorage keeps dump files in an S3-compatible bucket (AWS S3, MinIO, R2, ...). It keeps the
+// data of interrupted downloads as staged part objects, so it also implements ResumableDumpStorage.
+// The bucket must support composing objects server side (UploadPartCopy).
+type S3DumpStorage struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3DumpStorage(cfg S3DumpStorageConfig) (*S3DumpStorage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 dump storage requires an endpoint and a bucket")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	return &S3DumpStorage{
+		client: client,
+		bucket: cfg.Bucket,
+		prefix: strings.Trim(cfg.Prefix, "/"),
+	}, nil
+}
+
+func (s *S3DumpStorage) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	// Multipart uploads are only completed once the body is fully read, so a failed
+	// transfer is aborted instead of leaving a truncated object
+	_, err := s.client.PutObject(ctx, s.bucket, s.objectName(key), body, size, minio.PutObjectOptions{
+		PartSize:    s3DumpPartSize,
+		ContentType: dumpContentType(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3DumpStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	// GetObject is lazy, stat first so a missing object fails here rather than on first read
+	if _, err := s.Stat(ctx, key); err != nil {
+		return nil, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, s.objectName(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	return object, nil
+}
+
+func (s *S3DumpStorage) Stat(ctx context.Context, key string) (*DumpObject, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, s.objectName(key), minio.StatObjectOptions{})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrDumpObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+
+	return &DumpObject{Key: key, Size: info.Size, ModifiedAt: info.LastModified}, nil
+}
+
+func (s *S3DumpStorage) List(ctx context.Context, prefix string) ([]DumpObject, error) {
+	return s.list(ctx, prefix, false)
+}
+
+// list returns the completed objects under prefix, or the staged parts of partial writes when
+// partials is set
+func (s *S3DumpStorage) list(ctx context.Context, prefix string, partials bool) ([]DumpObject, error) {
+	objects := []DumpObject{}
+
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    s.objectName(prefix),
+		Recursive: true,
+	}) {
+		if info.Err != nil {
+			return nil, fmt.Errorf("failed to list objects under %q: %w", prefix, info.Err)
+		}
+		if strings.Contains(info.Key, s3PartialSuffix) != partials {
+			continue
+		}
+		objects = append(objects, DumpObject{
+			Key:        s.keyFromObjectName(info.Key),
+			Size:       info.Size,
+			ModifiedAt: info.LastModified,
+		})
+	}
+
+	return objects, nil
+}
+
+func (s *S3DumpStorage) Delete(ctx context.Context, key string) error {
+	// S3 deletes are idempotent, a missing key already succeeds
+	if err := s.client.RemoveObject(ctx, s.bucket, s.objectName(key), minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3DumpStorage) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	objects, err := s.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, object := range objects {
+		if err := s.Delete(ctx, object.Key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	// Interrupted downloads are not listed but still take up space
+	parts, err := s.list(ctx, prefix, true)
+	if err != nil {
+		return removed, err
+	}
+	for _, part := range parts {
+		if err := s.Delete(ctx, part.Key); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// s3Part is a staged part object of a partial write
+type s3Part struct {
+	key    string
+	offset int64
+	size   int64
+}
+
+func (s *S3DumpStorage) PartialSize(ctx context.Context, key string) (int64, error) {
+	parts, err := s.partialParts(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	_, size := contiguousParts(parts)
+	return size, nil
+}
+
+// AppendPartial uploads body in parts of s3DumpPartSize. A part is only stored once it is complete,
+// so an interrupted write keeps every part before the one in flight. Stored parts can not be cut,
+// so offset has to be a part boundary as reported by PartialSize.
+func (s *S3DumpStorage) AppendPartial(
+	ctx context.Context,
+	key string,
+	offset int64,
+	body io.Reader,
+) error {
+	parts, err := s.partialParts(ctx, key)
+	if err != nil {
+		return err
+	}
+	if _, size := contiguousParts(parts); offset > size {
+		return fmt.Errorf("partial %s holds %d bytes, can not write at %d", key, size, offset)
+	}
+
+	// Drop anything past offset, parts after a gap included
+	cut := int64(-1)
+	for _, part := range parts {
+		if part.offset+part.size <= offset {
+			continue
+		}
+		if err := s.Delete(ctx, part.key); err != nil {
+			return fmt.Errorf("failed to drop part of partial %s: %w", key, err)
+		}
+		if part.offset < offset {
+			cut = part.offset
+		}
+	}
+	if cut >= 0 {
+		return fmt.Errorf("partial %s has no part boundary at %d, kept data up to %d", key, offset, cut)
+	}
+
+	reader := contextReader{ctx: ctx, reader: body}
+	var chunk bytes.Buffer
+	for {
+		chunk.Reset()
+		read, readErr := io.CopyN(&chunk, reader, s3DumpPartSize)
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return fmt.Errorf("failed to read partial %s: %w", key, readErr)
+		}
+		if read == 0 {
+			return nil
+		}
+
+		_, err := s.client.PutObject(ctx, s.bucket, s.objectName(partKey(key, offset)),
+			bytes.NewReader(chunk.Bytes()), read, minio.PutObjectOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to upload part of partial %s at %d: %w", key, offset, err)
+		}
+		offset += read
+
+		if readErr != nil {
+			return nil
+		}
+	}
+}
+
+func (s *S3DumpStorage) CommitPartial(ctx context.Context, key string) error {
+	parts, err := s.partialParts(ctx, key)
+	if err != nil {
+		return err
+	}
+	if complete, _ := contiguousParts(parts); len(complete) != len(parts) {
+		return fmt.Errorf("partial %s has a gap after %d parts", key, len(complete))
+	}
+
+	// An empty write stores no parts
+	if len(parts) == 0 {
+		return s.Put(ctx, key, bytes.NewReader(nil), 0)
+	}
+
+	sources := make([]minio.CopySrcOptions, len(parts))
+	for i, part := range parts {
+		sources[i] = minio.CopySrcOptions{Bucket: s.bucket, Object: s.objectName(part.key)}
+	}
+	_, err = s.client.ComposeObject(ctx, minio.CopyDestOptions{
+		Bucket:          s.bucket,
+		Object:          s.objectName(key),
+		UserMetadata:    map[string]string{"Content-Type": dumpContentType(key)},
+		ReplaceMetadata: true,
+	}, sources...)
+	if err != nil {
+		return fmt.Errorf("failed to compose %s from %d parts: %w", key, len(parts), err)
+	}
+
+	// The object is in place, parts left behind by a failed cleanup are dropped by the next write
+	// of key or by DeletePrefix
+	_ = s.DiscardPartial(ctx, key)
+	return nil
+}
+
+func (s *S3DumpStorage) DiscardPartial(ctx context.Context, key string) error {
+	parts, err := s.partialParts(ctx, key)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		if err := s.Delete(ctx, part.key); err != nil {
+			return fmt.Errorf("failed to discard partial %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// partialParts returns the staged parts of key ordered by offset
+func (s *S3DumpStorage) partialParts(ctx context.Context, key string) ([]s3Part, error) {
+	objects, err := s.list(ctx, key+s3PartialSuffix, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partial %s: %w", key, err)
+	}
+	return parsePartialParts(key, objects), nil
+}
+
+func partKey(key string, offset int64) string {
+	// Zero padded so parts also list in offset order
+	return fmt.Sprintf("%s%s%020d", key, s3PartialSuffix, offset)
+}
+
+// parsePartialParts reads the offsets of the staged parts of key from their keys, ignoring
+// anything else stored under the partial prefix
+func parsePartialParts(key string, objects []DumpObject) []s3Part {
+	parts := make([]s3Part, 0, len(objects))
+	for _, object := range objects {
+		offset, err := strconv.ParseInt(strings.TrimPrefix(object.Key, key+s3PartialSuffix), 10, 64)
+		if err != nil || offset < 0 {
+			continue
+		}
+		parts = append(parts, s3Part{key: object.Key, offset: offset, size: object.Size})
+	}
+
+	slices.SortFunc(parts, func(a, b s3Part) int {
+		return cmp.Compare(a.offset, b.offset)
+	})
+	return parts
+}
+
+// contiguousParts returns the leading parts that continue each other from offset zero and the
+// bytes they hold. Parts after a gap can not be resumed from.
+func contiguousParts(parts []s3Part) ([]s3Part, int64) {
+	var size int64
+	for i, part := range parts {
+		if part.offset != size {
+			return parts[:i], size
+		}
+		size += part.size
+	}
+	return parts, size
+}
+
+func (s *S3DumpStorage) Location(key string) string {
+	return fmt.Sprintf("s3://%s/%s", s.bucket, s.objectName(key))
+}
+
+func (s *S3DumpStorage) objectName(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	// path.Join would drop a trailing slash, which list prefixes rely on
+	return s.prefix + "/" + key
+}
+
+func (s *S3DumpStorage) keyFromObjectName(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return strings.TrimPrefix(name, s.prefix+"/")
+}
+
+func isS3NotFound(err error) bool {
+	response := minio.ToErrorResponse(err)
+	return response.StatusCode == http.StatusNotFound ||
+		response.Code == "NoSuchKey" ||
+		response.Code == "NotFound"
+}
+
+func dumpContentType(key string) string {
+	switch path.Ext(key) {
+	case ".gz":
+		return "application/gzip"
+	case ".txt":
+		return "text/plain"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	logger "github.com/Bparsons0904/goLogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDumpStorage is an in-memory DumpStorage for tests
+type memoryDumpStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryDumpStorage() *memoryDumpStorage {
+	return &memoryDumpStorage{objects: map[string][]byte{}}
+}
+
+func (s *memoryDumpStorage) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memoryDumpStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDumpObjectNotFound, key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryDumpStorage) Stat(ctx context.Context, key string) (*DumpObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDumpObjectNotFound, key)
+	}
+	return &DumpObject{Key: key, Size: int64(len(data))}, nil
+}
+
+func (s *memoryDumpStorage) List(ctx context.Context, prefix string) ([]DumpObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects := []DumpObject{}
+	for key, data := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, DumpObject{Key: key, Size: int64(len(data))})
+		}
+	}
+	return objects, nil
+}
+
+func (s *memoryDumpStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryDumpStorage) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.objects, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (s *memoryDumpStorage) Location(key string) string {
+	return "memory://" + key
+}
+
+func TestDumpStorageKeys(t *testing.T) {
+	assert.Equal(t, "2025-01/releases.xml.gz", DumpFileKey("2025-01", "releases"))
+	assert.Equal(t, "2025-01/CHECKSUM.txt", DumpChecksumKey("2025-01"))
+	assert.Equal(t, "2025-01/", dumpYearMonthPrefix("2025-01"))
+}
+
+func TestLocalDumpStorageRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "discogs-data")
+	storage := NewLocalDumpStorage(root)
+
+	// A missing root lists as empty rather than failing
+	objects, err := storage.List(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+
+	require.NoError(t, storage.Put(ctx, "2025-01/labels.xml.gz", strings.NewReader("labels"), 6))
+	require.NoError(t, storage.Put(ctx, "2025-01/CHECKSUM.txt", strings.NewReader("sums"), -1))
+	require.NoError(t, storage.Put(ctx, "2025-02/labels.xml.gz", strings.NewReader("newer"), 5))
+
+	object, err := storage.Stat(ctx, "2025-01/labels.xml.gz")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), object.Size)
+
+	_, err = storage.Stat(ctx, "2025-01/artists.xml.gz")
+	assert.True(t, errors.Is(err, ErrDumpObjectNotFound))
+
+	reader, err := storage.Open(ctx, "2025-02/labels.xml.gz")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, "newer", string(data))
+
+	objects, err = storage.List(ctx, "2025-01/")
+	require.NoError(t, err)
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{"2025-01/CHECKSUM.txt", "2025-01/labels.xml.gz"}, keys)
+
+	removed, err := storage.DeletePrefix(ctx, "2025-01/")
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.NoDirExists(t, filepath.Join(root, "2025-01"))
+
+	require.NoError(t, storage.Delete(ctx, "2025-02/labels.xml.gz"))
+	require.NoError(t, storage.Delete(ctx, "2025-02/labels.xml.gz"), "deleting a missing key is not an error")
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestLocalDumpStorageFailedPutLeavesNoObject(t *testing.T) {
+	ctx := context.Background()
+	storage := NewLocalDumpStorage(t.TempDir())
+
+	require.NoError(t, storage.Put(ctx, "2025-01/labels.xml.gz", strings.NewReader("previous"), 8))
+
+	err := storage.Put(ctx, "2025-01/labels.xml.gz", io.MultiReader(strings.NewReader("part"), failingReader{}), -1)
+	require.Error(t, err)
+
+	reader, err := storage.Open(ctx, "2025-01/labels.xml.gz")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(reader)
+	_ = reader.Close()
+	assert.Equal(t, "previous", string(data), "a failed put must not replace the stored object")
+
+	objects, err := storage.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
+
+func TestFileCleanupServiceUsesStorage(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemoryDumpStorage()
+	service := &FileCleanupService{log: logger.New("test"), storage: storage}
+
+	require.NoError(t, storage.Put(ctx, "2025-01/releases.xml.gz", strings.NewReader("a"), 1))
+	require.NoError(t, storage.Put(ctx, "2025-02/releases.xml.gz", strings.NewReader("b"), 1))
+
+	files, err := service.ListStoredFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.True(t, files[0].IsXML)
+	assert.True(t, files[0].IsGZ)
+
+	require.NoError(t, service.CleanupYearMonth(ctx, "2025-01"))
+	files, err = service.ListStoredFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "2025-02/releases.xml.gz", files[0].Path)
+
+	require.NoError(t, service.CleanupAllFiles(ctx))
+	files, err = service.ListStoredFiles(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestDownloadServiceValidatesStoredChecksum(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemoryDumpStorage()
+	service := &DownloadService{log: logger.New("test"), storage: storage}
+
+	key := DumpFileKey("2025-01", "labels")
+	require.NoError(t, storage.Put(ctx, key, strings.NewReader("hello"), 5))
+
+	// sha256("hello")
+	checksum := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	info, err := service.GetFileStatus(ctx, key, checksum)
+	require.NoError(t, err)
+	assert.True(t, info.Validated)
+	assert.Equal(t, int64(5), info.Size)
+
+	info, err = service.GetFileStatus(ctx, key, strings.Repeat("0", 64))
+	require.NoError(t, err)
+	assert.False(t, info.Validated)
+
+	info, err = service.GetFileStatus(ctx, DumpFileKey("2025-01", "artists"), checksum)
+	require.NoError(t, err)
+	assert.False(t, info.Downloaded)
+}
+
+
+func TestS3PartialPartsResumeFromContiguousData(t *testing.T) {
+	key := "2025-01/releases.xml.gz"
+	objects := []DumpObject{
+		{Key: partKey(key, 20), Size: 5},
+		{Key: partKey(key, 0), Size: 10},
+		{Key: partKey(key, 10), Size: 10},
+		{Key: key + s3PartialSuffix + "notes.txt", Size: 1},
+	}
+
+	parts := parsePartialParts(key, objects)
+	require.Len(t, parts, 3, "objects not named by an offset are ignored")
+	assert.Equal(t, []int64{0, 10, 20}, []int64{parts[0].offset, parts[1].offset, parts[2].offset})
+
+	complete, size := contiguousParts(parts)
+	assert.Len(t, complete, 3)
+	assert.Equal(t, int64(25), size)
+
+	// A missing part ends the data that can be resumed from
+	complete, size = contiguousParts(parsePartialParts(key, []DumpObject{objects[0], objects[1]}))
+	assert.Len(t, complete, 1)
+	assert.Equal(t, int64(10), size)
+
+	complete, size = contiguousParts(nil)
+	assert.Empty(t, complete)
+	assert.Zero(t, size)
+}
+
+func TestS3PartKeysListInOffsetOrder(t *testing.T) {
+	key := "2025-01/releases.xml.gz"
+	assert.Less(t, partKey(key, 9*s3DumpPartSize), partKey(key, 10*s3DumpPartSize))
+	assert.True(t, strings.HasPrefix(partKey(key, 0), key+s3PartialSuffix))
+}
@@ -2,8 +2,7 @@ package services
 
 import (
 	"context"
-	"os"
-	"path/filepath"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -12,14 +11,16 @@ import (
 )
 
 type FileCleanupService struct {
-	config config.Config
-	log    logger.Logger
+	config  config.Config
+	log     logger.Logger
+	storage DumpStorage
 }
 
-func NewFileCleanupService(config config.Config) *FileCleanupService {
+func NewFileCleanupService(config config.Config, storage DumpStorage) *FileCleanupService {
 	return &FileCleanupService{
-		config: config,
-		log:    logger.New("fileCleanupService"),
+		config:  config,
+		log:     logger.New("fileCleanupService"),
+		storage: storage,
 	}
 }
 
@@ -34,42 +35,22 @@ type StoredFile struct {
 func (fcs *FileCleanupService) ListStoredFiles(ctx context.Context) ([]StoredFile, error) {
 	log := fcs.log.Function("ListStoredFiles")
 
-	if _, err := os.Stat(DiscogsDataDir); os.IsNotExist(err) {
-		log.Info("Download directory does not exist", "directory", DiscogsDataDir)
-		return []StoredFile{}, nil
+	objects, err := fcs.storage.List(ctx, "")
+	if err != nil {
+		return nil, log.Err("failed to list stored files", err, "location", fcs.storage.Location(""))
 	}
 
-	var files []StoredFile
-
-	err := filepath.Walk(DiscogsDataDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		relPath, err := filepath.Rel(DiscogsDataDir, path)
-		if err != nil {
-			return err
-		}
-
-		fileName := info.Name()
+	files := make([]StoredFile, 0, len(objects))
+	for _, object := range objects {
+		fileName := path.Base(object.Key)
 
 		files = append(files, StoredFile{
-			Path:       relPath,
-			Size:       info.Size(),
-			ModifiedAt: info.ModTime(),
+			Path:       object.Key,
+			Size:       object.Size,
+			ModifiedAt: object.ModifiedAt,
 			IsXML:      strings.HasSuffix(fileName, ".xml") || strings.Contains(fileName, ".xml."),
 			IsGZ:       strings.HasSuffix(fileName, ".gz"),
 		})
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, log.Err("failed to walk directory", err, "directory", DiscogsDataDir)
 	}
 
 	log.Info("Listed stored files", "count", len(files))
@@ -79,30 +60,12 @@ func (fcs *FileCleanupService) ListStoredFiles(ctx context.Context) ([]StoredFil
 func (fcs *FileCleanupService) CleanupAllFiles(ctx context.Context) error {
 	log := fcs.log.Function("CleanupAllFiles")
 
-	if _, err := os.Stat(DiscogsDataDir); os.IsNotExist(err) {
-		log.Info("Download directory does not exist, nothing to cleanup", "directory", DiscogsDataDir)
-		return nil
-	}
-
-	entries, err := os.ReadDir(DiscogsDataDir)
+	removed, err := fcs.storage.DeletePrefix(ctx, "")
 	if err != nil {
-		return log.Err("failed to read download directory", err, "directory", DiscogsDataDir)
-	}
-
-	var errors []error
-	for _, entry := range entries {
-		entryPath := filepath.Join(DiscogsDataDir, entry.Name())
-		if err := os.RemoveAll(entryPath); err != nil {
-			errors = append(errors, err)
-			log.Er("failed to remove entry", err, "path", entryPath)
-		}
-	}
-
-	if len(errors) > 0 {
-		return log.Err("failed to cleanup some files", errors[0], "errorCount", len(errors))
+		return log.Err("failed to cleanup stored files", err, "location", fcs.storage.Location(""), "filesRemoved", removed)
 	}
 
-	log.Info("Successfully cleaned up all files", "directory", DiscogsDataDir, "itemsRemoved", len(entries))
+	log.Info("Successfully cleaned up all files", "location", fcs.storage.Location(""), "filesRemoved", removed)
 	return nil
 }
 
@@ -113,18 +76,14 @@ func (fcs *FileCleanupService) CleanupYearMonth(ctx context.Context, yearMonth s
 		return log.Err("invalid yearMonth format", nil, "yearMonth", yearMonth, "expected", "YYYY-MM")
 	}
 
-	downloadDir := filepath.Join(DiscogsDataDir, yearMonth)
-
-	if _, err := os.Stat(downloadDir); os.IsNotExist(err) {
-		log.Info("Download directory does not exist, nothing to cleanup", "directory", downloadDir)
-		return nil
-	}
+	prefix := dumpYearMonthPrefix(yearMonth)
 
-	if err := os.RemoveAll(downloadDir); err != nil {
-		return log.Err("failed to remove download directory", err, "directory", downloadDir)
+	removed, err := fcs.storage.DeletePrefix(ctx, prefix)
+	if err != nil {
+		return log.Err("failed to remove year-month files", err, "prefix", prefix, "filesRemoved", removed)
 	}
 
-	log.Info("Successfully cleaned up year-month directory", "directory", downloadDir)
+	log.Info("Successfully cleaned up year-month files", "prefix", prefix, "filesRemoved", removed)
 	return nil
 }
 
@@ -4,10 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 	logger "github.com/Bparsons0904/goLogger"
 	. "waugzee/internal/models"
 	"waugzee/internal/repositories"
+	"waugzee/internal/types"
 
 	"gorm.io/gorm"
 )
@@ -24,8 +24,9 @@ func NewFolderDataExtractionService(repos repositories.Repository) *FolderDataEx
 	}
 }
 
-// ExtractBasicInformation processes basic_information from folder releases and creates/updates
-// minimal Artist, Master, Release, Label, and Genre records
+// ExtractBasicInformation processes basic_information from folder releases and creates minimal
+// Artist, Master, Release, Label, and Genre records. Rows that already exist, whether loaded from
+// the monthly dump or a full API sync, are left as they are.
 func (f *FolderDataExtractionService) ExtractBasicInformation(
 	ctx context.Context,
 	tx *gorm.DB,
@@ -196,16 +197,17 @@ func (f *FolderDataExtractionService) ExtractBasicInformation(
 		// They could be processed as sub-genres in the future if needed
 	}
 
-	// Use batch operations for efficient database operations
+	// Use batch operations for efficient database operations. Only missing rows are inserted so
+	// basic information never replaces the fuller catalog data.
 	if len(artists) > 0 {
-		if err := f.repos.Artist.UpsertBatch(ctx, tx, artists); err != nil {
-			return log.Err("failed to upsert artists", err)
+		if err := f.repos.Artist.InsertMissingBatch(ctx, tx, artists); err != nil {
+			return log.Err("failed to insert artists", err)
 		}
 	}
 
 	if len(labels) > 0 {
-		if err := f.repos.Label.UpsertBatch(ctx, tx, labels); err != nil {
-			return log.Err("failed to upsert labels", err)
+		if err := f.repos.Label.InsertMissingBatch(ctx, tx, labels); err != nil {
+			return log.Err("failed to insert labels", err)
 		}
 	}
 
@@ -216,14 +218,14 @@ func (f *FolderDataExtractionService) ExtractBasicInformation(
 	}
 
 	if len(masters) > 0 {
-		if err := f.repos.Master.UpsertBatch(ctx, tx, masters); err != nil {
-			return log.Err("failed to upsert masters", err)
+		if err := f.repos.Master.InsertMissingBatch(ctx, tx, masters); err != nil {
+			return log.Err("failed to insert masters", err)
 		}
 	}
 
 	if len(releases) > 0 {
-		if err := f.repos.Release.UpsertBatch(ctx, tx, releases); err != nil {
-			return log.Err("failed to upsert releases", err)
+		if err := f.repos.Release.InsertMissingBatch(ctx, tx, releases); err != nil {
+			return log.Err("failed to insert releases", err)
 		}
 	}
 
@@ -331,7 +333,8 @@ func (f *FolderDataExtractionService) createReleaseAssociations(
 	return nil
 }
 
-// GetRecordsNeedingFullData identifies records that need full data population (LastSynced is null)
+// GetRecordsNeedingFullData identifies stored releases that only hold collection basic information,
+// i.e. neither the dump nor a full API sync has populated them
 func (f *FolderDataExtractionService) GetRecordsNeedingFullData(
 	ctx context.Context,
 	tx *gorm.DB,
@@ -343,27 +346,134 @@ func (f *FolderDataExtractionService) GetRecordsNeedingFullData(
 		return []int64{}, nil
 	}
 
-	var releases []struct {
-		ID         int64      `json:"discogsId"`
-		LastSynced *time.Time `json:"lastSynced"`
-	}
-
-	if err := tx.WithContext(ctx).
-		Model(&Release{}).
-		Select("id, last_synced").
-		Where("id IN ? AND last_synced IS NULL", releaseIDs).
-		Find(&releases).Error; err != nil {
+	states, err := f.repos.Release.GetCatalogStates(ctx, tx, releaseIDs)
+	if err != nil {
 		return nil, log.Err("failed to get releases needing full data", err)
 	}
 
-	needingFullData := make([]int64, len(releases))
-	for i, release := range releases {
-		needingFullData[i] = release.ID
+	needingFullData := make([]int64, 0)
+	for _, state := range states {
+		if !hasFullReleaseData(state) {
+			needingFullData = append(needingFullData, state.ID)
+		}
 	}
 
 	return needingFullData, nil
 }
 
+// CatalogEnrichmentResult splits a user's releases by where their full data comes from
+type CatalogEnrichmentResult struct {
+	// FromCatalog already hold tracklists, durations, credits and masters from the local catalog
+	FromCatalog []int64
+	// NeedsAPI are missing from the catalog and have to be fetched release by release
+	NeedsAPI []int64
+}
+
+// EnrichFromCatalog fills user releases from the local catalog tables before any API call is made.
+// Releases loaded by the monthly dump already carry their tracklist, master link and artist credits;
+// they are stamped as synced and get a total duration derived from their tracks when none is stored.
+// Only releases that are not stored, or hold nothing but collection basic information, are returned
+// for the per-release API fallback.
+func (f *FolderDataExtractionService) EnrichFromCatalog(
+	ctx context.Context,
+	tx *gorm.DB,
+	releaseIDs []int64,
+) (*CatalogEnrichmentResult, error) {
+	log := f.log.Function("EnrichFromCatalog")
+
+	if len(releaseIDs) == 0 {
+		return &CatalogEnrichmentResult{FromCatalog: []int64{}, NeedsAPI: []int64{}}, nil
+	}
+
+	states, err := f.repos.Release.GetCatalogStates(ctx, tx, releaseIDs)
+	if err != nil {
+		return nil, log.Err("failed to get release catalog states", err)
+	}
+
+	result, updates := classifyCatalogReleases(releaseIDs, states)
+
+	if err := f.repos.Release.MarkCatalogSynced(ctx, tx, updates); err != nil {
+		return nil, log.Err("failed to mark releases synced from catalog", err)
+	}
+
+	log.Info("Enriched releases from catalog",
+		"totalReleases", len(releaseIDs),
+		"fromCatalog", len(result.FromCatalog),
+		"updated", len(updates),
+		"needsAPI", len(result.NeedsAPI))
+
+	return result, nil
+}
+
+// classifyCatalogReleases decides for each requested release whether the catalog covers it and
+// which stored rows still need their sync stamp or total duration filled in
+func classifyCatalogReleases(
+	releaseIDs []int64,
+	states []repositories.ReleaseCatalogState,
+) (*CatalogEnrichmentResult, []repositories.ReleaseCatalogUpdate) {
+	statesByID := make(map[int64]repositories.ReleaseCatalogState, len(states))
+	for _, state := range states {
+		statesByID[state.ID] = state
+	}
+
+	result := &CatalogEnrichmentResult{FromCatalog: []int64{}, NeedsAPI: []int64{}}
+	updates := make([]repositories.ReleaseCatalogUpdate, 0)
+	seen := make(map[int64]bool, len(releaseIDs))
+
+	for _, id := range releaseIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		state, ok := statesByID[id]
+		if !ok || !hasFullReleaseData(state) {
+			result.NeedsAPI = append(result.NeedsAPI, id)
+			continue
+		}
+		result.FromCatalog = append(result.FromCatalog, id)
+
+		update := repositories.ReleaseCatalogUpdate{ReleaseID: id}
+		if state.TotalDuration == nil {
+			update.TotalDuration = catalogTotalDuration(state.TracksJSON, state.FormatDetailsJSON)
+		}
+		if state.LastSynced == nil || update.TotalDuration != nil {
+			updates = append(updates, update)
+		}
+	}
+
+	return result, updates
+}
+
+// hasFullReleaseData reports whether a stored release came from the dump or a full API sync rather
+// than only the basic information of a collection page, which never includes a tracklist
+func hasFullReleaseData(state repositories.ReleaseCatalogState) bool {
+	return state.ContentHash != nil || state.LastSynced != nil || len(state.TracksJSON) > 0
+}
+
+// catalogTotalDuration derives a total duration from stored tracks, falling back to the disc count
+// of the stored format details like XML processing does
+func catalogTotalDuration(tracks []Track, formatDetailsJSON []byte) *int {
+	typeTracks := make([]types.Track, 0, len(tracks))
+	for _, track := range tracks {
+		typeTracks = append(typeTracks, types.Track{
+			Position: track.Position,
+			Title:    track.Title,
+			Duration: track.Duration,
+		})
+	}
+
+	var format types.Format
+	if len(formatDetailsJSON) > 0 {
+		var details FormatDetails
+		if err := json.Unmarshal(formatDetailsJSON, &details); err == nil {
+			format = types.Format{Name: details.Name, Qty: details.Qty, Text: details.Text}
+		}
+	}
+
+	return calculateTotalDuration(typeTracks, format)
+}
+
 // createMasterAssociations handles the many-to-many relationships between masters and other entities
 func (f *FolderDataExtractionService) createMasterAssociations(
 	ctx context.Context,
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyCatalogReleases(t *testing.T) {
+	hash := "abc123"
+	synced := time.Now()
+	duration := 1800
+
+	states := []repositories.ReleaseCatalogState{
+		// Loaded by the dump, never stamped and without a stored duration
+		{
+			ID:          1,
+			ContentHash: &hash,
+			TracksJSON:  []Track{{Position: "A1", Duration: "3:30"}, {Position: "A2", Duration: "4:00"}},
+		},
+		// Loaded by the dump and already enriched by an earlier sync
+		{ID: 2, ContentHash: &hash, LastSynced: &synced, TotalDuration: &duration},
+		// Inserted from collection basic information only
+		{ID: 3},
+		// Fetched from the API without a tracklist
+		{ID: 4, LastSynced: &synced, TotalDuration: &duration},
+	}
+
+	result, updates := classifyCatalogReleases([]int64{1, 2, 3, 4, 5, 1}, states)
+
+	assert.Equal(t, []int64{1, 2, 4}, result.FromCatalog)
+	assert.Equal(t, []int64{3, 5}, result.NeedsAPI)
+
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, int64(1), updates[0].ReleaseID)
+		if assert.NotNil(t, updates[0].TotalDuration) {
+			assert.Equal(t, 450, *updates[0].TotalDuration)
+		}
+	}
+}
+
+func TestCatalogTotalDuration(t *testing.T) {
+	t.Run("Sums track durations", func(t *testing.T) {
+		total := catalogTotalDuration([]Track{{Duration: "1:00:00"}, {Duration: "2:30"}}, nil)
+		if assert.NotNil(t, total) {
+			assert.Equal(t, 3750, *total)
+		}
+	})
+
+	t.Run("Falls back to disc count from format details", func(t *testing.T) {
+		total := catalogTotalDuration([]Track{{Duration: ""}}, []byte(`{"name":"Vinyl","qty":"2"}`))
+		if assert.NotNil(t, total) {
+			assert.Equal(t, 4800, *total)
+		}
+	})
+
+	t.Run("Returns nil without durations or format", func(t *testing.T) {
+		assert.Nil(t, catalogTotalDuration(nil, nil))
+	})
+}
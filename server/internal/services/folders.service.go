@@ -26,6 +26,7 @@ type FoldersService struct {
 	folderDataExtractionService *FolderDataExtractionService
 	folderValidationService     *FolderValidationService
 	discogsRateLimiter          *DiscogsRateLimiterService
+	imageService                *ImageService
 }
 
 func NewFoldersService(
@@ -35,6 +36,7 @@ func NewFoldersService(
 	transactionService *TransactionService,
 	folderDataExtractionService *FolderDataExtractionService,
 	discogsRateLimiter *DiscogsRateLimiterService,
+	imageService *ImageService,
 ) *FoldersService {
 	log := logger.New("FoldersService")
 	folderValidationService := NewFolderValidationService(repos, db)
@@ -47,6 +49,7 @@ func NewFoldersService(
 		folderDataExtractionService: folderDataExtractionService,
 		folderValidationService:     folderValidationService,
 		discogsRateLimiter:          discogsRateLimiter,
+		imageService:                imageService,
 	}
 }
 
@@ -89,26 +92,15 @@ func (f *FoldersService) RequestUserFolders(
 		DiscogsAPIBaseURL,
 		*user.Configuration.DiscogsUsername,
 	)
-	message := events.Message{
-		ID:      requestID,
-		Service: events.API,
-		Event:   "api_request",
-		UserID:  user.ID.String(),
-		Payload: map[string]any{
-			"requestId":   requestID,
-			"requestType": "folders",
-			"url":         fullURL,
-			"method":      "GET",
-			"headers": map[string]string{
-				"Authorization": fmt.Sprintf("Discogs token=%s", *user.Configuration.DiscogsToken),
-			},
-			"callbackService": "orchestration",
-			"callbackEvent":   "api_response",
-		},
-		Timestamp: time.Now(),
-	}
-
-	if err := f.eventBus.Publish(events.WEBSOCKET, "user", message); err != nil {
+	message, err := newAPIRequestMessage(user.ID, *user.Configuration.DiscogsToken, events.APIRequest{
+		RequestID:   requestID,
+		RequestType: "folders",
+		URL:         fullURL,
+	})
+	if err == nil {
+		err = f.eventBus.Publish(events.WEBSOCKET, "user", message)
+	}
+	if err != nil {
 		_ = database.NewCacheBuilder(f.db.Cache.ClientAPI, requestID).
 			WithHashPattern(API_HASH).
 			WithContext(ctx).
@@ -373,28 +365,17 @@ func (f *FoldersService) RequestFolderReleases(
 		page,
 	)
 
-	message := events.Message{
-		ID:      requestID,
-		Service: events.API,
-		Event:   "api_request",
-		UserID:  user.ID.String(),
-		Payload: map[string]any{
-			"requestId":   requestID,
-			"requestType": "folder_releases",
-			"folderID":    folderID,
-			"page":        page,
-			"url":         fullURL,
-			"method":      "GET",
-			"headers": map[string]string{
-				"Authorization": fmt.Sprintf("Discogs token=%s", *user.Configuration.DiscogsToken),
-			},
-			"callbackService": "orchestration",
-			"callbackEvent":   "api_response",
-		},
-		Timestamp: time.Now(),
-	}
-
-	if err := f.eventBus.Publish(events.WEBSOCKET, "user", message); err != nil {
+	message, err := newAPIRequestMessage(user.ID, *user.Configuration.DiscogsToken, events.APIRequest{
+		RequestID:   requestID,
+		RequestType: "folder_releases",
+		URL:         fullURL,
+		FolderID:    &folderID,
+		Page:        &page,
+	})
+	if err == nil {
+		err = f.eventBus.Publish(events.WEBSOCKET, "user", message)
+	}
+	if err != nil {
 		_ = database.NewCacheBuilder(f.db.Cache.ClientAPI, requestID).
 			WithHashPattern(API_HASH).
 			WithContext(ctx).
@@ -491,28 +472,19 @@ func (f *FoldersService) ProcessFolderReleasesResponse(
 				return nil
 			}
 
-			message := events.Message{
-				ID:      requestID,
-				Service: events.API,
-				Event:   "api_request",
-				UserID:  metadata.UserID.String(),
-				Payload: map[string]any{
-					"requestId":   requestID,
-					"requestType": "folder_releases",
-					"folderID":    folderID,
-					"page":        currentPage + 1,
-					"url":         nextURL,
-					"method":      "GET",
-					"headers": map[string]string{
-						"Authorization": fmt.Sprintf("Discogs token=%s", metadata.DiscogsToken),
-					},
-					"callbackService": "orchestration",
-					"callbackEvent":   "api_response",
-				},
-				Timestamp: time.Now(),
+			nextPage := currentPage + 1
+			var message events.Message
+			message, err = newAPIRequestMessage(metadata.UserID, metadata.DiscogsToken, events.APIRequest{
+				RequestID:   requestID,
+				RequestType: "folder_releases",
+				URL:         nextURL,
+				FolderID:    &folderID,
+				Page:        &nextPage,
+			})
+			if err == nil {
+				err = f.eventBus.Publish(events.WEBSOCKET, "user", message)
 			}
-
-			if err = f.eventBus.Publish(events.WEBSOCKET, "user", message); err != nil {
+			if err != nil {
 				log.Warn("Failed to publish pagination request", "error", err)
 			}
 		} else {
@@ -610,6 +582,8 @@ func (f *FoldersService) ProcessFolderReleasesResponse(
 			}
 		}
 
+		f.publishCollectionSynced(ctx, metadata.UserID, syncState.MergedReleases)
+
 		// Clean up sync state and release queue
 		_ = database.NewCacheBuilder(f.db.Cache.ClientAPI, metadata.UserID.String()).
 			WithHashPattern(COLLECTION_SYNC_HASH).
@@ -633,18 +607,11 @@ func (f *FoldersService) ProcessFolderReleasesResponse(
 		log.Info("Sync completed - user will receive recommendation on next login", "userID", metadata.UserID)
 
 		// Send sync_complete event to notify client
-		completeMessage := events.Message{
-			ID:      metadata.UserID.String(),
-			Service: events.USER,
-			Event:   "sync_complete",
-			UserID:  metadata.UserID.String(),
-			Payload: map[string]any{
-				"message":       "Collection sync completed successfully",
-				"totalReleases": len(syncState.MergedReleases),
-			},
-			Timestamp: time.Now(),
+		syncCompleted := events.SyncCompleted{
+			Message:       "Collection sync completed successfully",
+			TotalReleases: len(syncState.MergedReleases),
 		}
-		if err = f.eventBus.Publish(events.WEBSOCKET, "user", completeMessage); err != nil {
+		if err = f.eventBus.PublishToUser(metadata.UserID.String(), syncCompleted); err != nil {
 			log.Warn("Failed to send sync_complete event", "error", err)
 		}
 	} else {
@@ -659,6 +626,16 @@ func (f *FoldersService) ProcessFolderReleasesResponse(
 			log.Warn("Failed to update sync state", "error", err)
 		}
 
+		syncProgress := events.SyncProgress{
+			SyncOperationID:  syncState.SyncOperationID,
+			ProcessedFolders: syncState.ProcessedFolders,
+			TotalFolders:     syncState.TotalFolders,
+			TotalReleases:    len(syncState.MergedReleases),
+		}
+		topic := events.SyncTopic(metadata.UserID.String())
+		if err = f.eventBus.PublishToTopic(topic, syncProgress); err != nil {
+			log.Warn("Failed to send sync_progress event", "error", err)
+		}
 	}
 
 	// Queue missing releases for processing only if sync is not complete
@@ -1016,7 +993,8 @@ func (f *FoldersService) executeSyncOperations(
 	return nil
 }
 
-// performReleaseValidation validates that releases exist and handles missing ones
+// performReleaseValidation validates that releases exist, enriches stored ones from the local catalog
+// and requests whatever the catalog cannot provide from the API
 func (f *FoldersService) performReleaseValidation(
 	ctx context.Context,
 	syncState *CollectionSyncState,
@@ -1046,6 +1024,23 @@ func (f *FoldersService) performReleaseValidation(
 		return log.Err("failed to validate releases", err)
 	}
 
+	// Fill stored releases from the local catalog first, only what the catalog lacks goes to the API
+	var enrichment *CatalogEnrichmentResult
+	err = f.transactionService.Execute(ctx, func(txCtx context.Context, tx *gorm.DB) error {
+		var enrichErr error
+		enrichment, enrichErr = f.folderDataExtractionService.EnrichFromCatalog(txCtx, tx, existingReleases)
+		return enrichErr
+	})
+	if err != nil {
+		return log.Err("failed to enrich releases from catalog", err)
+	}
+	missingReleases = append(missingReleases, enrichment.NeedsAPI...)
+
+	log.Info("Catalog enrichment complete",
+		"fromCatalog", len(enrichment.FromCatalog),
+		"apiRequestsAvoided", len(releaseIDs)-len(missingReleases),
+		"apiRequestsNeeded", len(missingReleases))
+
 	syncState.ExistingReleaseIDs = existingReleases
 	syncState.MissingReleaseIDs = missingReleases
 	syncState.ReleaseValidationDone = true
@@ -1171,6 +1166,8 @@ func (f *FoldersService) TriggerSyncCompletion(ctx context.Context, userID uuid.
 		}
 	}
 
+	f.publishCollectionSynced(ctx, userID, syncState.MergedReleases)
+
 	// Clean up sync state and release queue
 	_ = database.NewCacheBuilder(f.db.Cache.ClientAPI, userID.String()).
 		WithHashPattern(COLLECTION_SYNC_HASH).
@@ -1194,24 +1191,54 @@ func (f *FoldersService) TriggerSyncCompletion(ctx context.Context, userID uuid.
 	log.Info("Sync completed - user will receive recommendation on next login", "userID", userID)
 
 	// Send sync_complete event to notify client
-	completeMessage := events.Message{
-		ID:      userID.String(),
-		Service: events.USER,
-		Event:   "sync_complete",
-		UserID:  userID.String(),
-		Payload: map[string]any{
-			"message":       "Collection sync completed successfully",
-			"totalReleases": len(syncState.MergedReleases),
-		},
-		Timestamp: time.Now(),
-	}
-	if err = f.eventBus.Publish(events.WEBSOCKET, "user", completeMessage); err != nil {
+	syncCompleted := events.SyncCompleted{
+		Message:       "Collection sync completed successfully",
+		TotalReleases: len(syncState.MergedReleases),
+	}
+	if err = f.eventBus.PublishToUser(userID.String(), syncCompleted); err != nil {
 		log.Warn("Failed to send sync_complete event", "error", err)
 	}
 
 	return nil
 }
 
+// publishCollectionSynced hands the work following a sync, such as prewarming cover images, to
+// the durable SYNC channel
+func (f *FoldersService) publishCollectionSynced(
+	ctx context.Context,
+	userID uuid.UUID,
+	releases map[int]*UserRelease,
+) {
+	if len(releases) == 0 {
+		return
+	}
+
+	seen := make(map[int64]bool, len(releases))
+	releaseIDs := make([]int64, 0, len(releases))
+	for _, userRelease := range releases {
+		if !seen[userRelease.ReleaseID] {
+			seen[userRelease.ReleaseID] = true
+			releaseIDs = append(releaseIDs, userRelease.ReleaseID)
+		}
+	}
+
+	message, err := events.NewMessage(
+		events.SYSTEM,
+		userID.String(),
+		events.CollectionSynced{ReleaseIDs: releaseIDs},
+	)
+	if err == nil {
+		err = f.eventBus.Publish(events.SYNC, events.COLLECTION_SYNCED, message)
+	}
+	if err != nil {
+		log := f.log.Function("publishCollectionSynced")
+		log.Warn("Failed to publish collection synced, prewarming here", "userID", userID, "error", err)
+		if f.imageService != nil {
+			go f.imageService.PrewarmReleases(context.WithoutCancel(ctx), releaseIDs)
+		}
+	}
+}
+
 func (f *FoldersService) ClearSyncState(ctx context.Context, userID uuid.UUID) error {
 	log := f.log.Function("ClearSyncState")
 
@@ -1249,18 +1276,8 @@ func (f *FoldersService) clearSyncStateOnError(ctx context.Context, userID uuid.
 	}
 
 	// Send sync_error event to notify client
-	errorMessage := events.Message{
-		ID:      userID.String(),
-		Service: events.USER,
-		Event:   "sync_error",
-		UserID:  userID.String(),
-		Payload: map[string]any{
-			"error":   "Sync failed",
-			"message": reason,
-		},
-		Timestamp: time.Now(),
-	}
-	if err = f.eventBus.Publish(events.WEBSOCKET, "user", errorMessage); err != nil {
+	syncFailed := events.SyncFailed{Error: "Sync failed", Message: reason}
+	if err = f.eventBus.PublishToUser(userID.String(), syncFailed); err != nil {
 		log.Warn("Failed to send sync_error event", "error", err)
 	}
 }
@@ -73,7 +73,6 @@ func (gsm *GenreStyleManager) BatchUpsertMissingGenres(ctx context.Context, tx *
 		}
 	}
 
-
 	existingGenres := make([]*models.Genre, 0)
 	if err := tx.WithContext(ctx).Find(&existingGenres).Error; err != nil {
 		return log.Err("failed to fetch existing genres", err)
@@ -85,7 +84,6 @@ func (gsm *GenreStyleManager) BatchUpsertMissingGenres(ctx context.Context, tx *
 		existingMap[key] = genre
 	}
 
-
 	var newGenres []*models.Genre
 	for _, entry := range entries {
 		if _, exists := existingMap[entry.Key]; !exists {
@@ -123,6 +121,28 @@ func (gsm *GenreStyleManager) BatchUpsertMissingGenres(ctx context.Context, tx *
 	return nil
 }
 
+// EnsureLoaded fills the name lookup from stored genres when the collection and upsert
+// steps ran in an earlier process, such as when an association step resumes from a checkpoint
+func (gsm *GenreStyleManager) EnsureLoaded(ctx context.Context, tx *gorm.DB) error {
+	log := gsm.log.Function("EnsureLoaded")
+
+	if len(gsm.nameToID) > 0 {
+		return nil
+	}
+
+	genres, err := gsm.genreRepo.GetAll(ctx, tx)
+	if err != nil {
+		return log.Err("failed to load existing genres", err)
+	}
+
+	for _, genre := range genres {
+		gsm.nameToID[genre.NameLower+"|"+genre.Type] = genre.ID
+	}
+
+	log.Info("Loaded existing genres", "count", len(gsm.nameToID))
+	return nil
+}
+
 func (gsm *GenreStyleManager) GetGenreIDsByNames(genres []string, styles []string) []int64 {
 	var genreIDs []int64
 	keysSeen := make(map[string]struct{})
@@ -197,4 +217,3 @@ func (gsm *GenreStyleManager) GetStats() map[string]any {
 		"genreMappings":  len(gsm.nameToID),
 	}
 }
-
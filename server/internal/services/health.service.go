@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"waugzee/config"
+	"waugzee/internal/database"
+	"waugzee/internal/events"
+	logger "github.com/Bparsons0904/goLogger"
+)
+
+type HealthStatus string
+
+const (
+	HealthStatusUp HealthStatus = "up"
+	// HealthStatusDegraded means a non critical dependency is down, the replica still serves requests
+	HealthStatusDegraded HealthStatus = "degraded"
+	HealthStatusDown     HealthStatus = "down"
+)
+
+const (
+	HealthCheckPostgres  = "postgres"
+	HealthCheckValkey    = "valkey"
+	HealthCheckEventBus  = "eventBus"
+	HealthCheckScheduler = "scheduler"
+	HealthCheckAuth      = "auth"
+
+	// healthCheckTimeout bounds each check, so a hanging dependency fails the probe instead of
+	// outlasting the orchestrator's probe timeout
+	healthCheckTimeout = 2 * time.Second
+)
+
+var ErrSchedulerNotRunning = errors.New("scheduler is not running")
+
+// DependencyHealth is the result of checking one dependency
+type DependencyHealth struct {
+	Status    HealthStatus   `json:"status"`
+	Critical  bool           `json:"critical"`
+	LatencyMs float64        `json:"latencyMs"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+type HealthReport struct {
+	Status        HealthStatus                `json:"status"`
+	Version       string                      `json:"version"`
+	UptimeSeconds int64                       `json:"uptimeSeconds"`
+	CheckedAt     time.Time                   `json:"checkedAt"`
+	Checks        map[string]DependencyHealth `json:"checks,omitempty"`
+}
+
+// Ready reports whether the replica should receive traffic, only a critical dependency being down
+// takes it out of rotation
+func (r HealthReport) Ready() bool {
+	return r.Status != HealthStatusDown
+}
+
+// healthCheck checks a dependency, returning details to report with it
+type healthCheck struct {
+	name string
+	// critical checks take the replica out of rotation when they fail. Auth is not critical, an
+	// identity provider outage affects every replica alike and signed in users keep working.
+	critical bool
+	check    func(ctx context.Context) (map[string]any, error)
+}
+
+type HealthService struct {
+	log     logger.Logger
+	version string
+	started time.Time
+	checks  []healthCheck
+}
+
+func NewHealthService(
+	db database.DB,
+	eventBus *events.EventBus,
+	scheduler *SchedulerService,
+	auth AuthProvider,
+	config config.Config,
+) *HealthService {
+	return &HealthService{
+		log:     logger.New("healthService"),
+		version: config.GeneralVersion,
+		started: time.Now(),
+		checks: []healthCheck{
+			{name: HealthCheckPostgres, critical: true, check: postgresHealthCheck(db)},
+			{name: HealthCheckValkey, critical: true, check: valkeyHealthCheck(db.Cache.ClientAPI)},
+			{name: HealthCheckEventBus, critical: true, check: eventBusHealthCheck(eventBus)},
+			{name: HealthCheckScheduler, critical: true, check: schedulerHealthCheck(scheduler)},
+			{name: HealthCheckAuth, check: authHealthCheck(auth)},
+		},
+	}
+}
+
+// Live reports the process is up without touching any dependency, so an outage of a shared
+// dependency does not get every replica restarted
+func (s *HealthService) Live() HealthReport {
+	return s.report(HealthStatusUp, nil)
+}
+
+// Ready runs every check concurrently and reports the status and latency of each dependency
+func (s *HealthService) Ready(ctx context.Context) HealthReport {
+	log := s.log.Function("Ready")
+
+	results := make([]DependencyHealth, len(s.checks))
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Go(func() {
+			results[i] = runHealthCheck(ctx, check)
+		})
+	}
+	wg.Wait()
+
+	checks := make(map[string]DependencyHealth, len(s.checks))
+	for i, check := range s.checks {
+		checks[check.name] = results[i]
+		if results[i].Status != HealthStatusUp {
+			log.Warn("Health check failed",
+				"check", check.name,
+				"critical", check.critical,
+				"error", results[i].Error)
+		}
+	}
+
+	return s.report(aggregateHealth(checks), checks)
+}
+
+func (s *HealthService) report(status HealthStatus, checks map[string]DependencyHealth) HealthReport {
+	return HealthReport{
+		Status:        status,
+		Version:       s.version,
+		UptimeSeconds: int64(time.Since(s.started).Seconds()),
+		CheckedAt:     time.Now().UTC(),
+		Checks:        checks,
+	}
+}
+
+func runHealthCheck(ctx context.Context, check healthCheck) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.check(ctx)
+	result := DependencyHealth{
+		Status:    HealthStatusUp,
+		Critical:  check.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// aggregateHealth is down when a critical check is down and degraded when any other check is
+func aggregateHealth(checks map[string]DependencyHealth) HealthStatus {
+	status := HealthStatusUp
+	for _, check := range checks {
+		if check.Status == HealthStatusUp {
+			continue
+		}
+		if check.Critical {
+			return HealthStatusDown
+		}
+		status = HealthStatusDegraded
+	}
+	return status
+}
+
+func postgresHealthCheck(db database.DB) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		sqlDB, err := db.SQL.DB()
+		if err != nil {
+			return nil, err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return nil, err
+		}
+
+		stats := sqlDB.Stats()
+		return map[string]any{
+			"openConnections": stats.OpenConnections,
+			"inUse":           stats.InUse,
+			"idle":            stats.Idle,
+		}, nil
+	}
+}
+
+func valkeyHealthCheck(client database.CacheClient) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		return nil, client.Do(ctx, client.B().Ping().Build()).Error()
+	}
+}
+
+func eventBusHealthCheck(eventBus *events.EventBus) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		channels, err := eventBus.Health(ctx)
+		if channels == nil {
+			return nil, err
+		}
+		return map[string]any{"channels": channels}, err
+	}
+}
+
+func schedulerHealthCheck(scheduler *SchedulerService) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		details := map[string]any{"jobs": scheduler.GetJobCount()}
+		if next := scheduler.GetNextRunTime(); next != nil {
+			details["nextRunAt"] = next
+		}
+		if !scheduler.IsRunning() {
+			return details, ErrSchedulerNotRunning
+		}
+		return details, nil
+	}
+}
+
+func authHealthCheck(auth AuthProvider) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		details := map[string]any{"provider": auth.Name()}
+		fetchedAt, err := auth.CheckKeys(ctx)
+		if err != nil {
+			return details, err
+		}
+		if !fetchedAt.IsZero() {
+			details["keysFetchedAt"] = fetchedAt
+			details["keysAgeSeconds"] = int64(time.Since(fetchedAt).Seconds())
+		}
+		return details, nil
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"waugzee/config"
+	logger "github.com/Bparsons0904/goLogger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateHealth(t *testing.T) {
+	up := DependencyHealth{Status: HealthStatusUp, Critical: true}
+	down := DependencyHealth{Status: HealthStatusDown, Critical: true}
+	optionalDown := DependencyHealth{Status: HealthStatusDown}
+
+	assert.Equal(t, HealthStatusUp, aggregateHealth(map[string]DependencyHealth{"postgres": up}))
+	assert.Equal(t, HealthStatusDegraded, aggregateHealth(map[string]DependencyHealth{
+		"postgres": up,
+		"auth":     optionalDown,
+	}))
+	assert.Equal(t, HealthStatusDown, aggregateHealth(map[string]DependencyHealth{
+		"postgres": down,
+		"auth":     optionalDown,
+	}))
+
+	assert.True(t, HealthReport{Status: HealthStatusDegraded}.Ready())
+	assert.False(t, HealthReport{Status: HealthStatusDown}.Ready())
+}
+
+func TestRunHealthCheck(t *testing.T) {
+	t.Run("up", func(t *testing.T) {
+		result := runHealthCheck(context.Background(), healthCheck{
+			critical: true,
+			check: func(ctx context.Context) (map[string]any, error) {
+				return map[string]any{"jobs": 3}, nil
+			},
+		})
+
+		assert.Equal(t, HealthStatusUp, result.Status)
+		assert.True(t, result.Critical)
+		assert.Equal(t, 3, result.Details["jobs"])
+		assert.Empty(t, result.Error)
+	})
+
+	t.Run("failure keeps details", func(t *testing.T) {
+		result := runHealthCheck(context.Background(), healthCheck{
+			check: func(ctx context.Context) (map[string]any, error) {
+				return map[string]any{"provider": "oidc"}, errors.New("JWKS request failed")
+			},
+		})
+
+		assert.Equal(t, HealthStatusDown, result.Status)
+		assert.Equal(t, "JWKS request failed", result.Error)
+		assert.Equal(t, "oidc", result.Details["provider"])
+	})
+
+	t.Run("hanging dependency times out", func(t *testing.T) {
+		start := time.Now()
+		result := runHealthCheck(context.Background(), healthCheck{
+			check: func(ctx context.Context) (map[string]any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		})
+
+		assert.Equal(t, HealthStatusDown, result.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), result.Error)
+		assert.Less(t, time.Since(start), healthCheckTimeout+time.Second)
+		assert.GreaterOrEqual(t, result.LatencyMs, float64(healthCheckTimeout.Milliseconds()))
+	})
+}
+
+func TestHealthServiceReady(t *testing.T) {
+	service := &HealthService{
+		log:     logger.New("test"),
+		version: "1.2.3",
+		started: time.Now().Add(-time.Minute),
+		checks: []healthCheck{
+			{name: HealthCheckPostgres, critical: true, check: func(ctx context.Context) (map[string]any, error) {
+				return nil, nil
+			}},
+			{name: HealthCheckScheduler, critical: true, check: schedulerHealthCheck(&SchedulerService{})},
+		},
+	}
+
+	report := service.Ready(context.Background())
+	assert.Equal(t, HealthStatusDown, report.Status)
+	assert.Equal(t, "1.2.3", report.Version)
+	assert.GreaterOrEqual(t, report.UptimeSeconds, int64(60))
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, HealthStatusUp, report.Checks[HealthCheckPostgres].Status)
+	assert.Equal(t, ErrSchedulerNotRunning.Error(), report.Checks[HealthCheckScheduler].Error)
+
+	live := service.Live()
+	assert.Equal(t, HealthStatusUp, live.Status)
+	assert.Nil(t, live.Checks, "liveness does not check dependencies")
+}
+
+func TestAuthHealthCheck(t *testing.T) {
+	provider, err := NewLocalAuthProvider(config.Config{})
+	require.NoError(t, err)
+
+	details, err := authHealthCheck(provider)(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, AuthProviderLocal, details["provider"])
+	assert.NotContains(t, details, "keysFetchedAt", "local tokens have no keys to fetch")
+}
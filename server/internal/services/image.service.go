@@ -0,0 +1,530 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"waugzee/config"
+	"waugzee/internal/database"
+	"waugzee/internal/events"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/repositories"
+	"waugzee/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/valkey-io/valkey-go"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	ImageSizeThumb    = "thumb"
+	ImageSizeSmall    = "small"
+	ImageSizeMedium   = "medium"
+	ImageSizeLarge    = "large"
+	ImageSizeOriginal = "original"
+
+	DefaultImageStorageDir = "/app/image-cache"
+
+	IMAGE_FETCH_LIMIT_PREFIX = "image_fetch_limit:%s" // %s = user ID
+	// A user's requests may download and render this many uncached images per window
+	IMAGE_FETCH_LIMIT  = 60
+	IMAGE_FETCH_WINDOW = time.Minute
+
+	imageDownloadTimeout  = 30 * time.Second
+	imageLoadTimeout      = time.Minute
+	imageMaxDownloadBytes = 20 << 20
+	imageJPEGQuality      = 85
+	imagePrewarmWorkers   = 4
+
+	// A small compressed file can declare huge dimensions, decoding is refused above this many pixels
+	imageMaxPixels = 40_000_000
+)
+
+// ImageSizes maps each served size to the longest edge in pixels, 0 serves the original untouched
+var ImageSizes = map[string]int{
+	ImageSizeThumb:    150,
+	ImageSizeSmall:    300,
+	ImageSizeMedium:   600,
+	ImageSizeLarge:    1200,
+	ImageSizeOriginal: 0,
+}
+
+// ImagePrewarmSizes are rendered for every release after a collection sync
+var ImagePrewarmSizes = []string{ImageSizeThumb, ImageSizeSmall}
+
+var (
+	ErrInvalidImageSize = errors.New("invalid image size")
+	ErrImageNotFound    = errors.New("release has no cover image")
+	ErrImageUnavailable = errors.New("cover image could not be fetched")
+	ErrImageRateLimited = errors.New("too many uncached image requests")
+)
+
+// countImageFetchScript counts a fetch in the user's current window, starting the window on the
+// first fetch so the counter expires on its own
+var countImageFetchScript = valkey.NewLuaScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count`)
+
+// ImageVariant identifies one size of a release's current cover image. The version is derived from
+// the source URL, so a new cover image gets new storage keys and a new ETag.
+type ImageVariant struct {
+	ReleaseID int64
+	Size      string
+	SourceURL string
+	Version   string
+}
+
+func (v ImageVariant) ETag() string {
+	return fmt.Sprintf(`"%s-%s"`, v.Version, v.Size)
+}
+
+func (v ImageVariant) prefix() string {
+	return fmt.Sprintf("releases/%d/", v.ReleaseID)
+}
+
+func (v ImageVariant) originalKey() string {
+	return path.Join(v.prefix(), v.Version, ImageSizeOriginal)
+}
+
+func (v ImageVariant) key() string {
+	if v.Size == ImageSizeOriginal {
+		return v.originalKey()
+	}
+	return path.Join(v.prefix(), v.Version, v.Size+".jpg")
+}
+
+type CachedImage struct {
+	Data        []byte
+	ContentType string
+	ModifiedAt  time.Time
+}
+
+// NewImageStorage builds the blob store for cached cover images selected by IMAGE_STORAGE_BACKEND.
+// It reuses the dump storage backends, which hold any kind of object.
+func NewImageStorage(cfg config.Config) (DumpStorage, error) {
+	switch strings.ToLower(cfg.ImageStorageBackend) {
+	case "", DumpStorageBackendLocal:
+		dir := cfg.ImageStorageDir
+		if dir == "" {
+			dir = DefaultImageStorageDir
+		}
+		return NewLocalDumpStorage(dir), nil
+	case DumpStorageBackendS3:
+		return NewS3DumpStorage(S3DumpStorageConfig{
+			Endpoint:  cfg.DiscogsS3Endpoint,
+			Bucket:    cfg.ImageS3Bucket,
+			Region:    cfg.DiscogsS3Region,
+			AccessKey: cfg.DiscogsS3AccessKey,
+			SecretKey: cfg.DiscogsS3SecretKey,
+			UseSSL:    cfg.DiscogsS3UseSSL,
+			Prefix:    cfg.ImageS3Prefix,
+		})
+	default:
+		return nil, fmt.Errorf("unknown image storage backend: %s", cfg.ImageStorageBackend)
+	}
+}
+
+// ImageService caches release cover art so clients never hot-link the Discogs image servers.
+// Originals are downloaded on first request and every size is rendered from the stored original.
+type ImageService struct {
+	repos      repositories.Repository
+	db         database.DB
+	storage    DumpStorage
+	httpClient *http.Client
+	group      singleflight.Group
+	fetchLimit int64
+	log        logger.Logger
+}
+
+func NewImageService(repos repositories.Repository, db database.DB, storage DumpStorage) *ImageService {
+	return &ImageService{
+		repos:      repos,
+		db:         db,
+		storage:    storage,
+		httpClient: &http.Client{Timeout: imageDownloadTimeout},
+		fetchLimit: IMAGE_FETCH_LIMIT,
+		log:        logger.New("imageService"),
+	}
+}
+
+// ResolveVariant looks up the current cover image of a release without touching storage, so
+// callers can answer conditional requests from the ETag alone
+func (s *ImageService) ResolveVariant(ctx context.Context, releaseID int64, size string) (*ImageVariant, error) {
+	log := s.log.Function("ResolveVariant")
+
+	if _, ok := ImageSizes[size]; !ok {
+		return nil, log.Err("unknown image size", ErrInvalidImageSize, "size", size)
+	}
+
+	source, err := s.repos.Release.GetImageSource(ctx, s.db.SQLWithContext(ctx), releaseID)
+	if err != nil {
+		return nil, log.Err("failed to get release image source", err, "releaseID", releaseID)
+	}
+	if source == nil {
+		return nil, log.Err("release not found", ErrImageNotFound, "releaseID", releaseID)
+	}
+
+	sourceURL := coverImageURL(source)
+	if sourceURL == "" {
+		return nil, log.Err("release has no image", ErrImageNotFound, "releaseID", releaseID)
+	}
+
+	sum := sha256.Sum256([]byte(sourceURL))
+	return &ImageVariant{
+		ReleaseID: releaseID,
+		Size:      size,
+		SourceURL: sourceURL,
+		Version:   hex.EncodeToString(sum[:8]),
+	}, nil
+}
+
+// Load returns the stored variant, downloading the original and rendering the size on first use
+func (s *ImageService) Load(ctx context.Context, variant *ImageVariant) (*CachedImage, error) {
+	if cached, err := s.read(ctx, variant.key()); err == nil {
+		return cached, nil
+	} else if !errors.Is(err, ErrDumpObjectNotFound) {
+		return nil, err
+	}
+
+	return s.renderShared(ctx, variant)
+}
+
+// LoadForUser is Load for a user's request. Cache misses count against the user's fetch limit, so
+// one account cannot make the server download and render the whole catalog.
+func (s *ImageService) LoadForUser(
+	ctx context.Context,
+	userID uuid.UUID,
+	variant *ImageVariant,
+) (*CachedImage, error) {
+	if cached, err := s.read(ctx, variant.key()); err == nil {
+		return cached, nil
+	} else if !errors.Is(err, ErrDumpObjectNotFound) {
+		return nil, err
+	}
+
+	if err := s.countFetch(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.renderShared(ctx, variant)
+}
+
+func (s *ImageService) renderShared(ctx context.Context, variant *ImageVariant) (*CachedImage, error) {
+	// Concurrent requests for the same variant share one render. It runs detached from the first
+	// caller, so that client going away does not fail every request waiting on the same render.
+	result, err, _ := s.group.Do(variant.key(), func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), imageLoadTimeout)
+		defer cancel()
+		return s.render(ctx, variant)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*CachedImage), nil
+}
+
+// PrewarmReleases renders the prewarm sizes of every release that has a cover image, so the first
+// page load after a sync is served from the cache
+func (s *ImageService) PrewarmReleases(ctx context.Context, releaseIDs []int64) {
+	log := s.log.Function("PrewarmReleases")
+
+	jobs := make(chan int64)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	warmed, failed := 0, 0
+
+	for range imagePrewarmWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for releaseID := range jobs {
+				for _, size := range ImagePrewarmSizes {
+					variant, err := s.ResolveVariant(ctx, releaseID, size)
+					if err == nil {
+						_, err = s.Load(ctx, variant)
+					}
+
+					mu.Lock()
+					switch {
+					case err == nil:
+						warmed++
+					case !errors.Is(err, ErrImageNotFound):
+						failed++
+					}
+					mu.Unlock()
+
+					if err != nil {
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	for _, releaseID := range releaseIDs {
+		select {
+		case jobs <- releaseID:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	log.Info("Prewarmed release images", "releases", len(releaseIDs), "variants", warmed, "failed", failed)
+}
+
+// HandleCollectionSynced prewarms the images of a synced collection. It is subscribed to the
+// durable SYNC channel, so one replica prewarms each sync and a sync is not missed while every
+// replica restarts.
+func (s *ImageService) HandleCollectionSynced(event events.ChannelEvent) error {
+	if event.Event != events.COLLECTION_SYNCED {
+		return nil
+	}
+
+	synced, err := events.DecodePayload[events.CollectionSynced](event.Message)
+	if err != nil {
+		return s.log.Function("HandleCollectionSynced").Err("invalid collection synced event", err,
+			"userID", event.Message.UserID)
+	}
+
+	s.PrewarmReleases(context.Background(), synced.ReleaseIDs)
+	return nil
+}
+
+func (s *ImageService) countFetch(ctx context.Context, userID uuid.UUID) error {
+	log := s.log.Function("countFetch")
+
+	key := fmt.Sprintf(IMAGE_FETCH_LIMIT_PREFIX, userID)
+	count, err := countImageFetchScript.Exec(
+		ctx,
+		s.db.Cache.General,
+		[]string{key},
+		[]string{fmt.Sprint(IMAGE_FETCH_WINDOW.Milliseconds())},
+	).AsInt64()
+	if err != nil {
+		return log.Err("failed to count image fetch", err, "userID", userID)
+	}
+
+	if count > s.fetchLimit {
+		return log.Err("image fetch limit reached", ErrImageRateLimited, "userID", userID, "count", count)
+	}
+	return nil
+}
+
+func (s *ImageService) render(ctx context.Context, variant *ImageVariant) (*CachedImage, error) {
+	log := s.log.Function("render").With("releaseID", variant.ReleaseID, "size", variant.Size)
+
+	original, err := s.loadOriginal(ctx, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	if variant.Size == ImageSizeOriginal {
+		return original, nil
+	}
+
+	data, err := resizeImage(original.Data, ImageSizes[variant.Size])
+	if err != nil {
+		return nil, log.Err("failed to resize image", err)
+	}
+
+	if err := s.storage.Put(ctx, variant.key(), bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, log.Err("failed to store image variant", err)
+	}
+
+	return &CachedImage{Data: data, ContentType: "image/jpeg", ModifiedAt: time.Now().UTC()}, nil
+}
+
+// loadOriginal reads the stored original of the variant's cover image. Sizes rendered at the same
+// time share a single download, keyed apart from the variant renders that call it.
+func (s *ImageService) loadOriginal(ctx context.Context, variant *ImageVariant) (*CachedImage, error) {
+	result, err, _ := s.group.Do("fetch:"+variant.originalKey(), func() (any, error) {
+		original, err := s.read(ctx, variant.originalKey())
+		if errors.Is(err, ErrDumpObjectNotFound) {
+			original, err = s.fetchOriginal(ctx, variant)
+		}
+		return original, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*CachedImage), nil
+}
+
+// fetchOriginal downloads the cover image and removes any earlier version stored for the release
+func (s *ImageService) fetchOriginal(ctx context.Context, variant *ImageVariant) (*CachedImage, error) {
+	log := s.log.Function("fetchOriginal").With("releaseID", variant.ReleaseID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, variant.SourceURL, nil)
+	if err != nil {
+		return nil, log.Err("invalid image URL", ErrImageUnavailable, "url", variant.SourceURL)
+	}
+	req.Header.Set("User-Agent", DiscogsUserAgent)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, log.Err("failed to download image", fmt.Errorf("%w: %v", ErrImageUnavailable, err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, log.Err("failed to download image",
+			fmt.Errorf("%w: status %d", ErrImageUnavailable, resp.StatusCode))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, imageMaxDownloadBytes+1))
+	if err != nil {
+		return nil, log.Err("failed to read image", fmt.Errorf("%w: %v", ErrImageUnavailable, err))
+	}
+	if len(data) > imageMaxDownloadBytes {
+		return nil, log.Err("image too large", ErrImageUnavailable, "limit", imageMaxDownloadBytes)
+	}
+
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, log.Err("downloaded file is not an image", ErrImageUnavailable, "contentType", contentType)
+	}
+
+	if err := s.storage.Put(ctx, variant.originalKey(), bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, log.Err("failed to store original image", err)
+	}
+
+	// Variants of an older cover image are never served again. Files of this version are kept, as
+	// other sizes may be rendering from it right now.
+	if err := s.removeOtherVersions(ctx, variant); err != nil {
+		log.Warn("Failed to remove older image versions", "error", err)
+	}
+
+	log.Info("Cached cover image", "bytes", len(data))
+	return &CachedImage{Data: data, ContentType: contentType, ModifiedAt: time.Now().UTC()}, nil
+}
+
+func (s *ImageService) removeOtherVersions(ctx context.Context, variant *ImageVariant) error {
+	objects, err := s.storage.List(ctx, variant.prefix())
+	if err != nil {
+		return err
+	}
+
+	current := path.Join(variant.prefix(), variant.Version) + "/"
+	for _, object := range objects {
+		if strings.HasPrefix(object.Key, current) {
+			continue
+		}
+		if err := s.storage.Delete(ctx, object.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ImageService) read(ctx context.Context, key string) (*CachedImage, error) {
+	object, err := s.storage.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := s.storage.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached image %s: %w", key, err)
+	}
+
+	return &CachedImage{
+		Data:        data,
+		ContentType: http.DetectContentType(data),
+		ModifiedAt:  object.ModifiedAt,
+	}, nil
+}
+
+// coverImageURL prefers the full size cover image, then the primary image stored with the release
+// data and finally the thumbnail
+func coverImageURL(source *repositories.ReleaseImageSource) string {
+	if source.CoverImage != nil && *source.CoverImage != "" {
+		return *source.CoverImage
+	}
+
+	if len(source.ImagesJSON) > 0 {
+		var images []types.Image
+		if err := json.Unmarshal(source.ImagesJSON, &images); err == nil {
+			for _, image := range images {
+				if image.Type == "primary" && image.URI != "" {
+					return image.URI
+				}
+			}
+			for _, image := range images {
+				if image.URI != "" {
+					return image.URI
+				}
+			}
+		}
+	}
+
+	if source.Thumb != nil && *source.Thumb != "" {
+		return *source.Thumb
+	}
+	return ""
+}
+
+// resizeImage scales an image to fit within maxEdge pixels on its longest side, never upscaling,
+// and encodes the result as JPEG
+func resizeImage(data []byte, maxEdge int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image header: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > imageMaxPixels/config.Height {
+		return nil, fmt.Errorf("image of %dx%d pixels exceeds the %d pixel limit", config.Width, config.Height, imageMaxPixels)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxEdge || height > maxEdge {
+		if width >= height {
+			height = max(1, height*maxEdge/width)
+			width = maxEdge
+		} else {
+			width = max(1, width*maxEdge/height)
+			height = maxEdge
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	// Transparent areas become white instead of black once encoded as JPEG
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, dst, &jpeg.Options{Quality: imageJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return out.Bytes(), nil
+}
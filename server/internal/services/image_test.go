@@ -0,0 +1,237 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"waugzee/internal/database"
+	"waugzee/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		for y := range height {
+			img.Set(x, y, color.RGBA{R: 200, G: 50, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestResizeImage(t *testing.T) {
+	tests := []struct {
+		name           string
+		width, height  int
+		maxEdge        int
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{"landscape is bounded by width", 800, 400, 300, 300, 150},
+		{"portrait is bounded by height", 400, 800, 300, 150, 300},
+		{"small images are not upscaled", 100, 80, 300, 100, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := resizeImage(testPNG(t, tt.width, tt.height), tt.maxEdge)
+			require.NoError(t, err)
+
+			resized, err := jpeg.Decode(bytes.NewReader(data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedWidth, resized.Bounds().Dx())
+			assert.Equal(t, tt.expectedHeight, resized.Bounds().Dy())
+		})
+	}
+
+	t.Run("rejects data that is not an image", func(t *testing.T) {
+		_, err := resizeImage([]byte("not an image"), 150)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects images above the pixel limit before decoding", func(t *testing.T) {
+		// Rewrite the PNG header to declare 20000x20000 pixels, the pixel data stays 1x1
+		data := testPNG(t, 1, 1)
+		binary.BigEndian.PutUint32(data[16:20], 20000)
+		binary.BigEndian.PutUint32(data[20:24], 20000)
+		binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+		_, err := resizeImage(data, 150)
+		assert.ErrorContains(t, err, "pixel limit")
+	})
+}
+
+func TestCoverImageURL(t *testing.T) {
+	cover := "https://img.discogs.com/cover.jpg"
+	thumb := "https://img.discogs.com/thumb.jpg"
+	images := []byte(`[{"type":"secondary","uri":"https://img.discogs.com/back.jpg"},` +
+		`{"type":"primary","uri":"https://img.discogs.com/front.jpg"}]`)
+
+	assert.Equal(t, cover, coverImageURL(&repositories.ReleaseImageSource{
+		CoverImage: &cover, Thumb: &thumb, ImagesJSON: images,
+	}))
+	assert.Equal(t, "https://img.discogs.com/front.jpg", coverImageURL(&repositories.ReleaseImageSource{
+		Thumb: &thumb, ImagesJSON: images,
+	}))
+	assert.Equal(t, thumb, coverImageURL(&repositories.ReleaseImageSource{Thumb: &thumb}))
+	assert.Empty(t, coverImageURL(&repositories.ReleaseImageSource{}))
+}
+
+func TestImageVariant_Keys(t *testing.T) {
+	variant := ImageVariant{ReleaseID: 42, Size: ImageSizeThumb, Version: "abc"}
+
+	assert.Equal(t, "releases/42/abc/thumb.jpg", variant.key())
+	assert.Equal(t, "releases/42/abc/original", variant.originalKey())
+	assert.Equal(t, `"abc-thumb"`, variant.ETag())
+
+	variant.Size = ImageSizeOriginal
+	assert.Equal(t, variant.originalKey(), variant.key())
+}
+
+func TestImageService_Load(t *testing.T) {
+	original := testPNG(t, 600, 600)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(original)
+	}))
+	defer server.Close()
+
+	storage := NewLocalDumpStorage(t.TempDir())
+	service := NewImageService(repositories.Repository{}, database.DB{}, storage)
+	ctx := context.Background()
+
+	thumb := &ImageVariant{ReleaseID: 1, Size: ImageSizeThumb, SourceURL: server.URL, Version: "v1"}
+	image, err := service.Load(ctx, thumb)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", image.ContentType)
+
+	cached, err := service.Load(ctx, thumb)
+	require.NoError(t, err)
+	assert.Equal(t, image.Data, cached.Data)
+
+	small := &ImageVariant{ReleaseID: 1, Size: ImageSizeSmall, SourceURL: server.URL, Version: "v1"}
+	_, err = service.Load(ctx, small)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "sizes are rendered from the stored original")
+
+	// A new cover image replaces every stored variant of the old one
+	updated := &ImageVariant{ReleaseID: 1, Size: ImageSizeThumb, SourceURL: server.URL + "/new", Version: "v2"}
+	_, err = service.Load(ctx, updated)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	_, err = storage.Stat(ctx, thumb.key())
+	assert.ErrorIs(t, err, ErrDumpObjectNotFound)
+}
+
+func TestImageService_LoadSharesOriginalAcrossSizes(t *testing.T) {
+	original := testPNG(t, 600, 600)
+	release := make(chan struct{})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_, _ = w.Write(original)
+	}))
+	defer server.Close()
+
+	storage := NewLocalDumpStorage(t.TempDir())
+	service := NewImageService(repositories.Repository{}, database.DB{}, storage)
+	sizes := []string{ImageSizeThumb, ImageSizeSmall, ImageSizeMedium}
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(sizes))
+	for i, size := range sizes {
+		ctx := context.Background()
+		if i == 0 {
+			ctx = firstCtx
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			variant := &ImageVariant{ReleaseID: 1, Size: size, SourceURL: server.URL, Version: "v1"}
+			_, errs[i] = service.Load(ctx, variant)
+		}()
+	}
+
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	// The first caller goes away while the download it started is still running
+	cancelFirst()
+	close(release)
+	wg.Wait()
+
+	for i, size := range sizes {
+		require.NoError(t, errs[i], size)
+		variant := ImageVariant{ReleaseID: 1, Size: size, Version: "v1"}
+		_, err := storage.Stat(context.Background(), variant.key())
+		assert.NoError(t, err, "%s was removed while rendering", size)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestImageService_LoadForUserLimitsMisses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testPNG(t, 600, 600))
+	}))
+	defer server.Close()
+
+	_, cache := newTestValkey(t)
+	service := NewImageService(
+		repositories.Repository{},
+		database.DB{Cache: database.Cache{General: cache}},
+		NewLocalDumpStorage(t.TempDir()),
+	)
+	service.fetchLimit = 2
+	ctx := context.Background()
+	userID, otherUserID := uuid.New(), uuid.New()
+
+	thumb := &ImageVariant{ReleaseID: 1, Size: ImageSizeThumb, SourceURL: server.URL, Version: "v1"}
+	small := &ImageVariant{ReleaseID: 1, Size: ImageSizeSmall, SourceURL: server.URL, Version: "v1"}
+	medium := &ImageVariant{ReleaseID: 1, Size: ImageSizeMedium, SourceURL: server.URL, Version: "v1"}
+
+	_, err := service.LoadForUser(ctx, userID, thumb)
+	require.NoError(t, err)
+	_, err = service.LoadForUser(ctx, userID, small)
+	require.NoError(t, err)
+
+	_, err = service.LoadForUser(ctx, userID, medium)
+	assert.ErrorIs(t, err, ErrImageRateLimited)
+
+	// Cached variants do not count against the limit, and the limit is per user
+	_, err = service.LoadForUser(ctx, userID, thumb)
+	assert.NoError(t, err)
+	_, err = service.LoadForUser(ctx, otherUserID, medium)
+	assert.NoError(t, err)
+}
+
+func TestImageService_LoadUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	service := NewImageService(repositories.Repository{}, database.DB{}, NewLocalDumpStorage(t.TempDir()))
+	variant := &ImageVariant{ReleaseID: 1, Size: ImageSizeThumb, SourceURL: server.URL, Version: "v1"}
+
+	_, err := service.Load(context.Background(), variant)
+	assert.ErrorIs(t, err, ErrImageUnavailable)
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+	"waugzee/internal/metrics"
+	"waugzee/internal/models"
+)
+
+const (
+	defaultConverterWorkers = 4
+	defaultBatchWriters     = 2
+	defaultMaxFailureRatio  = 0.001
+)
+
+// Pipeline stage names used in step throughput metrics
+const (
+	PipelineStageParse   = "parse"
+	PipelineStageConvert = "convert"
+	PipelineStageWrite   = "write"
+)
+
+// IngestionPipelineConfig sizes the dump ingestion pipeline. Parsing a gzip stream is inherently
+// sequential, so there is always a single parser; conversion and writing fan out.
+type IngestionPipelineConfig struct {
+	ConverterWorkers int
+	BatchWriters     int
+	// MaxFailureRatio is the share of dead-lettered entities above which a step is marked failed
+	MaxFailureRatio float64
+}
+
+// resolve applies per-step overrides on top of the service defaults
+func (c IngestionPipelineConfig) resolve(converterWorkers, batchWriters int) (int, int) {
+	if converterWorkers <= 0 {
+		converterWorkers = c.ConverterWorkers
+	}
+	if converterWorkers <= 0 {
+		converterWorkers = defaultConverterWorkers
+	}
+
+	if batchWriters <= 0 {
+		batchWriters = c.BatchWriters
+	}
+	if batchWriters <= 0 {
+		batchWriters = defaultBatchWriters
+	}
+
+	return converterWorkers, batchWriters
+}
+
+// failureRatioExceeded reports whether a step dead-lettered too many of its entities
+func (c IngestionPipelineConfig) failureRatioExceeded(failed, processed int64) bool {
+	if failed == 0 || processed == 0 {
+		return false
+	}
+
+	maxRatio := c.MaxFailureRatio
+	if maxRatio <= 0 {
+		maxRatio = defaultMaxFailureRatio
+	}
+
+	return float64(failed)/float64(processed) > maxRatio
+}
+
+// pipelineChunk is a batch of consecutive dump elements covering sequence numbers [start, end)
+type pipelineChunk[T any] struct {
+	start int64
+	end   int64
+	items []T
+}
+
+// batchOutcome is what writing one chunk produced
+type batchOutcome struct {
+	changes models.ChangeCounts
+	failed  int64
+}
+
+// commitPosition is the committed prefix of a step: everything below Watermark has been written
+// or dead-lettered, and Changes/Failed cover exactly that prefix
+type commitPosition struct {
+	Watermark int64
+	Changes   models.ChangeCounts
+	Failed    int64
+}
+
+type pendingCommit struct {
+	end     int64
+	outcome batchOutcome
+}
+
+// commitTracker turns out-of-order batch commits from concurrent writers into a contiguous
+// watermark, so a checkpoint never claims an element whose batch has not been written yet
+type commitTracker struct {
+	mu       sync.Mutex
+	position commitPosition
+	pending  map[int64]pendingCommit
+}
+
+func newCommitTracker(start commitPosition) *commitTracker {
+	return &commitTracker{
+		position: start,
+		pending:  make(map[int64]pendingCommit),
+	}
+}
+
+// Commit records a written chunk and reports whether the watermark advanced
+func (c *commitTracker) Commit(start, end int64, outcome batchOutcome) (commitPosition, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending[start] = pendingCommit{end: end, outcome: outcome}
+
+	advanced := false
+	for {
+		next, ok := c.pending[c.position.Watermark]
+		if !ok {
+			break
+		}
+		delete(c.pending, c.position.Watermark)
+		c.position.Watermark = next.end
+		c.position.Changes.Inserted += next.outcome.changes.Inserted
+		c.position.Changes.Updated += next.outcome.changes.Updated
+		c.position.Changes.Unchanged += next.outcome.changes.Unchanged
+		c.position.Failed += next.outcome.failed
+		advanced = true
+	}
+
+	return c.position, advanced
+}
+
+// Position returns the current committed prefix
+func (c *commitTracker) Position() commitPosition {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.position
+}
+
+// failedEntity is an entity that still failed to write once isolated on its own
+type failedEntity[T any] struct {
+	entity *T
+	err    error
+}
+
+// writeWithBisect writes a batch and, when that fails, splits it in half and retries each half
+// until the entities that cannot be written are isolated. Every other entity is still written,
+// and only change counts from successful writes are returned.
+func writeWithBisect[T any](
+	ctx context.Context,
+	batch []*T,
+	write func([]*T) (models.ChangeCounts, error),
+) (models.ChangeCounts, []failedEntity[T]) {
+	if len(batch) == 0 {
+		return models.ChangeCounts{}, nil
+	}
+
+	changes, err := write(batch)
+	if err == nil {
+		return changes, nil
+	}
+
+	// Bisecting a cancelled run only produces more failures of the same kind
+	if len(batch) == 1 || ctx.Err() != nil {
+		failed := make([]failedEntity[T], len(batch))
+		for i, entity := range batch {
+			failed[i] = failedEntity[T]{entity: entity, err: err}
+		}
+		return models.ChangeCounts{}, failed
+	}
+
+	mid := len(batch) / 2
+	leftChanges, leftFailed := writeWithBisect(ctx, batch[:mid], write)
+	rightChanges, rightFailed := writeWithBisect(ctx, batch[mid:], write)
+
+	return models.ChangeCounts{
+		Inserted:  leftChanges.Inserted + rightChanges.Inserted,
+		Updated:   leftChanges.Updated + rightChanges.Updated,
+		Unchanged: leftChanges.Unchanged + rightChanges.Unchanged,
+	}, append(leftFailed, rightFailed...)
+}
+
+// deadLetterEntities stores entities that could not be written so they can be inspected and replayed.
+// Failing to store them is logged rather than returned; the failure is already counted on the step.
+func deadLetterEntities[T any](
+	ctx context.Context,
+	service *DiscogsXMLParserService,
+	yearMonth string,
+	step models.ProcessingStep,
+	entityType string,
+	failed []failedEntity[T],
+) error {
+	log := service.log.Function("deadLetterEntities").With("step", step)
+
+	deadLetters := make([]*models.DumpDeadLetter, 0, len(failed))
+	for _, failure := range failed {
+		deadLetter := &models.DumpDeadLetter{
+			YearMonth:  yearMonth,
+			Step:       step,
+			EntityType: entityType,
+			Error:      failure.err.Error(),
+		}
+
+		if dumpEntity, ok := any(failure.entity).(models.DumpEntity); ok {
+			id := dumpEntity.GetID()
+			deadLetter.EntityID = &id
+		}
+
+		if payload, err := json.Marshal(failure.entity); err == nil {
+			deadLetter.Payload = payload
+		} else {
+			log.Warn("Failed to marshal dead-lettered entity", "error", err)
+		}
+
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	if err := service.repos.DumpDeadLetter.CreateBatch(ctx, service.db.SQLWithContext(ctx), deadLetters); err != nil {
+		return log.Err("failed to store dead-lettered entities", err, "count", len(deadLetters))
+	}
+	return nil
+}
+
+// stageMeter accumulates item counts and busy time for one pipeline stage across its workers, and
+// reports them to Prometheus as they happen
+type stageMeter struct {
+	entityType string
+	stage      string
+	workers    int
+	items      atomic.Int64
+	busy       atomic.Int64
+}
+
+func (m *stageMeter) init(entityType, stage string, workers int) {
+	m.entityType = entityType
+	m.stage = stage
+	m.workers = workers
+}
+
+func (m *stageMeter) record(items int, started time.Time) {
+	m.add(int64(items), time.Since(started))
+}
+
+func (m *stageMeter) add(items int64, busy time.Duration) {
+	m.items.Add(items)
+	m.busy.Add(int64(busy))
+	metrics.ObserveDumpStage(m.entityType, m.stage, items, busy)
+}
+
+func (m *stageMeter) throughput(elapsed time.Duration) *models.StageThroughput {
+	items := m.items.Load()
+	busy := time.Duration(m.busy.Load())
+
+	result := &models.StageThroughput{
+		Workers:     m.workers,
+		Items:       items,
+		BusySeconds: busy.Seconds(),
+	}
+	if elapsed > 0 {
+		result.ItemsPerSecond = float64(items) / elapsed.Seconds()
+		if m.workers > 0 {
+			result.Utilization = busy.Seconds() / (elapsed.Seconds() * float64(m.workers))
+		}
+	}
+
+	return result
+}
+
+// pipelineMeters tracks every stage of one ProcessXMLEntities run
+type pipelineMeters struct {
+	parse   stageMeter
+	convert stageMeter
+	write   stageMeter
+}
+
+func newPipelineMeters(entityType string, converterWorkers, batchWriters int) *pipelineMeters {
+	meters := &pipelineMeters{}
+	meters.parse.init(entityType, PipelineStageParse, 1)
+	meters.convert.init(entityType, PipelineStageConvert, converterWorkers)
+	meters.write.init(entityType, PipelineStageWrite, batchWriters)
+	return meters
+}
+
+func (m *pipelineMeters) throughput(elapsed time.Duration) map[string]*models.StageThroughput {
+	return map[string]*models.StageThroughput{
+		PipelineStageParse:   m.parse.throughput(elapsed),
+		PipelineStageConvert: m.convert.throughput(elapsed),
+		PipelineStageWrite:   m.write.throughput(elapsed),
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"waugzee/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitTrackerAdvancesOverContiguousPrefix(t *testing.T) {
+	tracker := newCommitTracker(commitPosition{
+		Watermark: 100,
+		Changes:   models.ChangeCounts{Inserted: 5},
+		Failed:    1,
+	})
+
+	position, advanced := tracker.Commit(200, 300, batchOutcome{changes: models.ChangeCounts{Updated: 7}})
+	assert.False(t, advanced, "a gap below the batch must hold the watermark")
+	assert.Equal(t, int64(100), position.Watermark)
+
+	position, advanced = tracker.Commit(100, 200, batchOutcome{
+		changes: models.ChangeCounts{Inserted: 1, Unchanged: 98},
+		failed:  1,
+	})
+	assert.True(t, advanced)
+	assert.Equal(t, int64(300), position.Watermark)
+	assert.Equal(t, models.ChangeCounts{Inserted: 6, Updated: 7, Unchanged: 98}, position.Changes)
+	assert.Equal(t, int64(2), position.Failed)
+
+	assert.Equal(t, position, tracker.Position())
+}
+
+func TestIngestionPipelineConfigResolve(t *testing.T) {
+	converters, writers := IngestionPipelineConfig{}.resolve(0, 0)
+	assert.Equal(t, defaultConverterWorkers, converters)
+	assert.Equal(t, defaultBatchWriters, writers)
+
+	converters, writers = IngestionPipelineConfig{ConverterWorkers: 8, BatchWriters: 3}.resolve(0, 1)
+	assert.Equal(t, 8, converters)
+	assert.Equal(t, 1, writers)
+}
+
+func TestFailureRatioExceeded(t *testing.T) {
+	config := IngestionPipelineConfig{MaxFailureRatio: 0.01}
+
+	assert.False(t, config.failureRatioExceeded(0, 0))
+	assert.False(t, config.failureRatioExceeded(1, 100))
+	assert.True(t, config.failureRatioExceeded(2, 100))
+	assert.True(t, IngestionPipelineConfig{}.failureRatioExceeded(2, 1000))
+}
+
+func TestWriteWithBisectIsolatesBadEntities(t *testing.T) {
+	bad := map[int]bool{3: true, 6: true}
+	batch := make([]*int, 8)
+	for i := range batch {
+		value := i
+		batch[i] = &value
+	}
+
+	var written []int
+	write := func(entities []*int) (models.ChangeCounts, error) {
+		for _, entity := range entities {
+			if bad[*entity] {
+				return models.ChangeCounts{}, errors.New("constraint violation")
+			}
+		}
+		for _, entity := range entities {
+			written = append(written, *entity)
+		}
+		return models.ChangeCounts{Inserted: int64(len(entities))}, nil
+	}
+
+	changes, failed := writeWithBisect(context.Background(), batch, write)
+
+	assert.Equal(t, int64(6), changes.Inserted)
+	assert.ElementsMatch(t, []int{0, 1, 2, 4, 5, 7}, written)
+	if assert.Len(t, failed, 2) {
+		assert.Equal(t, 3, *failed[0].entity)
+		assert.Equal(t, 6, *failed[1].entity)
+		assert.EqualError(t, failed[0].err, "constraint violation")
+	}
+}
+
+func TestWriteWithBisectStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	write := func(entities []*int) (models.ChangeCounts, error) {
+		calls++
+		return models.ChangeCounts{}, ctx.Err()
+	}
+
+	one, two := 1, 2
+	_, failed := writeWithBisect(ctx, []*int{&one, &two}, write)
+
+	assert.Equal(t, 1, calls)
+	assert.Len(t, failed, 2)
+}
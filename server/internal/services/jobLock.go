@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+
+	"github.com/google/uuid"
+	"github.com/valkey-io/valkey-go"
+)
+
+const (
+	JOB_LOCK_PREFIX = "job_lock:%s" // %s = job name
+	// A lease outlives a few missed heartbeats, so a slow Valkey round trip does not drop it,
+	// while a replica that dies mid-job frees the job within one TTL
+	JOB_LOCK_TTL            = 30 * time.Second
+	JOB_LOCK_RENEW_INTERVAL = 10 * time.Second
+)
+
+var (
+	ErrJobAlreadyRunning = errors.New("job is already running on another instance")
+	ErrJobLockLost       = errors.New("job lock lost")
+)
+
+// The renew and release scripts only touch the key while it still holds our token, so a
+// replica whose lease expired can never extend or delete a lease another replica now holds
+var (
+	renewJobLockScript = valkey.NewLuaScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseJobLockScript = valkey.NewLuaScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// jobLocker hands out per-job leases in Valkey so a job runs on one replica at a time
+type jobLocker struct {
+	cache      valkey.Client
+	instanceID string
+	ttl        time.Duration
+	renewEvery time.Duration
+	log        logger.Logger
+}
+
+func newJobLocker(cache valkey.Client) *jobLocker {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &jobLocker{
+		cache:      cache,
+		instanceID: fmt.Sprintf("%s:%s", hostname, uuid.NewString()),
+		ttl:        JOB_LOCK_TTL,
+		renewEvery: JOB_LOCK_RENEW_INTERVAL,
+		log:        logger.New("jobLocker"),
+	}
+}
+
+// jobLease is a held job lock. Its context is cancelled if the lease is lost, so the job stops
+// before another replica can start the same work.
+type jobLease struct {
+	locker *jobLocker
+	key    string
+	token  string
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// IsHeld reports whether any replica currently holds the lock for a job
+func (l *jobLocker) IsHeld(ctx context.Context, jobName string) (bool, error) {
+	key := fmt.Sprintf(JOB_LOCK_PREFIX, jobName)
+
+	count, err := l.cache.Do(ctx, l.cache.B().Exists().Key(key).Build()).AsInt64()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Acquire takes the lock for a job and starts renewing it. It returns nil without an error when
+// another replica holds the lock.
+func (l *jobLocker) Acquire(ctx context.Context, jobName string) (*jobLease, error) {
+	key := fmt.Sprintf(JOB_LOCK_PREFIX, jobName)
+	token := fmt.Sprintf("%s:%s", l.instanceID, uuid.NewString())
+
+	err := l.cache.Do(ctx, l.cache.B().Set().Key(key).Value(token).Nx().Px(l.ttl).Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	leaseCtx, cancel := context.WithCancelCause(ctx)
+	lease := &jobLease{
+		locker: l,
+		key:    key,
+		token:  token,
+		ctx:    leaseCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go lease.heartbeat()
+
+	return lease, nil
+}
+
+// heartbeat extends the lease until it is released. A renewal that finds another token means the
+// lease already expired; renewal errors are retried until the lease would have expired on its own.
+func (l *jobLease) heartbeat() {
+	log := l.locker.log.Function("heartbeat").With("key", l.key)
+
+	ticker := time.NewTicker(l.locker.renewEvery)
+	defer ticker.Stop()
+
+	lastRenewed := time.Now()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		renewCtx, cancel := context.WithTimeout(context.Background(), l.locker.renewEvery)
+		renewed, err := renewJobLockScript.Exec(
+			renewCtx,
+			l.locker.cache,
+			[]string{l.key},
+			[]string{l.token, fmt.Sprintf("%d", l.locker.ttl.Milliseconds())},
+		).AsInt64()
+		cancel()
+
+		switch {
+		case err == nil && renewed == 1:
+			lastRenewed = time.Now()
+		case err == nil:
+			log.Warn("Job lock taken over after expiring, stopping job")
+			l.cancel(ErrJobLockLost)
+			return
+		case time.Since(lastRenewed) >= l.locker.ttl:
+			log.Er("Job lock expired while renewals were failing, stopping job", err)
+			l.cancel(ErrJobLockLost)
+			return
+		default:
+			log.Warn("Failed to renew job lock, retrying", "error", err)
+		}
+	}
+}
+
+// Context is cancelled with ErrJobLockLost if the lease is lost while the job runs
+func (l *jobLease) Context() context.Context {
+	return l.ctx
+}
+
+// Release stops renewal and frees the lock if it is still ours
+func (l *jobLease) Release() {
+	l.once.Do(func() {
+		close(l.done)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := releaseJobLockScript.Exec(ctx, l.locker.cache, []string{l.key}, []string{l.token}).Error()
+		if err != nil {
+			l.locker.log.Function("Release").Warn("Failed to release job lock", "key", l.key, "error", err)
+		}
+
+		l.cancel(nil)
+	})
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valkey-io/valkey-go"
+)
+
+func newTestValkey(t *testing.T) (*miniredis.Miniredis, valkey.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client, err := valkey.NewClient(valkey.ClientOption{
+		InitAddress:  []string{server.Addr()},
+		DisableCache: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	return server, client
+}
+
+// newTestJobLocker renews quickly so lease loss is noticed within a test
+func newTestJobLocker(client valkey.Client) *jobLocker {
+	locker := newJobLocker(client)
+	locker.ttl = time.Second
+	locker.renewEvery = 10 * time.Millisecond
+	return locker
+}
+
+func waitForLeaseLoss(t *testing.T, lease *jobLease) {
+	t.Helper()
+
+	select {
+	case <-lease.Context().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("lease context was not cancelled")
+	}
+	assert.ErrorIs(t, context.Cause(lease.Context()), ErrJobLockLost)
+}
+
+func TestJobLockSecondHolderCannotAcquire(t *testing.T) {
+	_, client := newTestValkey(t)
+	ctx := context.Background()
+	first, second := newTestJobLocker(client), newTestJobLocker(client)
+
+	lease, err := first.Acquire(ctx, "sync")
+	require.NoError(t, err)
+	require.NotNil(t, lease)
+	defer lease.Release()
+
+	other, err := second.Acquire(ctx, "sync")
+	require.NoError(t, err)
+	assert.Nil(t, other)
+
+	held, err := second.IsHeld(ctx, "sync")
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	// Other jobs are not blocked
+	unrelated, err := second.Acquire(ctx, "cleanup")
+	require.NoError(t, err)
+	require.NotNil(t, unrelated)
+	unrelated.Release()
+}
+
+func TestJobLockAcquireAfterRelease(t *testing.T) {
+	_, client := newTestValkey(t)
+	ctx := context.Background()
+	first, second := newTestJobLocker(client), newTestJobLocker(client)
+
+	lease, err := first.Acquire(ctx, "sync")
+	require.NoError(t, err)
+	lease.Release()
+	assert.Error(t, lease.Context().Err())
+
+	held, err := second.IsHeld(ctx, "sync")
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	next, err := second.Acquire(ctx, "sync")
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	next.Release()
+}
+
+func TestJobLockRenewFailsAfterTakeover(t *testing.T) {
+	server, client := newTestValkey(t)
+	ctx := context.Background()
+	locker := newJobLocker(client)
+
+	lease, err := locker.Acquire(ctx, "sync")
+	require.NoError(t, err)
+	defer lease.Release()
+
+	renew := func() int64 {
+		renewed, err := renewJobLockScript.Exec(ctx, client, []string{lease.key}, []string{lease.token, "30000"}).AsInt64()
+		require.NoError(t, err)
+		return renewed
+	}
+	assert.Equal(t, int64(1), renew())
+
+	// The lease expired and another replica took the job
+	require.NoError(t, server.Set(lease.key, "other-replica"))
+	server.SetTTL(lease.key, time.Minute)
+
+	assert.Equal(t, int64(0), renew())
+	assert.Equal(t, time.Minute, server.TTL(lease.key))
+}
+
+func TestJobLockReleaseOnlyDeletesOwnToken(t *testing.T) {
+	server, client := newTestValkey(t)
+	ctx := context.Background()
+	locker := newJobLocker(client)
+
+	lease, err := locker.Acquire(ctx, "sync")
+	require.NoError(t, err)
+
+	require.NoError(t, server.Set(lease.key, "other-replica"))
+	lease.Release()
+
+	value, err := server.Get(lease.key)
+	require.NoError(t, err)
+	assert.Equal(t, "other-replica", value)
+
+	own, err := locker.Acquire(ctx, "dumps")
+	require.NoError(t, err)
+	own.Release()
+	assert.False(t, server.Exists(own.key))
+}
+
+func TestJobLockTakeoverCancelsJob(t *testing.T) {
+	server, client := newTestValkey(t)
+	locker := newTestJobLocker(client)
+
+	lease, err := locker.Acquire(context.Background(), "sync")
+	require.NoError(t, err)
+	defer lease.Release()
+
+	require.NoError(t, server.Set(lease.key, "other-replica"))
+	waitForLeaseLoss(t, lease)
+
+	// The lease that lost the lock leaves the new holder alone
+	lease.Release()
+	value, err := server.Get(lease.key)
+	require.NoError(t, err)
+	assert.Equal(t, "other-replica", value)
+}
+
+func TestJobLockFailingRenewalsCancelJobOnceExpired(t *testing.T) {
+	server, client := newTestValkey(t)
+	locker := newTestJobLocker(client)
+	locker.ttl = 100 * time.Millisecond
+
+	lease, err := locker.Acquire(context.Background(), "sync")
+	require.NoError(t, err)
+	defer lease.Release()
+
+	server.SetError("LOADING Valkey is loading the dataset")
+	waitForLeaseLoss(t, lease)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+	"waugzee/config"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/types"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// LocalAuthIssuer and LocalAuthClientID identify tokens issued by the local provider
+	LocalAuthIssuer   = "waugzee-local"
+	LocalAuthClientID = "waugzee-local-dev"
+
+	localAuthTokenLifetime = 24 * time.Hour
+	localAuthSubjectPrefix = "local|"
+	localAuthMinSecretLen  = 32
+)
+
+var (
+	ErrLocalAuthDisabled     = errors.New("local authentication is not enabled")
+	ErrLocalAuthInvalidEmail = errors.New("a valid email is required")
+)
+
+// LocalAuthProvider signs users in without an identity provider, for development and trying the
+// app out. Anyone who can reach the server can sign in as any email, so config validation refuses
+// it in production.
+type LocalAuthProvider struct {
+	secret []byte
+	log    logger.Logger
+}
+
+func NewLocalAuthProvider(cfg config.Config) (*LocalAuthProvider, error) {
+	log := logger.New("LocalAuthProvider")
+
+	secret := []byte(cfg.LocalAuthSecret)
+	if len(secret) == 0 {
+		// Tokens stop validating on restart, which is fine for local development
+		secret = make([]byte, localAuthMinSecretLen)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, log.Err("failed to generate local auth secret", err)
+		}
+		log.Warn("LOCAL_AUTH_SECRET not set, using a random secret for this process")
+	} else if len(secret) < localAuthMinSecretLen {
+		return nil, log.ErrMsg("LOCAL_AUTH_SECRET must be at least 32 characters")
+	}
+
+	log.Warn("Local authentication enabled, do not use outside development")
+	return &LocalAuthProvider{secret: secret, log: log}, nil
+}
+
+func (p *LocalAuthProvider) Name() string {
+	return AuthProviderLocal
+}
+
+// IssueIDToken signs an ID token for the email, so the client signs in through the same callback
+// as with a real provider
+func (p *LocalAuthProvider) IssueIDToken(email string, name string) (string, time.Time, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return "", time.Time{}, ErrLocalAuthInvalidEmail
+	}
+	email = strings.ToLower(address.Address)
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(localAuthTokenLifetime)
+	claims := oidcIDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    LocalAuthIssuer,
+			Subject:   localAuthSubjectPrefix + email,
+			Audience:  jwt.ClaimStrings{LocalAuthClientID},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		Email:         email,
+		Name:          name,
+		EmailVerified: []byte("true"),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(p.secret)
+	if err != nil {
+		return "", time.Time{}, p.log.Function("IssueIDToken").Err("failed to sign local ID token", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+func (p *LocalAuthProvider) ValidateIDToken(ctx context.Context, idToken string) (*types.TokenInfo, error) {
+	log := p.log.TraceFromContext(ctx).Function("ValidateIDToken")
+
+	var claims oidcIDTokenClaims
+	_, err := jwt.ParseWithClaims(
+		idToken,
+		&claims,
+		func(token *jwt.Token) (any, error) {
+			return p.secret, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(LocalAuthIssuer),
+		jwt.WithAudience(LocalAuthClientID),
+	)
+	if err != nil {
+		return &types.TokenInfo{Valid: false}, log.Err("local ID token validation failed", err)
+	}
+
+	return claims.tokenInfo(), nil
+}
+
+// RevokeToken is a no-op, local tokens are only dropped by the client
+func (p *LocalAuthProvider) RevokeToken(ctx context.Context, token string, tokenType string) error {
+	return nil
+}
+
+// GetLogoutURL sends the user straight back, there is no provider session to end
+func (p *LocalAuthProvider) GetLogoutURL(
+	ctx context.Context,
+	idTokenHint, postLogoutRedirectURI, state string,
+) (string, error) {
+	return postLogoutRedirectURI, nil
+}
+
+func (p *LocalAuthProvider) GetConfig() AuthProviderConfig {
+	return AuthProviderConfig{
+		Provider: AuthProviderLocal,
+		ClientID: LocalAuthClientID,
+	}
+}
+
+// CheckKeys always succeeds, local tokens are signed with the configured secret
+func (p *LocalAuthProvider) CheckKeys(ctx context.Context) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (p *LocalAuthProvider) Close() error {
+	return nil
+}
@@ -0,0 +1,668 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/types"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksForcedRefreshInterval is the least time between key set refetches forced by unknown kids
+const jwksForcedRefreshInterval = time.Minute
+
+// oidcSigningMethods are the asymmetric algorithms accepted for ID tokens
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// OIDCDiscovery represents OIDC discovery document
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKS_URI              string `json:"jwks_uri"`
+	RevocationEndpoint    string `json:"revocation_endpoint"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// JWK represents a JSON Web Key
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKSet represents a set of JSON Web Keys
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type OIDCProviderConfig struct {
+	// Name is recorded as the provider of users signing in, defaults to "oidc"
+	Name      string
+	IssuerURL string
+	ClientID  string
+	// ClientSecret authenticates token revocation for confidential clients, public clients leave
+	// it empty
+	ClientSecret string
+}
+
+// oidcIDTokenClaims are the ID token claims read by OIDCProvider. Claims whose shape differs
+// between providers are kept raw and decoded after parsing.
+type oidcIDTokenClaims struct {
+	jwt.RegisteredClaims
+	Email         string          `json:"email,omitempty"`
+	Name          string          `json:"name,omitempty"`
+	GivenName     string          `json:"given_name,omitempty"`
+	FamilyName    string          `json:"family_name,omitempty"`
+	PreferredName string          `json:"preferred_username,omitempty"`
+	EmailVerified json.RawMessage `json:"email_verified,omitempty"`
+	Nonce         string          `json:"nonce,omitempty"`
+	AuthorizedBy  string          `json:"azp,omitempty"`
+	// Keycloak and Authentik put roles and groups in plain claims, Zitadel in a project scoped
+	// claim keyed by role
+	Roles        json.RawMessage `json:"roles,omitempty"`
+	Groups       json.RawMessage `json:"groups,omitempty"`
+	ZitadelRoles json.RawMessage `json:"urn:zitadel:iam:org:project:roles,omitempty"`
+	ProjectID    string          `json:"urn:zitadel:iam:org:project:id,omitempty"`
+}
+
+// OIDCProvider authenticates users against any OpenID Connect provider found by issuer URL
+// discovery, such as Keycloak, Authentik, Dex or Zitadel
+type OIDCProvider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	log          logger.Logger
+	httpClient   *http.Client
+
+	// clientAuth adds client authentication to revocation requests, providers that authenticate
+	// clients differently replace it
+	clientAuth func(data url.Values) error
+
+	// OIDC discovery and JWK caching
+	discovery     *OIDCDiscovery
+	jwks          *JWKSet
+	discoveryMux  sync.RWMutex
+	jwksMux       sync.RWMutex
+	discoveryTime time.Time
+	jwksTime      time.Time
+	cacheTTL      time.Duration
+	// jwksRefreshedAt is when an unknown kid last forced a refetch, guarded by jwksMux
+	jwksRefreshedAt time.Time
+}
+
+func NewOIDCProvider(cfg OIDCProviderConfig) (*OIDCProvider, error) {
+	log := logger.New("OIDCProvider")
+
+	if cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil, log.ErrMsg("OIDC configuration required but not provided: missing issuer URL or client ID")
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = AuthProviderOIDC
+	}
+
+	provider := &OIDCProvider{
+		name:         name,
+		issuer:       strings.TrimSuffix(cfg.IssuerURL, "/"),
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		log:          log.With("provider", name),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: false},
+			},
+		},
+		cacheTTL: 15 * time.Minute, // Cache OIDC discovery and JWKS for 15 minutes
+	}
+	provider.clientAuth = provider.authenticateClient
+
+	log.Info("OIDC provider initialized", "provider", name, "issuer", provider.issuer)
+	return provider, nil
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+// ValidateIDToken validates an OIDC ID token with proper JWT signature verification
+func (p *OIDCProvider) ValidateIDToken(
+	ctx context.Context,
+	idToken string,
+) (*types.TokenInfo, error) {
+	log := p.log.TraceFromContext(ctx).Function("ValidateIDToken")
+
+	var claims oidcIDTokenClaims
+	token, err := jwt.ParseWithClaims(
+		idToken,
+		&claims,
+		func(token *jwt.Token) (any, error) {
+			kidHeader, ok := token.Header["kid"].(string)
+			if !ok {
+				return nil, log.ErrMsg("missing or invalid 'kid' in JWT header")
+			}
+
+			publicKey, err := p.getPublicKeyForToken(ctx, kidHeader)
+			if err != nil {
+				return nil, log.Err("failed to get public key", err)
+			}
+
+			return publicKey, nil
+		},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithAudience(p.clientID),
+	)
+	if err != nil {
+		return &types.TokenInfo{Valid: false}, log.Err("JWT signature verification failed", err)
+	}
+
+	if !token.Valid {
+		return &types.TokenInfo{Valid: false}, log.ErrMsg("JWT token is invalid")
+	}
+
+	// Issuers are compared without the trailing slash some providers, like Authentik, add
+	if strings.TrimSuffix(claims.Issuer, "/") != p.issuer {
+		return &types.TokenInfo{
+			Valid: false,
+		}, log.ErrMsg(
+			"invalid issuer: expected " + p.issuer + ", got " + claims.Issuer,
+		)
+	}
+
+	log.Info("ID token validation successful",
+		"sub", claims.Subject,
+		"email", claims.Email,
+		"exp", claims.ExpiresAt.Time,
+		"iss", claims.Issuer)
+
+	return claims.tokenInfo(), nil
+}
+
+// tokenInfo maps the claims onto the provider independent token information
+func (c *oidcIDTokenClaims) tokenInfo() *types.TokenInfo {
+	// Build display name if 'name' is missing but we have given/family names
+	displayName := c.Name
+	if displayName == "" && (c.GivenName != "" || c.FamilyName != "") {
+		displayName = strings.TrimSpace(c.GivenName + " " + c.FamilyName)
+	}
+
+	roles := claimStrings(c.Roles)
+	roles = append(roles, claimStrings(c.Groups)...)
+	roles = append(roles, claimStrings(c.ZitadelRoles)...)
+
+	// Extract project ID (try multiple claim locations)
+	projectID := c.ProjectID
+	if projectID == "" {
+		projectID = c.AuthorizedBy
+	}
+
+	return &types.TokenInfo{
+		UserID:        c.Subject,
+		Email:         c.Email,
+		Name:          displayName,
+		GivenName:     c.GivenName,
+		FamilyName:    c.FamilyName,
+		PreferredName: c.PreferredName,
+		EmailVerified: claimBool(c.EmailVerified),
+		Roles:         roles,
+		ProjectID:     projectID,
+		Nonce:         c.Nonce,
+		Valid:         true,
+	}
+}
+
+// claimStrings reads a claim holding a string, a list of strings or an object keyed by value
+func claimStrings(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil && single != "" {
+		return []string{single}
+	}
+
+	var keyed map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keyed); err == nil {
+		values := make([]string, 0, len(keyed))
+		for key := range keyed {
+			values = append(values, key)
+		}
+		slices.Sort(values)
+		return values
+	}
+
+	return nil
+}
+
+// claimBool reads a boolean claim that some providers send as a string
+func claimBool(raw json.RawMessage) bool {
+	var value bool
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.EqualFold(text, "true")
+	}
+
+	return false
+}
+
+// getOIDCDiscovery fetches and caches the OIDC discovery document
+func (p *OIDCProvider) getOIDCDiscovery(ctx context.Context) (*OIDCDiscovery, error) {
+	log := p.log.TraceFromContext(ctx).Function("getOIDCDiscovery")
+
+	// Check cache first
+	p.discoveryMux.RLock()
+	if p.discovery != nil && time.Since(p.discoveryTime) < p.cacheTTL {
+		discovery := p.discovery
+		p.discoveryMux.RUnlock()
+		return discovery, nil
+	}
+	p.discoveryMux.RUnlock()
+
+	// Fetch from OIDC discovery endpoint
+	discoveryURL := p.issuer + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", discoveryURL, nil)
+	if err != nil {
+		return nil, log.Err("failed to create discovery request", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, log.Err("failed to fetch OIDC discovery", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Info("failed to close discovery response body", "error", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, log.Error("OIDC discovery request failed",
+			"statusCode", resp.StatusCode)
+	}
+
+	var discovery OIDCDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, log.Err("failed to decode OIDC discovery", err)
+	}
+
+	// Validate discovery document
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.issuer {
+		return nil, log.ErrMsg(
+			"invalid issuer in discovery document: expected " + p.issuer + ", got " + discovery.Issuer,
+		)
+	}
+
+	if discovery.JWKS_URI == "" {
+		return nil, log.ErrMsg("missing JWKS URI in discovery document")
+	}
+
+	// Cache the discovery document
+	p.discoveryMux.Lock()
+	p.discovery = &discovery
+	p.discoveryTime = time.Now()
+	p.discoveryMux.Unlock()
+
+	log.Info("OIDC discovery fetched successfully", "jwks_uri", discovery.JWKS_URI)
+	return &discovery, nil
+}
+
+// getJWKS fetches and caches the JSON Web Key Set, refresh skips the cache
+func (p *OIDCProvider) getJWKS(ctx context.Context, refresh bool) (*JWKSet, error) {
+	log := p.log.TraceFromContext(ctx).Function("getJWKS")
+
+	// Check cache first
+	p.jwksMux.RLock()
+	if !refresh && p.jwks != nil && time.Since(p.jwksTime) < p.cacheTTL {
+		jwks := p.jwks
+		p.jwksMux.RUnlock()
+		return jwks, nil
+	}
+	p.jwksMux.RUnlock()
+
+	// Get OIDC discovery to find JWKS URI
+	discovery, err := p.getOIDCDiscovery(ctx)
+	if err != nil {
+		return nil, log.Err("failed to get OIDC discovery for JWKS", err)
+	}
+
+	// Fetch JWKS
+	req, err := http.NewRequestWithContext(ctx, "GET", discovery.JWKS_URI, nil)
+	if err != nil {
+		return nil, log.Err("failed to create JWKS request", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, log.Err("failed to fetch JWKS", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Info("failed to close JWKS response body", "error", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, log.Error("JWKS request failed",
+			"statusCode", resp.StatusCode)
+	}
+
+	var jwks JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, log.Err("failed to decode JWKS", err)
+	}
+
+	if len(jwks.Keys) == 0 {
+		return nil, log.ErrMsg("JWKS contains no keys")
+	}
+
+	// Cache the JWKS
+	p.jwksMux.Lock()
+	p.jwks = &jwks
+	p.jwksTime = time.Now()
+	p.jwksMux.Unlock()
+
+	log.Info("JWKS fetched successfully", "keys_count", len(jwks.Keys))
+	return &jwks, nil
+}
+
+// getPublicKeyForToken retrieves the public key for JWT verification based on kid header. An
+// unknown kid refetches the key set, so keys rotated by the provider are picked up right away, but
+// at most once per jwksForcedRefreshInterval so unauthenticated callers can not make every request
+// hit the provider. Unknown kids within the interval are rejected.
+func (p *OIDCProvider) getPublicKeyForToken(ctx context.Context, kidHeader string) (any, error) {
+	log := p.log.TraceFromContext(ctx).Function("getPublicKeyForToken")
+
+	jwks, err := p.getJWKS(ctx, false)
+	if err != nil {
+		return nil, log.Err("failed to get JWKS", err)
+	}
+
+	targetJWK := findJWK(jwks, kidHeader)
+	if targetJWK == nil && p.claimForcedRefresh() {
+		if jwks, err = p.getJWKS(ctx, true); err != nil {
+			return nil, log.Err("failed to refresh JWKS", err)
+		}
+		targetJWK = findJWK(jwks, kidHeader)
+	}
+
+	if targetJWK == nil {
+		return nil, log.ErrMsg("no matching key found: kid " + kidHeader + " not found in JWKS")
+	}
+
+	publicKey, err := targetJWK.publicKey()
+	if err != nil {
+		return nil, log.Err("failed to decode public key", err, "kid", kidHeader)
+	}
+
+	log.Debug("public key retrieved successfully", "kid", kidHeader, "keyType", targetJWK.Kty)
+	return publicKey, nil
+}
+
+// claimForcedRefresh reports whether an unknown kid may refetch the key set now, recording the
+// refetch so concurrent requests do not refetch too
+func (p *OIDCProvider) claimForcedRefresh() bool {
+	p.jwksMux.Lock()
+	defer p.jwksMux.Unlock()
+
+	if time.Since(p.jwksRefreshedAt) < jwksForcedRefreshInterval {
+		return false
+	}
+	p.jwksRefreshedAt = time.Now()
+	return true
+}
+
+func findJWK(jwks *JWKSet, kid string) *JWK {
+	for i := range jwks.Keys {
+		if jwks.Keys[i].Kid == kid {
+			return &jwks.Keys[i]
+		}
+	}
+	return nil
+}
+
+// publicKey decodes an RSA or elliptic curve JSON Web Key
+func (k *JWK) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode RSA modulus (n): %w", err)
+		}
+		eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode RSA exponent (e): %w", err)
+		}
+
+		e := new(big.Int).SetBytes(eBytes)
+		// Validate RSA exponent fits in int (prevent overflow on 32-bit systems)
+		if !e.IsInt64() || e.Int64() > int64(^uint(0)>>1) {
+			return nil, fmt.Errorf("RSA exponent too large: %s", e.String())
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(nBytes), E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported elliptic curve: %s", k.Crv)
+		}
+
+		xBytes, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode EC x coordinate: %w", err)
+		}
+		yBytes, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode EC y coordinate: %w", err)
+		}
+
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", k.Crv)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+// authenticateClient identifies the client on revocation requests, with its secret when it has one
+func (p *OIDCProvider) authenticateClient(data url.Values) error {
+	data.Set("client_id", p.clientID)
+	if p.clientSecret != "" {
+		data.Set("client_secret", p.clientSecret)
+	}
+	return nil
+}
+
+// RevokeToken revokes an access or refresh token with the provider
+func (p *OIDCProvider) RevokeToken(ctx context.Context, token string, tokenType string) error {
+	log := p.log.TraceFromContext(ctx).Function("RevokeToken")
+
+	// Get OIDC discovery to find revocation endpoint
+	discovery, err := p.getOIDCDiscovery(ctx)
+	if err != nil {
+		return log.Err("failed to get OIDC discovery for token revocation", err)
+	}
+
+	if discovery.RevocationEndpoint == "" {
+		return log.ErrMsg(
+			"revocation endpoint not available: revocation_endpoint not found in OIDC discovery",
+		)
+	}
+
+	// Prepare form data for revocation request
+	data := url.Values{}
+	data.Set("token", token)
+	if tokenType != "" {
+		data.Set("token_type_hint", tokenType)
+	}
+
+	if err := p.clientAuth(data); err != nil {
+		return log.Err("failed to authenticate client for revocation", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		discovery.RevocationEndpoint,
+		strings.NewReader(data.Encode()),
+	)
+	if err != nil {
+		return log.Err("failed to create token revocation request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return log.Err("failed to make token revocation request", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Info("failed to close revocation response body", "error", closeErr)
+		}
+	}()
+
+	// RFC 7009 states that revocation endpoint should return 200 for successful revocation
+	// or invalid tokens (to prevent token scanning attacks)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return log.Error("token revocation request failed",
+			"statusCode", resp.StatusCode,
+			"responseBody", string(body))
+	}
+
+	log.Info(
+		"token revocation successful",
+		"tokenType",
+		tokenType,
+		"endpoint",
+		discovery.RevocationEndpoint,
+	)
+	return nil
+}
+
+// GetLogoutURL generates the OIDC logout URL using the end_session_endpoint
+func (p *OIDCProvider) GetLogoutURL(
+	ctx context.Context,
+	idTokenHint, postLogoutRedirectURI, state string,
+) (string, error) {
+	log := p.log.TraceFromContext(ctx).Function("GetLogoutURL")
+
+	// Get OIDC discovery to find end session endpoint
+	discovery, err := p.getOIDCDiscovery(ctx)
+	if err != nil {
+		return "", log.Err("failed to get OIDC discovery for logout URL", err)
+	}
+
+	if discovery.EndSessionEndpoint == "" {
+		return "", log.ErrMsg(
+			"end session endpoint not available: end_session_endpoint not found in OIDC discovery",
+		)
+	}
+
+	// Build logout URL with query parameters
+	logoutURL, err := url.Parse(discovery.EndSessionEndpoint)
+	if err != nil {
+		return "", log.Err("failed to parse end session endpoint", err)
+	}
+
+	params := logoutURL.Query()
+
+	// Add ID token hint if provided (recommended for better UX)
+	if idTokenHint != "" {
+		params.Set("id_token_hint", idTokenHint)
+	}
+
+	// Add post logout redirect URI if provided
+	if postLogoutRedirectURI != "" {
+		params.Set("post_logout_redirect_uri", postLogoutRedirectURI)
+		// Providers that can not match the redirect through the ID token hint need the client
+		params.Set("client_id", p.clientID)
+	}
+
+	// Add state parameter if provided (for maintaining application state across logout)
+	if state != "" {
+		params.Set("state", state)
+	}
+
+	logoutURL.RawQuery = params.Encode()
+
+	log.Info("logout URL generated successfully",
+		"endpoint", discovery.EndSessionEndpoint,
+		"hasIdToken", idTokenHint != "",
+		"hasRedirectURI", postLogoutRedirectURI != "")
+
+	return logoutURL.String(), nil
+}
+
+// GetConfig returns the OIDC configuration for client consumption
+func (p *OIDCProvider) GetConfig() AuthProviderConfig {
+	return AuthProviderConfig{
+		Provider:    p.name,
+		Domain:      strings.TrimPrefix(p.issuer, "https://"),
+		InstanceURL: p.issuer,
+		ClientID:    p.clientID,
+	}
+}
+
+// CheckKeys fetches the JWKS once the cached set expired and returns when the set was fetched
+func (p *OIDCProvider) CheckKeys(ctx context.Context) (time.Time, error) {
+	if _, err := p.getJWKS(ctx, false); err != nil {
+		return time.Time{}, err
+	}
+
+	p.jwksMux.RLock()
+	defer p.jwksMux.RUnlock()
+	return p.jwksTime, nil
+}
+
+// Close cleans up the provider resources
+func (p *OIDCProvider) Close() error {
+	// No resources to clean up for HTTP client
+	return nil
+}
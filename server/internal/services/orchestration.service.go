@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 	"waugzee/internal/database"
 	"waugzee/internal/events"
@@ -23,6 +24,28 @@ type RequestMetadata struct {
 	FolderID     *int      `json:"folderId,omitempty"`
 }
 
+// newAPIRequestMessage builds the api_request event asking the user's client to make a Discogs GET
+// request, answered with an api_response handled by the orchestration service
+func newAPIRequestMessage(
+	userID uuid.UUID,
+	discogsToken string,
+	request events.APIRequest,
+) (events.Message, error) {
+	request.Method = "GET"
+	request.Headers = map[string]string{
+		"Authorization": fmt.Sprintf("Discogs token=%s", discogsToken),
+	}
+	request.CallbackService = "orchestration"
+	request.CallbackEvent = "api_response"
+
+	message, err := events.NewMessage(events.API, userID.String(), request)
+	if err != nil {
+		return events.Message{}, err
+	}
+	message.ID = request.RequestID
+	return message, nil
+}
+
 type OrchestrationService struct {
 	log                logger.Logger
 	eventBus           *events.EventBus
@@ -40,6 +63,7 @@ func NewOrchestrationService(
 	db database.DB,
 	transactionService *TransactionService,
 	discogsRateLimiter *DiscogsRateLimiterService,
+	imageService *ImageService,
 ) *OrchestrationService {
 	log := logger.New("OrchestrationService")
 	folderDataExtractionService := NewFolderDataExtractionService(repos)
@@ -50,6 +74,7 @@ func NewOrchestrationService(
 		transactionService,
 		folderDataExtractionService,
 		discogsRateLimiter,
+		imageService,
 	)
 	releaseSyncService := NewReleaseSyncService(eventBus, repos, db, discogsRateLimiter)
 	return &OrchestrationService{
@@ -89,17 +114,8 @@ func (o *OrchestrationService) SyncUserFoldersAndCollection(
 	}
 
 	// Send sync_start event to notify client
-	startMessage := events.Message{
-		ID:      user.ID.String(),
-		Service: events.USER,
-		Event:   "sync_start",
-		UserID:  user.ID.String(),
-		Payload: map[string]any{
-			"message": "Collection sync started",
-		},
-		Timestamp: time.Now(),
-	}
-	if err := o.eventBus.Publish(events.WEBSOCKET, "user", startMessage); err != nil {
+	syncStarted := events.SyncStarted{Message: "Collection sync started"}
+	if err := o.eventBus.PublishToUser(user.ID.String(), syncStarted); err != nil {
 		log.Warn("Failed to send sync_start event", "error", err)
 	}
 
@@ -107,18 +123,11 @@ func (o *OrchestrationService) SyncUserFoldersAndCollection(
 	_, err := o.foldersService.RequestUserFolders(ctx, user)
 	if err != nil {
 		// Send sync_error event
-		errorMessage := events.Message{
-			ID:      user.ID.String(),
-			Service: events.USER,
-			Event:   "sync_error",
-			UserID:  user.ID.String(),
-			Payload: map[string]any{
-				"error":   "Failed to initiate folder discovery",
-				"message": err.Error(),
-			},
-			Timestamp: time.Now(),
+		syncFailed := events.SyncFailed{
+			Error:   "Failed to initiate folder discovery",
+			Message: err.Error(),
 		}
-		if publishErr := o.eventBus.Publish(events.WEBSOCKET, "user", errorMessage); publishErr != nil {
+		if publishErr := o.eventBus.PublishToUser(user.ID.String(), syncFailed); publishErr != nil {
 			log.Warn("Failed to send sync_error event", "error", publishErr)
 		}
 		return log.Err("failed to initiate folder discovery", err)
@@ -254,7 +263,8 @@ func (o *OrchestrationService) handleReleaseResponse(
 	// Extract syncStateId from response data to update collection sync state
 	syncStateID, exists := responseData["syncStateId"]
 	if !exists {
-		log.Warn("No syncStateId in release response - cannot update collection sync state")
+		// Release refreshes are requested outside of a collection sync
+		log.Debug("No syncStateId in release response, not part of a collection sync")
 		return nil
 	}
 
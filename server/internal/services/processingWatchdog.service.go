@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"waugzee/config"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/models"
+	"waugzee/internal/repositories"
+)
+
+const (
+	// ProcessingHeartbeatInterval is how often a job refreshes the heartbeat of the record it works on
+	ProcessingHeartbeatInterval = time.Minute
+	// DefaultProcessingHeartbeatTimeout is how long an active record may go without a heartbeat
+	// before the watchdog fails it
+	DefaultProcessingHeartbeatTimeout = 30 * time.Minute
+)
+
+// StartProcessingHeartbeat refreshes the heartbeat of an active processing record every
+// ProcessingHeartbeatInterval until the returned stop func is called or ctx is done
+func StartProcessingHeartbeat(
+	ctx context.Context,
+	repo repositories.DiscogsDataProcessingRepository,
+	id int64,
+) (stop func()) {
+	return startProcessingHeartbeat(ctx, repo, id, ProcessingHeartbeatInterval)
+}
+
+func startProcessingHeartbeat(
+	ctx context.Context,
+	repo repositories.DiscogsDataProcessingRepository,
+	id int64,
+	interval time.Duration,
+) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// A missed beat is retried on the next tick, the repository logs the error
+				_ = repo.Heartbeat(ctx, id)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// ProcessingWatchdogService fails processing records whose job stopped without finishing them,
+// e.g. because the instance running it was killed, so the next scheduled run can retry them
+type ProcessingWatchdogService struct {
+	repo    repositories.DiscogsDataProcessingRepository
+	timeout time.Duration
+	log     logger.Logger
+}
+
+func NewProcessingWatchdogService(
+	repo repositories.DiscogsDataProcessingRepository,
+	config config.Config,
+) *ProcessingWatchdogService {
+	timeout := DefaultProcessingHeartbeatTimeout
+	if config.DiscogsHeartbeatTimeoutMinutes > 0 {
+		timeout = time.Duration(config.DiscogsHeartbeatTimeoutMinutes) * time.Minute
+	}
+
+	return &ProcessingWatchdogService{
+		repo:    repo,
+		timeout: timeout,
+		log:     logger.New("processingWatchdogService"),
+	}
+}
+
+// FailStaleRecords moves every downloading or processing record without a heartbeat within the
+// timeout to failed and returns how many were failed
+func (s *ProcessingWatchdogService) FailStaleRecords(ctx context.Context) (int, error) {
+	log := s.log.Function("FailStaleRecords")
+
+	deadline := time.Now().UTC().Add(-s.timeout)
+	stale, err := s.repo.GetStale(ctx, deadline)
+	if err != nil {
+		return 0, log.Err("failed to get stale processing records", err)
+	}
+
+	failed := 0
+	for _, record := range stale {
+		lastSeen := record.UpdatedAt
+		if record.HeartbeatAt != nil && record.HeartbeatAt.After(lastSeen) {
+			lastSeen = *record.HeartbeatAt
+		}
+		reason := fmt.Sprintf(
+			"no heartbeat while %s since %s, the job stopped without finishing",
+			record.Status,
+			lastSeen.UTC().Format(time.RFC3339),
+		)
+
+		if err := record.UpdateStatus(models.ProcessingStatusFailed, reason); err != nil {
+			return failed, log.Err("failed to transition stale record", err, "yearMonth", record.YearMonth)
+		}
+		record.ErrorMessage = &reason
+
+		if err := s.repo.Update(ctx, record); err != nil {
+			// The job came back to life and moved the record on, leave it alone
+			if errors.Is(err, models.ErrProcessingStatusConflict) {
+				log.Info("Stale record changed status before it was failed", "yearMonth", record.YearMonth)
+				continue
+			}
+			return failed, log.Err("failed to fail stale record", err, "yearMonth", record.YearMonth)
+		}
+
+		log.Warn("Failed stale processing record", "yearMonth", record.YearMonth, "reason", reason)
+		failed++
+	}
+
+	return failed, nil
+}
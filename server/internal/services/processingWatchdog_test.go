@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	logger "github.com/Bparsons0904/goLogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProcessingRepository keeps processing records in memory and applies the same status
+// conflict check as the real repository
+type fakeProcessingRepository struct {
+	repositories.DiscogsDataProcessingRepository
+
+	mu          sync.Mutex
+	records     map[int64]models.DiscogsDataProcessing
+	transitions []models.DiscogsDataProcessingTransition
+	heartbeats  int
+}
+
+func newFakeProcessingRepository(records ...models.DiscogsDataProcessing) *fakeProcessingRepository {
+	repo := &fakeProcessingRepository{records: map[int64]models.DiscogsDataProcessing{}}
+	for _, record := range records {
+		repo.records[record.ID] = record
+	}
+	return repo
+}
+
+func (r *fakeProcessingRepository) Update(ctx context.Context, processing *models.DiscogsDataProcessing) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.records[processing.ID]
+	if stored.Status != processing.PersistedStatus() {
+		return fmt.Errorf("%w: expected %s", models.ErrProcessingStatusConflict, processing.PersistedStatus())
+	}
+
+	r.transitions = append(r.transitions, processing.PendingTransitions()...)
+	processing.ClearPendingTransitions()
+	r.records[processing.ID] = *processing
+	return nil
+}
+
+func (r *fakeProcessingRepository) Heartbeat(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeats++
+	return nil
+}
+
+func (r *fakeProcessingRepository) GetStale(
+	ctx context.Context,
+	deadline time.Time,
+) ([]*models.DiscogsDataProcessing, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stale []*models.DiscogsDataProcessing
+	for _, record := range r.records {
+		if record.Status != models.ProcessingStatusDownloading &&
+			record.Status != models.ProcessingStatusProcessing {
+			continue
+		}
+		if record.HeartbeatAt != nil && record.HeartbeatAt.After(deadline) {
+			continue
+		}
+		copied := record
+		stale = append(stale, &copied)
+	}
+	return stale, nil
+}
+
+func (r *fakeProcessingRepository) status(id int64) models.ProcessingStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.records[id].Status
+}
+
+func TestProcessingWatchdogFailsStaleRecords(t *testing.T) {
+	stale := time.Now().UTC().Add(-2 * time.Hour)
+	fresh := time.Now().UTC()
+
+	repo := newFakeProcessingRepository(
+		models.DiscogsDataProcessing{
+			BaseDiscogModel: models.BaseDiscogModel{ID: 1},
+			YearMonth:       "2025-01",
+			Status:          models.ProcessingStatusProcessing,
+			HeartbeatAt:     &stale,
+		},
+		models.DiscogsDataProcessing{
+			BaseDiscogModel: models.BaseDiscogModel{ID: 2},
+			YearMonth:       "2025-02",
+			Status:          models.ProcessingStatusDownloading,
+			HeartbeatAt:     &fresh,
+		},
+	)
+	watchdog := &ProcessingWatchdogService{repo: repo, timeout: time.Hour, log: logger.New("test")}
+
+	failed, err := watchdog.FailStaleRecords(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+
+	assert.Equal(t, models.ProcessingStatusFailed, repo.status(1))
+	assert.Equal(t, models.ProcessingStatusDownloading, repo.status(2))
+
+	require.Len(t, repo.transitions, 1)
+	transition := repo.transitions[0]
+	assert.Equal(t, models.ProcessingStatusProcessing, transition.FromStatus)
+	assert.Equal(t, models.ProcessingStatusFailed, transition.ToStatus)
+	require.NotNil(t, transition.Reason)
+	assert.Contains(t, *transition.Reason, "no heartbeat while processing")
+	assert.Equal(t, *transition.Reason, *repo.records[1].ErrorMessage)
+}
+
+// conflictingProcessingRepository moves a record on between GetStale and Update, as a job that
+// recovers just in time would
+type conflictingProcessingRepository struct {
+	*fakeProcessingRepository
+}
+
+func (r conflictingProcessingRepository) GetStale(
+	ctx context.Context,
+	deadline time.Time,
+) ([]*models.DiscogsDataProcessing, error) {
+	stale, err := r.fakeProcessingRepository.GetStale(ctx, deadline)
+
+	r.mu.Lock()
+	for id, record := range r.records {
+		record.Status = models.ProcessingStatusCompleted
+		r.records[id] = record
+	}
+	r.mu.Unlock()
+
+	return stale, err
+}
+
+func TestProcessingWatchdogLeavesRecordsThatMovedOn(t *testing.T) {
+	repo := newFakeProcessingRepository(models.DiscogsDataProcessing{
+		BaseDiscogModel: models.BaseDiscogModel{ID: 1},
+		YearMonth:       "2025-01",
+		Status:          models.ProcessingStatusProcessing,
+	})
+	watchdog := &ProcessingWatchdogService{
+		repo:    conflictingProcessingRepository{repo},
+		timeout: time.Hour,
+		log:     logger.New("test"),
+	}
+
+	failed, err := watchdog.FailStaleRecords(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, failed)
+	assert.Equal(t, models.ProcessingStatusCompleted, repo.status(1))
+	assert.Empty(t, repo.transitions)
+}
+
+func TestProcessingHeartbeatRunsUntilStopped(t *testing.T) {
+	repo := newFakeProcessingRepository()
+
+	stop := startProcessingHeartbeat(context.Background(), repo, 1, time.Millisecond)
+	require.Eventually(t, func() bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return repo.heartbeats >= 3
+	}, time.Second, time.Millisecond)
+	stop()
+
+	repo.mu.Lock()
+	beats := repo.heartbeats
+	repo.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, beats, repo.heartbeats, "no heartbeats after stop")
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+	"waugzee/config"
+	"waugzee/internal/database"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultReleaseStaleAfter is how long a synced release stays fresh before it is refreshed
+	DefaultReleaseStaleAfter = 90 * 24 * time.Hour
+	// DefaultReleaseRefreshBatchSize caps the releases refreshed per user in one job run, so a
+	// large collection is spread over several runs instead of draining the user's rate limit
+	DefaultReleaseRefreshBatchSize = 50
+)
+
+var ErrReleaseRefreshNotRequested = errors.New("release refresh could not be requested")
+
+// ReleaseStalenessPolicy decides when a stored release needs fresh data from the API
+type ReleaseStalenessPolicy struct {
+	// MaxAge is how long a synced release stays fresh, zero disables the age check
+	MaxAge time.Duration
+}
+
+// IsStale reports whether a release was never fully synced, was changed by a dump after its last
+// sync, or was last synced longer than MaxAge ago
+func (p ReleaseStalenessPolicy) IsStale(release repositories.ReleaseFreshness, now time.Time) bool {
+	if release.LastSynced == nil {
+		return true
+	}
+	if release.DateChanged != nil && release.DateChanged.After(*release.LastSynced) {
+		return true
+	}
+	return p.MaxAge > 0 && now.Sub(*release.LastSynced) > p.MaxAge
+}
+
+// ReleaseFreshnessService keeps the releases in users' collections up to date by refreshing stale
+// ones through each user's client proxy
+type ReleaseFreshnessService struct {
+	repos                repositories.Repository
+	db                   database.DB
+	transaction          *TransactionService
+	releaseSync          *ReleaseSyncService
+	folderDataExtraction *FolderDataExtractionService
+	policy               ReleaseStalenessPolicy
+	batchSize            int
+	log                  logger.Logger
+}
+
+func NewReleaseFreshnessService(
+	repos repositories.Repository,
+	db database.DB,
+	transaction *TransactionService,
+	releaseSync *ReleaseSyncService,
+	folderDataExtraction *FolderDataExtractionService,
+	config config.Config,
+) *ReleaseFreshnessService {
+	policy := ReleaseStalenessPolicy{MaxAge: DefaultReleaseStaleAfter}
+	if config.ReleaseStaleAfterDays > 0 {
+		policy.MaxAge = time.Duration(config.ReleaseStaleAfterDays) * 24 * time.Hour
+	}
+
+	batchSize := DefaultReleaseRefreshBatchSize
+	if config.ReleaseRefreshBatchSize > 0 {
+		batchSize = config.ReleaseRefreshBatchSize
+	}
+
+	return &ReleaseFreshnessService{
+		repos:                repos,
+		db:                   db,
+		transaction:          transaction,
+		releaseSync:          releaseSync,
+		folderDataExtraction: folderDataExtraction,
+		policy:               policy,
+		batchSize:            batchSize,
+		log:                  logger.New("releaseFreshnessService"),
+	}
+}
+
+// RefreshStaleReleases requests fresh data for the stalest releases of every active user with a
+// Discogs token and returns how many requests were published. Users whose client is not connected
+// keep their stale releases until a later run.
+func (s *ReleaseFreshnessService) RefreshStaleReleases(ctx context.Context) (int, error) {
+	log := s.log.Function("RefreshStaleReleases")
+
+	users, err := s.repos.User.GetAllUsers(ctx, s.db.SQLWithContext(ctx))
+	if err != nil {
+		return 0, log.Err("failed to get users", err)
+	}
+
+	requested := 0
+	for _, listed := range users {
+		user, err := s.repos.User.GetByID(ctx, s.db.SQLWithContext(ctx), listed.ID)
+		if err != nil {
+			log.Warn("Failed to load user for release refresh", "userID", listed.ID, "error", err)
+			continue
+		}
+		if user.Configuration == nil || user.Configuration.DiscogsToken == nil ||
+			*user.Configuration.DiscogsToken == "" {
+			continue
+		}
+
+		count, err := s.refreshUser(ctx, user)
+		if err != nil {
+			log.Warn("Failed to refresh stale releases", "userID", user.ID, "error", err)
+			continue
+		}
+		requested += count
+	}
+
+	return requested, nil
+}
+
+// RefreshRelease requests fresh data for one release right away, whatever its staleness
+func (s *ReleaseFreshnessService) RefreshRelease(ctx context.Context, user *User, releaseID int64) error {
+	log := s.log.Function("RefreshRelease")
+
+	requested, err := s.releaseSync.RefreshReleases(ctx, user, []int64{releaseID})
+	if err != nil {
+		return log.Err("failed to refresh release", err, "releaseID", releaseID)
+	}
+	if requested == 0 {
+		return log.Err("failed to refresh release", ErrReleaseRefreshNotRequested, "releaseID", releaseID)
+	}
+
+	return nil
+}
+
+func (s *ReleaseFreshnessService) refreshUser(ctx context.Context, user *User) (int, error) {
+	log := s.log.Function("refreshUser").With("userID", user.ID)
+
+	freshness, err := s.repos.Release.GetCollectionFreshness(ctx, s.db.SQLWithContext(ctx), user.ID)
+	if err != nil {
+		return 0, log.Err("failed to get collection freshness", err)
+	}
+
+	// Dump releases that were never stamped only need the catalog, not the API
+	now := time.Now()
+	unsynced := make([]int64, 0)
+	for _, release := range freshness {
+		if release.LastSynced == nil && release.ContentHash != nil {
+			unsynced = append(unsynced, release.ID)
+		}
+	}
+	if len(unsynced) > 0 {
+		var enrichment *CatalogEnrichmentResult
+		err = s.transaction.Execute(ctx, func(txCtx context.Context, tx *gorm.DB) error {
+			var enrichErr error
+			enrichment, enrichErr = s.folderDataExtraction.EnrichFromCatalog(txCtx, tx, unsynced)
+			return enrichErr
+		})
+		if err != nil {
+			return 0, log.Err("failed to enrich releases from catalog", err)
+		}
+		fromCatalog := make(map[int64]bool, len(enrichment.FromCatalog))
+		for _, id := range enrichment.FromCatalog {
+			fromCatalog[id] = true
+		}
+		for i := range freshness {
+			if fromCatalog[freshness[i].ID] {
+				freshness[i].LastSynced = &now
+			}
+		}
+	}
+
+	stale := selectStaleReleases(freshness, s.policy, now, s.batchSize)
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	requested, err := s.releaseSync.RefreshReleases(ctx, user, stale)
+	if err != nil {
+		return 0, log.Err("failed to request release refresh", err)
+	}
+
+	log.Info("Requested refresh of stale releases", "stale", len(stale), "requested", requested)
+	return requested, nil
+}
+
+// selectStaleReleases picks up to limit stale releases, never synced ones first and then the ones
+// synced longest ago
+func selectStaleReleases(
+	releases []repositories.ReleaseFreshness,
+	policy ReleaseStalenessPolicy,
+	now time.Time,
+	limit int,
+) []int64 {
+	stale := make([]repositories.ReleaseFreshness, 0)
+	for _, release := range releases {
+		if policy.IsStale(release, now) {
+			stale = append(stale, release)
+		}
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		a, b := stale[i].LastSynced, stale[j].LastSynced
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+
+	if limit > 0 && len(stale) > limit {
+		stale = stale[:limit]
+	}
+
+	ids := make([]int64, len(stale))
+	for i, release := range stale {
+		ids[i] = release.ID
+	}
+	return ids
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"waugzee/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseStalenessPolicy_IsStale(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := ReleaseStalenessPolicy{MaxAge: 30 * 24 * time.Hour}
+	at := func(daysAgo int) *time.Time {
+		value := now.AddDate(0, 0, -daysAgo)
+		return &value
+	}
+
+	tests := []struct {
+		name    string
+		release repositories.ReleaseFreshness
+		stale   bool
+	}{
+		{"never synced", repositories.ReleaseFreshness{ID: 1}, true},
+		{"recently synced", repositories.ReleaseFreshness{ID: 2, LastSynced: at(5)}, false},
+		{"synced too long ago", repositories.ReleaseFreshness{ID: 3, LastSynced: at(31)}, true},
+		{"changed by dump after sync", repositories.ReleaseFreshness{ID: 4, LastSynced: at(5), DateChanged: at(2)}, true},
+		{"changed before sync", repositories.ReleaseFreshness{ID: 5, LastSynced: at(5), DateChanged: at(10)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.stale, policy.IsStale(tt.release, now))
+		})
+	}
+
+	t.Run("zero max age disables the age check", func(t *testing.T) {
+		release := repositories.ReleaseFreshness{ID: 6, LastSynced: at(3650)}
+		assert.False(t, ReleaseStalenessPolicy{}.IsStale(release, now))
+	})
+}
+
+func TestSelectStaleReleases(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := ReleaseStalenessPolicy{MaxAge: 30 * 24 * time.Hour}
+	at := func(daysAgo int) *time.Time {
+		value := now.AddDate(0, 0, -daysAgo)
+		return &value
+	}
+
+	releases := []repositories.ReleaseFreshness{
+		{ID: 1, LastSynced: at(40)},
+		{ID: 2, LastSynced: at(1)},
+		{ID: 3},
+		{ID: 4, LastSynced: at(90)},
+		{ID: 5, LastSynced: at(60)},
+	}
+
+	assert.Equal(t, []int64{3, 4, 5, 1}, selectStaleReleases(releases, policy, now, 0))
+	assert.Equal(t, []int64{3, 4}, selectStaleReleases(releases, policy, now, 2))
+	assert.Empty(t, selectStaleReleases(releases[1:2], policy, now, 10))
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 	"waugzee/internal/database"
@@ -15,6 +16,8 @@ import (
 	"github.com/google/uuid"
 )
 
+var ErrDiscogsTokenMissing = errors.New("user does not have a Discogs token")
+
 type ReleaseSyncService struct {
 	log                logger.Logger
 	eventBus           *events.EventBus
@@ -62,19 +65,41 @@ func (rs *ReleaseSyncService) RequestMissingReleases(
 		"missingCount", len(missingReleaseIDs),
 		"syncStateID", syncStateID)
 
-	// Track request IDs to update sync state
-	requestIDs := make([]string, 0, len(missingReleaseIDs))
+	requestIDs := rs.publishReleaseRequests(ctx, user, missingReleaseIDs, syncStateID)
+
+	// Update sync state with pending request IDs
+	if len(requestIDs) > 0 {
+		err := rs.updateSyncStateWithPendingRequests(ctx, syncStateID, requestIDs)
+		if err != nil {
+			log.Warn("Failed to update sync state with pending requests", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// publishReleaseRequests sends one API request per release through the user's client proxy, up to
+// MaxPendingAPIRequests, and returns the IDs of the requests that were published
+func (rs *ReleaseSyncService) publishReleaseRequests(
+	ctx context.Context,
+	user *User,
+	releaseIDs []int64,
+	syncStateID string,
+) []string {
+	log := rs.log.Function("publishReleaseRequests")
+
+	requestIDs := make([]string, 0, len(releaseIDs))
 
 	// Limit concurrent API requests to prevent overwhelming the system
 	processedCount := 0
-	maxRequests := min(len(missingReleaseIDs), MaxPendingAPIRequests)
+	maxRequests := min(len(releaseIDs), MaxPendingAPIRequests)
 
 	// Request each missing release up to the limit
-	for _, releaseID := range missingReleaseIDs {
+	for _, releaseID := range releaseIDs {
 		if processedCount >= maxRequests {
-			log.Warn("Reached maximum concurrent API requests, queuing remaining releases",
+			log.Warn("Reached maximum concurrent API requests, leaving remaining releases for later",
 				"maxRequests", MaxPendingAPIRequests,
-				"totalRequested", len(missingReleaseIDs),
+				"totalRequested", len(releaseIDs),
 				"processed", processedCount)
 			break
 		}
@@ -108,32 +133,20 @@ func (rs *ReleaseSyncService) RequestMissingReleases(
 
 		// Create API request message
 		fullURL := fmt.Sprintf("%s/releases/%d", DiscogsAPIBaseURL, releaseID)
-		message := events.Message{
-			ID:      requestID,
-			Service: events.API,
-			Event:   "api_request",
-			UserID:  user.ID.String(),
-			Payload: map[string]any{
-				"requestId":   requestID,
-				"requestType": "release",
-				"releaseId":   releaseID,
-				"syncStateId": syncStateID,
-				"url":         fullURL,
-				"method":      "GET",
-				"headers": map[string]string{
-					"Authorization": fmt.Sprintf(
-						"Discogs token=%s",
-						*user.Configuration.DiscogsToken,
-					),
-				},
-				"callbackService": "orchestration",
-				"callbackEvent":   "api_response",
-			},
-			Timestamp: time.Now(),
-		}
+		// Refresh requests are not part of a collection sync and carry no sync state
+		message, err := newAPIRequestMessage(user.ID, *user.Configuration.DiscogsToken, events.APIRequest{
+			RequestID:   requestID,
+			RequestType: "release",
+			URL:         fullURL,
+			ReleaseID:   &releaseID,
+			SyncStateID: syncStateID,
+		})
 
 		// Publish API request
-		if err := rs.eventBus.Publish(events.WEBSOCKET, "user", message); err != nil {
+		if err == nil {
+			err = rs.eventBus.Publish(events.WEBSOCKET, "user", message)
+		}
+		if err != nil {
 			// Clean up cache entry since we can't proceed
 			_ = database.NewCacheBuilder(rs.db.Cache.ClientAPI, requestID).
 				WithHashPattern(API_HASH).
@@ -146,20 +159,41 @@ func (rs *ReleaseSyncService) RequestMissingReleases(
 
 		requestIDs = append(requestIDs, requestID)
 		processedCount++
-		log.Debug("Requested missing release",
+		log.Debug("Requested release",
 			"releaseID", releaseID,
 			"requestID", requestID)
 	}
 
-	// Update sync state with pending request IDs
-	if len(requestIDs) > 0 {
-		err := rs.updateSyncStateWithPendingRequests(ctx, syncStateID, requestIDs)
-		if err != nil {
-			log.Warn("Failed to update sync state with pending requests", "error", err)
-		}
+	return requestIDs
+}
+
+// RefreshReleases requests fresh data for releases that are already stored, outside of any
+// collection sync. Responses are saved like any other release response. It returns how many
+// requests were published.
+func (rs *ReleaseSyncService) RefreshReleases(
+	ctx context.Context,
+	user *User,
+	releaseIDs []int64,
+) (int, error) {
+	log := rs.log.Function("RefreshReleases")
+
+	if len(releaseIDs) == 0 {
+		return 0, nil
 	}
 
-	return nil
+	if user.Configuration == nil || user.Configuration.DiscogsToken == nil ||
+		*user.Configuration.DiscogsToken == "" {
+		return 0, log.Err("cannot refresh releases", ErrDiscogsTokenMissing, "userID", user.ID)
+	}
+
+	requestIDs := rs.publishReleaseRequests(ctx, user, releaseIDs, "")
+
+	log.Info("Requested release refresh",
+		"userID", user.ID,
+		"releases", len(releaseIDs),
+		"requested", len(requestIDs))
+
+	return len(requestIDs), nil
 }
 
 // ProcessReleaseResponse handles API response for individual release requests
@@ -213,6 +247,31 @@ func (rs *ReleaseSyncService) ProcessReleaseResponse(
 	if releaseData.MasterID > 0 {
 		release.MasterID = &releaseData.MasterID
 	}
+	if changed, err := time.Parse(time.RFC3339, releaseData.DateChanged); err == nil {
+		release.DateChanged = &changed
+	}
+
+	// Store images in the same shape as the dump and use the primary one as cover image
+	if len(releaseData.Images) > 0 {
+		images := make([]types.Image, 0, len(releaseData.Images))
+		for _, image := range releaseData.Images {
+			images = append(images, types.Image{
+				URI:    image.URI,
+				URI150: image.URI150,
+				Type:   image.Type,
+				Width:  image.Width,
+				Height: image.Height,
+			})
+			if image.Type == "primary" && release.CoverImage == nil && image.URI != "" {
+				release.CoverImage = &image.URI
+			}
+		}
+		if imagesJSON, err := json.Marshal(images); err == nil {
+			release.ImagesJSON = imagesJSON
+		} else {
+			log.Warn("Failed to marshal images", "releaseID", releaseData.ID, "error", err)
+		}
+	}
 
 	// Set format (default to vinyl)
 	release.Format = FormatVinyl
@@ -312,8 +371,19 @@ type DiscogsReleaseData struct {
 	ResourceURL string                 `json:"resource_url"`
 	URI         string                 `json:"uri"`
 	MasterID    int64                  `json:"master_id"`
+	DateChanged string                 `json:"date_changed"`
 	Tracklist   []DiscogsTracklistItem `json:"tracklist"`
 	Formats     []DiscogsFormat        `json:"formats"`
+	Images      []DiscogsImage         `json:"images"`
+}
+
+// DiscogsImage represents an image of a release from Discogs API response
+type DiscogsImage struct {
+	Type        string `json:"type"`
+	URI         string `json:"uri"`
+	URI150      string `json:"uri150"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
 }
 
 // DiscogsTracklistItem represents a track from Discogs API response
@@ -2,22 +2,32 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
+	"waugzee/internal/database"
 	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/metrics"
+	"waugzee/internal/models"
+	"waugzee/internal/repositories"
 
 	"github.com/go-co-op/gocron"
 )
 
-type Schedule int
+// Schedule is a standard five-field cron expression, evaluated in UTC
+type Schedule string
 
 const (
-	Hourly          Schedule = iota
-	Daily                    // Start at 02:00 UTC every day
-	DailyProcessing          // Start at 03:00 UTC every day (1 hour after download)
-	Monthly                  // Start at 02:00 UTC on last day of month
+	Hourly          Schedule = "0 * * * *"
+	Daily           Schedule = "0 2 * * *" // Start at 02:00 UTC every day
+	DailyProcessing Schedule = "0 3 * * *" // Start at 03:00 UTC every day (1 hour after download)
+	// Monthly starts at 02:00 UTC on the last day of the month, which cron cannot express
+	Monthly Schedule = "@lastdayofmonth"
 )
 
+var ErrJobNotFound = errors.New("job not found")
+
 // Job represents a scheduled task that can be executed by the scheduler
 type Job interface {
 	// Name returns a human-readable name for the job
@@ -29,17 +39,29 @@ type Job interface {
 	Schedule() Schedule
 }
 
+// JobInfo describes a registered job and its most recent run
+type JobInfo struct {
+	Name     string         `json:"name"`
+	Schedule Schedule       `json:"schedule"`
+	NextRun  *time.Time     `json:"nextRun,omitempty"`
+	LastRun  *models.JobRun `json:"lastRun,omitempty"`
+}
+
 type SchedulerService struct {
-	scheduler *gocron.Scheduler
-	jobs      []Job
-	log       logger.Logger
-	started   bool
-	mu        sync.Mutex
-	ctx       context.Context
-	cancel    context.CancelFunc
+	scheduler  *gocron.Scheduler
+	jobs       []Job
+	scheduled  map[string]*gocron.Job
+	db         database.DB
+	jobRunRepo repositories.JobRunRepository
+	locker     *jobLocker
+	log        logger.Logger
+	started    bool
+	mu         sync.Mutex
+	ctx        context.Context
+	cancel     context.CancelFunc
 }
 
-func NewSchedulerService() *SchedulerService {
+func NewSchedulerService(db database.DB, repos repositories.Repository) *SchedulerService {
 	// Create scheduler in UTC timezone
 	scheduler := gocron.NewScheduler(time.UTC)
 
@@ -47,22 +69,78 @@ func NewSchedulerService() *SchedulerService {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &SchedulerService{
-		scheduler: scheduler,
-		jobs:      make([]Job, 0),
-		log:       logger.New("scheduler"),
-		started:   false,
-		ctx:       ctx,
-		cancel:    cancel,
+		scheduler:  scheduler,
+		jobs:       make([]Job, 0),
+		scheduled:  make(map[string]*gocron.Job),
+		db:         db,
+		jobRunRepo: repos.JobRun,
+		locker:     newJobLocker(db.Cache.General),
+		log:        logger.New("scheduler"),
+		started:    false,
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 }
 
-func (s *SchedulerService) executeJob(job Job, log logger.Logger) {
-	log.Info("Executing scheduled job", "job", job.Name())
-	if err := job.Execute(s.ctx); err != nil {
+// executeJob runs a job under its distributed lock and records it in the job run history. Replicas
+// that find the lock held skip the run. History writes are detached from the job context so a run
+// cancelled by shutdown or a lost lock is still marked as finished.
+func (s *SchedulerService) executeJob(ctx context.Context, job Job, trigger models.JobRunTrigger, log logger.Logger) {
+	historyCtx := context.WithoutCancel(ctx)
+
+	lease, err := s.locker.Acquire(ctx, job.Name())
+	if err != nil {
+		_ = log.Err("Failed to acquire job lock, skipping run", err, "job", job.Name())
+		return
+	}
+	if lease == nil {
+		log.Info("Job is running on another instance, skipping", "job", job.Name())
+		return
+	}
+	defer lease.Release()
+	ctx = lease.Context()
+
+	// Holding the lock means no other replica is running this job, so any run still marked
+	// running belongs to a replica that died mid-job
+	abandoned, err := s.jobRunRepo.FailAbandoned(historyCtx, s.db.SQLWithContext(historyCtx), job.Name())
+	if err != nil {
+		log.Warn("Failed to close abandoned job runs", "job", job.Name(), "error", err)
+	} else if abandoned > 0 {
+		log.Warn("Closed job runs abandoned by another instance", "job", job.Name(), "count", abandoned)
+	}
+
+	run := &models.JobRun{
+		JobName:   job.Name(),
+		Trigger:   trigger,
+		Status:    models.JobRunStatusRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := s.jobRunRepo.Create(historyCtx, s.db.SQLWithContext(historyCtx), run); err != nil {
+		log.Warn("Failed to record job run start", "job", job.Name(), "error", err)
+		run = nil
+	}
+
+	log.Info("Executing scheduled job", "job", job.Name(), "trigger", trigger)
+	started := time.Now()
+	err = job.Execute(ctx)
+	if err != nil && errors.Is(context.Cause(ctx), ErrJobLockLost) {
+		err = fmt.Errorf("%w: %w", ErrJobLockLost, err)
+	}
+	metrics.ObserveJob(job.Name(), time.Since(started), err)
+	if err != nil {
 		_ = log.Err("Job execution failed", err, "job", job.Name())
 	} else {
 		log.Info("Job execution completed successfully", "job", job.Name())
 	}
+
+	if run == nil {
+		return
+	}
+
+	run.Finish(err)
+	if updateErr := s.jobRunRepo.Update(historyCtx, s.db.SQLWithContext(historyCtx), run); updateErr != nil {
+		log.Warn("Failed to record job run outcome", "job", job.Name(), "error", updateErr)
+	}
 }
 
 // AddJob registers a job with the scheduler
@@ -72,33 +150,33 @@ func (s *SchedulerService) AddJob(job Job) error {
 
 	log := s.log.Function("AddJob")
 
-	var err error
-	switch job.Schedule() {
-	case Daily:
-		_, err = s.scheduler.Every(1).Day().At("02:00").Do(func() {
-			s.executeJob(job, log)
-		})
-	case DailyProcessing:
-		_, err = s.scheduler.Every(1).Day().At("03:00").Do(func() {
-			s.executeJob(job, log)
-		})
-	case Monthly:
-		_, err = s.scheduler.Every(1).MonthLastDay().At("02:00").Do(func() {
-			s.executeJob(job, log)
-		})
-	case Hourly:
-		_, err = s.scheduler.Every(1).Hour().Do(func() {
-			s.executeJob(job, log)
-		})
+	run := func() {
+		s.executeJob(s.ctx, job, models.JobRunTriggerScheduled, log)
+	}
+
+	var (
+		scheduled *gocron.Job
+		err       error
+	)
+	if job.Schedule() == Monthly {
+		scheduled, err = s.scheduler.Every(1).MonthLastDay().At("02:00").Do(run)
+	} else {
+		scheduled, err = s.scheduler.Cron(string(job.Schedule())).Do(run)
 	}
 
 	if err != nil {
-		return log.Err("failed to register job with scheduler", err, "job", job.Name())
+		return log.Err(
+			"failed to register job with scheduler",
+			err,
+			"job", job.Name(),
+			"schedule", job.Schedule(),
+		)
 	}
 
 	// Store job reference for management
 	s.jobs = append(s.jobs, job)
-	log.Info("Job registered successfully", "job", job.Name())
+	s.scheduled[job.Name()] = scheduled
+	log.Info("Job registered successfully", "job", job.Name(), "schedule", job.Schedule())
 
 	return nil
 }
@@ -188,11 +266,9 @@ func (s *SchedulerService) GetNextRunTime() *time.Time {
 
 // TriggerJobByName manually executes a registered job by name
 func (s *SchedulerService) TriggerJobByName(ctx context.Context, jobName string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	log := s.log.Function("TriggerJobByName")
 
+	s.mu.Lock()
 	var targetJob Job
 	for _, job := range s.jobs {
 		if job.Name() == jobName {
@@ -200,19 +276,57 @@ func (s *SchedulerService) TriggerJobByName(ctx context.Context, jobName string)
 			break
 		}
 	}
+	s.mu.Unlock()
 
 	if targetJob == nil {
-		return log.Errorf("job not found: %s", jobName)
+		return log.Err("failed to trigger job", ErrJobNotFound, "job", jobName)
 	}
 
+	// The run itself still takes the lock, this only lets the caller know it would be skipped
+	held, err := s.locker.IsHeld(ctx, jobName)
+	if err != nil {
+		return log.Err("failed to check job lock", err, "job", jobName)
+	}
+	if held {
+		return log.Err("failed to trigger job", ErrJobAlreadyRunning, "job", jobName)
+	}
+
+	// The run outlives the request that triggered it
+	runCtx := context.WithoutCancel(ctx)
 	go func() {
 		log.Info("Manually triggering job", "job", jobName)
-		if err := targetJob.Execute(ctx); err != nil {
-			_ = log.Err("Manual job execution failed", err, "job", jobName)
-		} else {
-			log.Info("Manual job execution completed", "job", jobName)
-		}
+		s.executeJob(runCtx, targetJob, models.JobRunTriggerManual, log)
 	}()
 
 	return nil
 }
+
+// ListJobs returns every registered job with its next scheduled run and most recent run
+func (s *SchedulerService) ListJobs(ctx context.Context) ([]JobInfo, error) {
+	log := s.log.TraceFromContext(ctx).Function("ListJobs")
+
+	s.mu.Lock()
+	jobs := make([]JobInfo, len(s.jobs))
+	names := make([]string, len(s.jobs))
+	for i, job := range s.jobs {
+		jobs[i] = JobInfo{Name: job.Name(), Schedule: job.Schedule()}
+		names[i] = job.Name()
+
+		if scheduled, ok := s.scheduled[job.Name()]; ok && s.started {
+			nextRun := scheduled.NextRun()
+			jobs[i].NextRun = &nextRun
+		}
+	}
+	s.mu.Unlock()
+
+	lastRuns, err := s.jobRunRepo.GetLatestByJobNames(ctx, s.db.SQLWithContext(ctx), names)
+	if err != nil {
+		return nil, log.Err("failed to get latest job runs", err)
+	}
+
+	for i := range jobs {
+		jobs[i].LastRun = lastRuns[jobs[i].Name]
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"waugzee/internal/database"
+	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// recordingJobRuns keeps job run history in memory, sending every finished run on updated
+type recordingJobRuns struct {
+	repositories.JobRunRepository
+	mu      sync.Mutex
+	created []models.JobRun
+	updated chan models.JobRun
+}
+
+func (r *recordingJobRuns) Create(_ context.Context, _ *gorm.DB, run *models.JobRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, *run)
+	return nil
+}
+
+func (r *recordingJobRuns) Update(_ context.Context, _ *gorm.DB, run *models.JobRun) error {
+	r.updated <- *run
+	return nil
+}
+
+func (r *recordingJobRuns) FailAbandoned(context.Context, *gorm.DB, string) (int64, error) {
+	return 0, nil
+}
+
+func (r *recordingJobRuns) createdRuns() []models.JobRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.JobRun(nil), r.created...)
+}
+
+type testJob struct {
+	name    string
+	execute func(ctx context.Context) error
+}
+
+func (j testJob) Name() string                      { return j.name }
+func (j testJob) Schedule() Schedule                { return Daily }
+func (j testJob) Execute(ctx context.Context) error { return j.execute(ctx) }
+
+func newTestScheduler(t *testing.T) (*SchedulerService, *recordingJobRuns) {
+	t.Helper()
+
+	sqlDB, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	require.NoError(t, err)
+
+	_, cache := newTestValkey(t)
+	runs := &recordingJobRuns{updated: make(chan models.JobRun, 1)}
+	scheduler := NewSchedulerService(
+		database.DB{SQL: sqlDB, Cache: database.Cache{General: cache}},
+		repositories.Repository{JobRun: runs},
+	)
+	return scheduler, runs
+}
+
+func waitForFinishedRun(t *testing.T, runs *recordingJobRuns) models.JobRun {
+	t.Helper()
+
+	select {
+	case run := <-runs.updated:
+		return run
+	case <-time.After(5 * time.Second):
+		t.Fatal("job run was not finished")
+		return models.JobRun{}
+	}
+}
+
+func TestExecuteJobRecordsRuns(t *testing.T) {
+	log := logger.New("scheduler").Function("TestExecuteJobRecordsRuns")
+	tests := []struct {
+		name   string
+		err    error
+		status models.JobRunStatus
+	}{
+		{name: "success", status: models.JobRunStatusSucceeded},
+		{name: "failure", err: errors.New("dump unavailable"), status: models.JobRunStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler, runs := newTestScheduler(t)
+			job := testJob{name: "test", execute: func(context.Context) error { return tt.err }}
+
+			scheduler.executeJob(context.Background(), job, models.JobRunTriggerScheduled, log)
+
+			created := runs.createdRuns()
+			require.Len(t, created, 1)
+			assert.Equal(t, "test", created[0].JobName)
+			assert.Equal(t, models.JobRunTriggerScheduled, created[0].Trigger)
+			assert.Equal(t, models.JobRunStatusRunning, created[0].Status)
+
+			finished := waitForFinishedRun(t, runs)
+			assert.Equal(t, tt.status, finished.Status)
+			require.NotNil(t, finished.FinishedAt)
+			require.NotNil(t, finished.DurationMs)
+			if tt.err != nil {
+				require.NotNil(t, finished.Error)
+				assert.Equal(t, tt.err.Error(), *finished.Error)
+			} else {
+				assert.Nil(t, finished.Error)
+			}
+		})
+	}
+}
+
+func TestExecuteJobSkipsWhenLockIsHeld(t *testing.T) {
+	log := logger.New("scheduler").Function("TestExecuteJobSkipsWhenLockIsHeld")
+	scheduler, runs := newTestScheduler(t)
+
+	lease, err := newJobLocker(scheduler.locker.cache).Acquire(context.Background(), "test")
+	require.NoError(t, err)
+	defer lease.Release()
+
+	executed := false
+	job := testJob{name: "test", execute: func(context.Context) error {
+		executed = true
+		return nil
+	}}
+	scheduler.executeJob(context.Background(), job, models.JobRunTriggerScheduled, log)
+
+	assert.False(t, executed)
+	assert.Empty(t, runs.createdRuns())
+}
+
+func TestTriggeredJobOutlivesRequest(t *testing.T) {
+	scheduler, runs := newTestScheduler(t)
+
+	running, proceed := make(chan struct{}), make(chan struct{})
+	require.NoError(t, scheduler.AddJob(testJob{name: "test", execute: func(ctx context.Context) error {
+		close(running)
+		<-proceed
+		return ctx.Err()
+	}}))
+
+	requestCtx, cancelRequest := context.WithCancel(context.Background())
+	require.NoError(t, scheduler.TriggerJobByName(requestCtx, "test"))
+
+	<-running
+	// The request finishes while the job is still running
+	cancelRequest()
+	close(proceed)
+
+	finished := waitForFinishedRun(t, runs)
+	assert.Equal(t, models.JobRunTriggerManual, finished.Trigger)
+	assert.Equal(t, models.JobRunStatusSucceeded, finished.Status)
+}
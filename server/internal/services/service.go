@@ -8,7 +8,9 @@ import (
 )
 
 type Service struct {
-	Zitadel              *ZitadelService
+	Auth                 AuthProvider
+	ApiToken             *ApiTokenService
+	Authorization        *AuthorizationService
 	Discogs              *DiscogsService
 	Transaction          *TransactionService
 	Scheduler            *SchedulerService
@@ -16,40 +18,79 @@ type Service struct {
 	FolderDataExtraction *FolderDataExtractionService
 	DiscogsRateLimiter   *DiscogsRateLimiterService
 	Download             *DownloadService
+	DumpStorage          DumpStorage
+	DumpImport           *DumpImportService
+	Image                *ImageService
 	DiscogsXMLParser     *DiscogsXMLParserService
+	ProcessingWatchdog   *ProcessingWatchdogService
 	ReleaseSync          *ReleaseSyncService
+	ReleaseFreshness     *ReleaseFreshnessService
 	FileCleanup          *FileCleanupService
 	KleioImport          *KleioImportService       // TODO: REMOVE_AFTER_MIGRATION
 	CacheInvalidation    *CacheInvalidationService
 	Logging              *LoggingService
+	Webhook              *WebhookService
+	StylusWear           *StylusWearService
+	Health               *HealthService
 }
 
 func New(db database.DB, config config.Config, eventBus *events.EventBus) (Service, error) {
 	transactionService := NewTransactionService(db)
 	repos := repositories.New(db)
 
-	zitadelService, err := NewZitadelService(config)
+	authProvider, err := NewAuthProvider(config)
 	if err != nil {
 		return Service{}, err
 	}
 
+	dumpStorage, err := NewDumpStorage(config)
+	if err != nil {
+		return Service{}, err
+	}
+
+	imageStorage, err := NewImageStorage(config)
+	if err != nil {
+		return Service{}, err
+	}
+
+	authorizationService, err := NewAuthorizationService(repos, db, config)
+	if err != nil {
+		return Service{}, err
+	}
+
+	apiTokenService := NewApiTokenService(repos, db)
 	discogsService := NewDiscogsService()
-	schedulerService := NewSchedulerService()
+	schedulerService := NewSchedulerService(db, repos)
 	discogsRateLimiterService := NewDiscogsRateLimiterService(db.Cache.ClientAPI)
+	imageService := NewImageService(repos, db, imageStorage)
 	orchestrationService := NewOrchestrationService(
 		eventBus,
 		repos,
 		db,
 		transactionService,
 		discogsRateLimiterService,
+		imageService,
 	)
 	folderDataExtractionService := NewFolderDataExtractionService(repos)
-	downloadService := NewDownloadService(config, eventBus)
-	discogsXMLParserService := NewDiscogsXMLParserService(repos, db, eventBus)
+	downloadService := NewDownloadService(config, eventBus, dumpStorage)
+	dumpImportService := NewDumpImportService(repos, downloadService, dumpStorage)
+	discogsXMLParserService := NewDiscogsXMLParserService(repos, db, eventBus, dumpStorage, config)
+	processingWatchdogService := NewProcessingWatchdogService(repos.DiscogsDataProcessing, config)
 	releaseSyncService := NewReleaseSyncService(eventBus, repos, db, discogsRateLimiterService)
-	fileCleanupService := NewFileCleanupService(config)
+	releaseFreshnessService := NewReleaseFreshnessService(
+		repos,
+		db,
+		transactionService,
+		releaseSyncService,
+		folderDataExtractionService,
+		config,
+	)
+	fileCleanupService := NewFileCleanupService(config, dumpStorage)
 	cacheInvalidationService := NewCacheInvalidationService(eventBus)
 	loggingService := NewLoggingService(config.VictoriaLogsURL)
+	webhookService := NewWebhookService(repos, db, config)
+	stylusWearService := NewStylusWearService(repos, db, eventBus)
+	healthService := NewHealthService(db, eventBus, schedulerService, authProvider, config)
 	// TODO: REMOVE_AFTER_MIGRATION - One-time Kleio data import service
 	kleioImportService := NewKleioImportService(
 		db,
@@ -59,8 +100,23 @@ func New(db database.DB, config config.Config, eventBus *events.EventBus) (Servi
 		transactionService,
 	)
 
+	if err := eventBus.Subscribe(events.SYNC, imageService.HandleCollectionSynced); err != nil {
+		return Service{}, err
+	}
+	if err := eventBus.Subscribe(events.SYNC, webhookService.HandleEvent); err != nil {
+		return Service{}, err
+	}
+	if err := eventBus.Subscribe(events.ACTIVITY, webhookService.HandleEvent); err != nil {
+		return Service{}, err
+	}
+	if err := eventBus.Subscribe(events.ACTIVITY, stylusWearService.HandlePlayLogged); err != nil {
+		return Service{}, err
+	}
+
 	return Service{
-		Zitadel:              zitadelService,
+		Auth:                 authProvider,
+		ApiToken:             apiTokenService,
+		Authorization:        authorizationService,
 		Discogs:              discogsService,
 		Transaction:          transactionService,
 		Scheduler:            schedulerService,
@@ -68,11 +124,19 @@ func New(db database.DB, config config.Config, eventBus *events.EventBus) (Servi
 		FolderDataExtraction: folderDataExtractionService,
 		DiscogsRateLimiter:   discogsRateLimiterService,
 		Download:             downloadService,
+		DumpStorage:          dumpStorage,
+		DumpImport:           dumpImportService,
+		Image:                imageService,
 		DiscogsXMLParser:     discogsXMLParserService,
+		ProcessingWatchdog:   processingWatchdogService,
 		ReleaseSync:          releaseSyncService,
+		ReleaseFreshness:     releaseFreshnessService,
 		FileCleanup:          fileCleanupService,
 		CacheInvalidation:    cacheInvalidationService,
 		Logging:              loggingService,
+		Webhook:              webhookService,
+		StylusWear:           stylusWearService,
+		Health:               healthService,
 		KleioImport:          kleioImportService, // TODO: REMOVE_AFTER_MIGRATION
 	}, nil
 }
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"waugzee/internal/database"
+	"waugzee/internal/events"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/google/uuid"
+	"github.com/valkey-io/valkey-go"
+)
+
+const (
+	STYLUS_WEAR_NOTIFIED_PREFIX = "stylus_wear_notified:%s" // %s = user stylus ID
+
+	// StylusWearWarningRatio is the share of a stylus's recommended hours after which its user is
+	// told to replace it
+	StylusWearWarningRatio = 0.9
+	// DefaultStylusReplaceHours is used for styluses without recommended replacement hours
+	DefaultStylusReplaceHours = 1000
+)
+
+// StylusWearService tells users their stylus is nearing its recommended replacement hours by
+// publishing stylus_replacement_due once per stylus
+type StylusWearService struct {
+	repos    repositories.Repository
+	db       database.DB
+	eventBus *events.EventBus
+	log      logger.Logger
+}
+
+func NewStylusWearService(
+	repos repositories.Repository,
+	db database.DB,
+	eventBus *events.EventBus,
+) *StylusWearService {
+	return &StylusWearService{
+		repos:    repos,
+		db:       db,
+		eventBus: eventBus,
+		log:      logger.New("stylusWearService"),
+	}
+}
+
+// HandlePlayLogged checks the wear of the stylus a play was logged with. It is subscribed to the
+// durable ACTIVITY channel, a Valkey key per stylus keeps redelivered plays from notifying twice.
+func (s *StylusWearService) HandlePlayLogged(event events.ChannelEvent) error {
+	if event.Event != events.PLAY_LOGGED {
+		return nil
+	}
+
+	log := s.log.Function("HandlePlayLogged")
+
+	played, err := events.DecodePayload[events.PlayLogged](event.Message)
+	if err != nil {
+		return log.Err("invalid play logged event", err, "userID", event.Message.UserID)
+	}
+	if played.UserStylusID == nil {
+		return nil
+	}
+
+	userID, err := uuid.Parse(event.Message.UserID)
+	if err != nil {
+		return log.Err("invalid play logged user", err, "userID", event.Message.UserID)
+	}
+	userStylusID, err := uuid.Parse(*played.UserStylusID)
+	if err != nil {
+		return log.Err("invalid play logged stylus", err, "userStylusID", *played.UserStylusID)
+	}
+
+	return s.checkWear(context.Background(), userID, userStylusID)
+}
+
+func (s *StylusWearService) checkWear(ctx context.Context, userID uuid.UUID, userStylusID uuid.UUID) error {
+	log := s.log.Function("checkWear").With("userID", userID, "userStylusID", userStylusID)
+
+	styluses, err := s.repos.Stylus.GetUserStyluses(ctx, s.db.SQLWithContext(ctx), userID)
+	if err != nil {
+		return log.Err("failed to get user styluses", err)
+	}
+
+	var userStylus *UserStylus
+	for _, stylus := range styluses {
+		if stylus.ID == userStylusID {
+			userStylus = stylus
+			break
+		}
+	}
+	if userStylus == nil || !userStylus.IsActive {
+		return nil
+	}
+
+	usage, err := s.repos.Stylus.GetStylusUsageHours(ctx, s.db.SQLWithContext(ctx), userID)
+	if err != nil {
+		return log.Err("failed to get stylus usage hours", err)
+	}
+
+	hoursUsed := usage[userStylusID]
+	replaceHours := stylusReplaceHours(userStylus)
+	if !stylusNeedsReplacement(hoursUsed, replaceHours) {
+		return nil
+	}
+
+	cache := s.db.Cache.General
+	key := fmt.Sprintf(STYLUS_WEAR_NOTIFIED_PREFIX, userStylusID)
+	err = cache.Do(ctx, cache.B().Set().Key(key).Value(userID.String()).Nx().Build()).Error()
+	if valkey.IsValkeyNil(err) {
+		return nil
+	}
+	if err != nil {
+		return log.Err("failed to record stylus wear notification", err)
+	}
+
+	err = s.eventBus.PublishActivity(userID.String(), events.StylusReplacementDue{
+		UserStylusID:            userStylusID.String(),
+		Stylus:                  stylusName(userStylus),
+		HoursUsed:               hoursUsed,
+		RecommendedReplaceHours: replaceHours,
+	})
+	if err != nil {
+		// Forget the notification so the redelivered play tries again
+		if delErr := cache.Do(ctx, cache.B().Del().Key(key).Build()).Error(); delErr != nil {
+			log.Warn("Failed to clear stylus wear notification", "error", delErr)
+		}
+		return log.Err("failed to publish stylus replacement due", err)
+	}
+
+	log.Info("Stylus nearing replacement", "hoursUsed", hoursUsed, "replaceHours", replaceHours)
+	return nil
+}
+
+func stylusReplaceHours(userStylus *UserStylus) int {
+	if userStylus.Stylus == nil || userStylus.Stylus.RecommendedReplaceHours == nil ||
+		*userStylus.Stylus.RecommendedReplaceHours <= 0 {
+		return DefaultStylusReplaceHours
+	}
+	return *userStylus.Stylus.RecommendedReplaceHours
+}
+
+func stylusNeedsReplacement(hoursUsed float64, replaceHours int) bool {
+	return hoursUsed >= float64(replaceHours)*StylusWearWarningRatio
+}
+
+func stylusName(userStylus *UserStylus) string {
+	if userStylus.Stylus == nil {
+		return ""
+	}
+	return strings.TrimSpace(userStylus.Stylus.Brand + " " + userStylus.Stylus.Model)
+}
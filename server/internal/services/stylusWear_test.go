@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+	. "waugzee/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStylusReplaceHours(t *testing.T) {
+	hours := 500
+	zero := 0
+
+	assert.Equal(t, 500, stylusReplaceHours(&UserStylus{Stylus: &Stylus{RecommendedReplaceHours: &hours}}))
+	assert.Equal(t, DefaultStylusReplaceHours, stylusReplaceHours(&UserStylus{Stylus: &Stylus{}}))
+	assert.Equal(t, DefaultStylusReplaceHours,
+		stylusReplaceHours(&UserStylus{Stylus: &Stylus{RecommendedReplaceHours: &zero}}))
+	assert.Equal(t, DefaultStylusReplaceHours, stylusReplaceHours(&UserStylus{}))
+}
+
+func TestStylusNeedsReplacement(t *testing.T) {
+	assert.False(t, stylusNeedsReplacement(0, 1000))
+	assert.False(t, stylusNeedsReplacement(899.9, 1000))
+	assert.True(t, stylusNeedsReplacement(900, 1000))
+	assert.True(t, stylusNeedsReplacement(1200, 1000))
+}
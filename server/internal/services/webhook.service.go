@@ -0,0 +1,665 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"waugzee/config"
+	"waugzee/internal/database"
+	"waugzee/internal/events"
+	logger "github.com/Bparsons0904/goLogger"
+	. "waugzee/internal/models"
+	"waugzee/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// WebhookSecretPrefix marks webhook signing secrets, like ApiTokenPrefix for tokens
+	WebhookSecretPrefix = "whsec_"
+
+	WEBHOOK_SIGNATURE_HEADER = "X-Waugzee-Signature"
+	WEBHOOK_EVENT_HEADER     = "X-Waugzee-Event"
+	WEBHOOK_DELIVERY_HEADER  = "X-Waugzee-Delivery"
+
+	MaxWebhooksPerUser          = 10
+	MaxWebhookURLLength         = 2048
+	MaxWebhookDescriptionLength = 200
+	// WebhookMaxAttempts is how many times a delivery is attempted before it is failed, the retries
+	// span about an hour
+	WebhookMaxAttempts = 8
+	// WebhookDeliveriesLimit is how many recent deliveries of a webhook are listed
+	WebhookDeliveriesLimit = 100
+
+	webhookSecretBytes = 32
+	webhookTimeout     = 10 * time.Second
+	webhookDialTimeout = 5 * time.Second
+	// webhookLease is how long a claimed delivery is hidden from other replicas, it outlives a
+	// request so a delivery is only sent twice when its replica dies mid-attempt
+	webhookLease             = 2 * time.Minute
+	webhookPollInterval      = 5 * time.Second
+	webhookClaimBatch        = 20
+	webhookRetryBaseDelay    = 30 * time.Second
+	webhookRetryMaxDelay     = time.Hour
+	webhookResponseBodyLimit = 1024
+)
+
+var (
+	ErrWebhookNotFound          = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound  = errors.New("webhook delivery not found")
+	ErrWebhookURLRequired       = errors.New("webhook url is required")
+	ErrWebhookURLInvalid        = errors.New("webhook url is invalid")
+	ErrWebhookURLNotHTTPS       = errors.New("webhook url must use https")
+	ErrWebhookAddressForbidden  = errors.New("webhook url must not point at a private network")
+	ErrWebhookDescriptionLength = errors.New("webhook description is too long")
+	ErrWebhookEventRequired     = errors.New("at least one event is required")
+	ErrWebhookInvalidEvent      = errors.New("invalid webhook event")
+	ErrWebhookLimitReached      = errors.New("webhook limit reached")
+)
+
+// webhookEvents maps the bus events webhooks are sent for to the name endpoints receive
+var webhookEvents = map[string]WebhookEvent{
+	events.PLAY_LOGGED:            WebhookEventPlayLogged,
+	events.CLEANING_LOGGED:        WebhookEventCleaningLogged,
+	events.COLLECTION_SYNCED:      WebhookEventSyncCompleted,
+	events.STYLUS_REPLACEMENT_DUE: WebhookEventStylusReplacementDue,
+	events.RECOMMENDATION_CREATED: WebhookEventRecommendationCreated,
+}
+
+// WebhookBody is the JSON body of a delivery. ID is the event's, it stays the same across retries
+// and replays so receivers can drop duplicates.
+type WebhookBody struct {
+	ID        string         `json:"id"`
+	Event     WebhookEvent   `json:"event"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Data      map[string]any `json:"data"`
+}
+
+// WebhookService manages users' webhooks and delivers their events. Events are stored as
+// deliveries when they are handled from the bus, and a dispatcher on every replica sends due
+// deliveries, retrying failures with exponential backoff.
+type WebhookService struct {
+	repos        repositories.Repository
+	db           database.DB
+	client       *http.Client
+	allowPrivate bool
+	log          logger.Logger
+
+	wakeup chan struct{}
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewWebhookService(repos repositories.Repository, db database.DB, config config.Config) *WebhookService {
+	return &WebhookService{
+		repos:        repos,
+		db:           db,
+		client:       newWebhookClient(config.WebhookAllowPrivateNetworks),
+		allowPrivate: config.WebhookAllowPrivateNetworks,
+		log:          logger.New("webhookService"),
+		wakeup:       make(chan struct{}, 1),
+	}
+}
+
+// Create registers a webhook for the user and returns it with its signing secret, which is only
+// returned here
+func (s *WebhookService) Create(
+	ctx context.Context,
+	userID uuid.UUID,
+	rawURL string,
+	description string,
+	webhookEvents []WebhookEvent,
+) (*Webhook, string, error) {
+	log := s.log.Function("Create").With("userID", userID)
+
+	endpoint, err := validateWebhookURL(rawURL, s.allowPrivate)
+	if err != nil {
+		return nil, "", log.Err("invalid webhook url", err)
+	}
+	description = strings.TrimSpace(description)
+	if len(description) > MaxWebhookDescriptionLength {
+		return nil, "", log.Err("invalid webhook description", ErrWebhookDescriptionLength)
+	}
+	if err := validateWebhookEvents(webhookEvents); err != nil {
+		return nil, "", log.Err("invalid webhook events", err)
+	}
+
+	count, err := s.repos.Webhook.CountByUser(ctx, s.db.SQLWithContext(ctx), userID)
+	if err != nil {
+		return nil, "", log.Err("failed to count webhooks", err)
+	}
+	if count >= MaxWebhooksPerUser {
+		return nil, "", log.Err("too many webhooks", ErrWebhookLimitReached, "count", count)
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, "", log.Err("failed to generate webhook secret", err)
+	}
+
+	webhook := &Webhook{
+		UserID:      userID,
+		URL:         endpoint,
+		Description: description,
+		Events:      uniqueWebhookEvents(webhookEvents),
+		Secret:      secret,
+		IsActive:    true,
+	}
+	if err := s.repos.Webhook.Create(ctx, s.db.SQLWithContext(ctx), webhook); err != nil {
+		return nil, "", log.Err("failed to create webhook", err)
+	}
+
+	log.Info("Created webhook", "webhookID", webhook.ID, "events", webhook.Events)
+	return webhook, secret, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, userID uuid.UUID) ([]*Webhook, error) {
+	log := s.log.Function("List")
+
+	webhooks, err := s.repos.Webhook.GetByUser(ctx, s.db.SQLWithContext(ctx), userID)
+	if err != nil {
+		return nil, log.Err("failed to list webhooks", err, "userID", userID)
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) error {
+	log := s.log.Function("Delete")
+
+	deleted, err := s.repos.Webhook.Delete(ctx, s.db.SQLWithContext(ctx), userID, webhookID)
+	if err != nil {
+		return log.Err("failed to delete webhook", err, "userID", userID, "webhookID", webhookID)
+	}
+	if !deleted {
+		return log.Err("webhook not found", ErrWebhookNotFound, "userID", userID, "webhookID", webhookID)
+	}
+
+	log.Info("Deleted webhook", "userID", userID, "webhookID", webhookID)
+	return nil
+}
+
+// Deliveries returns the most recent deliveries of the user's webhook, newest first
+func (s *WebhookService) Deliveries(
+	ctx context.Context,
+	userID uuid.UUID,
+	webhookID uuid.UUID,
+) ([]*WebhookDelivery, error) {
+	log := s.log.Function("Deliveries").With("userID", userID, "webhookID", webhookID)
+
+	if _, err := s.getWebhook(ctx, userID, webhookID); err != nil {
+		return nil, log.Err("failed to get webhook", err)
+	}
+
+	deliveries, err := s.repos.Webhook.GetDeliveries(
+		ctx,
+		s.db.SQLWithContext(ctx),
+		webhookID,
+		WebhookDeliveriesLimit,
+	)
+	if err != nil {
+		return nil, log.Err("failed to list webhook deliveries", err)
+	}
+
+	return deliveries, nil
+}
+
+// Replay sends a delivery's body again as a new delivery, whatever the outcome of the original
+func (s *WebhookService) Replay(
+	ctx context.Context,
+	userID uuid.UUID,
+	webhookID uuid.UUID,
+	deliveryID uuid.UUID,
+) (*WebhookDelivery, error) {
+	log := s.log.Function("Replay").With("userID", userID, "webhookID", webhookID, "deliveryID", deliveryID)
+
+	if _, err := s.getWebhook(ctx, userID, webhookID); err != nil {
+		return nil, log.Err("failed to get webhook", err)
+	}
+
+	original, err := s.repos.Webhook.GetDelivery(ctx, s.db.SQLWithContext(ctx), webhookID, deliveryID)
+	if err != nil {
+		return nil, log.Err("failed to get webhook delivery", err)
+	}
+	if original == nil {
+		return nil, log.Err("webhook delivery not found", ErrWebhookDeliveryNotFound)
+	}
+
+	now := time.Now().UTC()
+	replay := &WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		UserID:        original.UserID,
+		Event:         original.Event,
+		EventID:       original.EventID,
+		Payload:       original.Payload,
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		ReplayOf:      &original.ID,
+	}
+	if err := s.repos.Webhook.CreateDelivery(ctx, s.db.SQLWithContext(ctx), replay); err != nil {
+		return nil, log.Err("failed to create replay delivery", err)
+	}
+
+	s.wake()
+	log.Info("Replaying webhook delivery", "replayID", replay.ID)
+	return replay, nil
+}
+
+func (s *WebhookService) getWebhook(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) (*Webhook, error) {
+	webhook, err := s.repos.Webhook.GetByID(ctx, s.db.SQLWithContext(ctx), userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// HandleEvent stores a delivery of the event for each of the user's webhooks subscribed to it. It
+// is subscribed to the durable ACTIVITY and SYNC channels, a redelivered event is stored once per
+// webhook.
+func (s *WebhookService) HandleEvent(event events.ChannelEvent) error {
+	webhookEvent, ok := webhookEvents[event.Message.Event]
+	if !ok {
+		return nil
+	}
+
+	log := s.log.Function("HandleEvent").With("event", event.Message.Event, "eventID", event.Message.ID)
+	ctx := context.Background()
+
+	userID, err := uuid.Parse(event.Message.UserID)
+	if err != nil {
+		return log.Err("event has no valid user", err, "userID", event.Message.UserID)
+	}
+
+	webhooks, err := s.repos.Webhook.GetSubscribed(ctx, s.db.SQLWithContext(ctx), userID, webhookEvent)
+	if err != nil {
+		return log.Err("failed to get subscribed webhooks", err, "userID", userID)
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(WebhookBody{
+		ID:        event.Message.ID,
+		Event:     webhookEvent,
+		CreatedAt: event.Message.Timestamp.UTC(),
+		Data:      event.Message.Payload,
+	})
+	if err != nil {
+		return log.Err("failed to encode webhook body", err)
+	}
+
+	now := time.Now().UTC()
+	deliveries := make([]*WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, &WebhookDelivery{
+			WebhookID:     webhook.ID,
+			UserID:        userID,
+			Event:         webhookEvent,
+			EventID:       event.Message.ID,
+			Payload:       body,
+			Status:        WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+
+	created, err := s.repos.Webhook.CreateDeliveries(ctx, s.db.SQLWithContext(ctx), deliveries)
+	if err != nil {
+		return log.Err("failed to store webhook deliveries", err, "userID", userID)
+	}
+
+	if created > 0 {
+		s.wake()
+	}
+	return nil
+}
+
+// Start runs the dispatcher until Stop is called
+func (s *WebhookService) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.dispatch(ctx, s.done)
+
+	s.log.Function("Start").Info("Webhook dispatcher started")
+}
+
+// Stop stops the dispatcher and waits for attempts in flight, which are retried if cut short
+func (s *WebhookService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+
+	s.log.Function("Stop").Info("Webhook dispatcher stopped")
+}
+
+// wake has the dispatcher look for due deliveries now rather than at its next poll
+func (s *WebhookService) wake() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (s *WebhookService) dispatch(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wakeup:
+		}
+	}
+}
+
+// deliverDue attempts due deliveries a batch at a time until none are left
+func (s *WebhookService) deliverDue(ctx context.Context) {
+	log := s.log.Function("deliverDue")
+
+	for ctx.Err() == nil {
+		now := time.Now().UTC()
+		deliveries, err := s.repos.Webhook.ClaimDueDeliveries(
+			ctx,
+			s.db.SQLWithContext(ctx),
+			now,
+			now.Add(webhookLease),
+			webhookClaimBatch,
+		)
+		if err != nil {
+			log.Warn("Failed to claim webhook deliveries", "error", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Go(func() { s.attempt(ctx, delivery) })
+		}
+		wg.Wait()
+
+		if len(deliveries) < webhookClaimBatch {
+			return
+		}
+	}
+}
+
+func (s *WebhookService) attempt(ctx context.Context, delivery *WebhookDelivery) {
+	log := s.log.Function("attempt").With("deliveryID", delivery.ID, "webhookID", delivery.WebhookID)
+
+	var result webhookAttempt
+	if delivery.Webhook == nil || !delivery.Webhook.IsActive {
+		message := "webhook was deleted or disabled"
+		delivery.Status = WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.Error = &message
+	} else {
+		result = s.send(ctx, delivery.Webhook, delivery)
+		recordWebhookAttempt(delivery, result, time.Now().UTC())
+	}
+
+	// The outcome is recorded even when shutdown cut the attempt short, so it is retried
+	updateCtx := context.WithoutCancel(ctx)
+	if err := s.repos.Webhook.UpdateDelivery(updateCtx, s.db.SQLWithContext(updateCtx), delivery); err != nil {
+		log.Warn("Failed to record webhook delivery attempt", "error", err)
+		return
+	}
+
+	if delivery.Status == WebhookDeliveryFailed {
+		log.Warn("Webhook delivery failed", "attempts", delivery.Attempts, "error", *delivery.Error,
+			"responseCode", result.ResponseCode)
+	}
+}
+
+// webhookAttempt is the outcome of sending a delivery once
+type webhookAttempt struct {
+	ResponseCode int
+	ResponseBody string
+	Duration     time.Duration
+	Err          error
+}
+
+func (a webhookAttempt) succeeded() bool {
+	return a.Err == nil && a.ResponseCode >= 200 && a.ResponseCode < 300
+}
+
+func (s *WebhookService) send(ctx context.Context, webhook *Webhook, delivery *WebhookDelivery) webhookAttempt {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return webhookAttempt{Err: err}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Waugzee-Webhooks/1.0")
+	request.Header.Set(WEBHOOK_EVENT_HEADER, string(delivery.Event))
+	request.Header.Set(WEBHOOK_DELIVERY_HEADER, delivery.ID.String())
+	request.Header.Set(WEBHOOK_SIGNATURE_HEADER, SignWebhookPayload(webhook.Secret, time.Now(), delivery.Payload))
+
+	started := time.Now()
+	response, err := s.client.Do(request)
+	if err != nil {
+		return webhookAttempt{Duration: time.Since(started), Err: err}
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, webhookResponseBodyLimit))
+	return webhookAttempt{
+		ResponseCode: response.StatusCode,
+		ResponseBody: string(body),
+		Duration:     time.Since(started),
+	}
+}
+
+// recordWebhookAttempt applies the outcome of an attempt to the delivery, scheduling a retry
+// while attempts are left
+func recordWebhookAttempt(delivery *WebhookDelivery, result webhookAttempt, now time.Time) {
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nil
+	delivery.ResponseCode = nil
+	delivery.ResponseBody = nil
+	delivery.Error = nil
+
+	durationMs := result.Duration.Milliseconds()
+	delivery.DurationMs = &durationMs
+	if result.ResponseCode != 0 {
+		code := result.ResponseCode
+		body := result.ResponseBody
+		delivery.ResponseCode = &code
+		delivery.ResponseBody = &body
+	}
+
+	if result.succeeded() {
+		delivery.Status = WebhookDeliverySucceeded
+		return
+	}
+
+	message := fmt.Sprintf("endpoint responded with status %d", result.ResponseCode)
+	if result.Err != nil {
+		message = result.Err.Error()
+	}
+	delivery.Error = &message
+
+	if delivery.Attempts >= WebhookMaxAttempts {
+		delivery.Status = WebhookDeliveryFailed
+		return
+	}
+
+	next := now.Add(webhookRetryDelay(delivery.Attempts))
+	delivery.Status = WebhookDeliveryPending
+	delivery.NextAttemptAt = &next
+}
+
+// webhookRetryDelay is how long a delivery waits after its attempts-th failed attempt, doubling
+// from the base delay up to the max delay
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempts && delay < webhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMaxDelay)
+}
+
+// SignWebhookPayload returns the signature header of a body sent at the time, "t=<unix>,v1=<hex>".
+// Receivers compute the HMAC-SHA256 of "<t>.<body>" keyed with the webhook secret, compare it to
+// v1, and reject old timestamps to stop replayed requests.
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateWebhookURL(rawURL string, allowPrivate bool) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", ErrWebhookURLRequired
+	}
+	if len(rawURL) > MaxWebhookURLLength {
+		return "", ErrWebhookURLInvalid
+	}
+
+	endpoint, err := url.Parse(rawURL)
+	if err != nil || endpoint.Hostname() == "" || endpoint.User != nil {
+		return "", ErrWebhookURLInvalid
+	}
+	if endpoint.Scheme != "https" {
+		return "", ErrWebhookURLNotHTTPS
+	}
+	endpoint.Fragment = ""
+
+	if !allowPrivate {
+		host := strings.ToLower(strings.TrimSuffix(endpoint.Hostname(), "."))
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+			strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+			return "", ErrWebhookAddressForbidden
+		}
+		if addr, err := netip.ParseAddr(host); err == nil && isPrivateWebhookAddress(addr) {
+			return "", ErrWebhookAddressForbidden
+		}
+	}
+
+	return endpoint.String(), nil
+}
+
+func validateWebhookEvents(webhookEvents []WebhookEvent) error {
+	if len(webhookEvents) == 0 {
+		return ErrWebhookEventRequired
+	}
+	for _, event := range webhookEvents {
+		if !IsValidWebhookEvent(event) {
+			return ErrWebhookInvalidEvent
+		}
+	}
+	return nil
+}
+
+func uniqueWebhookEvents(webhookEvents []WebhookEvent) []WebhookEvent {
+	unique := make([]WebhookEvent, 0, len(webhookEvents))
+	seen := make(map[WebhookEvent]bool, len(webhookEvents))
+	for _, event := range webhookEvents {
+		if !seen[event] {
+			seen[event] = true
+			unique = append(unique, event)
+		}
+	}
+	return unique
+}
+
+// cgnatPrefix is the shared address space carriers use, not covered by netip's IsPrivate
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPrivateWebhookAddress reports whether a webhook may not be sent to the address because it is
+// not on the public internet
+func isPrivateWebhookAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() ||
+		cgnatPrefix.Contains(addr)
+}
+
+// newWebhookClient builds the client deliveries are sent with. Unless private networks are
+// allowed, the address is checked when connecting, after DNS resolution, so a public hostname
+// resolving to a private address is refused too. Redirects are not followed.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookDialTimeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || isPrivateWebhookAddress(addrPort.Addr()) {
+				return ErrWebhookAddressForbidden
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookDialTimeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
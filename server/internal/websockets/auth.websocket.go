@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 	"waugzee/internal/events"
+	"waugzee/internal/models"
 
 	"github.com/google/uuid"
 )
@@ -15,6 +16,7 @@ const (
 	AUTH_SUCCESS           = "auth_success"
 	AUTH_FAILURE           = "auth_failure"
 	AUTH_HANDSHAKE_TIMEOUT = 10 * time.Second
+	REPLAY_TIMEOUT         = 10 * time.Second
 )
 
 // startAuthTimeout initiates the authentication timeout for a client
@@ -71,7 +73,7 @@ func (c *Client) handleAuthResponse(message Message) {
 	}
 
 	// Validate ID token (JWT)
-	tokenInfo, err := c.Manager.zitadelService.ValidateIDToken(context.Background(), token)
+	tokenInfo, err := c.Manager.tokenValidator.ValidateIDToken(context.Background(), token)
 	if err != nil {
 		log.Info("WebSocket token validation failed", "clientID", c.ID, "error", err.Error())
 		c.sendAuthFailure("Authentication failed")
@@ -89,25 +91,97 @@ func (c *Client) handleAuthResponse(message Message) {
 		return
 	}
 
-	// Set client as authenticated with the validated user
-	c.Status = STATUS_AUTHENTICATED
 	c.UserID = user.ID
+	c.Authorization = c.Manager.authorizationService.Resolve(user, tokenInfo.Roles)
+
+	// Clients send the last sequence they saw on each stream to have missed messages replayed
+	replays := c.loadReplays(message.Payload)
+
+	replayStatus := make(map[string]any, len(replays))
+	for _, replay := range replays {
+		replayStatus[replay.name] = map[string]any{
+			"count":    len(replay.result.Messages),
+			"complete": replay.result.Complete,
+		}
+	}
+
+	authSuccess := Message{
+		ID:      uuid.New().String(),
+		Service: events.SYSTEM,
+		Event:   AUTH_SUCCESS,
+		UserID:  c.UserID.String(),
+		Payload: map[string]any{
+			"action": "authenticated",
+			"userId": c.UserID.String(),
+			"replay": replayStatus,
+		},
+		Timestamp: time.Now(),
+	}
+
+	queued := []Message{authSuccess}
+	for _, replay := range replays {
+		queued = append(queued, replay.result.Messages...)
+	}
+
+	// writePump writes the replay, so a slow client never blocks this read loop. It is queued before
+	// the client is marked authenticated, which is when live messages start to arrive.
+	c.replay <- queued
+	c.Status = STATUS_AUTHENTICATED
 
 	log.Info("WebSocket client authenticated successfully",
 		"clientID", c.ID,
 		"userID", user.ID,
-		"email", tokenInfo.Email)
+		"email", tokenInfo.Email,
+		"replayed", len(queued)-1)
+}
 
-	authSuccess := Message{
-		ID:        uuid.New().String(),
-		Service:   events.SYSTEM,
-		Event:     AUTH_SUCCESS,
-		UserID:    c.UserID.String(),
-		Payload:   map[string]any{"action": "authenticated", "userId": c.UserID.String()},
-		Timestamp: time.Now(),
+// streamReplay is what a client missed on one stream
+type streamReplay struct {
+	name   string
+	result *events.ReplayResult
+}
+
+// loadReplays reads the messages the client missed after the sequences in its auth response,
+// "lastSequence" for the user's own stream and "lastAdminSequence" for dump pipeline updates.
+// Replayed messages can also arrive live while the client reconnects, clients drop sequences they
+// have already seen.
+func (c *Client) loadReplays(payload map[string]any) []streamReplay {
+	log := c.Manager.log.Function("loadReplays")
+
+	requested := []struct {
+		name   string
+		field  string
+		stream string
+		allow  bool
+	}{
+		{"user", "lastSequence", events.UserStream(c.UserID.String()), true},
+		{"admin", "lastAdminSequence", events.ADMIN_STREAM, c.Authorization.Can(models.PermissionDumpsManage)},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), REPLAY_TIMEOUT)
+	defer cancel()
+
+	var replays []streamReplay
+	for _, request := range requested {
+		lastSequence, _ := payload[request.field].(string)
+		if lastSequence == "" || !request.allow {
+			continue
+		}
+
+		result, err := c.Manager.eventBus.Replay(ctx, request.stream, lastSequence)
+		if err != nil {
+			log.Warn("Failed to replay missed messages",
+				"clientID", c.ID,
+				"stream", request.stream,
+				"lastSequence", lastSequence,
+				"error", err)
+			result = &events.ReplayResult{Complete: false}
+		}
+
+		replays = append(replays, streamReplay{name: request.name, result: result})
 	}
 
-	c.send <- authSuccess
+	return replays
 }
 
 // sendAuthFailure sends authentication failure response and closes connection
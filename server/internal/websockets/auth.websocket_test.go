@@ -0,0 +1,82 @@
+package websockets
+
+import (
+	"context"
+	"testing"
+	"time"
+	"waugzee/config"
+	"waugzee/internal/database"
+	"waugzee/internal/models"
+	"waugzee/internal/repositories"
+	"waugzee/internal/services"
+	"waugzee/internal/types"
+	logger "github.com/Bparsons0904/goLogger"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type acceptingValidator struct{}
+
+func (acceptingValidator) ValidateIDToken(context.Context, string) (*types.TokenInfo, error) {
+	return &types.TokenInfo{UserID: "oidc-user"}, nil
+}
+
+// knownUsers finds every OIDC user, like the repository does for a user who has signed in before
+type knownUsers struct {
+	repositories.UserRepository
+	user *models.User
+}
+
+func (u knownUsers) GetByOIDCUserID(context.Context, *gorm.DB, string) (*models.User, error) {
+	return u.user, nil
+}
+
+func TestHandleAuthResponseDoesNotBlockOnFullSendBuffer(t *testing.T) {
+	authorizationService, err := services.NewAuthorizationService(
+		repositories.Repository{},
+		database.DB{},
+		config.Config{},
+	)
+	require.NoError(t, err)
+
+	user := &models.User{}
+	user.ID = uuid.New()
+	client := &Client{
+		ID:     uuid.New().String(),
+		Status: STATUS_UNAUTHENTICATED,
+		// Nothing drains send, like a client whose writePump has exited
+		send:   make(chan Message),
+		replay: make(chan []Message, 1),
+		topics: make(map[string]bool),
+		Manager: &Manager{
+			log:                  logger.New("websockets"),
+			tokenValidator:       acceptingValidator{},
+			authorizationService: authorizationService,
+			userRepo:             knownUsers{user: user},
+		},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.handleAuthResponse(Message{Payload: map[string]any{"token": "id-token"}})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handleAuthResponse blocked the read loop")
+	}
+
+	assert.Equal(t, STATUS_AUTHENTICATED, client.Status)
+	select {
+	case queued := <-client.replay:
+		require.NotEmpty(t, queued)
+		assert.Equal(t, AUTH_SUCCESS, queued[0].Event)
+	default:
+		t.Fatal("auth success was not queued for writePump")
+	}
+}
@@ -45,6 +45,29 @@ func (h *Hub) run(m *Manager) {
 	}
 }
 
+// statusNames labels the client statuses in metrics
+var statusNames = map[int]string{
+	STATUS_UNAUTHENTICATED: "unauthenticated",
+	STATUS_PENDING:         "pending",
+	STATUS_AUTHENTICATED:   "authenticated",
+	STATUS_CLOSED:          "closed",
+}
+
+// ConnectionCounts returns the number of connected clients by status
+func (m *Manager) ConnectionCounts() map[string]int {
+	m.hub.mutex.RLock()
+	defer m.hub.mutex.RUnlock()
+
+	counts := make(map[string]int, len(statusNames))
+	for _, name := range statusNames {
+		counts[name] = 0
+	}
+	for _, client := range m.hub.clients {
+		counts[statusNames[client.Status]]++
+	}
+	return counts
+}
+
 func (m *Manager) unregisterClient(client *Client) {
 	log := m.log.Function("unregisterClient")
 	log.Info(
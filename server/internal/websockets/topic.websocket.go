@@ -0,0 +1,146 @@
+package websockets
+
+import (
+	"errors"
+	"time"
+	"waugzee/internal/events"
+	"waugzee/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Topic subscription constants
+const (
+	SUBSCRIBE             = "subscribe"
+	UNSUBSCRIBE           = "unsubscribe"
+	SUBSCRIBED            = "subscribed"
+	UNSUBSCRIBED          = "unsubscribed"
+	SUBSCRIPTION_FAILURE  = "subscription_failure"
+	MAX_TOPICS_PER_CLIENT = 32
+)
+
+var (
+	ErrInvalidTopic   = errors.New("invalid topic")
+	ErrTopicForbidden = errors.New("not allowed to subscribe to topic")
+	ErrTooManyTopics  = errors.New("too many topic subscriptions")
+)
+
+// authorizeTopic checks the client may follow the topic. A user follows their own collection sync,
+// and system managers any user's. Dump topics carry the admin progress updates, error messages
+// included, so they need dumps:manage like the admin stream.
+func (c *Client) authorizeTopic(topic string) error {
+	kind, id, ok := events.ParseTopic(topic)
+	if !ok {
+		return ErrInvalidTopic
+	}
+
+	switch kind {
+	case events.TOPIC_SYNC:
+		if id == c.UserID.String() || c.Authorization.Can(models.PermissionSystemManage) {
+			return nil
+		}
+	case events.TOPIC_DUMP:
+		if c.Authorization.Can(models.PermissionDumpsManage) {
+			return nil
+		}
+	}
+
+	return ErrTopicForbidden
+}
+
+func (c *Client) subscribe(topic string) error {
+	if err := c.authorizeTopic(topic); err != nil {
+		return err
+	}
+
+	c.topicsMutex.Lock()
+	defer c.topicsMutex.Unlock()
+
+	if c.topics[topic] {
+		return nil
+	}
+	if len(c.topics) >= MAX_TOPICS_PER_CLIENT {
+		return ErrTooManyTopics
+	}
+	c.topics[topic] = true
+	return nil
+}
+
+func (c *Client) unsubscribe(topic string) {
+	c.topicsMutex.Lock()
+	defer c.topicsMutex.Unlock()
+
+	delete(c.topics, topic)
+}
+
+// subscribedTo reports whether the client follows the topic, false for messages without one
+func (c *Client) subscribedTo(topic string) bool {
+	if topic == "" {
+		return false
+	}
+
+	c.topicsMutex.RLock()
+	defer c.topicsMutex.RUnlock()
+
+	return c.topics[topic]
+}
+
+// handleSubscription processes subscribe and unsubscribe requests, the payload names the topic
+func (c *Client) handleSubscription(message Message) {
+	log := c.Manager.log.Function("handleSubscription")
+
+	topic, _ := message.Payload["topic"].(string)
+
+	if message.Event == UNSUBSCRIBE {
+		c.unsubscribe(topic)
+		c.sendSubscriptionReply(UNSUBSCRIBED, topic, "")
+		return
+	}
+
+	if err := c.subscribe(topic); err != nil {
+		log.Warn("Topic subscription refused",
+			"clientID", c.ID,
+			"userID", c.UserID,
+			"topic", topic,
+			"reason", err)
+		c.sendSubscriptionReply(SUBSCRIPTION_FAILURE, topic, err.Error())
+		return
+	}
+
+	log.Info("Client subscribed to topic", "clientID", c.ID, "userID", c.UserID, "topic", topic)
+	c.sendSubscriptionReply(SUBSCRIBED, topic, "")
+}
+
+func (c *Client) sendSubscriptionReply(event string, topic string, reason string) {
+	payload := map[string]any{"topic": topic}
+	if reason != "" {
+		payload["reason"] = reason
+	}
+
+	c.Manager.deliver(c, Message{
+		ID:        uuid.New().String(),
+		Service:   events.SYSTEM,
+		Event:     event,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	})
+}
+
+// sendToTopic delivers a message published to a topic alone to the topic's subscribers
+func (m *Manager) sendToTopic(message Message) {
+	log := m.log.Function("sendToTopic")
+
+	if message.Topic == "" {
+		log.Warn("Cannot send to topic: Topic is empty", "messageID", message.ID)
+		return
+	}
+
+	m.hub.mutex.RLock()
+	defer m.hub.mutex.RUnlock()
+
+	for _, client := range m.hub.clients {
+		if client.Status == STATUS_AUTHENTICATED && client.subscribedTo(message.Topic) {
+			m.deliver(client, message)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package websockets
+
+import (
+	"fmt"
+	"testing"
+	"waugzee/internal/events"
+	"waugzee/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTopicClient(roles ...models.Role) *Client {
+	return &Client{
+		ID:            uuid.New().String(),
+		UserID:        uuid.New(),
+		Authorization: models.NewAuthorization(roles),
+		Status:        STATUS_AUTHENTICATED,
+		topics:        make(map[string]bool),
+	}
+}
+
+func TestAuthorizeTopic(t *testing.T) {
+	viewer := newTopicClient(models.RoleViewer)
+	admin := newTopicClient(models.RoleAdmin)
+	otherUser := uuid.New().String()
+
+	assert.NoError(t, viewer.authorizeTopic(events.SyncTopic(viewer.UserID.String())))
+	assert.ErrorIs(t, viewer.authorizeTopic(events.SyncTopic(otherUser)), ErrTopicForbidden)
+	assert.NoError(t, admin.authorizeTopic(events.SyncTopic(otherUser)))
+
+	maintainer := newTopicClient(models.RoleCatalogMaintainer)
+	assert.NoError(t, maintainer.authorizeTopic(events.DumpTopic("2024-01")))
+	assert.NoError(t, admin.authorizeTopic(events.DumpTopic("2024-01")))
+	assert.ErrorIs(t, viewer.authorizeTopic(events.DumpTopic("2024-01")), ErrTopicForbidden)
+	assert.ErrorIs(t, newTopicClient(models.RoleMember).authorizeTopic(events.DumpTopic("2024-01")), ErrTopicForbidden)
+
+	assert.ErrorIs(t, viewer.authorizeTopic("session:abc"), ErrInvalidTopic)
+	assert.ErrorIs(t, viewer.authorizeTopic(""), ErrInvalidTopic)
+}
+
+func TestSubscribe(t *testing.T) {
+	client := newTopicClient(models.RoleAdmin)
+	topic := events.DumpTopic("2024-01")
+
+	assert.False(t, client.subscribedTo(topic))
+	assert.NoError(t, client.subscribe(topic))
+	assert.NoError(t, client.subscribe(topic), "subscribing twice is not an error")
+	assert.True(t, client.subscribedTo(topic))
+	assert.False(t, client.subscribedTo(""), "messages without a topic match no subscription")
+
+	client.unsubscribe(topic)
+	assert.False(t, client.subscribedTo(topic))
+
+	for month := range MAX_TOPICS_PER_CLIENT {
+		assert.NoError(t, client.subscribe(events.DumpTopic(fmt.Sprintf("%d-%02d", 2000+month/12, month%12+1))))
+	}
+	assert.ErrorIs(t, client.subscribe(topic), ErrTooManyTopics)
+}
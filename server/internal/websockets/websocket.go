@@ -3,11 +3,13 @@ package websockets
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 	"waugzee/config"
 	"waugzee/internal/database"
 	"waugzee/internal/events"
 	logger "github.com/Bparsons0904/goLogger"
+	"waugzee/internal/models"
 	"waugzee/internal/repositories"
 	"waugzee/internal/services"
 	"waugzee/internal/types"
@@ -44,18 +46,25 @@ const (
 	SEND_CHANNEL_SIZE = 64
 )
 
-// ZitadelService interface to avoid circular imports
-type ZitadelService interface {
+// TokenValidator validates the ID tokens clients authenticate with
+type TokenValidator interface {
 	ValidateIDToken(ctx context.Context, token string) (*types.TokenInfo, error)
 }
 
 type Client struct {
-	ID         string
-	UserID     uuid.UUID
-	Connection *websocket.Conn
-	Manager    *Manager
-	Status     int
-	send       chan Message
+	ID            string
+	UserID        uuid.UUID
+	Authorization *models.Authorization
+	Connection    *websocket.Conn
+	Manager       *Manager
+	Status        int
+	send          chan Message
+	// replay carries the auth success and missed messages to writePump, which writes them before
+	// any live message. It holds one batch, as a client authenticates once.
+	replay chan []Message
+	// topics the client subscribed to, see topic.websocket.go
+	topics      map[string]bool
+	topicsMutex sync.RWMutex
 }
 
 type Manager struct {
@@ -64,7 +73,8 @@ type Manager struct {
 	config               config.Config
 	log                  logger.Logger
 	eventBus             *events.EventBus
-	zitadelService       ZitadelService
+	tokenValidator       TokenValidator
+	authorizationService *services.AuthorizationService
 	userRepo             repositories.UserRepository
 	orchestrationService *services.OrchestrationService
 }
@@ -89,7 +99,8 @@ func New(
 		config:               config,
 		log:                  log,
 		eventBus:             eventBus,
-		zitadelService:       services.Zitadel,
+		tokenValidator:       services.Auth,
+		authorizationService: services.Authorization,
 		userRepo:             repos.User,
 		orchestrationService: services.Orchestration,
 	}
@@ -113,6 +124,8 @@ func (m *Manager) HandleWebSocket(c *websocket.Conn) {
 		Manager:    m,
 		Status:     STATUS_UNAUTHENTICATED,
 		send:       make(chan Message, SEND_CHANNEL_SIZE),
+		replay:     make(chan []Message, 1),
+		topics:     make(map[string]bool),
 	}
 
 	if err := client.sendAuthRequest(); err != nil {
@@ -148,6 +161,8 @@ func (m *Manager) BroadcastMessage(message Message) {
 	}
 }
 
+// sendToAdminUsers sends dump pipeline updates to the clients whose users may manage dumps, and
+// to the subscribers of the message's topic
 func (m *Manager) sendToAdminUsers(message Message) {
 	log := m.log.Function("sendToAdminUsers")
 	m.hub.mutex.RLock()
@@ -165,20 +180,13 @@ func (m *Manager) sendToAdminUsers(message Message) {
 			continue
 		}
 
-		user, err := m.userRepo.GetByID(context.Background(), m.db.Primary, client.UserID)
-		if err != nil || user == nil {
-			log.Warn("Failed to get user for admin check", "userID", client.UserID, "error", err)
-			continue
-		}
-
-		if user.IsAdmin {
+		if client.Authorization.Can(models.PermissionDumpsManage) {
 			totalAdmins++
-			select {
-			case client.send <- message:
+			if m.deliver(client, message) {
 				sentCount++
-			default:
-				log.Warn("Admin client send channel full", "clientID", client.ID)
 			}
+		} else if client.subscribedTo(message.Topic) {
+			m.deliver(client, message)
 		}
 	}
 
@@ -241,6 +249,8 @@ func (c *Client) routeMessage(message Message) {
 	switch message.Event {
 	case API_RESPONSE:
 		c.handleAPIResponse(message)
+	case SUBSCRIBE, UNSUBSCRIBE:
+		c.handleSubscription(message)
 	default:
 		log.Warn("Unknown message event", "event", message.Event)
 	}
@@ -263,19 +273,34 @@ func (c *Client) writePump() {
 	}()
 
 	for {
+		// The replay is queued before the client can receive live messages, checking it first
+		// keeps missed messages ahead of the live ones
 		select {
-		case message, ok := <-c.send:
-			if err := c.Connection.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT)); err != nil {
-				log.Er("failed to set write deadline", err, "clientID", c.ID)
+		case messages := <-c.replay:
+			if !c.writeMessages(log, messages) {
+				return
+			}
+			continue
+		default:
+		}
+
+		select {
+		case messages := <-c.replay:
+			if !c.writeMessages(log, messages) {
+				return
 			}
+
+		case message, ok := <-c.send:
 			if !ok {
+				if err := c.Connection.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT)); err != nil {
+					log.Er("failed to set write deadline", err, "clientID", c.ID)
+				}
 				log.Info("Channel closed", "clientID", c.ID)
 				_ = c.Connection.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
 
-			if err := c.Connection.WriteJSON(message); err != nil {
-				log.Er("WebSocket write error", err, "clientID", c.ID, "message", message)
+			if !c.writeMessages(log, []Message{message}) {
 				return
 			}
 
@@ -290,6 +315,20 @@ func (c *Client) writePump() {
 	}
 }
 
+// writeMessages writes messages in order and reports whether the connection is still usable
+func (c *Client) writeMessages(log logger.Logger, messages []Message) bool {
+	for _, message := range messages {
+		if err := c.Connection.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT)); err != nil {
+			log.Er("failed to set write deadline", err, "clientID", c.ID)
+		}
+		if err := c.Connection.WriteJSON(message); err != nil {
+			log.Er("WebSocket write error", err, "clientID", c.ID, "message", message)
+			return false
+		}
+	}
+	return true
+}
+
 func (m *Manager) subscribeToEventBus() {
 	log := m.log.Function("subscribeToEventBus")
 	log.Info("Starting WebSocket events subscription")
@@ -302,6 +341,8 @@ func (m *Manager) subscribeToEventBus() {
 			m.sendToSpecificUser(event.Message)
 		case "admin":
 			m.sendToAdminUsers(event.Message)
+		case "topic":
+			m.sendToTopic(event.Message)
 		default:
 			log.Warn("Unknown WebSocket event type", "event", event.Event)
 		}
@@ -342,40 +383,63 @@ func (m *Manager) sendToSpecificUser(message Message) {
 		return
 	}
 
-	// Find the client for this specific user
-	var targetClient *Client
+	m.hub.mutex.RLock()
+	defer m.hub.mutex.RUnlock()
+
+	// Send to every connection the user has open, such as several tabs, and to the subscribers
+	// of the message's topic
+	connections := 0
 	for _, client := range m.hub.clients {
-		if client.Status == STATUS_AUTHENTICATED && client.UserID == userID {
-			targetClient = client
-			break
+		if client.Status != STATUS_AUTHENTICATED {
+			continue
+		}
+		if client.UserID == userID {
+			connections++
+			m.deliver(client, message)
+		} else if client.subscribedTo(message.Topic) {
+			m.deliver(client, message)
 		}
 	}
 
-	if targetClient == nil {
-		log.Warn(
-			"User not found or not connected",
+	if connections == 0 {
+		// Kept in the user's stream, the client replays it when it reconnects
+		log.Info(
+			"User not connected",
 			"messageID",
 			message.ID,
 			"userID",
 			message.UserID,
 		)
-		return
 	}
+}
+
+// deliver queues the message for the client without blocking. A client too slow to keep up with
+// sequenced messages is disconnected, it reconnects and replays what it missed instead of
+// silently losing them.
+func (m *Manager) deliver(client *Client, message Message) bool {
+	log := m.log.Function("deliver")
 
-	// Send message to the specific user's client
 	select {
-	case targetClient.send <- message:
+	case client.send <- message:
+		return true
 	default:
-		log.Warn(
-			"User's send channel full, dropping message",
-			"messageID",
-			message.ID,
-			"userID",
-			message.UserID,
-			"clientID",
-			targetClient.ID,
-		)
 	}
+
+	if message.Sequence == "" {
+		log.Warn("Client send channel full, dropping message",
+			"messageID", message.ID,
+			"clientID", client.ID)
+		return false
+	}
+
+	log.Warn("Client send channel full, disconnecting for replay",
+		"messageID", message.ID,
+		"sequence", message.Sequence,
+		"clientID", client.ID)
+	if err := client.Connection.Close(); err != nil {
+		log.Er("failed to close slow client", err, "clientID", client.ID)
+	}
+	return false
 }
 
 // handleAPIResponse processes API responses from the client and routes them to the orchestration service